@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/applicationconfig"
+	"github.com/batnoter/batnoter-api/internal/audit"
+	"github.com/batnoter/batnoter-api/internal/db"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// purgeUsersCmd represents the purgeusers command
+var purgeUsersCmd = &cobra.Command{
+	Use:          "purgeusers",
+	Short:        "Permanently deletes the deleted user accounts",
+	Long:         "Permanently deletes the data of the user accounts deleted before the configured grace period. The server also runs it periodically",
+	SilenceUsage: true, // do not print usage info in case of error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logrus.Info("starting purge of deleted users")
+
+		db, err := db.Connect(conf.Database)
+		if err != nil {
+			return err
+		}
+		applicationconfig := applicationconfig.NewApplicationConfig(conf, db)
+		count, err := purgeDeletedUsers(applicationconfig)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("purge of deleted users completed. purged users: %d", count)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgeUsersCmd)
+}
+
+// purgeDeletedUsers permanently deletes the users deleted before the grace period & records it in their audit trail.
+// It returns the count of purged users.
+func purgeDeletedUsers(applicationconfig *applicationconfig.ApplicationConfig) (int, error) {
+	deletedBefore := time.Now().Add(-applicationconfig.Config.App.AccountDeletionGracePeriod).UTC()
+	userIDs, err := applicationconfig.UserService.Purge(deletedBefore)
+	// users purged before the failure are recorded as well
+	for _, userID := range userIDs {
+		if err := applicationconfig.AuditService.Record(0, userID, audit.ActionUserPurged, ""); err != nil {
+			logrus.WithField("user-id", userID).WithError(err).Warn("recording purge of user failed")
+		}
+	}
+	return len(userIDs), err
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/batnoter/batnoter-api/internal/applicationconfig"
+	"github.com/batnoter/batnoter-api/internal/db"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// reencryptCmd represents the reencrypt command
+var reencryptCmd = &cobra.Command{
+	Use:          "reencrypt",
+	Short:        "Re-encrypts stored github tokens",
+	Long:         "Encrypts the stored github tokens of all the linked identities with the primary encryption key. Run it after adding a new primary key or to encrypt the tokens stored as plaintext",
+	SilenceUsage: true, // do not print usage info in case of error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logrus.Info("starting github token re-encryption")
+
+		db, err := db.Connect(conf.Database)
+		if err != nil {
+			return err
+		}
+		applicationconfig := applicationconfig.NewApplicationConfig(conf, db)
+		count, err := applicationconfig.UserService.ReEncryptTokens()
+		if err != nil {
+			return err
+		}
+		logrus.Infof("github token re-encryption completed. re-encrypted tokens: %d", count)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reencryptCmd)
+}
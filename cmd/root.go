@@ -60,6 +60,10 @@ func initConfig() {
 	e := enviper.New(viper.New())
 	e.SetDefault("httpserver.host", "0.0.0.0")
 	e.SetDefault("httpserver.port", "8080")
+	e.SetDefault("app.accesstokenttl", "15m")
+	e.SetDefault("app.refreshtokenttl", "720h")
+	e.SetDefault("attachments.folder", "attachments")
+	e.SetDefault("attachments.maxsize", 10<<20)
 
 	var cfgFile string
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .config.yaml)")
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/batnoter/batnoter-api/internal/applicationconfig"
 	"github.com/batnoter/batnoter-api/internal/db"
 	"github.com/batnoter/batnoter-api/internal/httpservice"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -18,10 +21,40 @@ var serveCmd = &cobra.Command{
 			return err
 		}
 		applicationconfig := applicationconfig.NewApplicationConfig(conf, db)
+		if err := applicationconfig.ExportService.ResumeUnfinished(); err != nil {
+			logrus.WithError(err).Error("resuming unfinished exports failed")
+		}
+		if err := applicationconfig.ImportService.ResumeUnfinished(); err != nil {
+			logrus.WithError(err).Error("resuming unfinished imports failed")
+		}
+		go runPeriodically(maintenanceInterval, func() {
+			count, err := purgeDeletedUsers(applicationconfig)
+			if err != nil {
+				logrus.WithError(err).Error("purge of deleted users failed")
+			} else if count > 0 {
+				logrus.Infof("purge of deleted users completed. purged users: %d", count)
+			}
+		})
+		go runPeriodically(maintenanceInterval, func() {
+			if _, err := applicationconfig.ExportService.DeleteExpired(); err != nil {
+				logrus.WithError(err).Error("deleting expired exports failed")
+			}
+		})
 		return httpservice.Run(applicationconfig)
 	},
 }
 
+// maintenanceInterval is the interval in which the maintenance tasks (e.g. purge of deleted users) run while the server is running.
+const maintenanceInterval = time.Hour
+
+func runPeriodically(interval time.Duration, task func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		task()
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(serveCmd)
 
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// signingkeyCmd represents the signingkey command
+var signingkeyCmd = &cobra.Command{
+	Use:          "signingkey",
+	Short:        "Generates a new app token signing key",
+	Long:         "Generates a new PEM encoded private key which can be added to the signing keys config. Supported algorithms are EdDSA & RS256",
+	SilenceUsage: true, // do not print usage info in case of error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		alg, _ := cmd.Flags().GetString("alg")
+
+		var privateKey interface{}
+		var err error
+		switch alg {
+		case "EdDSA":
+			_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+		case "RS256":
+			privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		default:
+			return fmt.Errorf("unsupported algorithm: %s", alg)
+		}
+		if err != nil {
+			return err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+		if err != nil {
+			return err
+		}
+		return pem.Encode(os.Stdout, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(signingkeyCmd)
+	signingkeyCmd.Flags().String("alg", "EdDSA", "signing algorithm of the key (EdDSA or RS256)")
+}
@@ -1,11 +1,27 @@
 package applicationconfig
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/attachment"
+	"github.com/batnoter/batnoter-api/internal/audit"
 	"github.com/batnoter/batnoter-api/internal/auth"
 	"github.com/batnoter/batnoter-api/internal/config"
+	"github.com/batnoter/batnoter-api/internal/encryption"
+	"github.com/batnoter/batnoter-api/internal/export"
 	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/importer"
+	"github.com/batnoter/batnoter-api/internal/mail"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/batnoter/batnoter-api/internal/notebook"
 	"github.com/batnoter/batnoter-api/internal/preference"
+	"github.com/batnoter/batnoter-api/internal/publish"
+	"github.com/batnoter/batnoter-api/internal/share"
 	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	gh "golang.org/x/oauth2/github"
 	"gorm.io/gorm"
@@ -13,45 +29,194 @@ import (
 
 // ApplicationConfig is an application config store used to store and get the application config & dependencies.
 type ApplicationConfig struct {
-	Config            config.Config
-	DB                *gorm.DB
-	OAuth2Config      oauth2.Config
-	AuthService       auth.Service
-	UserService       user.Service
-	PreferenceService preference.Service
-	GithubService     github.Service
+	Config             config.Config
+	DB                 *gorm.DB
+	OAuth2Config       oauth2.Config
+	AuthService        auth.Service
+	APITokenService    auth.APITokenService
+	AuditService       audit.Service
+	ExportService      export.Service
+	NotesExportService export.NotesService
+	ImportService      importer.Service
+	UserService        user.Service
+	PreferenceService  preference.Service
+	NotebookService    notebook.Service
+	ShareService       share.Service
+	PublishService     publish.Service
+	AttachmentService  attachment.Service
+	GithubService      github.Service
+	GithubAppService   github.AppService
+	TokenService       user.TokenService
+	MarkdownRenderer   markdown.Renderer
 }
 
 // NewApplicationConfig creates and returns an application config store.
 func NewApplicationConfig(config config.Config, db *gorm.DB) *ApplicationConfig {
+	// the broad `repo` scope is not required when notes are accessed with github app installation tokens
+	scopes := []string{"read:user", "user:email", "repo"}
+	if config.OAuth2.Github.App.ID != 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+
 	// create github oauth2 config
 	oauth2Config := oauth2.Config{
 		RedirectURL:  config.OAuth2.Github.RedirectURL,
 		ClientID:     config.OAuth2.Github.ClientID,
 		ClientSecret: config.OAuth2.Github.ClientSecret,
-		Scopes:       []string{"read:user", "user:email", "repo"},
+		Scopes:       scopes,
 		Endpoint:     gh.Endpoint,
 	}
 
+	authRepo := auth.NewRepository(db)
+	sessionRepo := auth.NewSessionRepository(db)
 	authService := auth.NewService(auth.TokenConfig{
-		SecretKey: config.App.SecretKey,
-		Issuer:    "https://batnoter.com",
+		SecretKey:           config.App.SecretKey,
+		SigningKeys:         signingKeys(config.Signing),
+		SecretKeyValidUntil: secretKeyValidUntil(config.Signing),
+		Issuer:              "https://batnoter.com",
+		AccessTokenTTL:      config.App.AccessTokenTTL,
+		RefreshTokenTTL:     config.App.RefreshTokenTTL,
+	}, authRepo, sessionRepo)
+	apiTokenRepo := auth.NewAPITokenRepository(db)
+	apiTokenService := auth.NewAPITokenService(apiTokenRepo)
+	auditRepo := audit.NewRepository(db)
+	auditService := audit.NewService(auditRepo)
+	userRepo := user.NewRepository(db, newEncrypter(config.Encryption))
+	userService := user.NewService(userRepo, user.Admins{
+		Emails:    config.App.Admins.Emails,
+		GithubIDs: config.App.Admins.GithubIDs,
 	})
-	userRepo := user.NewRepository(db)
-	userService := user.NewService(userRepo)
 	preferenceRepo := preference.NewRepository(db)
 	preferenceService := preference.NewService(preferenceRepo)
+	notebookRepo := notebook.NewRepository(db)
+	notebookService := notebook.NewService(notebookRepo)
 
 	githubClientBuilder := github.NewClientBuilder(&oauth2Config)
 	githubService := github.NewService(githubClientBuilder)
+	githubAppService := github.NewAppService(githubAppConfig(config.OAuth2.Github.App), githubClientBuilder)
+	tokenService := user.NewTokenService(&oauth2Config, githubAppService, userService)
+	exportRepo := export.NewRepository(db)
+	mailer := mail.NewMailer(mail.Config{
+		Host:     config.Mail.Host,
+		Port:     config.Mail.Port,
+		Username: config.Mail.Username,
+		Password: config.Mail.Password,
+		From:     config.Mail.From,
+	})
+	exportService := export.NewService(exportRepo, userService, authService, auditService, githubService, tokenService,
+		newArchiveStore(config.Exports), mailer, config.App.ClientURL)
+	markdownRenderer := markdown.NewRenderer()
+	notesExportService := export.NewNotesService(githubService, markdownRenderer)
+	importRepo := importer.NewRepository(db)
+	importService := importer.NewService(importRepo, userService, githubService, tokenService, newUploadStore(config.Imports))
+	publishService := publish.NewService(githubService, markdownRenderer)
+	attachmentRepo := attachment.NewRepository(db)
+	attachmentService := attachment.NewService(attachment.Config{
+		Folder:  config.Attachments.Folder,
+		MaxSize: config.Attachments.MaxSize,
+	}, attachmentRepo, githubService)
+	shareRepo := share.NewRepository(db)
+	shareService := share.NewService(shareRepo)
 
 	return &ApplicationConfig{
-		Config:            config,
-		DB:                db,
-		OAuth2Config:      oauth2Config,
-		AuthService:       authService,
-		UserService:       userService,
-		PreferenceService: preferenceService,
-		GithubService:     githubService,
+		Config:             config,
+		DB:                 db,
+		OAuth2Config:       oauth2Config,
+		AuthService:        authService,
+		APITokenService:    apiTokenService,
+		AuditService:       auditService,
+		ExportService:      exportService,
+		NotesExportService: notesExportService,
+		ImportService:      importService,
+		UserService:        userService,
+		PreferenceService:  preferenceService,
+		NotebookService:    notebookService,
+		ShareService:       shareService,
+		PublishService:     publishService,
+		AttachmentService:  attachmentService,
+		GithubService:      githubService,
+		GithubAppService:   githubAppService,
+		TokenService:       tokenService,
+		MarkdownRenderer:   markdownRenderer,
+	}
+}
+
+func newArchiveStore(exportsConfig config.Exports) export.ArchiveStore {
+	dir := exportsConfig.Dir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "batnoter-exports")
+	}
+	store, err := export.NewFileStore(dir)
+	if err != nil {
+		logrus.Fatal("invalid exports config: ", err)
+	}
+	return store
+}
+
+func newUploadStore(importsConfig config.Imports) importer.UploadStore {
+	dir := importsConfig.Dir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "batnoter-imports")
+	}
+	// the uploads are stored as files like the export archives
+	store, err := export.NewFileStore(dir)
+	if err != nil {
+		logrus.Fatal("invalid imports config: ", err)
+	}
+	return store
+}
+
+func newEncrypter(encryptionConfig config.Encryption) encryption.Encrypter {
+	// key ids are lowercased since the config keys are case insensitive
+	keys := make(map[string]string, len(encryptionConfig.Keys))
+	for keyID, key := range encryptionConfig.Keys {
+		keys[strings.ToLower(keyID)] = key
+	}
+	encrypter, err := encryption.NewEncrypter(encryption.KeyConfig{
+		PrimaryKeyID: strings.ToLower(encryptionConfig.PrimaryKeyID),
+		Keys:         keys,
+	})
+	if err != nil {
+		logrus.Fatal("invalid encryption config: ", err)
+	}
+	return encrypter
+}
+
+func signingKeys(signingConfig config.Signing) auth.SigningKeys {
+	// key ids are lowercased since the config keys are case insensitive
+	keys := make(map[string]string, len(signingConfig.Keys))
+	for keyID, key := range signingConfig.Keys {
+		keys[strings.ToLower(keyID)] = key
+	}
+	signingKeys, err := auth.ParseSigningKeys(strings.ToLower(signingConfig.PrimaryKeyID), keys)
+	if err != nil {
+		logrus.Fatal("invalid signing config: ", err)
+	}
+	return signingKeys
+}
+
+func secretKeyValidUntil(signingConfig config.Signing) time.Time {
+	if signingConfig.SecretKeyValidUntil == "" {
+		return time.Time{}
+	}
+	validUntil, err := time.Parse(time.RFC3339, signingConfig.SecretKeyValidUntil)
+	if err != nil {
+		logrus.Fatal("invalid signing config: ", err)
+	}
+	return validUntil
+}
+
+func githubAppConfig(appConfig config.GithubApp) github.AppConfig {
+	if appConfig.ID == 0 {
+		return github.AppConfig{}
+	}
+	privateKey, err := github.ParseAppPrivateKey(appConfig.PrivateKey)
+	if err != nil {
+		logrus.Fatal("invalid github app private key: ", err)
+	}
+	return github.AppConfig{
+		AppID:      appConfig.ID,
+		Slug:       appConfig.Slug,
+		PrivateKey: privateKey,
 	}
 }
@@ -0,0 +1,373 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	// decoders of the image formats of the attachments
+	_ "image/gif"
+
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrInvalidImage is returned when the uploaded image is malformed & its metadata can not be stripped.
+var ErrInvalidImage = errors.New("image could not be read")
+
+// errImageNotProcessed is returned when the variant of the image can not be generated.
+var errImageNotProcessed = errors.New("image could not be processed")
+
+const (
+	// images larger than these many pixels are not decoded to generate the variants,
+	// the decoded image takes up to 4 bytes per pixel (e.g. 96 MB for the largest image)
+	maxImagePixels = 24_000_000
+
+	// maximum count of the images decoded at once, so the memory used by the concurrent requests stays bounded
+	maxConcurrentDecodes = 2
+
+	exifOrientationTag = 0x0112
+	exifShortType      = 3
+)
+
+// decodeSlots limits the images decoded at once across the requests.
+var decodeSlots = make(chan struct{}, maxConcurrentDecodes)
+
+var (
+	jpegExifHeader = []byte("Exif\x00\x00")
+	pngSignature   = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// variantSpec represents the bounds & the jpeg quality of a variant of the images.
+type variantSpec struct {
+	maxSide int
+	quality int
+}
+
+// variantSpecs are the specs of the variants by their size.
+var variantSpecs = map[string]variantSpec{
+	SizeThumb: {maxSide: 256, quality: 80},
+	SizeWeb:   {maxSide: 1600, quality: 85},
+}
+
+// stripMetadata removes the metadata (e.g. exif with the gps location of the photos, xmp & text comments) from the
+// image without re-encoding it. The orientation of the jpeg images is retained in a minimal exif segment so that
+// the photos are still displayed upright. The images of the other types are returned as is.
+func stripMetadata(contentType string, content []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEGMetadata(content)
+	case "image/png":
+		return stripPNGMetadata(content)
+	case "image/webp":
+		return stripWebPMetadata(content)
+	}
+	return content, nil
+}
+
+// stripJPEGMetadata removes the app1 (exif & xmp), app13 (iptc) & comment segments of the jpeg image.
+// The segments required to display the image correctly (e.g. jfif, icc profile & adobe) are retained.
+func stripJPEGMetadata(content []byte) ([]byte, error) {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil, ErrInvalidImage
+	}
+	out := make([]byte, 0, len(content))
+	out = append(out, 0xFF, 0xD8)
+	var segments []byte
+	orientation := 1
+	i := 2
+	for {
+		if i+2 > len(content) || content[i] != 0xFF {
+			return nil, ErrInvalidImage
+		}
+		marker := content[i+1]
+		switch {
+		case marker == 0xFF:
+			// fill byte preceding the marker
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// the scan data follows the start of scan segment, the rest of the image does not contain metadata
+			if exif := minimalExif(orientation); exif != nil {
+				// jfif requires its app0 segment to be the first segment
+				if len(segments) >= 4 && segments[1] == 0xE0 {
+					app0End := 2 + int(binary.BigEndian.Uint16(segments[2:4]))
+					out = append(out, segments[:app0End]...)
+					segments = segments[app0End:]
+				}
+				out = append(out, exif...)
+			}
+			out = append(out, segments...)
+			return append(out, content[i:]...), nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			segments = append(segments, content[i:i+2]...)
+			i += 2
+			continue
+		}
+		if i+4 > len(content) {
+			return nil, ErrInvalidImage
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:i+4]))
+		if end > len(content) || end < i+4 {
+			return nil, ErrInvalidImage
+		}
+		switch marker {
+		case 0xE1:
+			if payload := content[i+4 : end]; bytes.HasPrefix(payload, jpegExifHeader) {
+				orientation = exifOrientation(payload[len(jpegExifHeader):])
+			}
+		case 0xED, 0xFE:
+		default:
+			segments = append(segments, content[i:end]...)
+		}
+		i = end
+	}
+}
+
+// exifOrientation returns the orientation (1 to 8) of the image from the tiff structure of its exif data.
+// It returns 1 (upright) when the orientation is missing or invalid.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for e := 0; e < count; e++ {
+		entry := offset + 2 + e*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) != exifOrientationTag || order.Uint16(tiff[entry+2:entry+4]) != exifShortType {
+			continue
+		}
+		if orientation := int(order.Uint16(tiff[entry+8 : entry+10])); orientation >= 1 && orientation <= 8 {
+			return orientation
+		}
+		return 1
+	}
+	return 1
+}
+
+// minimalExif returns the app1 segment with the exif data containing only the orientation.
+// It returns nil when the image is upright.
+func minimalExif(orientation int) []byte {
+	if orientation <= 1 {
+		return nil
+	}
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // big endian tiff header with the offset of ifd0
+		0x00, 0x01, // count of the ifd0 entries
+		0x01, 0x12, 0x00, exifShortType, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00, // orientation entry
+		0x00, 0x00, 0x00, 0x00, // offset of the next ifd
+	}
+	payload := append(append([]byte{}, jpegExifHeader...), tiff...)
+	segment := []byte{0xFF, 0xE1, 0x00, 0x00}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// stripPNGMetadata removes the exif, text & time chunks of the png image.
+func stripPNGMetadata(content []byte) ([]byte, error) {
+	if !bytes.HasPrefix(content, pngSignature) {
+		return nil, ErrInvalidImage
+	}
+	out := make([]byte, 0, len(content))
+	out = append(out, pngSignature...)
+	i := len(pngSignature)
+	for i < len(content) {
+		if i+8 > len(content) {
+			return nil, ErrInvalidImage
+		}
+		length := int(binary.BigEndian.Uint32(content[i : i+4]))
+		end := i + 12 + length
+		if length < 0 || end > len(content) || end < i {
+			return nil, ErrInvalidImage
+		}
+		switch string(content[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, content[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// stripWebPMetadata removes the exif & xmp chunks of the webp image & clears their flags in the extended header.
+func stripWebPMetadata(content []byte) ([]byte, error) {
+	if len(content) < 12 || string(content[:4]) != "RIFF" || string(content[8:12]) != "WEBP" {
+		return nil, ErrInvalidImage
+	}
+	out := make([]byte, 0, len(content))
+	out = append(out, content[:12]...)
+	i := 12
+	for i < len(content) {
+		if i+8 > len(content) {
+			return nil, ErrInvalidImage
+		}
+		size := int(binary.LittleEndian.Uint32(content[i+4 : i+8]))
+		end := i + 8 + size + size%2 // chunks are padded to even size
+		if size < 0 || end > len(content) || end < i {
+			return nil, ErrInvalidImage
+		}
+		switch string(content[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, content[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // exif & xmp flags
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, content[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+// makeVariant generates the variant of the image with the size. The image is scaled down to fit in the bounds of
+// the variant & it is rotated as per its exif orientation since the variants do not contain any metadata.
+// The variants of the jpeg & opaque webp images are encoded as jpeg, the variants of the other images as png.
+// The image is decoded only when one of the decode slots is free, the context error is returned when the context
+// is done while waiting for a slot. It returns the content of the variant along with its content type.
+func makeVariant(ctx context.Context, contentType string, content []byte, size string) ([]byte, string, error) {
+	spec, ok := variantSpecs[size]
+	if !ok {
+		return nil, "", errImageNotProcessed
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || config.Width*config.Height > maxImagePixels {
+		return nil, "", errImageNotProcessed
+	}
+	select {
+	case decodeSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+	defer func() { <-decodeSlots }()
+	src, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", errImageNotProcessed
+	}
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(content)
+	}
+
+	// the image is scaled before it is rotated, so the bounds are swapped for the orientations which transpose the image
+	width, height := fit(src.Bounds().Dx(), src.Bounds().Dy(), spec.maxSide)
+	if orientation >= 5 {
+		width, height = fit(src.Bounds().Dy(), src.Bounds().Dx(), spec.maxSide)
+		width, height = height, width
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), src, src.Bounds(), draw.Src, nil)
+	dst := orient(scaled, orientation)
+
+	buf := new(bytes.Buffer)
+	opaque, _ := src.(interface{ Opaque() bool })
+	if contentType == "image/jpeg" || (contentType == "image/webp" && opaque != nil && opaque.Opaque()) {
+		if err := jpeg.Encode(buf, dst, &jpeg.Options{Quality: spec.quality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(buf, dst); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// jpegOrientation returns the exif orientation of the jpeg image.
+func jpegOrientation(content []byte) int {
+	i := 2
+	for i+4 <= len(content) && content[i] == 0xFF {
+		marker := content[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(content[i+2:i+4]))
+		if end > len(content) {
+			break
+		}
+		if payload := content[i+4 : end]; marker == 0xE1 && bytes.HasPrefix(payload, jpegExifHeader) {
+			return exifOrientation(payload[len(jpegExifHeader):])
+		}
+		i = end
+	}
+	return 1
+}
+
+// fit returns the dimensions of the image scaled down to fit in the square with the side retaining its aspect ratio.
+// The images smaller than the square are not scaled up.
+func fit(width int, height int, side int) (int, int) {
+	if width <= side && height <= side {
+		return width, height
+	}
+	if width >= height {
+		return side, max(1, height*side/width)
+	}
+	return max(1, width*side/height), side
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// orient returns the image transformed as per the exif orientation so that it is displayed upright.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if orientation >= 5 {
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // flipped horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // flipped vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, img.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
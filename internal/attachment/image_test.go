@@ -0,0 +1,269 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripMetadata(t *testing.T) {
+	t.Run("should strip the exif of the jpeg image retaining its orientation", func(t *testing.T) {
+		original := withExif(encodeJPEG(40, 20), exifWithGPS(6))
+		assert.Contains(t, string(original), "GPS-LOCATION")
+
+		stripped, err := stripMetadata("image/jpeg", original)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(stripped), "GPS-LOCATION")
+		assert.Equal(t, 6, jpegOrientation(stripped))
+		assert.Equal(t, []byte{0xFF, 0xD8, 0xFF, 0xE0}, stripped[:4], "jfif segment must be the first segment")
+		img, err := jpeg.Decode(bytes.NewReader(stripped))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+	})
+
+	t.Run("should strip the exif of the upright jpeg image completely", func(t *testing.T) {
+		stripped, err := stripMetadata("image/jpeg", withExif(encodeJPEG(8, 8), exifWithGPS(1)))
+		assert.NoError(t, err)
+		assert.NotContains(t, string(stripped), "Exif")
+		assert.Equal(t, encodeJPEG(8, 8), stripped)
+	})
+
+	t.Run("should strip the text & exif chunks of the png image", func(t *testing.T) {
+		original := encodePNG(4, 4)
+		original = insertPNGChunk(original, "tEXt", []byte("Comment\x00GPS-LOCATION"))
+		original = insertPNGChunk(original, "eXIf", []byte("MM\x00\x2aGPS-LOCATION"))
+
+		stripped, err := stripMetadata("image/png", original)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(stripped), "GPS-LOCATION")
+		assert.Equal(t, encodePNG(4, 4), stripped)
+	})
+
+	t.Run("should strip the exif & xmp chunks of the webp image", func(t *testing.T) {
+		vp8x := []byte{0x08 | 0x04, 0, 0, 0, 3, 0, 0, 3, 0, 0}
+		original := riff(chunk("VP8X", vp8x), chunk("VP8L", []byte("image")), chunk("EXIF", []byte("GPS-LOCATION")), chunk("XMP ", []byte("<x/>")))
+
+		stripped, err := stripMetadata("image/webp", original)
+		assert.NoError(t, err)
+		assert.Equal(t, riff(chunk("VP8X", []byte{0, 0, 0, 0, 3, 0, 0, 3, 0, 0}), chunk("VP8L", []byte("image"))), stripped)
+	})
+
+	t.Run("should return error when the image is malformed", func(t *testing.T) {
+		for contentType, content := range map[string][]byte{
+			"image/jpeg": encodeJPEG(8, 8)[:20],
+			"image/png":  encodePNG(8, 8)[:20],
+			"image/webp": riff(chunk("VP8L", []byte("image")))[:18],
+		} {
+			_, err := stripMetadata(contentType, content)
+			assert.ErrorIs(t, err, ErrInvalidImage, contentType)
+		}
+	})
+
+	t.Run("should return the images of the other types as is", func(t *testing.T) {
+		content := []byte("GIF89a")
+		stripped, err := stripMetadata("image/gif", content)
+		assert.NoError(t, err)
+		assert.Equal(t, content, stripped)
+	})
+}
+
+func TestMakeVariant(t *testing.T) {
+	t.Run("should scale the image down to fit in the bounds of the variant", func(t *testing.T) {
+		content, contentType, err := makeVariant(context.Background(), "image/png", encodePNG(1000, 500), SizeThumb)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", contentType)
+		img, err := png.Decode(bytes.NewReader(content))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 256, 128), img.Bounds())
+	})
+
+	t.Run("should not scale up the image smaller than the bounds of the variant", func(t *testing.T) {
+		content, contentType, err := makeVariant(context.Background(), "image/jpeg", encodeJPEG(300, 200), SizeWeb)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/jpeg", contentType)
+		img, err := jpeg.Decode(bytes.NewReader(content))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 300, 200), img.Bounds())
+	})
+
+	t.Run("should rotate the image as per its exif orientation", func(t *testing.T) {
+		// the image is rotated 90° clockwise when displayed
+		original, err := stripMetadata("image/jpeg", withExif(encodeJPEG(600, 300), exifWithGPS(6)))
+		assert.NoError(t, err)
+
+		content, _, err := makeVariant(context.Background(), "image/jpeg", original, SizeThumb)
+		assert.NoError(t, err)
+		img, err := jpeg.Decode(bytes.NewReader(content))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 128, 256), img.Bounds())
+		assert.Equal(t, 1, jpegOrientation(content))
+		// the red left half of the image is at the top after the rotation
+		r, _, b, _ := img.At(64, 32).RGBA()
+		assert.Greater(t, r, b)
+		r, _, b, _ = img.At(64, 224).RGBA()
+		assert.Greater(t, b, r)
+	})
+
+	t.Run("should return error when the image can not be decoded", func(t *testing.T) {
+		_, _, err := makeVariant(context.Background(), "image/png", []byte("not an image"), SizeThumb)
+		assert.ErrorIs(t, err, errImageNotProcessed)
+	})
+
+	t.Run("should not decode the image larger than the maximum pixels", func(t *testing.T) {
+		// the header of the png image declares the dimensions, the pixels are not needed to reject it
+		content := encodePNG(1, 1)
+		binary.BigEndian.PutUint32(content[16:], 6000)
+		binary.BigEndian.PutUint32(content[20:], 4001)
+		binary.BigEndian.PutUint32(content[29:], crc32.ChecksumIEEE(content[12:29]))
+		_, _, err := makeVariant(context.Background(), "image/png", content, SizeThumb)
+		assert.ErrorIs(t, err, errImageNotProcessed)
+	})
+
+	t.Run("should return error when the context is done while all the decode slots are taken", func(t *testing.T) {
+		for i := 0; i < maxConcurrentDecodes; i++ {
+			decodeSlots <- struct{}{}
+		}
+		defer func() {
+			for i := 0; i < maxConcurrentDecodes; i++ {
+				<-decodeSlots
+			}
+		}()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err := makeVariant(ctx, "image/png", encodePNG(16, 16), SizeThumb)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestOrient(t *testing.T) {
+	t.Run("should transform the pixels as per the exif orientation", func(t *testing.T) {
+		// 2x1 image with the red pixel on the left & the blue pixel on the right
+		img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+		red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+		img.SetRGBA(0, 0, red)
+		img.SetRGBA(1, 0, blue)
+
+		tests := map[int][]color.RGBA{ // pixels of the oriented image in row-major order
+			1: {red, blue},
+			2: {blue, red},
+			3: {blue, red},
+			4: {red, blue},
+			5: {red, blue},
+			6: {red, blue},
+			7: {blue, red},
+			8: {blue, red},
+		}
+		for orientation, expected := range tests {
+			oriented := orient(img, orientation)
+			var pixels []color.RGBA
+			for y := 0; y < oriented.Bounds().Dy(); y++ {
+				for x := 0; x < oriented.Bounds().Dx(); x++ {
+					pixels = append(pixels, oriented.RGBAAt(x, y))
+				}
+			}
+			assert.Equal(t, expected, pixels, orientation)
+			if orientation >= 5 {
+				assert.Equal(t, image.Rect(0, 0, 1, 2), oriented.Bounds(), orientation)
+			}
+		}
+	})
+}
+
+// encodePNG returns the png image whose left half is red & right half is blue.
+func encodePNG(width int, height int) []byte {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, halves(width, height)); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+// encodeJPEG returns the jfif image whose left half is red & right half is blue.
+func encodeJPEG(width int, height int) []byte {
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, halves(width, height), nil); err != nil {
+		panic(err)
+	}
+	// go encoder does not write the jfif segment written by the cameras & the other encoders
+	jfif := []byte{0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00, 0x01, 0x01, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00}
+	return append(append([]byte{0xFF, 0xD8}, jfif...), buf.Bytes()[2:]...)
+}
+
+func halves(width int, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// exifWithGPS returns the little endian exif data with the orientation & a fake gps location in the image description.
+func exifWithGPS(orientation int) []byte {
+	description := []byte("GPS-LOCATION\x00")
+	tiff := []byte{'I', 'I', 0x2A, 0x00, 0x08, 0x00, 0x00, 0x00, 0x02, 0x00}
+	tiff = append(tiff, 0x0E, 0x01, 0x02, 0x00, byte(len(description)), 0x00, 0x00, 0x00, 38, 0x00, 0x00, 0x00) // image description
+	tiff = append(tiff, 0x12, 0x01, 0x03, 0x00, 0x01, 0x00, 0x00, 0x00, byte(orientation), 0x00, 0x00, 0x00)    // orientation
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+	tiff = append(tiff, description...)
+	return append([]byte("Exif\x00\x00"), tiff...)
+}
+
+// withExif inserts the app1 segment with the exif data after the jfif segment of the jpeg image.
+func withExif(content []byte, exif []byte) []byte {
+	app0End := 4 + int(binary.BigEndian.Uint16(content[4:6]))
+	segment := []byte{0xFF, 0xE1, 0x00, 0x00}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(exif)+2))
+	out := append([]byte{}, content[:app0End]...)
+	out = append(out, segment...)
+	out = append(out, exif...)
+	return append(out, content[app0End:]...)
+}
+
+// insertPNGChunk inserts the chunk after the header chunk of the png image.
+func insertPNGChunk(content []byte, chunkType string, data []byte) []byte {
+	ihdrEnd := 8 + 12 + int(binary.BigEndian.Uint32(content[8:12]))
+	c := make([]byte, 4, 12+len(data))
+	binary.BigEndian.PutUint32(c, uint32(len(data)))
+	c = append(c, chunkType...)
+	c = append(c, data...)
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(append([]byte(chunkType), data...)))
+	c = append(c, checksum...)
+	out := append([]byte{}, content[:ihdrEnd]...)
+	out = append(out, c...)
+	return append(out, content[ihdrEnd:]...)
+}
+
+func riff(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	out := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+	return append(out, body...)
+}
+
+func chunk(fourCC string, data []byte) []byte {
+	c := append([]byte(fourCC), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(c[4:], uint32(len(data)))
+	c = append(c, data...)
+	if len(data)%2 == 1 {
+		c = append(c, 0)
+	}
+	return c
+}
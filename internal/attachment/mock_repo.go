@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+
+// Package attachment is a generated GoMock package.
+package attachment
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepo is a mock of Repo interface.
+type MockRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder
+}
+
+// MockRepoMockRecorder is the mock recorder for MockRepo.
+type MockRepoMockRecorder struct {
+	mock *MockRepo
+}
+
+// NewMockRepo creates a new mock instance.
+func NewMockRepo(ctrl *gomock.Controller) *MockRepo {
+	mock := &MockRepo{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
+	return m.recorder
+}
+
+// DeleteVariants mocks base method.
+func (m *MockRepo) DeleteVariants(userID uint, blobSHA string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariants", userID, blobSHA)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVariants indicates an expected call of DeleteVariants.
+func (mr *MockRepoMockRecorder) DeleteVariants(userID, blobSHA interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariants", reflect.TypeOf((*MockRepo)(nil).DeleteVariants), userID, blobSHA)
+}
+
+// GetVariant mocks base method.
+func (m *MockRepo) GetVariant(userID uint, blobSHA, size string) (Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariant", userID, blobSHA, size)
+	ret0, _ := ret[0].(Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariant indicates an expected call of GetVariant.
+func (mr *MockRepoMockRecorder) GetVariant(userID, blobSHA, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariant", reflect.TypeOf((*MockRepo)(nil).GetVariant), userID, blobSHA, size)
+}
+
+// SaveVariant mocks base method.
+func (m *MockRepo) SaveVariant(variant Variant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVariant", variant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVariant indicates an expected call of SaveVariant.
+func (mr *MockRepoMockRecorder) SaveVariant(variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVariant", reflect.TypeOf((*MockRepo)(nil).SaveVariant), variant)
+}
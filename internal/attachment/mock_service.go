@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package attachment is a generated GoMock package.
+package attachment
+
+import (
+	context "context"
+	reflect "reflect"
+
+	github "github.com/batnoter/batnoter-api/internal/github"
+	gomock "github.com/golang/mock/gomock"
+	oauth2 "golang.org/x/oauth2"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockService) Delete(ctx context.Context, ghToken oauth2.Token, repoUserID uint, repoDetails github.GitRepoProps, name, authorName, authorEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, ghToken, repoUserID, repoDetails, name, authorName, authorEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockServiceMockRecorder) Delete(ctx, ghToken, repoUserID, repoDetails, name, authorName, authorEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), ctx, ghToken, repoUserID, repoDetails, name, authorName, authorEmail)
+}
+
+// Get mocks base method.
+func (m *MockService) Get(ctx context.Context, ghToken oauth2.Token, repoUserID uint, repoDetails github.GitRepoProps, name, size string) (Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, ghToken, repoUserID, repoDetails, name, size)
+	ret0, _ := ret[0].(Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockServiceMockRecorder) Get(ctx, ghToken, repoUserID, repoDetails, name, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), ctx, ghToken, repoUserID, repoDetails, name, size)
+}
+
+// MaxSize mocks base method.
+func (m *MockService) MaxSize() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxSize")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// MaxSize indicates an expected call of MaxSize.
+func (mr *MockServiceMockRecorder) MaxSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxSize", reflect.TypeOf((*MockService)(nil).MaxSize))
+}
+
+// Upload mocks base method.
+func (m *MockService) Upload(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, content []byte, authorName, authorEmail string) (Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", ctx, ghToken, repoDetails, content, authorName, authorEmail)
+	ret0, _ := ret[0].(Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockServiceMockRecorder) Upload(ctx, ghToken, repoDetails, content, authorName, authorEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockService)(nil).Upload), ctx, ghToken, repoDetails, content, authorName, authorEmail)
+}
@@ -0,0 +1,34 @@
+package attachment
+
+import "time"
+
+// Attachment represents a file (e.g. image or pdf) attached to the notes & stored in the notes repo.
+// Name is the content hash of the file along with the extension of its detected content type, so the same file
+// uploaded twice is stored only once. Content is only set when the attachment is retrieved.
+type Attachment struct {
+	Name        string
+	Path        string
+	SHA         string // this is a blob sha (not commit sha)
+	ContentType string
+	Size        int
+	Content     []byte
+}
+
+// Variant represents an entity model used to store & retrieve the derived variants (e.g. thumbnails) of the image
+// attachments to/from database. The variants are cached for the user owning the repo of the source image by its blob sha
+// along with their size, so the variants are purged along with the user.
+type Variant struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint
+
+	BlobSHA     string
+	Size        string
+	ContentType string
+	Content     []byte
+}
+
+// TableName returns the database table name of the attachment variants.
+func (Variant) TableName() string {
+	return "attachment_variants"
+}
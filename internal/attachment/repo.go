@@ -0,0 +1,58 @@
+package attachment
+
+import (
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repo represents an attachment variant repository.
+// It provides methods to store & retrieve the cached variants of the image attachments from the database.
+//go:generate mockgen -source=repo.go -package=attachment -destination=mock_repo.go
+type Repo interface {
+	GetVariant(userID uint, blobSHA string, size string) (Variant, error)
+	SaveVariant(variant Variant) error
+	DeleteVariants(userID uint, blobSHA string) error
+}
+
+type repoImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates and returns a new instance of attachment variant repository.
+func NewRepository(db *gorm.DB) Repo {
+	return &repoImpl{
+		db: db,
+	}
+}
+
+// GetVariant returns the variant record of the source image blob of a user with the size.
+// The variant is empty when it is not cached yet.
+func (r *repoImpl) GetVariant(userID uint, blobSHA string, size string) (Variant, error) {
+	var variant Variant
+	err := r.db.Where("user_id = ? and blob_sha = ? and size = ?", userID, blobSHA, size).First(&variant).Error
+	if err == gorm.ErrRecordNotFound {
+		return Variant{}, nil
+	}
+	if err != nil {
+		return variant, errors.Wrap(err, "retrieving attachment variant from database failed")
+	}
+	return variant, nil
+}
+
+// SaveVariant stores a given variant record to database.
+// The variant generated concurrently by another request is retained when it is stored first.
+func (r *repoImpl) SaveVariant(variant Variant) error {
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&variant).Error; err != nil {
+		return errors.Wrap(err, "storing attachment variant to database failed")
+	}
+	return nil
+}
+
+// DeleteVariants deletes all the variant records of the source image blob of a user.
+func (r *repoImpl) DeleteVariants(userID uint, blobSHA string) error {
+	if err := r.db.Where("user_id = ? and blob_sha = ?", userID, blobSHA).Delete(&Variant{}).Error; err != nil {
+		return errors.Wrap(err, "deleting attachment variants from database failed")
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package attachment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// ErrAttachmentNotFound is returned when the attachment does not exist in the repo.
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
+// ErrEmptyAttachment is returned when the uploaded attachment has no content.
+var ErrEmptyAttachment = errors.New("attachment is empty")
+
+// ErrAttachmentTooLarge is returned when the uploaded attachment is larger than the configured maximum size.
+var ErrAttachmentTooLarge = errors.New("attachment is too large")
+
+// ErrUnsupportedType is returned when the content type detected from the uploaded attachment is not supported.
+var ErrUnsupportedType = errors.New("attachment type is not supported")
+
+// ErrVariantNotSupported is returned when the variant of an attachment which is not an image is requested.
+var ErrVariantNotSupported = errors.New("variants are only available for images")
+
+const (
+	// SizeThumb is the size of the thumbnails of the images, fitting in a square of 256 pixels.
+	SizeThumb = "thumb"
+
+	// SizeWeb is the size of the web optimized images, fitting in a square of 1600 pixels.
+	SizeWeb = "web"
+)
+
+// Sizes are the sizes of the variants generated from the images.
+var Sizes = []interface{}{SizeThumb, SizeWeb}
+
+// ValidNameRegex validates the name of the attachments, the sha256 hash of the content followed by the extension.
+const ValidNameRegex = `^[a-f0-9]{64}\.[a-z]+$`
+
+// extensions are the extensions of the attachments by their supported content types.
+// The svg images are not supported since they can contain scripts.
+var extensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"application/pdf": ".pdf",
+}
+
+// contentTypes are the content types of the attachments by their extension.
+var contentTypes = func() map[string]string {
+	types := make(map[string]string, len(extensions))
+	for contentType, ext := range extensions {
+		types[ext] = contentType
+	}
+	return types
+}()
+
+var validName = regexp.MustCompile(ValidNameRegex)
+
+// Config represents the configuration of the attachments.
+// Folder is the folder of the repo the attachments are stored in & MaxSize is the maximum size of an attachment in bytes.
+type Config struct {
+	Folder  string
+	MaxSize int64
+}
+
+// Service represents an attachment service.
+// It stores the files attached to the notes in the attachments folder of the notes repo. The metadata of the images is
+// stripped before they are stored & the resized variants of the images are generated when they are retrieved.
+//go:generate mockgen -source=service.go -package=attachment -destination=mock_service.go
+type Service interface {
+	MaxSize() int64
+	Upload(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, content []byte, authorName string, authorEmail string) (Attachment, error)
+	Get(ctx context.Context, ghToken oauth2.Token, repoUserID uint, repoDetails github.GitRepoProps, name string, size string) (Attachment, error)
+	Delete(ctx context.Context, ghToken oauth2.Token, repoUserID uint, repoDetails github.GitRepoProps, name string, authorName string,
+		authorEmail string) error
+}
+
+type service struct {
+	config        Config
+	repo          Repo
+	githubService github.Service
+}
+
+// NewService creates and returns a new attachment service.
+func NewService(config Config, repo Repo, githubService github.Service) Service {
+	return &service{
+		config:        config,
+		repo:          repo,
+		githubService: githubService,
+	}
+}
+
+// MaxSize returns the maximum size of an attachment in bytes.
+func (s *service) MaxSize() int64 {
+	return s.config.MaxSize
+}
+
+// Upload stores the attachment in the attachments folder of the repo. The content type is detected from the content,
+// the content type declared by the client is not trusted. The metadata (e.g. gps location) of the images is stripped &
+// the attachment is named after the sha256 hash of the stripped content, the attachment which already exists in the
+// repo is returned without committing it again. ErrInvalidImage is returned when the image is malformed.
+// It returns the stored attachment without its content along with any error occurred while storing it.
+func (s *service) Upload(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, content []byte,
+	authorName string, authorEmail string) (Attachment, error) {
+	if len(content) == 0 {
+		return Attachment{}, ErrEmptyAttachment
+	}
+	if int64(len(content)) > s.config.MaxSize {
+		return Attachment{}, ErrAttachmentTooLarge
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil {
+		return Attachment{}, ErrUnsupportedType
+	}
+	ext, ok := extensions[contentType]
+	if !ok {
+		return Attachment{}, ErrUnsupportedType
+	}
+	if content, err = stripMetadata(contentType, content); err != nil {
+		return Attachment{}, err
+	}
+	hash := sha256.Sum256(content)
+	name := hex.EncodeToString(hash[:]) + ext
+	attachment := Attachment{
+		Name:        name,
+		Path:        s.path(name),
+		ContentType: contentType,
+		Size:        len(content),
+	}
+
+	fileProps := github.GitFileProps{Path: attachment.Path, RepoDetails: repoDetails}
+	existing, err := s.githubService.StatFile(ctx, ghToken, fileProps)
+	if err == nil {
+		attachment.SHA = existing.SHA
+		return attachment, nil
+	}
+	if !errors.Is(err, github.ErrFileNotFound) {
+		return Attachment{}, err
+	}
+	fileProps.Content = string(content)
+	fileProps.AuthorName = authorName
+	fileProps.AuthorEmail = authorEmail
+	gitFile, err := s.githubService.SaveFile(ctx, ghToken, fileProps)
+	if err != nil {
+		return Attachment{}, err
+	}
+	attachment.SHA = gitFile.SHA
+	return attachment, nil
+}
+
+// Get retrieves the attachment along with its content from the attachments folder of the repo.
+// The variant of the image with the size is returned when the size is provided, the original is returned otherwise.
+// The variants are generated when they are requested for the first time & cached for the user owning the repo by the
+// blob sha of the image, the original is returned when the variant of the image can not be generated.
+// ErrAttachmentNotFound is returned when the attachment does not exist or its name is invalid &
+// ErrVariantNotSupported is returned when the variant of an attachment which is not an image is requested.
+func (s *service) Get(ctx context.Context, ghToken oauth2.Token, repoUserID uint, repoDetails github.GitRepoProps, name string,
+	size string) (Attachment, error) {
+	attachment, err := s.stat(ctx, ghToken, repoDetails, name)
+	if err != nil {
+		return Attachment{}, err
+	}
+	if size != "" {
+		if _, ok := variantSpecs[size]; !ok || !strings.HasPrefix(attachment.ContentType, "image/") {
+			return Attachment{}, ErrVariantNotSupported
+		}
+		variant, err := s.repo.GetVariant(repoUserID, attachment.SHA, size)
+		if err != nil {
+			return Attachment{}, err
+		}
+		if variant.ID != 0 {
+			return withVariant(attachment, variant), nil
+		}
+	}
+	content, err := s.githubService.GetBlob(ctx, ghToken, github.GitFileProps{SHA: attachment.SHA, Path: attachment.Path, RepoDetails: repoDetails})
+	if err != nil {
+		return Attachment{}, err
+	}
+	attachment.Content = content
+	if size == "" {
+		return attachment, nil
+	}
+
+	variantContent, contentType, err := makeVariant(ctx, attachment.ContentType, content, size)
+	if ctx.Err() != nil {
+		return Attachment{}, ctx.Err()
+	}
+	if err != nil {
+		// the images which can not be decoded (e.g. too large images) are served as is
+		logrus.WithField("blob-sha", attachment.SHA).WithField("size", size).WithError(err).Warn("generating attachment variant failed")
+		return attachment, nil
+	}
+	variant := Variant{UserID: repoUserID, BlobSHA: attachment.SHA, Size: size, ContentType: contentType, Content: variantContent}
+	if err := s.repo.SaveVariant(variant); err != nil {
+		// the variant is generated again on the next request
+		logrus.WithField("blob-sha", attachment.SHA).WithField("size", size).WithError(err).Warn("caching attachment variant failed")
+	}
+	return withVariant(attachment, variant), nil
+}
+
+// Delete deletes the attachment from the attachments folder of the repo along with the cached variants of the image.
+// The notes referencing the attachment are not updated.
+// ErrAttachmentNotFound is returned when the attachment does not exist or its name is invalid.
+func (s *service) Delete(ctx context.Context, ghToken oauth2.Token, repoUserID uint, repoDetails github.GitRepoProps, name string,
+	authorName string, authorEmail string) error {
+	attachment, err := s.stat(ctx, ghToken, repoDetails, name)
+	if err != nil {
+		return err
+	}
+	err = s.githubService.DeleteFile(ctx, ghToken, github.GitFileProps{
+		SHA:         attachment.SHA,
+		Path:        attachment.Path,
+		AuthorName:  authorName,
+		AuthorEmail: authorEmail,
+		RepoDetails: repoDetails,
+	})
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteVariants(repoUserID, attachment.SHA)
+}
+
+// stat returns the attachment with the name without its content.
+func (s *service) stat(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, name string) (Attachment, error) {
+	contentType, ok := contentTypes[path.Ext(name)]
+	if !validName.MatchString(name) || !ok {
+		return Attachment{}, ErrAttachmentNotFound
+	}
+	gitFile, err := s.githubService.StatFile(ctx, ghToken, github.GitFileProps{Path: s.path(name), RepoDetails: repoDetails})
+	if errors.Is(err, github.ErrFileNotFound) {
+		return Attachment{}, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return Attachment{}, err
+	}
+	return Attachment{
+		Name:        name,
+		Path:        gitFile.Path,
+		SHA:         gitFile.SHA,
+		ContentType: contentType,
+		Size:        gitFile.Size,
+	}, nil
+}
+
+// withVariant returns the attachment with the content & the content type of its variant.
+func withVariant(attachment Attachment, variant Variant) Attachment {
+	attachment.ContentType = variant.ContentType
+	attachment.Content = variant.Content
+	attachment.Size = len(variant.Content)
+	return attachment
+}
+
+func (s *service) path(name string) string {
+	return path.Join(s.config.Folder, name)
+}
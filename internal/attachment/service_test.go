@@ -0,0 +1,265 @@
+package attachment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+const repoUserID = uint(1012)
+
+var (
+	ghToken     = oauth2.Token{AccessToken: "gho_token"}
+	repoDetails = github.GitRepoProps{Repository: "notes", DefaultBranch: "main", Owner: "johndoe"}
+	config      = Config{Folder: "attachments", MaxSize: 1 << 10}
+	pngContent  = encodePNG(2, 2)
+	pngName     = hash(pngContent) + ".png"
+	pngPath     = "attachments/" + pngName
+)
+
+func TestUpload(t *testing.T) {
+	t.Run("should store the attachment named after the hash of its content", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, github.GitFileProps{Path: pngPath, RepoDetails: repoDetails}).
+			Return(github.GitFile{}, github.ErrFileNotFound)
+		mockGithubService.EXPECT().SaveFile(gomock.Any(), ghToken, github.GitFileProps{Path: pngPath, Content: string(pngContent),
+			AuthorName: "John Doe", AuthorEmail: "john@example.com", RepoDetails: repoDetails}).Return(github.GitFile{SHA: "blobsha", Path: pngPath}, nil)
+
+		attachment, err := service.Upload(context.Background(), ghToken, repoDetails, pngContent, "John Doe", "john@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, Attachment{Name: pngName, Path: pngPath, SHA: "blobsha", ContentType: "image/png", Size: len(pngContent)}, attachment)
+	})
+
+	t.Run("should store the photo without its exif metadata", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		photo := withExif(encodeJPEG(8, 8), exifWithGPS(1))
+		stripped := encodeJPEG(8, 8)
+		strippedPath := "attachments/" + hash(stripped) + ".jpg"
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, github.GitFileProps{Path: strippedPath, RepoDetails: repoDetails}).
+			Return(github.GitFile{}, github.ErrFileNotFound)
+		mockGithubService.EXPECT().SaveFile(gomock.Any(), ghToken, github.GitFileProps{Path: strippedPath, Content: string(stripped),
+			AuthorName: "John Doe", AuthorEmail: "john@example.com", RepoDetails: repoDetails}).Return(github.GitFile{SHA: "blobsha", Path: strippedPath}, nil)
+
+		attachment, err := service.Upload(context.Background(), ghToken, repoDetails, photo, "John Doe", "john@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, Attachment{Name: hash(stripped) + ".jpg", Path: strippedPath, SHA: "blobsha", ContentType: "image/jpeg", Size: len(stripped)}, attachment)
+	})
+
+	t.Run("should return error when the image is malformed", func(t *testing.T) {
+		service := NewService(config, nil, nil)
+
+		_, err := service.Upload(context.Background(), ghToken, repoDetails, pngContent[:30], "John Doe", "john@example.com")
+		assert.ErrorIs(t, err, ErrInvalidImage)
+	})
+
+	t.Run("should return the existing attachment without storing it again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, github.GitFileProps{Path: pngPath, RepoDetails: repoDetails}).
+			Return(github.GitFile{SHA: "blobsha", Path: pngPath, Size: len(pngContent)}, nil)
+
+		attachment, err := service.Upload(context.Background(), ghToken, repoDetails, pngContent, "John Doe", "john@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "blobsha", attachment.SHA)
+		assert.Equal(t, pngPath, attachment.Path)
+	})
+
+	t.Run("should detect the content type from the content", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		pdf := []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{}, github.ErrFileNotFound)
+		mockGithubService.EXPECT().SaveFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{SHA: "blobsha"}, nil)
+
+		attachment, err := service.Upload(context.Background(), ghToken, repoDetails, pdf, "John Doe", "john@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, hash(pdf)+".pdf", attachment.Name)
+		assert.Equal(t, "application/pdf", attachment.ContentType)
+	})
+
+	t.Run("should return error when the content type is not supported", func(t *testing.T) {
+		service := NewService(config, nil, nil)
+
+		_, err := service.Upload(context.Background(), ghToken, repoDetails, []byte(`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`), "John Doe", "john@example.com")
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+		_, err = service.Upload(context.Background(), ghToken, repoDetails, []byte("plain text"), "John Doe", "john@example.com")
+		assert.ErrorIs(t, err, ErrUnsupportedType)
+	})
+
+	t.Run("should return error when the attachment is empty or too large", func(t *testing.T) {
+		service := NewService(config, nil, nil)
+
+		_, err := service.Upload(context.Background(), ghToken, repoDetails, nil, "John Doe", "john@example.com")
+		assert.ErrorIs(t, err, ErrEmptyAttachment)
+		_, err = service.Upload(context.Background(), ghToken, repoDetails, append(pngContent, make([]byte, 1<<10)...), "John Doe", "john@example.com")
+		assert.ErrorIs(t, err, ErrAttachmentTooLarge)
+	})
+
+	t.Run("should return error when storing the attachment fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{}, github.ErrFileNotFound)
+		mockGithubService.EXPECT().SaveFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{}, errors.New("some error"))
+
+		_, err := service.Upload(context.Background(), ghToken, repoDetails, pngContent, "John Doe", "john@example.com")
+		assert.Error(t, err)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("should return the attachment along with its content", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, github.GitFileProps{Path: pngPath, RepoDetails: repoDetails}).
+			Return(github.GitFile{SHA: "blobsha", Path: pngPath, Size: len(pngContent)}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "blobsha", Path: pngPath, RepoDetails: repoDetails}).
+			Return(pngContent, nil)
+
+		attachment, err := service.Get(context.Background(), ghToken, repoUserID, repoDetails, pngName, "")
+		assert.NoError(t, err)
+		assert.Equal(t, Attachment{Name: pngName, Path: pngPath, SHA: "blobsha", ContentType: "image/png", Size: len(pngContent), Content: pngContent}, attachment)
+	})
+
+	t.Run("should generate & cache the variant of the image", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, mockRepo, mockGithubService)
+		large := encodePNG(512, 512)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, github.GitFileProps{Path: pngPath, RepoDetails: repoDetails}).
+			Return(github.GitFile{SHA: "blobsha", Path: pngPath, Size: len(large)}, nil)
+		mockRepo.EXPECT().GetVariant(repoUserID, "blobsha", SizeThumb).Return(Variant{}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "blobsha", Path: pngPath, RepoDetails: repoDetails}).
+			Return(large, nil)
+		var cached Variant
+		mockRepo.EXPECT().SaveVariant(gomock.Any()).DoAndReturn(func(variant Variant) error {
+			cached = variant
+			return nil
+		})
+
+		attachment, err := service.Get(context.Background(), ghToken, repoUserID, repoDetails, pngName, SizeThumb)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", attachment.ContentType)
+		assert.Equal(t, len(attachment.Content), attachment.Size)
+		assert.Less(t, attachment.Size, len(large))
+		assert.Equal(t, Variant{UserID: repoUserID, BlobSHA: "blobsha", Size: SizeThumb, ContentType: "image/png", Content: attachment.Content}, cached)
+	})
+
+	t.Run("should return the cached variant of the image without fetching the image", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, mockRepo, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{SHA: "blobsha", Path: pngPath, Size: 4096}, nil)
+		mockRepo.EXPECT().GetVariant(repoUserID, "blobsha", SizeWeb).Return(Variant{ID: 3, BlobSHA: "blobsha", Size: SizeWeb, ContentType: "image/png", Content: []byte("web")}, nil)
+
+		attachment, err := service.Get(context.Background(), ghToken, repoUserID, repoDetails, pngName, SizeWeb)
+		assert.NoError(t, err)
+		assert.Equal(t, Attachment{Name: pngName, Path: pngPath, SHA: "blobsha", ContentType: "image/png", Size: 3, Content: []byte("web")}, attachment)
+	})
+
+	t.Run("should return the original image when its variant can not be generated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, mockRepo, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{SHA: "blobsha", Path: pngPath, Size: 9}, nil)
+		mockRepo.EXPECT().GetVariant(repoUserID, "blobsha", SizeThumb).Return(Variant{}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, gomock.Any()).Return([]byte("corrupted"), nil)
+
+		attachment, err := service.Get(context.Background(), ghToken, repoUserID, repoDetails, pngName, SizeThumb)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("corrupted"), attachment.Content)
+		assert.Equal(t, "image/png", attachment.ContentType)
+	})
+
+	t.Run("should return error when the variant of a pdf document is requested", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		pdfPath := "attachments/" + hash([]byte("%PDF")) + ".pdf"
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{SHA: "blobsha", Path: pdfPath}, nil)
+
+		_, err := service.Get(context.Background(), ghToken, repoUserID, repoDetails, hash([]byte("%PDF"))+".pdf", SizeThumb)
+		assert.ErrorIs(t, err, ErrVariantNotSupported)
+	})
+
+	t.Run("should return not found error when the attachment does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{}, github.ErrFileNotFound)
+
+		_, err := service.Get(context.Background(), ghToken, repoUserID, repoDetails, pngName, "")
+		assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	})
+
+	t.Run("should return not found error when the name is invalid", func(t *testing.T) {
+		service := NewService(config, nil, nil)
+
+		for _, name := range []string{"../notes.md", "screenshot.png", hash(pngContent) + ".svg", hash(pngContent)} {
+			_, err := service.Get(context.Background(), ghToken, repoUserID, repoDetails, name, "")
+			assert.ErrorIs(t, err, ErrAttachmentNotFound, name)
+		}
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("should delete the attachment using its blob sha along with its variants", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, mockRepo, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, github.GitFileProps{Path: pngPath, RepoDetails: repoDetails}).
+			Return(github.GitFile{SHA: "blobsha", Path: pngPath}, nil)
+		mockGithubService.EXPECT().DeleteFile(gomock.Any(), ghToken, github.GitFileProps{SHA: "blobsha", Path: pngPath,
+			AuthorName: "John Doe", AuthorEmail: "john@example.com", RepoDetails: repoDetails}).Return(nil)
+		mockRepo.EXPECT().DeleteVariants(repoUserID, "blobsha").Return(nil)
+
+		err := service.Delete(context.Background(), ghToken, repoUserID, repoDetails, pngName, "John Doe", "john@example.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return not found error when the attachment does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(config, nil, mockGithubService)
+		mockGithubService.EXPECT().StatFile(gomock.Any(), ghToken, gomock.Any()).Return(github.GitFile{}, github.ErrFileNotFound)
+
+		err := service.Delete(context.Background(), ghToken, repoUserID, repoDetails, pngName, "John Doe", "john@example.com")
+		assert.ErrorIs(t, err, ErrAttachmentNotFound)
+	})
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package audit
+
+import "time"
+
+const (
+	// ActionUserDisabled is recorded when a user account is disabled.
+	ActionUserDisabled = "user.disabled"
+
+	// ActionUserEnabled is recorded when a disabled user account is re-enabled.
+	ActionUserEnabled = "user.enabled"
+
+	// ActionUserDeleted is recorded when a user account is deleted.
+	ActionUserDeleted = "user.deleted"
+
+	// ActionUserDeletionRequested is recorded when a user requests the deletion of own account.
+	ActionUserDeletionRequested = "user.deletion_requested"
+
+	// ActionUserPurged is recorded when the data of a deleted user account is permanently deleted.
+	ActionUserPurged = "user.purged"
+
+	// ActionSessionsRevoked is recorded when all the sessions of a user are revoked.
+	ActionSessionsRevoked = "user.sessions_revoked"
+)
+
+// Event represents an entity model used to store & retrieve audit events to/from database.
+// ActorID is the user who performed the action & UserID is the user the action was performed on.
+// ActorID is zero for the actions performed by the system.
+// Audit events are never updated or deleted.
+type Event struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	ActorID uint
+	UserID  uint
+	Action  string
+	Reason  string
+}
+
+// TableName returns the database table name of the audit events.
+func (Event) TableName() string {
+	return "audit_events"
+}
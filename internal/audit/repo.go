@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Repo represents an audit event repository.
+// It provides methods to store & retrieve the audit events from the database.
+//go:generate mockgen -source=repo.go -package=audit -destination=mock_repo.go
+type Repo interface {
+	Save(event Event) error
+	GetAllByUserID(userID uint) ([]Event, error)
+}
+
+type repoImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates and returns a new instance of audit event repository.
+func NewRepository(db *gorm.DB) Repo {
+	return &repoImpl{
+		db: db,
+	}
+}
+
+// Save stores a given audit event record to database.
+func (r *repoImpl) Save(event Event) error {
+	if err := r.db.Create(&event).Error; err != nil {
+		return errors.Wrap(err, "storing audit event to database failed")
+	}
+	return nil
+}
+
+// GetAllByUserID returns the audit event records of a user, latest first.
+func (r *repoImpl) GetAllByUserID(userID uint) ([]Event, error) {
+	var events []Event
+	if err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&events).Error; err != nil {
+		return nil, errors.Wrap(err, "retrieving user's audit events from database failed")
+	}
+	return events, nil
+}
@@ -0,0 +1,40 @@
+package audit
+
+import "github.com/sirupsen/logrus"
+
+// Service represents an audit service.
+// It provides methods to record & retrieve the audit trail of the actions performed on user accounts.
+//go:generate mockgen -source=service.go -package=audit -destination=mock_service.go
+type Service interface {
+	Record(actorID uint, userID uint, action string, reason string) error
+	GetByUserID(userID uint) ([]Event, error)
+}
+
+type service struct {
+	repo Repo
+}
+
+// NewService creates and returns a new audit service.
+func NewService(repo Repo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// Record stores an audit event of the action performed by the actor on the user.
+// It returns any error occurred while storing the event.
+func (s *service) Record(actorID uint, userID uint, action string, reason string) error {
+	logrus.WithField("actor-id", actorID).WithField("user-id", userID).WithField("action", action).Info("recording audit event")
+	return s.repo.Save(Event{
+		ActorID: actorID,
+		UserID:  userID,
+		Action:  action,
+		Reason:  reason,
+	})
+}
+
+// GetByUserID retrieves the audit trail of the user with given user id.
+// It returns the audit events along with any error occurred while retrieving them.
+func (s *service) GetByUserID(userID uint) ([]Event, error) {
+	return s.repo.GetAllByUserID(userID)
+}
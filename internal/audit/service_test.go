@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	actorID = uint(1001)
+	userID  = uint(1234)
+)
+
+func TestRecord(t *testing.T) {
+	t.Run("should store the audit event of the action", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Save(Event{ActorID: actorID, UserID: userID, Action: ActionUserDisabled, Reason: "spam"}).Return(nil)
+
+		err := service.Record(actorID, userID, ActionUserDisabled, "spam")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when storing the audit event fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Save(gomock.Any()).Return(errors.New("some error"))
+
+		err := service.Record(actorID, userID, ActionUserEnabled, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetByUserID(t *testing.T) {
+	t.Run("should retrieve the audit trail of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		events := []Event{{ID: 1, ActorID: actorID, UserID: userID, Action: ActionUserDisabled, Reason: "spam"}}
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetAllByUserID(userID).Return(events, nil)
+
+		result, err := service.GetByUserID(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, events, result)
+	})
+}
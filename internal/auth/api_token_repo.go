@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// APITokenRepo represents an api token repository.
+// It provides methods to store, retrieve & revoke the user generated api tokens from the database.
+//go:generate mockgen -source=api_token_repo.go -package=auth -destination=mock_api_token_repo.go
+type APITokenRepo interface {
+	Save(apiToken APIToken) (APIToken, error)
+	Get(apiTokenID uint) (APIToken, error)
+	GetByHash(tokenHash string) (APIToken, error)
+	GetAllByUserID(userID uint) ([]APIToken, error)
+	Touch(apiTokenID uint) error
+	Revoke(apiTokenID uint) error
+}
+
+type apiTokenRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository creates and returns a new instance of api token repository.
+func NewAPITokenRepository(db *gorm.DB) APITokenRepo {
+	return &apiTokenRepoImpl{
+		db: db,
+	}
+}
+
+// Save stores a given api token record to database.
+func (r *apiTokenRepoImpl) Save(apiToken APIToken) (APIToken, error) {
+	if err := r.db.Save(&apiToken).Error; err != nil {
+		return apiToken, errors.Wrap(err, "storing api token to database failed")
+	}
+	return apiToken, nil
+}
+
+// Get returns an api token record by api-token-id.
+func (r *apiTokenRepoImpl) Get(apiTokenID uint) (APIToken, error) {
+	var apiToken APIToken
+	err := r.db.Where("id = ?", apiTokenID).First(&apiToken).Error
+	if err == gorm.ErrRecordNotFound {
+		return APIToken{}, nil
+	}
+	if err != nil {
+		return apiToken, errors.Wrap(err, "retrieving api token from database failed")
+	}
+	return apiToken, nil
+}
+
+// GetByHash returns an api token record matching the provided token hash.
+func (r *apiTokenRepoImpl) GetByHash(tokenHash string) (APIToken, error) {
+	var apiToken APIToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&apiToken).Error
+	if err == gorm.ErrRecordNotFound {
+		return APIToken{}, nil
+	}
+	if err != nil {
+		return apiToken, errors.Wrap(err, "retrieving api token from database failed")
+	}
+	return apiToken, nil
+}
+
+// GetAllByUserID returns the api tokens of a user which are not revoked.
+func (r *apiTokenRepoImpl) GetAllByUserID(userID uint) ([]APIToken, error) {
+	var apiTokens []APIToken
+	err := r.db.Where("user_id = ? and revoked_at is null", userID).Order("created_at desc").Find(&apiTokens).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving user's api tokens from database failed")
+	}
+	return apiTokens, nil
+}
+
+// Touch updates the last used time of an api token record matching provided api-token-id.
+func (r *apiTokenRepoImpl) Touch(apiTokenID uint) error {
+	if err := r.db.Model(&APIToken{}).Where("id = ?", apiTokenID).UpdateColumn("last_used_at", time.Now().UTC()).Error; err != nil {
+		return errors.Wrap(err, "updating api token last used time in database failed")
+	}
+	return nil
+}
+
+// Revoke marks an api token record matching provided api-token-id as revoked in database.
+func (r *apiTokenRepoImpl) Revoke(apiTokenID uint) error {
+	if err := r.db.Model(&APIToken{}).Where("id = ? and revoked_at is null", apiTokenID).Update("revoked_at", time.Now().UTC()).Error; err != nil {
+		return errors.Wrap(err, "revoking api token in database failed")
+	}
+	return nil
+}
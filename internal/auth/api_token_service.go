@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// APITokenPrefix is the prefix of the user generated api tokens.
+// It is used to distinguish the api tokens from the jwt tokens.
+const APITokenPrefix = "bnp_"
+
+// last used time of an api token is updated at most once in this interval to avoid a db write on every request
+const apiTokenTouchInterval = time.Minute
+
+// ErrInvalidAPIToken is returned when the api token is unknown, expired or revoked.
+var ErrInvalidAPIToken = errors.New("invalid api token")
+
+// ErrAPITokenNotFound is returned when the api token to be revoked does not exist for the user.
+var ErrAPITokenNotFound = errors.New("api token not found")
+
+// APITokenService represents an api token service.
+// It provides methods to create, validate & revoke the user generated api tokens used by scripts & cli clients.
+//go:generate mockgen -source=api_token_service.go -package=auth -destination=mock_api_token_service.go
+type APITokenService interface {
+	Create(userID uint, name string, scopes []string, expiresAt *time.Time) (APIToken, string, error)
+	GetAll(userID uint) ([]APIToken, error)
+	Revoke(userID uint, apiTokenID uint) error
+	Validate(token string) (APIToken, error)
+}
+
+type apiTokenService struct {
+	repo APITokenRepo
+}
+
+// NewAPITokenService creates and returns a new api token service.
+func NewAPITokenService(repo APITokenRepo) APITokenService {
+	return &apiTokenService{
+		repo: repo,
+	}
+}
+
+// Create generates a new api token for a user & stores the hash of the token.
+// It returns the stored api token record along with the plain token which is never stored & can not be retrieved later.
+func (a *apiTokenService) Create(userID uint, name string, scopes []string, expiresAt *time.Time) (APIToken, string, error) {
+	token, err := generateOpaqueToken()
+	if err != nil {
+		return APIToken{}, "", err
+	}
+	token = APITokenPrefix + token
+	apiToken, err := a.repo.Save(APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(token),
+		Scopes:    JoinScopes(scopes),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return APIToken{}, "", err
+	}
+	return apiToken, token, nil
+}
+
+// GetAll returns the api tokens of a user which are not revoked.
+func (a *apiTokenService) GetAll(userID uint) ([]APIToken, error) {
+	return a.repo.GetAllByUserID(userID)
+}
+
+// Revoke revokes an api token of a user.
+func (a *apiTokenService) Revoke(userID uint, apiTokenID uint) error {
+	apiToken, err := a.repo.Get(apiTokenID)
+	if err != nil {
+		return err
+	}
+	if apiToken.ID == 0 || apiToken.UserID != userID {
+		return ErrAPITokenNotFound
+	}
+	return a.repo.Revoke(apiToken.ID)
+}
+
+// Validate checks the validity of given api token & records its last used time.
+// It returns the api token record along with any error occurred while validating the token.
+func (a *apiTokenService) Validate(token string) (APIToken, error) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	apiToken, err := a.repo.GetByHash(hashToken(token))
+	if err != nil {
+		return APIToken{}, err
+	}
+	if apiToken.ID == 0 || apiToken.RevokedAt != nil {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	if apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(time.Now()) {
+		return APIToken{}, ErrInvalidAPIToken
+	}
+	if apiToken.LastUsedAt == nil || time.Since(*apiToken.LastUsedAt) > apiTokenTouchInterval {
+		if err := a.repo.Touch(apiToken.ID); err != nil {
+			// failing to record the last used time should not fail the request
+			logrus.WithField("api-token-id", apiToken.ID).WithError(err).Warn("updating api token last used time failed")
+		}
+	}
+	return apiToken, nil
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIToken(t *testing.T) {
+	t.Run("should create api token & store the hash of the token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		var stored APIToken
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(apiToken APIToken) (APIToken, error) {
+			stored = apiToken
+			apiToken.ID = 31
+			return apiToken, nil
+		})
+
+		apiToken, token, err := service.Create(userID, "cron", []string{ScopeNotesRead, ScopeNotesWrite}, nil)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token, APITokenPrefix))
+		assert.Equal(t, uint(31), apiToken.ID)
+		assert.Equal(t, hashToken(token), stored.TokenHash)
+		assert.Equal(t, "notes:read notes:write", stored.Scopes)
+		assert.Equal(t, userID, stored.UserID)
+	})
+
+	t.Run("should return error when storing api token fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		mockRepo.EXPECT().Save(gomock.Any()).Return(APIToken{}, errors.New("some error"))
+
+		_, _, err := service.Create(userID, "cron", []string{ScopeNotesRead}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateAPIToken(t *testing.T) {
+	t.Run("should return api token & update last used time when the token is valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		apiToken := validAPIToken()
+		mockRepo.EXPECT().GetByHash(hashToken(apiTokenString)).Return(apiToken, nil)
+		mockRepo.EXPECT().Touch(apiToken.ID).Return(nil)
+
+		result, err := service.Validate(apiTokenString)
+		assert.NoError(t, err)
+		assert.Equal(t, apiToken, result)
+	})
+
+	t.Run("should return error when the token is expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		apiToken := validAPIToken()
+		expiresAt := time.Now().Add(-time.Minute)
+		apiToken.ExpiresAt = &expiresAt
+		mockRepo.EXPECT().GetByHash(hashToken(apiTokenString)).Return(apiToken, nil)
+
+		_, err := service.Validate(apiTokenString)
+		assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	})
+
+	t.Run("should return error when the token is revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		apiToken := validAPIToken()
+		revokedAt := time.Now()
+		apiToken.RevokedAt = &revokedAt
+		mockRepo.EXPECT().GetByHash(hashToken(apiTokenString)).Return(apiToken, nil)
+
+		_, err := service.Validate(apiTokenString)
+		assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	})
+
+	t.Run("should return error when the token does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		mockRepo.EXPECT().GetByHash(hashToken(apiTokenString)).Return(APIToken{}, nil)
+
+		_, err := service.Validate(apiTokenString)
+		assert.ErrorIs(t, err, ErrInvalidAPIToken)
+	})
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	t.Run("should revoke the api token of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		apiToken := validAPIToken()
+		mockRepo.EXPECT().Get(apiToken.ID).Return(apiToken, nil)
+		mockRepo.EXPECT().Revoke(apiToken.ID).Return(nil)
+
+		err := service.Revoke(userID, apiToken.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when the api token belongs to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockAPITokenRepo(ctrl)
+		service := NewAPITokenService(mockRepo)
+		apiToken := validAPIToken()
+		mockRepo.EXPECT().Get(apiToken.ID).Return(apiToken, nil)
+
+		err := service.Revoke(userID+1, apiToken.ID)
+		assert.ErrorIs(t, err, ErrAPITokenNotFound)
+	})
+}
+
+const apiTokenString = "bnp_dGhpcy1pcy1hbi1hcGktdG9rZW4"
+
+func validAPIToken() APIToken {
+	apiToken := APIToken{
+		UserID:    userID,
+		Name:      "cron",
+		TokenHash: hashToken(apiTokenString),
+		Scopes:    ScopeNotesRead,
+	}
+	apiToken.ID = 31
+	return apiToken
+}
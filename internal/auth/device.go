@@ -0,0 +1,61 @@
+package auth
+
+import "strings"
+
+// maxUserAgentLength is the size of the user_agent column of the sessions table.
+const maxUserAgentLength = 512
+
+// known browsers & operating systems, ordered by the precedence of their user agent tokens.
+// e.g. edge & chrome user agents also contain "Safari".
+var (
+	browsers = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+	operatingSystems = []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// deviceName returns a human readable device name like "Chrome on macOS" derived from the user agent.
+func deviceName(userAgent string) string {
+	browser := match(userAgent, browsers)
+	os := match(userAgent, operatingSystems)
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+	return "Unknown device"
+}
+
+// truncateUserAgent truncates the user agent to the characters which fit in the sessions table.
+func truncateUserAgent(userAgent string) string {
+	runes := []rune(userAgent)
+	if len(runes) <= maxUserAgentLength {
+		return userAgent
+	}
+	return string(runes[:maxUserAgentLength])
+}
+
+func match(userAgent string, candidates []struct{ token, name string }) string {
+	for _, c := range candidates {
+		if strings.Contains(userAgent, c.token) {
+			return c.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: api_token_repo.go
+
+// Package auth is a generated GoMock package.
+package auth
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAPITokenRepo is a mock of APITokenRepo interface.
+type MockAPITokenRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPITokenRepoMockRecorder
+}
+
+// MockAPITokenRepoMockRecorder is the mock recorder for MockAPITokenRepo.
+type MockAPITokenRepoMockRecorder struct {
+	mock *MockAPITokenRepo
+}
+
+// NewMockAPITokenRepo creates a new mock instance.
+func NewMockAPITokenRepo(ctrl *gomock.Controller) *MockAPITokenRepo {
+	mock := &MockAPITokenRepo{ctrl: ctrl}
+	mock.recorder = &MockAPITokenRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPITokenRepo) EXPECT() *MockAPITokenRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockAPITokenRepo) Get(apiTokenID uint) (APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", apiTokenID)
+	ret0, _ := ret[0].(APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAPITokenRepoMockRecorder) Get(apiTokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAPITokenRepo)(nil).Get), apiTokenID)
+}
+
+// GetAllByUserID mocks base method.
+func (m *MockAPITokenRepo) GetAllByUserID(userID uint) ([]APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUserID", userID)
+	ret0, _ := ret[0].([]APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUserID indicates an expected call of GetAllByUserID.
+func (mr *MockAPITokenRepoMockRecorder) GetAllByUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockAPITokenRepo)(nil).GetAllByUserID), userID)
+}
+
+// GetByHash mocks base method.
+func (m *MockAPITokenRepo) GetByHash(tokenHash string) (APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", tokenHash)
+	ret0, _ := ret[0].(APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockAPITokenRepoMockRecorder) GetByHash(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockAPITokenRepo)(nil).GetByHash), tokenHash)
+}
+
+// Revoke mocks base method.
+func (m *MockAPITokenRepo) Revoke(apiTokenID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", apiTokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPITokenRepoMockRecorder) Revoke(apiTokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPITokenRepo)(nil).Revoke), apiTokenID)
+}
+
+// Save mocks base method.
+func (m *MockAPITokenRepo) Save(apiToken APIToken) (APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", apiToken)
+	ret0, _ := ret[0].(APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockAPITokenRepoMockRecorder) Save(apiToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockAPITokenRepo)(nil).Save), apiToken)
+}
+
+// Touch mocks base method.
+func (m *MockAPITokenRepo) Touch(apiTokenID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", apiTokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockAPITokenRepoMockRecorder) Touch(apiTokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockAPITokenRepo)(nil).Touch), apiTokenID)
+}
@@ -7,7 +7,7 @@ package auth
 import (
 	reflect "reflect"
 
-	jwt "github.com/dgrijalva/jwt-go"
+	jwt_go "github.com/dgrijalva/jwt-go"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,25 +35,156 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // GenerateToken mocks base method.
-func (m *MockService) GenerateToken(userID uint) (string, error) {
+func (m *MockService) GenerateToken(userID uint, tokenID string, scopes []string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", userID)
+	ret := m.ctrl.Call(m, "GenerateToken", userID, tokenID, scopes)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockServiceMockRecorder) GenerateToken(userID interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GenerateToken(userID, tokenID, scopes interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockService)(nil).GenerateToken), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockService)(nil).GenerateToken), userID, tokenID, scopes)
+}
+
+// GetJWKS mocks base method.
+func (m *MockService) GetJWKS() JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS")
+	ret0, _ := ret[0].(JWKS)
+	return ret0
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockServiceMockRecorder) GetJWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockService)(nil).GetJWKS))
+}
+
+// GetSessionHistory mocks base method.
+func (m *MockService) GetSessionHistory(userID uint) ([]Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionHistory", userID)
+	ret0, _ := ret[0].([]Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionHistory indicates an expected call of GetSessionHistory.
+func (mr *MockServiceMockRecorder) GetSessionHistory(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionHistory", reflect.TypeOf((*MockService)(nil).GetSessionHistory), userID)
+}
+
+// GetSessions mocks base method.
+func (m *MockService) GetSessions(userID uint) ([]Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", userID)
+	ret0, _ := ret[0].([]Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockServiceMockRecorder) GetSessions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockService)(nil).GetSessions), userID)
+}
+
+// IssueTokens mocks base method.
+func (m *MockService) IssueTokens(userID uint, client Client, scopes []string) (Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueTokens", userID, client, scopes)
+	ret0, _ := ret[0].(Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueTokens indicates an expected call of IssueTokens.
+func (mr *MockServiceMockRecorder) IssueTokens(userID, client, scopes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokens", reflect.TypeOf((*MockService)(nil).IssueTokens), userID, client, scopes)
+}
+
+// RefreshTokens mocks base method.
+func (m *MockService) RefreshTokens(refreshToken string, isUserActive UserActiveFunc) (Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokens", refreshToken, isUserActive)
+	ret0, _ := ret[0].(Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshTokens indicates an expected call of RefreshTokens.
+func (mr *MockServiceMockRecorder) RefreshTokens(refreshToken, isUserActive interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokens", reflect.TypeOf((*MockService)(nil).RefreshTokens), refreshToken, isUserActive)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockService) RevokeAllSessions(userID uint) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockServiceMockRecorder) RevokeAllSessions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockService)(nil).RevokeAllSessions), userID)
+}
+
+// RevokeRefreshToken mocks base method.
+func (m *MockService) RevokeRefreshToken(refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockServiceMockRecorder) RevokeRefreshToken(refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockService)(nil).RevokeRefreshToken), refreshToken)
+}
+
+// RevokeSession mocks base method.
+func (m *MockService) RevokeSession(userID, sessionID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockServiceMockRecorder) RevokeSession(userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockService)(nil).RevokeSession), userID, sessionID)
+}
+
+// ValidateSession mocks base method.
+func (m *MockService) ValidateSession(tokenID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateSession", tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateSession indicates an expected call of ValidateSession.
+func (mr *MockServiceMockRecorder) ValidateSession(tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateSession", reflect.TypeOf((*MockService)(nil).ValidateSession), tokenID)
 }
 
 // ValidateToken mocks base method.
-func (m *MockService) ValidateToken(token string) (*jwt.Token, error) {
+func (m *MockService) ValidateToken(token string) (*jwt_go.Token, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ValidateToken", token)
-	ret0, _ := ret[0].(*jwt.Token)
+	ret0, _ := ret[0].(*jwt_go.Token)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
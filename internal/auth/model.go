@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken represents an entity model used to store & retrieve refresh tokens to/from database.
+// Only the hash of the refresh token is stored. All the tokens rotated from the same login share the family id.
+type RefreshToken struct {
+	gorm.Model
+	UserID uint
+
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// Session represents an entity model used to store & retrieve user sessions to/from database.
+// A session is created on every login. TokenID is used as jti claim of the jwt tokens & family id of
+// the refresh tokens issued for the session. Scopes are granted to all the tokens of the session.
+type Session struct {
+	gorm.Model
+	UserID uint
+
+	TokenID    string
+	Device     string
+	UserAgent  string
+	IPAddress  string
+	Scopes     string
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+}
+
+// APIToken represents an entity model used to store & retrieve user generated api tokens to/from database.
+// Only the hash of the token is stored. Scopes are stored as a space separated list.
+type APIToken struct {
+	gorm.Model
+	UserID uint
+
+	Name       string
+	TokenHash  string
+	Scopes     string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
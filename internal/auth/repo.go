@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Repo represents a refresh token repository.
+// It provides methods to store, retrieve & revoke the refresh tokens from the database.
+//go:generate mockgen -source=repo.go -package=auth -destination=mock_repo.go
+type Repo interface {
+	Save(refreshToken RefreshToken) error
+	GetByHash(tokenHash string) (RefreshToken, error)
+	Revoke(refreshTokenID uint) (bool, error)
+	RevokeFamily(familyID string) error
+}
+
+type repoImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates and returns a new instance of refresh token repository.
+func NewRepository(db *gorm.DB) Repo {
+	return &repoImpl{
+		db: db,
+	}
+}
+
+// Save stores a given refresh token record to database.
+func (r *repoImpl) Save(refreshToken RefreshToken) error {
+	if err := r.db.Save(&refreshToken).Error; err != nil {
+		return errors.Wrap(err, "storing refresh token to database failed")
+	}
+	return nil
+}
+
+// GetByHash returns a refresh token record matching the provided token hash.
+func (r *repoImpl) GetByHash(tokenHash string) (RefreshToken, error) {
+	var refreshToken RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&refreshToken).Error
+	if err == gorm.ErrRecordNotFound {
+		return RefreshToken{}, nil
+	}
+	if err != nil {
+		return refreshToken, errors.Wrap(err, "retrieving refresh token from database failed")
+	}
+	return refreshToken, nil
+}
+
+// Revoke marks a refresh token record matching provided id as revoked in database.
+// It returns false if the token was already revoked.
+func (r *repoImpl) Revoke(refreshTokenID uint) (bool, error) {
+	result := r.db.Model(&RefreshToken{}).Where("id = ? and revoked_at is null", refreshTokenID).Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return false, errors.Wrap(result.Error, "revoking refresh token in database failed")
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily marks all the refresh token records of a token family as revoked in database.
+func (r *repoImpl) RevokeFamily(familyID string) error {
+	if err := r.db.Model(&RefreshToken{}).Where("family_id = ? and revoked_at is null", familyID).Update("revoked_at", time.Now().UTC()).Error; err != nil {
+		return errors.Wrap(err, "revoking refresh token family in database failed")
+	}
+	return nil
+}
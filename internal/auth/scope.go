@@ -0,0 +1,61 @@
+package auth
+
+import "strings"
+
+const (
+	// ScopeNotesRead allows read-only access to the notes & user profile.
+	ScopeNotesRead = "notes:read"
+
+	// ScopeNotesWrite allows creating, updating & deleting the notes.
+	ScopeNotesWrite = "notes:write"
+
+	// ScopePreferencesWrite allows updating the user preferences & account settings.
+	ScopePreferencesWrite = "preferences:write"
+
+	// ScopeAdmin allows access to the administrative actions.
+	ScopeAdmin = "admin"
+
+	// ScopeIdentityLink is granted only to the short-lived tokens used to link an identity provider to the account.
+	// It does not allow access to the api, so it is not one of the supported scopes.
+	ScopeIdentityLink = "identity:link"
+)
+
+// Scopes is the list of all the supported scopes.
+var Scopes = []interface{}{ScopeNotesRead, ScopeNotesWrite, ScopePreferencesWrite, ScopeAdmin}
+
+// UserScopes are the scopes granted to the tokens issued on user login.
+var UserScopes = []string{ScopeNotesRead, ScopeNotesWrite, ScopePreferencesWrite}
+
+// impliedScopes are the scopes implicitly granted along with a scope, e.g. the tokens which can modify the notes can also read them.
+var impliedScopes = map[string][]string{
+	ScopeNotesWrite: {ScopeNotesRead},
+	ScopeAdmin:      {ScopeNotesRead},
+}
+
+// HasScopes checks whether the granted space separated list of scopes contains all the required scopes.
+// The scopes implied by the granted scopes are considered granted.
+func HasScopes(granted string, required ...string) bool {
+	grantedScopes := make(map[string]bool)
+	for _, g := range SplitScopes(granted) {
+		grantedScopes[g] = true
+		for _, implied := range impliedScopes[g] {
+			grantedScopes[implied] = true
+		}
+	}
+	for _, r := range required {
+		if !grantedScopes[r] {
+			return false
+		}
+	}
+	return true
+}
+
+// JoinScopes returns the space separated list of scopes, used to store & transmit the scopes.
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// SplitScopes parses the space separated list of scopes.
+func SplitScopes(scopes string) []string {
+	return strings.Fields(scopes)
+}
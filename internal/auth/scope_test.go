@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasScopes(t *testing.T) {
+	t.Run("should return true when all the required scopes are granted", func(t *testing.T) {
+		assert.True(t, HasScopes("notes:read preferences:write", ScopeNotesRead, ScopePreferencesWrite))
+	})
+
+	t.Run("should return false when any of the required scopes is not granted", func(t *testing.T) {
+		assert.False(t, HasScopes("notes:read", ScopeNotesRead, ScopeNotesWrite))
+		assert.False(t, HasScopes("", ScopeNotesRead))
+	})
+
+	t.Run("should grant read access to the tokens with write or admin scope", func(t *testing.T) {
+		assert.True(t, HasScopes("notes:write", ScopeNotesRead))
+		assert.True(t, HasScopes("admin", ScopeNotesRead))
+		assert.False(t, HasScopes("notes:read", ScopeNotesWrite))
+		assert.False(t, HasScopes("preferences:write", ScopeNotesRead))
+	})
+}
@@ -1,46 +1,124 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
+// ErrInvalidRefreshToken is returned when the refresh token is unknown, expired, revoked or reused.
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// ErrInvalidSession is returned when the session of the token is unknown or revoked.
+var ErrInvalidSession = errors.New("invalid session")
+
+// ErrSessionNotFound is returned when the session to be revoked does not exist for the user.
+var ErrSessionNotFound = errors.New("session not found")
+
 // Service represents an auth service.
 // It provides methods to auth token generation and validation etc.
 //go:generate mockgen -source=service.go -package=auth -destination=mock_service.go
 type Service interface {
-	GenerateToken(userID uint) (string, error)
+	GenerateToken(userID uint, tokenID string, scopes []string) (string, error)
 	ValidateToken(token string) (*jwt.Token, error)
+	IssueTokens(userID uint, client Client, scopes []string) (Tokens, error)
+	RefreshTokens(refreshToken string, isUserActive UserActiveFunc) (Tokens, error)
+	RevokeRefreshToken(refreshToken string) error
+	ValidateSession(tokenID string) error
+	GetSessions(userID uint) ([]Session, error)
+	GetSessionHistory(userID uint) ([]Session, error)
+	RevokeSession(userID uint, sessionID uint) error
+	RevokeAllSessions(userID uint) (int, error)
+	GetJWKS() JWKS
 }
 
 // TokenConfig fields will be used to generate & parse the JWT token.
+// AccessTokenTTL is the validity of the JWT token & RefreshTokenTTL is the validity of the refresh token.
+// Tokens are signed with the primary signing key when the signing keys are configured, otherwise with the secret key.
 type TokenConfig struct {
-	SecretKey string
-	Issuer    string
+	SecretKey           string
+	SigningKeys         SigningKeys
+	SecretKeyValidUntil time.Time
+	Issuer              string
+	AccessTokenTTL      time.Duration
+	RefreshTokenTTL     time.Duration
+}
+
+// Tokens represents the access token & the refresh token issued to a user.
+type Tokens struct {
+	AccessToken           string
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// Claims represents the claims of the jwt token.
+// Scope is the space separated list of scopes granted to the token.
+type Claims struct {
+	jwt.StandardClaims
+	Scope string `json:"scope,omitempty"`
+}
+
+// UserActiveFunc reports whether the user with given user id is allowed to access the app.
+type UserActiveFunc func(userID uint) (bool, error)
+
+// Client represents the details of the client a user logs in from. These are stored against the session.
+type Client struct {
+	UserAgent string
+	IPAddress string
 }
 
 type service struct {
 	tokenConfig TokenConfig
+	repo        Repo
+	sessionRepo SessionRepo
 }
 
+const (
+	refreshTokenSize = 32
+
+	// last seen time of a session is updated at most once in this interval to avoid a db write on every request
+	sessionTouchInterval = time.Minute
+)
+
 // NewService creates and returns a new auth service.
-func NewService(tokenConfig TokenConfig) Service {
+func NewService(tokenConfig TokenConfig, repo Repo, sessionRepo SessionRepo) Service {
 	return &service{
 		tokenConfig: tokenConfig,
+		repo:        repo,
+		sessionRepo: sessionRepo,
 	}
 }
 
-// GenerateToken creates and returns a jwt string token for a user id.
+// GenerateToken creates and returns a jwt string token for a user id with the given scopes.
+// The token id is set as jti claim, it identifies the session the token belongs to.
 // It returns jwt token string along with any error occurred while creating the token.
-func (s *service) GenerateToken(userID uint) (string, error) {
-	claims := jwt.StandardClaims{
-		Subject:   strconv.FormatUint(uint64(userID), 10),
-		ExpiresAt: time.Now().Add(time.Hour * 72).Unix(),
-		Issuer:    s.tokenConfig.Issuer,
-		IssuedAt:  time.Now().Unix(),
+func (s *service) GenerateToken(userID uint, tokenID string, scopes []string) (string, error) {
+	claims := Claims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        tokenID,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			ExpiresAt: time.Now().Add(s.tokenConfig.AccessTokenTTL).Unix(),
+			Issuer:    s.tokenConfig.Issuer,
+			IssuedAt:  time.Now().Unix(),
+		},
+		Scope: JoinScopes(scopes),
+	}
+
+	if s.tokenConfig.SigningKeys.Enabled() {
+		// create token with claims & generate signed token using the primary signing key
+		key := s.tokenConfig.SigningKeys.Keys[s.tokenConfig.SigningKeys.PrimaryKeyID]
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.PrivateKey)
 	}
 
 	// create token with claims
@@ -55,14 +133,249 @@ func (s *service) GenerateToken(userID uint) (string, error) {
 }
 
 // ValidateToken checks the validity of given jwt token string.
+// Tokens with key id are verified with the matching signing key. Tokens without key id are verified with the secret key.
+// Once the signing keys are enabled, the tokens signed with the secret key are accepted only until SecretKeyValidUntil,
+// so the tokens issued before switching to the signing keys can remain valid during the migration.
 // It returns a jwt token along with any error occurred while validating the token.
 func (s *service) ValidateToken(tokenString string) (*jwt.Token, error) {
 	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if keyID, ok := token.Header["kid"].(string); ok {
+			key, ok := s.tokenConfig.SigningKeys.Keys[keyID]
+			if !ok {
+				return nil, fmt.Errorf("unknown signing key: %s", keyID)
+			}
+			// validate signing method
+			if token.Method.Alg() != key.Method.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			// return the public key of the signing key
+			return key.PublicKey, nil
+		}
 		// validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || s.tokenConfig.SecretKey == "" {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
+		if s.tokenConfig.SigningKeys.Enabled() && !time.Now().Before(s.tokenConfig.SecretKeyValidUntil) {
+			return nil, errors.New("tokens signed with the secret key are no longer accepted")
+		}
 		// return the secret signing key
 		return []byte(s.tokenConfig.SecretKey), nil
 	})
 }
+
+// GetJWKS returns the public keys used to verify the jwt tokens in json web key set format.
+// It allows other services to verify the tokens without the secret key.
+func (s *service) GetJWKS() JWKS {
+	return s.tokenConfig.SigningKeys.JWKS()
+}
+
+// IssueTokens creates a new session along with a short lived jwt token & a refresh token for a user id.
+// The scopes are stored against the session & granted to all the jwt tokens issued for the session.
+// The refresh token starts a new token family which is rotated on every refresh.
+func (s *service) IssueTokens(userID uint, client Client, scopes []string) (Tokens, error) {
+	now := time.Now().UTC()
+	session, err := s.sessionRepo.Save(Session{
+		UserID:     userID,
+		TokenID:    uuid.NewString(),
+		Device:     deviceName(client.UserAgent),
+		UserAgent:  truncateUserAgent(client.UserAgent),
+		IPAddress:  client.IPAddress,
+		Scopes:     JoinScopes(scopes),
+		LastSeenAt: now,
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+	return s.issueTokens(session)
+}
+
+// RefreshTokens exchanges a refresh token for a new jwt token & a new refresh token.
+// The refresh token can be used only once. If a used refresh token is presented again,
+// the entire token family is revoked since the token may have been stolen.
+// The token family is revoked as well when the user is no longer allowed to access the app.
+func (s *service) RefreshTokens(refreshToken string, isUserActive UserActiveFunc) (Tokens, error) {
+	rt, err := s.repo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return Tokens{}, err
+	}
+	if rt.ID == 0 {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if rt.RevokedAt != nil {
+		logrus.WithField("user-id", rt.UserID).Warn("reuse of refresh token detected. revoking the token family")
+		if err := s.repo.RevokeFamily(rt.FamilyID); err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	if rt.ExpiresAt.Before(time.Now()) {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	session, err := s.getActiveSession(rt.FamilyID)
+	if err != nil {
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	active, err := isUserActive(rt.UserID)
+	if err != nil {
+		return Tokens{}, err
+	}
+	if !active {
+		logrus.WithField("user-id", rt.UserID).Warn("refresh token of an inactive user presented. revoking the token family")
+		if err := s.revokeSession(session.ID, rt.FamilyID); err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+
+	// revoke is conditional, so only one of the concurrent requests with the same token succeeds
+	revoked, err := s.repo.Revoke(rt.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+	if !revoked {
+		logrus.WithField("user-id", rt.UserID).Warn("concurrent reuse of refresh token detected. revoking the token family")
+		if err := s.repo.RevokeFamily(rt.FamilyID); err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{}, ErrInvalidRefreshToken
+	}
+	return s.issueTokens(session)
+}
+
+// RevokeRefreshToken revokes the session & all the refresh tokens of the token family the given refresh token belongs to.
+func (s *service) RevokeRefreshToken(refreshToken string) error {
+	rt, err := s.repo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if rt.ID == 0 {
+		return ErrInvalidRefreshToken
+	}
+	session, err := s.sessionRepo.GetByTokenID(rt.FamilyID)
+	if err != nil {
+		return err
+	}
+	return s.revokeSession(session.ID, rt.FamilyID)
+}
+
+// ValidateSession checks that the session identified by the token id (jti claim) exists & is not revoked.
+// It also records the last seen time of the session.
+func (s *service) ValidateSession(tokenID string) error {
+	session, err := s.getActiveSession(tokenID)
+	if err != nil {
+		return err
+	}
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.sessionRepo.Touch(session.ID); err != nil {
+			// failing to record the last seen time should not fail the request
+			logrus.WithField("session-id", session.ID).WithError(err).Warn("updating session last seen time failed")
+		}
+	}
+	return nil
+}
+
+// GetSessions returns the active sessions of a user.
+// Sessions which are not used within the refresh token validity are considered expired.
+func (s *service) GetSessions(userID uint) ([]Session, error) {
+	return s.sessionRepo.GetAllActiveByUserID(userID, time.Now().Add(-s.tokenConfig.RefreshTokenTTL).UTC())
+}
+
+// GetSessionHistory returns all the sessions of a user including the revoked & expired ones.
+func (s *service) GetSessionHistory(userID uint) ([]Session, error) {
+	return s.sessionRepo.GetAllByUserID(userID)
+}
+
+// RevokeSession revokes the session of a user along with all the refresh tokens issued for the session.
+// The jwt tokens of the revoked session are rejected from the next request.
+func (s *service) RevokeSession(userID uint, sessionID uint) error {
+	session, err := s.sessionRepo.Get(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.ID == 0 || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return s.revokeSession(session.ID, session.TokenID)
+}
+
+// RevokeAllSessions revokes all the active sessions of a user along with their refresh tokens.
+// It returns the count of revoked sessions.
+func (s *service) RevokeAllSessions(userID uint) (int, error) {
+	sessions, err := s.sessionRepo.GetAllActiveByUserID(userID, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+	for i, session := range sessions {
+		if err := s.revokeSession(session.ID, session.TokenID); err != nil {
+			return i, err
+		}
+	}
+	return len(sessions), nil
+}
+
+func (s *service) getActiveSession(tokenID string) (Session, error) {
+	if tokenID == "" {
+		return Session{}, ErrInvalidSession
+	}
+	session, err := s.sessionRepo.GetByTokenID(tokenID)
+	if err != nil {
+		return Session{}, err
+	}
+	if session.ID == 0 || session.RevokedAt != nil {
+		return Session{}, ErrInvalidSession
+	}
+	return session, nil
+}
+
+func (s *service) revokeSession(sessionID uint, tokenID string) error {
+	if err := s.repo.RevokeFamily(tokenID); err != nil {
+		return err
+	}
+	if sessionID == 0 {
+		return nil
+	}
+	return s.sessionRepo.Revoke(sessionID)
+}
+
+// issueTokens creates a jwt token & a refresh token for the session.
+// The token id of the session is used as the family id of the refresh token.
+func (s *service) issueTokens(session Session) (Tokens, error) {
+	accessToken, err := s.GenerateToken(session.UserID, session.TokenID, SplitScopes(session.Scopes))
+	if err != nil {
+		return Tokens{}, err
+	}
+	refreshToken, err := generateOpaqueToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+	expiresAt := time.Now().Add(s.tokenConfig.RefreshTokenTTL).UTC()
+	if err := s.repo.Save(RefreshToken{
+		UserID:    session.UserID,
+		FamilyID:  session.TokenID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return Tokens{}, err
+	}
+	return Tokens{
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: expiresAt,
+	}, nil
+}
+
+// generateOpaqueToken returns an opaque random token used as refresh token & api token.
+func generateOpaqueToken() (string, error) {
+	b := make([]byte, refreshTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex encoded sha256 hash of the token.
+// Refresh tokens are random & long enough, so a fast unsalted hash is sufficient.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
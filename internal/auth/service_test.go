@@ -1,22 +1,51 @@
 package auth
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/dgrijalva/jwt-go"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLogin(t *testing.T) {
 	t.Run("should retrieve a valid token when request is valid", func(t *testing.T) {
 		tokenConfig := TokenConfig{
-			SecretKey: "key",
-			Issuer:    "test",
+			SecretKey:      "key",
+			Issuer:         "test",
+			AccessTokenTTL: 15 * time.Minute,
 		}
-		userID := uint(1001)
-		service := NewService(tokenConfig)
+		service := NewService(tokenConfig, nil, nil)
 
-		_, err := service.GenerateToken(userID)
+		token, err := service.GenerateToken(userID, tokenID, UserScopes)
 		assert.NoError(t, err)
+
+		parsedToken, err := service.ValidateToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, tokenID, parsedToken.Claims.(jwt.MapClaims)["jti"])
+		assert.Equal(t, "notes:read notes:write preferences:write", parsedToken.Claims.(jwt.MapClaims)["scope"])
+	})
+
+	t.Run("should sign token with the primary signing key when the signing keys are configured", func(t *testing.T) {
+		for _, pemKey := range []string{edPrivateKeyPEM(t), rsaPrivateKeyPEM(t)} {
+			signingKeys, err := ParseSigningKeys("key1", map[string]string{"key1": pemKey})
+			assert.NoError(t, err)
+			tokenConfig := validTokenConfig()
+			tokenConfig.SigningKeys = signingKeys
+			service := NewService(tokenConfig, nil, nil)
+
+			token, err := service.GenerateToken(userID, tokenID, UserScopes)
+			assert.NoError(t, err)
+
+			parsedToken, err := service.ValidateToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, "key1", parsedToken.Header["kid"])
+			assert.Equal(t, signingKeys.Keys["key1"].Method.Alg(), parsedToken.Method.Alg())
+		}
 	})
 }
 
@@ -27,7 +56,7 @@ func TestValidateToken(t *testing.T) {
 			SecretKey: "key",
 			Issuer:    "test",
 		}
-		service := NewService(tokenConfig)
+		service := NewService(tokenConfig, nil, nil)
 
 		_, err := service.ValidateToken(token)
 		assert.NoError(t, err)
@@ -38,7 +67,7 @@ func TestValidateToken(t *testing.T) {
 			SecretKey: "key",
 			Issuer:    "test",
 		}
-		service := NewService(tokenConfig)
+		service := NewService(tokenConfig, nil, nil)
 
 		_, err := service.ValidateToken(token)
 		assert.Error(t, err)
@@ -49,9 +78,527 @@ func TestValidateToken(t *testing.T) {
 			SecretKey: "key",
 			Issuer:    "test",
 		}
-		service := NewService(tokenConfig)
+		service := NewService(tokenConfig, nil, nil)
 
 		_, err := service.ValidateToken(token)
 		assert.Error(t, err)
 	})
 }
+
+func TestValidateTokenKeyRotation(t *testing.T) {
+	t.Run("should validate token signed with the old key after the primary key is rotated", func(t *testing.T) {
+		oldKeyPEM := edPrivateKeyPEM(t)
+		oldKeys, err := ParseSigningKeys("key1", map[string]string{"key1": oldKeyPEM})
+		assert.NoError(t, err)
+		oldTokenConfig := validTokenConfig()
+		oldTokenConfig.SigningKeys = oldKeys
+		token, err := NewService(oldTokenConfig, nil, nil).GenerateToken(userID, tokenID, UserScopes)
+		assert.NoError(t, err)
+
+		newKeys, err := ParseSigningKeys("key2", map[string]string{"key1": oldKeyPEM, "key2": rsaPrivateKeyPEM(t)})
+		assert.NoError(t, err)
+		newTokenConfig := validTokenConfig()
+		newTokenConfig.SigningKeys = newKeys
+		_, err = NewService(newTokenConfig, nil, nil).ValidateToken(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should validate token signed with the secret key within the window after switching to the signing keys", func(t *testing.T) {
+		token, err := NewService(validTokenConfig(), nil, nil).GenerateToken(userID, tokenID, UserScopes)
+		assert.NoError(t, err)
+
+		signingKeys, err := ParseSigningKeys("key1", map[string]string{"key1": edPrivateKeyPEM(t)})
+		assert.NoError(t, err)
+		tokenConfig := validTokenConfig()
+		tokenConfig.SigningKeys = signingKeys
+		tokenConfig.SecretKeyValidUntil = time.Now().Add(time.Hour)
+		_, err = NewService(tokenConfig, nil, nil).ValidateToken(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when the token is signed with the secret key after switching to the signing keys", func(t *testing.T) {
+		token, err := NewService(validTokenConfig(), nil, nil).GenerateToken(userID, tokenID, UserScopes)
+		assert.NoError(t, err)
+
+		signingKeys, err := ParseSigningKeys("key1", map[string]string{"key1": edPrivateKeyPEM(t)})
+		assert.NoError(t, err)
+		tokenConfig := validTokenConfig()
+		tokenConfig.SigningKeys = signingKeys
+		_, err = NewService(tokenConfig, nil, nil).ValidateToken(token)
+		assert.Error(t, err)
+
+		tokenConfig.SecretKeyValidUntil = time.Now().Add(-time.Minute)
+		_, err = NewService(tokenConfig, nil, nil).ValidateToken(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when the token is signed with an unknown key", func(t *testing.T) {
+		signingKeys, err := ParseSigningKeys("key1", map[string]string{"key1": edPrivateKeyPEM(t)})
+		assert.NoError(t, err)
+		tokenConfig := validTokenConfig()
+		tokenConfig.SigningKeys = signingKeys
+		token, err := NewService(tokenConfig, nil, nil).GenerateToken(userID, tokenID, UserScopes)
+		assert.NoError(t, err)
+
+		otherKeys, err := ParseSigningKeys("key2", map[string]string{"key2": edPrivateKeyPEM(t)})
+		assert.NoError(t, err)
+		tokenConfig.SigningKeys = otherKeys
+		_, err = NewService(tokenConfig, nil, nil).ValidateToken(token)
+		assert.Error(t, err)
+	})
+}
+
+func TestIssueTokens(t *testing.T) {
+	t.Run("should create session, issue access token & store the hash of refresh token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		var storedSession Session
+		mockSessionRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(session Session) (Session, error) {
+			storedSession = session
+			return session, nil
+		})
+		var stored RefreshToken
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(rt RefreshToken) error {
+			stored = rt
+			return nil
+		})
+
+		tokens, err := service.IssueTokens(userID, validClient(), UserScopes)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.Equal(t, userID, stored.UserID)
+		assert.NotEmpty(t, stored.FamilyID)
+		assert.Equal(t, storedSession.TokenID, stored.FamilyID)
+		assert.Equal(t, userID, storedSession.UserID)
+		assert.Equal(t, "Chrome on macOS", storedSession.Device)
+		assert.Equal(t, "203.0.113.10", storedSession.IPAddress)
+		assert.Equal(t, "notes:read notes:write preferences:write", storedSession.Scopes)
+		assert.Equal(t, hashToken(tokens.RefreshToken), stored.TokenHash)
+		assert.NotEqual(t, tokens.RefreshToken, stored.TokenHash)
+		assert.Equal(t, tokens.RefreshTokenExpiresAt, stored.ExpiresAt)
+	})
+
+	t.Run("should truncate the user agent to the length of the session column", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		var storedSession Session
+		mockSessionRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(session Session) (Session, error) {
+			storedSession = session
+			return session, nil
+		})
+		mockRepo.EXPECT().Save(gomock.Any()).Return(nil)
+		client := validClient()
+		client.UserAgent += strings.Repeat("é", 1000)
+
+		_, err := service.IssueTokens(userID, client, UserScopes)
+		assert.NoError(t, err)
+		assert.Equal(t, 512, utf8.RuneCountInString(storedSession.UserAgent))
+		assert.True(t, strings.HasPrefix(client.UserAgent, storedSession.UserAgent))
+		assert.Equal(t, "Chrome on macOS", storedSession.Device)
+	})
+
+	t.Run("should return error when storing refresh token fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		mockSessionRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(session Session) (Session, error) {
+			return session, nil
+		})
+		mockRepo.EXPECT().Save(gomock.Any()).Return(errors.New("some error"))
+
+		_, err := service.IssueTokens(userID, validClient(), UserScopes)
+		assert.Error(t, err)
+	})
+}
+
+func TestRefreshTokens(t *testing.T) {
+	t.Run("should rotate the refresh token within the same token family", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+		mockSessionRepo.EXPECT().GetByTokenID(rt.FamilyID).Return(validSession(), nil)
+		mockRepo.EXPECT().Revoke(rt.ID).Return(true, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(newRT RefreshToken) error {
+			assert.Equal(t, rt.FamilyID, newRT.FamilyID)
+			assert.Equal(t, rt.UserID, newRT.UserID)
+			return nil
+		})
+
+		tokens, err := service.RefreshTokens(refreshToken, activeUser)
+		assert.NoError(t, err)
+		assert.NotEqual(t, refreshToken, tokens.RefreshToken)
+	})
+
+	t.Run("should revoke the token family when a revoked refresh token is reused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		revokedAt := time.Now()
+		rt.RevokedAt = &revokedAt
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+		mockRepo.EXPECT().RevokeFamily(rt.FamilyID).Return(nil)
+
+		_, err := service.RefreshTokens(refreshToken, activeUser)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("should revoke the token family when the refresh token is concurrently reused", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+		mockSessionRepo.EXPECT().GetByTokenID(rt.FamilyID).Return(validSession(), nil)
+		mockRepo.EXPECT().Revoke(rt.ID).Return(false, nil)
+		mockRepo.EXPECT().RevokeFamily(rt.FamilyID).Return(nil)
+
+		_, err := service.RefreshTokens(refreshToken, activeUser)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("should return error when the refresh token is expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		rt.ExpiresAt = time.Now().Add(-time.Minute)
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+
+		_, err := service.RefreshTokens(refreshToken, activeUser)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("should return error when the refresh token does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(RefreshToken{}, nil)
+
+		_, err := service.RefreshTokens(refreshToken, activeUser)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("should return error when the session of the refresh token is revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		session := validSession()
+		revokedAt := time.Now()
+		session.RevokedAt = &revokedAt
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+		mockSessionRepo.EXPECT().GetByTokenID(rt.FamilyID).Return(session, nil)
+
+		_, err := service.RefreshTokens(refreshToken, activeUser)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+}
+
+func TestRefreshTokensOfInactiveUser(t *testing.T) {
+	t.Run("should revoke the session & the token family when the user is not active", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		session := validSession()
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+		mockSessionRepo.EXPECT().GetByTokenID(rt.FamilyID).Return(session, nil)
+		mockRepo.EXPECT().RevokeFamily(rt.FamilyID).Return(nil)
+		mockSessionRepo.EXPECT().Revoke(session.ID).Return(nil)
+
+		_, err := service.RefreshTokens(refreshToken, func(id uint) (bool, error) {
+			assert.Equal(t, rt.UserID, id)
+			return false, nil
+		})
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+
+	t.Run("should return error when checking the status of the user fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+		mockSessionRepo.EXPECT().GetByTokenID(rt.FamilyID).Return(validSession(), nil)
+
+		_, err := service.RefreshTokens(refreshToken, func(uint) (bool, error) {
+			return false, errors.New("some error")
+		})
+		assert.EqualError(t, err, "some error")
+	})
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	t.Run("should revoke the session & the token family of the refresh token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		rt := validRefreshToken()
+		session := validSession()
+		mockRepo.EXPECT().GetByHash(hashToken(refreshToken)).Return(rt, nil)
+		mockSessionRepo.EXPECT().GetByTokenID(rt.FamilyID).Return(session, nil)
+		mockRepo.EXPECT().RevokeFamily(rt.FamilyID).Return(nil)
+		mockSessionRepo.EXPECT().Revoke(session.ID).Return(nil)
+
+		err := service.RevokeRefreshToken(refreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when the refresh token does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		mockRepo.EXPECT().GetByHash(gomock.Any()).Return(RefreshToken{}, nil)
+
+		err := service.RevokeRefreshToken(refreshToken)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	})
+}
+
+func TestValidateSession(t *testing.T) {
+	t.Run("should not return error & update last seen time when the session is active", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), nil, mockSessionRepo)
+		session := validSession()
+		session.LastSeenAt = time.Now().Add(-time.Hour)
+		mockSessionRepo.EXPECT().GetByTokenID(tokenID).Return(session, nil)
+		mockSessionRepo.EXPECT().Touch(session.ID).Return(nil)
+
+		err := service.ValidateSession(tokenID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not update last seen time when the session was seen recently", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), nil, mockSessionRepo)
+		mockSessionRepo.EXPECT().GetByTokenID(tokenID).Return(validSession(), nil)
+
+		err := service.ValidateSession(tokenID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when the session is revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), nil, mockSessionRepo)
+		session := validSession()
+		revokedAt := time.Now()
+		session.RevokedAt = &revokedAt
+		mockSessionRepo.EXPECT().GetByTokenID(tokenID).Return(session, nil)
+
+		err := service.ValidateSession(tokenID)
+		assert.ErrorIs(t, err, ErrInvalidSession)
+	})
+
+	t.Run("should return the db error as is when retrieving the session fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), nil, mockSessionRepo)
+		mockSessionRepo.EXPECT().GetByTokenID(tokenID).Return(Session{}, errors.New("some error"))
+
+		err := service.ValidateSession(tokenID)
+		assert.EqualError(t, err, "some error")
+		assert.NotErrorIs(t, err, ErrInvalidSession)
+	})
+
+	t.Run("should return error when the token does not have token id", func(t *testing.T) {
+		service := NewService(validTokenConfig(), nil, nil)
+
+		err := service.ValidateSession("")
+		assert.ErrorIs(t, err, ErrInvalidSession)
+	})
+}
+
+func TestGetSessions(t *testing.T) {
+	t.Run("should return active sessions of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), nil, mockSessionRepo)
+		sessions := []Session{validSession()}
+		mockSessionRepo.EXPECT().GetAllActiveByUserID(userID, gomock.Any()).Return(sessions, nil)
+
+		result, err := service.GetSessions(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, sessions, result)
+	})
+}
+
+func TestGetSessionHistory(t *testing.T) {
+	t.Run("should return all the sessions of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), nil, mockSessionRepo)
+		revokedAt := time.Now()
+		revoked := validSession()
+		revoked.RevokedAt = &revokedAt
+		mockSessionRepo.EXPECT().GetAllByUserID(userID).Return([]Session{validSession(), revoked}, nil)
+
+		sessions, err := service.GetSessionHistory(userID)
+		assert.NoError(t, err)
+		assert.Len(t, sessions, 2)
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	t.Run("should revoke the session & its refresh tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		session := validSession()
+		mockSessionRepo.EXPECT().Get(session.ID).Return(session, nil)
+		mockRepo.EXPECT().RevokeFamily(session.TokenID).Return(nil)
+		mockSessionRepo.EXPECT().Revoke(session.ID).Return(nil)
+
+		err := service.RevokeSession(userID, session.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when the session belongs to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		session := validSession()
+		mockSessionRepo.EXPECT().Get(session.ID).Return(session, nil)
+
+		err := service.RevokeSession(userID+1, session.ID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	t.Run("should revoke all the active sessions of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		session := validSession()
+		other := validSession()
+		other.ID = session.ID + 1
+		other.TokenID = "another-token-id"
+		mockSessionRepo.EXPECT().GetAllActiveByUserID(userID, time.Time{}).Return([]Session{session, other}, nil)
+		mockRepo.EXPECT().RevokeFamily(session.TokenID).Return(nil)
+		mockSessionRepo.EXPECT().Revoke(session.ID).Return(nil)
+		mockRepo.EXPECT().RevokeFamily(other.TokenID).Return(nil)
+		mockSessionRepo.EXPECT().Revoke(other.ID).Return(nil)
+
+		count, err := service.RevokeAllSessions(userID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("should return error when revoking a session fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockSessionRepo := NewMockSessionRepo(ctrl)
+		service := NewService(validTokenConfig(), mockRepo, mockSessionRepo)
+		session := validSession()
+		mockSessionRepo.EXPECT().GetAllActiveByUserID(userID, time.Time{}).Return([]Session{session}, nil)
+		mockRepo.EXPECT().RevokeFamily(session.TokenID).Return(errors.New("some error"))
+
+		_, err := service.RevokeAllSessions(userID)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeviceName(t *testing.T) {
+	t.Run("should derive device name from user agent", func(t *testing.T) {
+		assert.Equal(t, "Chrome on macOS", deviceName(validClient().UserAgent))
+		assert.Equal(t, "Safari on iOS", deviceName("Mozilla/5.0 (iPhone; CPU iPhone OS 15_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.4 Mobile/15E148 Safari/604.1"))
+		assert.Equal(t, "Unknown device", deviceName(""))
+	})
+}
+
+const (
+	userID       = uint(1001)
+	tokenID      = "0b5ff8f2-4f4f-4b8d-9a5d-1d2c3b4a5e6f"
+	refreshToken = "dGhpcy1pcy1hLXJlZnJlc2gtdG9rZW4"
+)
+
+func validTokenConfig() TokenConfig {
+	return TokenConfig{
+		SecretKey:       "key",
+		Issuer:          "test",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 24 * time.Hour,
+	}
+}
+
+func validRefreshToken() RefreshToken {
+	rt := RefreshToken{
+		UserID:    userID,
+		FamilyID:  tokenID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	rt.ID = 11
+	return rt
+}
+
+func activeUser(uint) (bool, error) {
+	return true, nil
+}
+
+func validSession() Session {
+	session := Session{
+		UserID:     userID,
+		TokenID:    tokenID,
+		Device:     "Chrome on macOS",
+		Scopes:     "notes:read notes:write preferences:write",
+		LastSeenAt: time.Now(),
+	}
+	session.ID = 21
+	return session
+}
+
+func validClient() Client {
+	return Client{
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/100.0.4896.127 Safari/537.36",
+		IPAddress: "203.0.113.10",
+	}
+}
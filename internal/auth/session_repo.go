@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// SessionRepo represents a session repository.
+// It provides methods to store, retrieve & revoke the user sessions from the database.
+//go:generate mockgen -source=session_repo.go -package=auth -destination=mock_session_repo.go
+type SessionRepo interface {
+	Save(session Session) (Session, error)
+	Get(sessionID uint) (Session, error)
+	GetByTokenID(tokenID string) (Session, error)
+	GetAllActiveByUserID(userID uint, seenAfter time.Time) ([]Session, error)
+	GetAllByUserID(userID uint) ([]Session, error)
+	Touch(sessionID uint) error
+	Revoke(sessionID uint) error
+}
+
+type sessionRepoImpl struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates and returns a new instance of session repository.
+func NewSessionRepository(db *gorm.DB) SessionRepo {
+	return &sessionRepoImpl{
+		db: db,
+	}
+}
+
+// Save stores a given session record to database.
+func (r *sessionRepoImpl) Save(session Session) (Session, error) {
+	if err := r.db.Save(&session).Error; err != nil {
+		return session, errors.Wrap(err, "storing session to database failed")
+	}
+	return session, nil
+}
+
+// Get returns a session record by session-id.
+func (r *sessionRepoImpl) Get(sessionID uint) (Session, error) {
+	var session Session
+	err := r.db.Where("id = ?", sessionID).First(&session).Error
+	if err == gorm.ErrRecordNotFound {
+		return Session{}, nil
+	}
+	if err != nil {
+		return session, errors.Wrap(err, "retrieving session from database failed")
+	}
+	return session, nil
+}
+
+// GetByTokenID returns a session record matching the provided token id.
+func (r *sessionRepoImpl) GetByTokenID(tokenID string) (Session, error) {
+	var session Session
+	err := r.db.Where("token_id = ?", tokenID).First(&session).Error
+	if err == gorm.ErrRecordNotFound {
+		return Session{}, nil
+	}
+	if err != nil {
+		return session, errors.Wrap(err, "retrieving session from database failed")
+	}
+	return session, nil
+}
+
+// GetAllActiveByUserID returns the sessions of a user which are not revoked & were seen after the given time.
+func (r *sessionRepoImpl) GetAllActiveByUserID(userID uint, seenAfter time.Time) ([]Session, error) {
+	var sessions []Session
+	err := r.db.Where("user_id = ? and revoked_at is null and last_seen_at > ?", userID, seenAfter).
+		Order("last_seen_at desc").Find(&sessions).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving user's sessions from database failed")
+	}
+	return sessions, nil
+}
+
+// GetAllByUserID returns all the session records of a user including the revoked & expired ones.
+func (r *sessionRepoImpl) GetAllByUserID(userID uint) ([]Session, error) {
+	var sessions []Session
+	if err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&sessions).Error; err != nil {
+		return nil, errors.Wrap(err, "retrieving user's sessions from database failed")
+	}
+	return sessions, nil
+}
+
+// Touch updates the last seen time of a session record matching provided session-id.
+func (r *sessionRepoImpl) Touch(sessionID uint) error {
+	if err := r.db.Model(&Session{}).Where("id = ?", sessionID).UpdateColumn("last_seen_at", time.Now().UTC()).Error; err != nil {
+		return errors.Wrap(err, "updating session last seen time in database failed")
+	}
+	return nil
+}
+
+// Revoke marks a session record matching provided session-id as revoked in database.
+func (r *sessionRepoImpl) Revoke(sessionID uint) error {
+	if err := r.db.Model(&Session{}).Where("id = ? and revoked_at is null", sessionID).Update("revoked_at", time.Now().UTC()).Error; err != nil {
+		return errors.Wrap(err, "revoking session in database failed")
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+)
+
+// SigningMethodEdDSA signs & verifies the jwt tokens with ed25519 keys.
+// The jwt library does not support the EdDSA algorithm, so it is registered here.
+var SigningMethodEdDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+type signingMethodEdDSA struct{}
+
+// Alg returns the name of the EdDSA signing method.
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+// Verify verifies the signature of the signing string with the ed25519 public key.
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+	return nil
+}
+
+// Sign signs the signing string with the ed25519 private key.
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
+
+// SigningKey represents an asymmetric key used to sign & verify the jwt tokens.
+// PrivateKey is nil for the keys which are only used to verify the tokens signed before key rotation.
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// SigningKeys holds the signing keys by their key id.
+// New tokens are signed with the primary key, the remaining keys are only used to verify the tokens.
+type SigningKeys struct {
+	PrimaryKeyID string
+	Keys         map[string]SigningKey
+}
+
+// JWK represents a public key in json web key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS represents a json web key set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ParseSigningKeys parses the PEM encoded keys by their key id.
+// RSA keys are used with RS256 & ed25519 keys are used with EdDSA algorithm.
+// Private keys can be used to sign & verify the tokens, public keys can only be used to verify the tokens.
+// It returns an error if the keys are not valid or the primary key is missing.
+func ParseSigningKeys(primaryKeyID string, pemKeys map[string]string) (SigningKeys, error) {
+	keys := make(map[string]SigningKey, len(pemKeys))
+	for keyID, pemKey := range pemKeys {
+		key, err := parseSigningKey(keyID, pemKey)
+		if err != nil {
+			return SigningKeys{}, err
+		}
+		keys[keyID] = key
+	}
+	if len(keys) == 0 {
+		return SigningKeys{}, nil
+	}
+	primaryKey, ok := keys[primaryKeyID]
+	if !ok {
+		return SigningKeys{}, fmt.Errorf("primary signing key %s is not configured", primaryKeyID)
+	}
+	if primaryKey.PrivateKey == nil {
+		return SigningKeys{}, fmt.Errorf("primary signing key %s must be a private key", primaryKeyID)
+	}
+	return SigningKeys{
+		PrimaryKeyID: primaryKeyID,
+		Keys:         keys,
+	}, nil
+}
+
+// Enabled checks whether the asymmetric signing keys are configured.
+func (s SigningKeys) Enabled() bool {
+	return len(s.Keys) > 0
+}
+
+// JWKS returns the public keys of all the signing keys in json web key set format.
+func (s SigningKeys) JWKS() JWKS {
+	keyIDs := make([]string, 0, len(s.Keys))
+	for keyID := range s.Keys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(keyIDs))}
+	for _, keyID := range keyIDs {
+		key := s.Keys[keyID]
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func parseSigningKey(keyID string, pemKey string) (SigningKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("signing key %s is not PEM encoded", keyID)
+	}
+	var parsedKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsedKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsedKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsedKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return SigningKey{}, fmt.Errorf("signing key %s has unsupported PEM type: %s", keyID, block.Type)
+	}
+	if err != nil {
+		return SigningKey{}, errors.Wrapf(err, "parsing signing key %s failed", keyID)
+	}
+
+	signingKey := SigningKey{ID: keyID}
+	switch key := parsedKey.(type) {
+	case *rsa.PrivateKey:
+		signingKey.Method, signingKey.PrivateKey, signingKey.PublicKey = jwt.SigningMethodRS256, key, &key.PublicKey
+	case *rsa.PublicKey:
+		signingKey.Method, signingKey.PublicKey = jwt.SigningMethodRS256, key
+	case ed25519.PrivateKey:
+		signingKey.Method, signingKey.PrivateKey, signingKey.PublicKey = SigningMethodEdDSA, key, key.Public()
+	case ed25519.PublicKey:
+		signingKey.Method, signingKey.PublicKey = SigningMethodEdDSA, key
+	default:
+		return SigningKey{}, fmt.Errorf("signing key %s must be an RSA or ed25519 key", keyID)
+	}
+	return signingKey, nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSigningKeys(t *testing.T) {
+	t.Run("should parse private & public keys", func(t *testing.T) {
+		signingKeys, err := ParseSigningKeys("key2", map[string]string{
+			"key1": edPublicKeyPEM(t),
+			"key2": rsaPrivateKeyPEM(t),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "EdDSA", signingKeys.Keys["key1"].Method.Alg())
+		assert.Nil(t, signingKeys.Keys["key1"].PrivateKey)
+		assert.Equal(t, "RS256", signingKeys.Keys["key2"].Method.Alg())
+		assert.NotNil(t, signingKeys.Keys["key2"].PrivateKey)
+	})
+
+	t.Run("should return error when the primary key is a public key", func(t *testing.T) {
+		_, err := ParseSigningKeys("key1", map[string]string{"key1": edPublicKeyPEM(t)})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when the primary key is not configured", func(t *testing.T) {
+		_, err := ParseSigningKeys("key2", map[string]string{"key1": edPrivateKeyPEM(t)})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when the key is not PEM encoded", func(t *testing.T) {
+		_, err := ParseSigningKeys("key1", map[string]string{"key1": "invalid"})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return disabled signing keys when no keys are configured", func(t *testing.T) {
+		signingKeys, err := ParseSigningKeys("", nil)
+		assert.NoError(t, err)
+		assert.False(t, signingKeys.Enabled())
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	t.Run("should return public keys in json web key format", func(t *testing.T) {
+		signingKeys, err := ParseSigningKeys("key1", map[string]string{
+			"key1": edPrivateKeyPEM(t),
+			"key2": rsaPrivateKeyPEM(t),
+		})
+		assert.NoError(t, err)
+
+		jwks := signingKeys.JWKS()
+		assert.Len(t, jwks.Keys, 2)
+		assert.Equal(t, "key1", jwks.Keys[0].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+		assert.NotEmpty(t, jwks.Keys[0].X)
+		assert.Equal(t, "key2", jwks.Keys[1].Kid)
+		assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+		assert.NotEmpty(t, jwks.Keys[1].N)
+	})
+}
+
+func edPrivateKeyPEM(t *testing.T) string {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return privateKeyPEM(t, privateKey)
+}
+
+func edPublicKeyPEM(t *testing.T) string {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func rsaPrivateKeyPEM(t *testing.T) string {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return privateKeyPEM(t, privateKey)
+}
+
+func privateKeyPEM(t *testing.T, privateKey interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
@@ -1,9 +1,76 @@
 package config
 
+import "time"
+
 // App represents configuration properties specific to the application.
+// AccessTokenTTL & RefreshTokenTTL are the validity durations of the issued app tokens e.g. `15m`, `720h`.
+// AccountDeletionGracePeriod is the duration after which the data of the deleted user accounts is permanently deleted.
 type App struct {
-	SecretKey string
-	ClientURL string
+	SecretKey                  string
+	ClientURL                  string
+	AccessTokenTTL             time.Duration
+	RefreshTokenTTL            time.Duration
+	AccountDeletionGracePeriod time.Duration
+	Admins                     Admins
+}
+
+// Admins represents the users granted the admin role.
+// A user is an admin when either the email or the github id of the user is listed. Emails are case insensitive.
+type Admins struct {
+	Emails    []string
+	GithubIDs []int64
+}
+
+// Encryption represents configuration properties required to encrypt sensitive data at rest.
+// Keys holds base64 encoded 256 bit keys by their key id. New values are encrypted with the primary key,
+// the remaining keys are only used to decrypt the values encrypted before key rotation.
+// Key ids are case insensitive.
+type Encryption struct {
+	PrimaryKeyID string
+	Keys         map[string]string
+}
+
+// Signing represents configuration properties required to sign the app tokens with asymmetric keys.
+// Keys holds PEM encoded RSA or ed25519 keys by their key id. New tokens are signed with the primary key,
+// the remaining keys are only used to verify the tokens signed before key rotation & can be public keys.
+// App tokens are signed with the app secret key when no keys are configured. Key ids are case insensitive.
+// SecretKeyValidUntil is an optional RFC3339 time until which the tokens signed with the app secret key are still
+// accepted after switching to the keys. They are rejected right away when it is not set.
+type Signing struct {
+	PrimaryKeyID        string
+	Keys                map[string]string
+	SecretKeyValidUntil string
+}
+
+// Attachments represents configuration properties of the files (e.g. images) attached to the notes.
+// The attachments are stored in the folder of the notes repo. MaxSize is the maximum size of an attachment in bytes.
+type Attachments struct {
+	Folder  string
+	MaxSize int64
+}
+
+// Exports represents configuration properties of the personal data exports.
+// Dir is the directory the export archives are stored in, it must be shared when the app runs on multiple servers.
+// The archives are stored in the temp directory of the os when it is not set.
+type Exports struct {
+	Dir string
+}
+
+// Imports represents configuration properties of the imports of the notes from the other note taking apps.
+// Dir is the directory the uploads are stored in until they are imported, it must be shared when the app runs on multiple servers.
+// The uploads are stored in the temp directory of the os when it is not set.
+type Imports struct {
+	Dir string
+}
+
+// Mail represents configuration properties of the smtp server used to send the emails to the users.
+// From is the sender address e.g. `BatNoter <noreply@batnoter.com>`. Emails are not sent when the host is not set.
+type Mail struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
 }
 
 // Database represents configuration properties required to connect to a database.
@@ -39,12 +106,28 @@ type Github struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURL  string
+	App          GithubApp
+}
+
+// GithubApp represents configuration properties required to authenticate as a github app installation.
+// The github app authentication is enabled only when the app id is set.
+// PrivateKey is the PEM encoded private key generated for the github app.
+type GithubApp struct {
+	ID         int64
+	Slug       string
+	PrivateKey string
 }
 
 // Config represents all the application configurations grouped as per their category.
 type Config struct {
-	App        App
-	Database   Database
-	HTTPServer HTTPServer
-	OAuth2     OAuth2
+	App         App
+	Encryption  Encryption
+	Signing     Signing
+	Attachments Attachments
+	Exports     Exports
+	Imports     Imports
+	Mail        Mail
+	Database    Database
+	HTTPServer  HTTPServer
+	OAuth2      OAuth2
 }
@@ -0,0 +1,173 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Encrypter represents an envelope encrypter.
+// Every value is encrypted with a random data key & the data key is encrypted with a master key identified by key id.
+//go:generate mockgen -source=encrypter.go -package=encryption -destination=mock_encrypter.go
+type Encrypter interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+	NeedsRotation(ciphertext string) bool
+}
+
+// KeyConfig fields will be used to encrypt & decrypt the values.
+// Keys holds base64 encoded 256 bit master keys by their key id.
+// New values are always encrypted with the primary key, other keys are used only for decryption.
+type KeyConfig struct {
+	PrimaryKeyID string
+	Keys         map[string]string
+}
+
+const (
+	// prefix of the encrypted values, values without prefix are considered as plaintext
+	prefix    = "enc:v1"
+	separator = ":"
+	keySize   = 32
+)
+
+type encrypter struct {
+	primaryKeyID string
+	keys         map[string]cipher.AEAD
+}
+
+// NewEncrypter creates and returns a new envelope encrypter.
+// It returns an error if the keys are not valid or the primary key is missing.
+func NewEncrypter(keyConfig KeyConfig) (Encrypter, error) {
+	keys := make(map[string]cipher.AEAD, len(keyConfig.Keys))
+	for keyID, key := range keyConfig.Keys {
+		if strings.Contains(keyID, separator) {
+			return nil, fmt.Errorf("encryption key id must not contain '%s': %s", separator, keyID)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("encryption key %s is not set", keyID)
+		}
+		rawKey, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding encryption key %s failed", keyID)
+		}
+		if len(rawKey) != keySize {
+			return nil, fmt.Errorf("encryption key %s must be %d bytes long", keyID, keySize)
+		}
+		aead, err := newAEAD(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		keys[keyID] = aead
+	}
+	if _, ok := keys[keyConfig.PrimaryKeyID]; !ok {
+		return nil, fmt.Errorf("primary encryption key %s is not configured", keyConfig.PrimaryKeyID)
+	}
+	return &encrypter{
+		primaryKeyID: keyConfig.PrimaryKeyID,
+		keys:         keys,
+	}, nil
+}
+
+// Encrypt encrypts the plaintext with a random data key which in turn is encrypted with the primary key.
+// It returns the encrypted value in format `enc:v1:<key-id>:<encrypted-data-key>:<ciphertext>`.
+func (e *encrypter) Encrypt(plaintext string) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", errors.Wrap(err, "generating data key failed")
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataAEAD, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	encryptedDataKey, err := seal(e.keys[e.primaryKeyID], dataKey)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join([]string{
+		prefix,
+		e.primaryKeyID,
+		base64.RawStdEncoding.EncodeToString(encryptedDataKey),
+		base64.RawStdEncoding.EncodeToString(ciphertext),
+	}, separator), nil
+}
+
+// Decrypt decrypts the value encrypted by Encrypt using the master key identified by the key id of the value.
+// Values without the encryption prefix are returned as it is to support the values stored before encryption.
+func (e *encrypter) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix+separator) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, prefix+separator), separator)
+	if len(parts) != 3 {
+		return "", errors.New("invalid encrypted value format")
+	}
+	keyAEAD, ok := e.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("encryption key %s is not configured", parts[0])
+	}
+	encryptedDataKey, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "decoding data key failed")
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errors.Wrap(err, "decoding ciphertext failed")
+	}
+	dataKey, err := open(keyAEAD, encryptedDataKey)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypting data key failed")
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataAEAD, ciphertext)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypting value failed")
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation returns true if the value is not encrypted or it is encrypted with a key other than primary key.
+func (e *encrypter) NeedsRotation(value string) bool {
+	return !strings.HasPrefix(value, prefix+separator+e.primaryKeyID+separator)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cipher failed")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating gcm cipher failed")
+	}
+	return aead, nil
+}
+
+// seal encrypts the data & prepends the random nonce to the ciphertext.
+func seal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "generating nonce failed")
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func open(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
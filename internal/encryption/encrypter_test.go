@@ -0,0 +1,99 @@
+package encryption
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	key1      = "c2VjcmV0LWtleS1zZWNyZXQta2V5LXNlY3JldC1rZXk="
+	key2      = "YW5vdGhlci1rZXktYW5vdGhlci1rZXktYW5vdGhlci0="
+	plaintext = `{"access_token":"gho_token","token_type":"bearer","expiry":"0001-01-01T00:00:00Z"}`
+)
+
+func TestNewEncrypter(t *testing.T) {
+	t.Run("should return error when primary key is not configured", func(t *testing.T) {
+		_, err := NewEncrypter(KeyConfig{PrimaryKeyID: "key2", Keys: map[string]string{"key1": key1}})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when the key is not set", func(t *testing.T) {
+		_, err := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": ""}})
+		assert.EqualError(t, err, "encryption key key1 is not set")
+	})
+
+	t.Run("should return error when key is not 32 bytes long", func(t *testing.T) {
+		_, err := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": "c2VjcmV0"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when key id contains separator", func(t *testing.T) {
+		_, err := NewEncrypter(KeyConfig{PrimaryKeyID: "key:1", Keys: map[string]string{"key:1": key1}})
+		assert.Error(t, err)
+	})
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Run("should decrypt the value encrypted with primary key", func(t *testing.T) {
+		encrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": key1}})
+
+		ciphertext, err := encrypter.Encrypt(plaintext)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(ciphertext, "enc:v1:key1:"))
+		assert.NotContains(t, ciphertext, "gho_token")
+
+		value, err := encrypter.Decrypt(ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, value)
+	})
+
+	t.Run("should decrypt the value encrypted with an old key after key rotation", func(t *testing.T) {
+		oldEncrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": key1}})
+		encrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key2", Keys: map[string]string{"key1": key1, "key2": key2}})
+		ciphertext, _ := oldEncrypter.Encrypt(plaintext)
+
+		value, err := encrypter.Decrypt(ciphertext)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, value)
+	})
+
+	t.Run("should return the value as it is when the value is not encrypted", func(t *testing.T) {
+		encrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": key1}})
+
+		value, err := encrypter.Decrypt(plaintext)
+		assert.NoError(t, err)
+		assert.Equal(t, plaintext, value)
+	})
+
+	t.Run("should return error when the key used for encryption is not configured", func(t *testing.T) {
+		oldEncrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": key1}})
+		encrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key2", Keys: map[string]string{"key2": key2}})
+		ciphertext, _ := oldEncrypter.Encrypt(plaintext)
+
+		_, err := encrypter.Decrypt(ciphertext)
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when the encrypted value is tampered", func(t *testing.T) {
+		encrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": key1}})
+		ciphertext, _ := encrypter.Encrypt(plaintext)
+
+		_, err := encrypter.Decrypt(ciphertext[:len(ciphertext)-2] + "AA")
+		assert.Error(t, err)
+	})
+}
+
+func TestNeedsRotation(t *testing.T) {
+	t.Run("should return true only when the value is not encrypted with primary key", func(t *testing.T) {
+		oldEncrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key1", Keys: map[string]string{"key1": key1}})
+		encrypter, _ := NewEncrypter(KeyConfig{PrimaryKeyID: "key2", Keys: map[string]string{"key1": key1, "key2": key2}})
+		oldCiphertext, _ := oldEncrypter.Encrypt(plaintext)
+		ciphertext, _ := encrypter.Encrypt(plaintext)
+
+		assert.True(t, encrypter.NeedsRotation(plaintext))
+		assert.True(t, encrypter.NeedsRotation(oldCiphertext))
+		assert.False(t, encrypter.NeedsRotation(ciphertext))
+	})
+}
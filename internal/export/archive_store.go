@@ -0,0 +1,112 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ArchiveStore represents a storage of the export archives.
+// It provides methods to write, read & delete the archives identified by their export id.
+//go:generate mockgen -source=archive_store.go -package=export -destination=mock_archive_store.go
+type ArchiveStore interface {
+	Save(exportID uint, write func(w io.Writer) error) (int64, error)
+	Open(exportID uint) (io.ReadCloser, error)
+	Delete(exportID uint) error
+	DeleteBefore(modifiedBefore time.Time) (int, error)
+}
+
+const archiveExt = ".zip"
+
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore creates and returns a new archive store which stores the archives as files in the directory.
+// The directory is created when it does not exist. The directory must be shared when the app runs on multiple servers.
+func NewFileStore(dir string) (ArchiveStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "creating export archive directory failed")
+	}
+	return &fileStore{
+		dir: dir,
+	}, nil
+}
+
+// Save streams the archive written by the write function to the file of the export & returns the size of the archive.
+// The archive is first written to a temporary file, so a partially written archive is never served.
+func (f *fileStore) Save(exportID uint, write func(w io.Writer) error) (int64, error) {
+	tmp, err := os.CreateTemp(f.dir, fmt.Sprintf("%d-*.tmp", exportID))
+	if err != nil {
+		return 0, errors.Wrap(err, "creating export archive file failed")
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		tmp.Close()
+		return 0, errors.Wrap(err, "writing export archive file failed")
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, errors.Wrap(err, "writing export archive file failed")
+	}
+	if err := os.Rename(tmp.Name(), f.path(exportID)); err != nil {
+		return 0, errors.Wrap(err, "storing export archive file failed")
+	}
+	return size, nil
+}
+
+// Open opens the archive of the export for reading. The caller must close the archive.
+func (f *fileStore) Open(exportID uint) (io.ReadCloser, error) {
+	file, err := os.Open(f.path(exportID))
+	if err != nil {
+		return nil, errors.Wrap(err, "opening export archive file failed")
+	}
+	return file, nil
+}
+
+// Delete deletes the archive of the export, it does nothing when the archive does not exist.
+func (f *fileStore) Delete(exportID uint) error {
+	if err := os.Remove(f.path(exportID)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "deleting export archive file failed")
+	}
+	return nil
+}
+
+// DeleteBefore deletes the archives (& the leftover temporary files) last modified before the given time.
+// It returns the count of deleted archives.
+func (f *fileStore) DeleteBefore(modifiedBefore time.Time) (int, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return 0, errors.Wrap(err, "listing export archive files failed")
+	}
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(modifiedBefore) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(f.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return count, errors.Wrap(err, "deleting export archive file failed")
+		}
+		if strings.HasSuffix(entry.Name(), archiveExt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fileStore) path(exportID uint) string {
+	return filepath.Join(f.dir, fmt.Sprintf("%d%s", exportID, archiveExt))
+}
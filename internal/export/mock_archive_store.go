@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: archive_store.go
+
+// Package export is a generated GoMock package.
+package export
+
+import (
+	io "io"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockArchiveStore is a mock of ArchiveStore interface.
+type MockArchiveStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockArchiveStoreMockRecorder
+}
+
+// MockArchiveStoreMockRecorder is the mock recorder for MockArchiveStore.
+type MockArchiveStoreMockRecorder struct {
+	mock *MockArchiveStore
+}
+
+// NewMockArchiveStore creates a new mock instance.
+func NewMockArchiveStore(ctrl *gomock.Controller) *MockArchiveStore {
+	mock := &MockArchiveStore{ctrl: ctrl}
+	mock.recorder = &MockArchiveStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArchiveStore) EXPECT() *MockArchiveStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockArchiveStore) Delete(exportID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", exportID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockArchiveStoreMockRecorder) Delete(exportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArchiveStore)(nil).Delete), exportID)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockArchiveStore) DeleteBefore(modifiedBefore time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", modifiedBefore)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockArchiveStoreMockRecorder) DeleteBefore(modifiedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockArchiveStore)(nil).DeleteBefore), modifiedBefore)
+}
+
+// Open mocks base method.
+func (m *MockArchiveStore) Open(exportID uint) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", exportID)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockArchiveStoreMockRecorder) Open(exportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockArchiveStore)(nil).Open), exportID)
+}
+
+// Save mocks base method.
+func (m *MockArchiveStore) Save(exportID uint, write func(io.Writer) error) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", exportID, write)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockArchiveStoreMockRecorder) Save(exportID, write interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockArchiveStore)(nil).Save), exportID, write)
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: notes_service.go
+
+// Package export is a generated GoMock package.
+package export
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	github "github.com/batnoter/batnoter-api/internal/github"
+	gomock "github.com/golang/mock/gomock"
+	oauth2 "golang.org/x/oauth2"
+)
+
+// MockNotesService is a mock of NotesService interface.
+type MockNotesService struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotesServiceMockRecorder
+}
+
+// MockNotesServiceMockRecorder is the mock recorder for MockNotesService.
+type MockNotesServiceMockRecorder struct {
+	mock *MockNotesService
+}
+
+// NewMockNotesService creates a new mock instance.
+func NewMockNotesService(ctrl *gomock.Controller) *MockNotesService {
+	mock := &MockNotesService{ctrl: ctrl}
+	mock.recorder = &MockNotesServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotesService) EXPECT() *MockNotesServiceMockRecorder {
+	return m.recorder
+}
+
+// GetNotes mocks base method.
+func (m *MockNotesService) GetNotes(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, path string) ([]github.GitFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotes", ctx, ghToken, repoDetails, path)
+	ret0, _ := ret[0].([]github.GitFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotes indicates an expected call of GetNotes.
+func (mr *MockNotesServiceMockRecorder) GetNotes(ctx, ghToken, repoDetails, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotes", reflect.TypeOf((*MockNotesService)(nil).GetNotes), ctx, ghToken, repoDetails, path)
+}
+
+// Write mocks base method.
+func (m *MockNotesService) Write(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, notes []github.GitFile, format string, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Write", ctx, ghToken, repoDetails, notes, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Write indicates an expected call of Write.
+func (mr *MockNotesServiceMockRecorder) Write(ctx, ghToken, repoDetails, notes, format, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockNotesService)(nil).Write), ctx, ghToken, repoDetails, notes, format, w)
+}
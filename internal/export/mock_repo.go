@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+
+// Package export is a generated GoMock package.
+package export
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepo is a mock of Repo interface.
+type MockRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder
+}
+
+// MockRepoMockRecorder is the mock recorder for MockRepo.
+type MockRepoMockRecorder struct {
+	mock *MockRepo
+}
+
+// NewMockRepo creates a new mock instance.
+func NewMockRepo(ctrl *gomock.Controller) *MockRepo {
+	mock := &MockRepo{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockRepo) DeleteExpired(expiredBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", expiredBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockRepoMockRecorder) DeleteExpired(expiredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockRepo)(nil).DeleteExpired), expiredBefore)
+}
+
+// GetAllUnfinished mocks base method.
+func (m *MockRepo) GetAllUnfinished() ([]Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllUnfinished")
+	ret0, _ := ret[0].([]Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllUnfinished indicates an expected call of GetAllUnfinished.
+func (mr *MockRepoMockRecorder) GetAllUnfinished() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUnfinished", reflect.TypeOf((*MockRepo)(nil).GetAllUnfinished))
+}
+
+// GetLatestByUserID mocks base method.
+func (m *MockRepo) GetLatestByUserID(userID uint) (Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestByUserID", userID)
+	ret0, _ := ret[0].(Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestByUserID indicates an expected call of GetLatestByUserID.
+func (mr *MockRepoMockRecorder) GetLatestByUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestByUserID", reflect.TypeOf((*MockRepo)(nil).GetLatestByUserID), userID)
+}
+
+// Save mocks base method.
+func (m *MockRepo) Save(export Export) (Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", export)
+	ret0, _ := ret[0].(Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRepoMockRecorder) Save(export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepo)(nil).Save), export)
+}
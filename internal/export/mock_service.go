@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package export is a generated GoMock package.
+package export
+
+import (
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockService) DeleteExpired() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockServiceMockRecorder) DeleteExpired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockService)(nil).DeleteExpired))
+}
+
+// GetArchive mocks base method.
+func (m *MockService) GetArchive(userID uint) (Export, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchive", userID)
+	ret0, _ := ret[0].(Export)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetArchive indicates an expected call of GetArchive.
+func (mr *MockServiceMockRecorder) GetArchive(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchive", reflect.TypeOf((*MockService)(nil).GetArchive), userID)
+}
+
+// GetLatest mocks base method.
+func (m *MockService) GetLatest(userID uint) (Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatest", userID)
+	ret0, _ := ret[0].(Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatest indicates an expected call of GetLatest.
+func (mr *MockServiceMockRecorder) GetLatest(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatest", reflect.TypeOf((*MockService)(nil).GetLatest), userID)
+}
+
+// Request mocks base method.
+func (m *MockService) Request(userID uint, includeNotes bool) (Export, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request", userID, includeNotes)
+	ret0, _ := ret[0].(Export)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Request indicates an expected call of Request.
+func (mr *MockServiceMockRecorder) Request(userID, includeNotes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockService)(nil).Request), userID, includeNotes)
+}
+
+// ResumeUnfinished mocks base method.
+func (m *MockService) ResumeUnfinished() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeUnfinished")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeUnfinished indicates an expected call of ResumeUnfinished.
+func (mr *MockServiceMockRecorder) ResumeUnfinished() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeUnfinished", reflect.TypeOf((*MockService)(nil).ResumeUnfinished))
+}
@@ -0,0 +1,90 @@
+package export
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// StatusPending is the status of the export waiting to be processed.
+	StatusPending = "pending"
+
+	// StatusRunning is the status of the export being processed.
+	StatusRunning = "running"
+
+	// StatusCompleted is the status of the export whose archive is ready to be downloaded.
+	StatusCompleted = "completed"
+
+	// StatusFailed is the status of the export which could not be processed.
+	StatusFailed = "failed"
+)
+
+// Export represents an entity model used to store & retrieve personal data exports of the users to/from database.
+// The zip archive of the completed export is kept in the archive store until the export expires.
+type Export struct {
+	gorm.Model
+	UserID uint
+
+	IncludeNotes bool
+	Status       string
+	Error        string
+	Size         int
+	CompletedAt  *time.Time
+	ExpiresAt    *time.Time
+}
+
+// Ready checks whether the archive of the export can be downloaded.
+func (e Export) Ready() bool {
+	return e.Status == StatusCompleted && e.ExpiresAt != nil && e.ExpiresAt.After(time.Now())
+}
+
+// profileRecord represents the user profile written to the export archive. Secrets of the user are never exported.
+type profileRecord struct {
+	ID             uint       `json:"id"`
+	Email          string     `json:"email"`
+	Name           string     `json:"name,omitempty"`
+	Location       string     `json:"location,omitempty"`
+	AvatarURL      string     `json:"avatar_url,omitempty"`
+	DisplayName    string     `json:"display_name,omitempty"`
+	Timezone       string     `json:"timezone,omitempty"`
+	Locale         string     `json:"locale,omitempty"`
+	CommitName     string     `json:"commit_name,omitempty"`
+	CommitEmail    string     `json:"commit_email,omitempty"`
+	GithubID       int64      `json:"github_id"`
+	GithubUsername string     `json:"github_username"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason string     `json:"disabled_reason,omitempty"`
+}
+
+// preferenceRecord represents a preference record written to the export archive.
+type preferenceRecord struct {
+	Name           string    `json:"name"`
+	Owner          string    `json:"owner,omitempty"`
+	Visibility     string    `json:"visibility"`
+	DefaultBranch  string    `json:"default_branch"`
+	InstallationID int64     `json:"installation_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// sessionRecord represents a session record written to the export archive.
+type sessionRecord struct {
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	Scopes     string     `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// auditEventRecord represents an audit event written to the export archive.
+type auditEventRecord struct {
+	ActorID   uint      `json:"actor_id"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
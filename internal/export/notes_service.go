@@ -0,0 +1,337 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoNotes is returned when there are no notes in the path to be exported.
+var ErrNoNotes = errors.New("no notes found in the path")
+
+const (
+	// FormatZip exports the notes as a zip archive preserving the folder structure of the repo.
+	FormatZip = "zip"
+
+	// FormatMarkdown exports the notes as a single markdown document.
+	FormatMarkdown = "md"
+
+	// FormatHTML exports the notes as a single html document.
+	FormatHTML = "html"
+
+	// FormatJSON exports the notes along with their metadata as a json document.
+	FormatJSON = "json"
+)
+
+// Formats are the formats the notes can be exported in.
+var Formats = []interface{}{FormatZip, FormatMarkdown, FormatHTML, FormatJSON}
+
+// the notes are fetched concurrently ahead of the note being written, this limits the notes fetched at once
+const fetchConcurrency = 8
+
+// incompleteMessage marks the exported document incomplete when an error occurs after the document is partially written.
+const incompleteMessage = "export incomplete: fetching the notes failed"
+
+// NotesService represents a notes export service.
+// It streams the notes of a path of the repo in the requested format.
+//go:generate mockgen -source=notes_service.go -package=export -destination=mock_notes_service.go
+type NotesService interface {
+	GetNotes(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, path string) ([]github.GitFile, error)
+	Write(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, notes []github.GitFile, format string, w io.Writer) error
+}
+
+type notesService struct {
+	githubService github.Service
+	renderer      markdown.Renderer
+}
+
+// NewNotesService creates and returns a new notes export service.
+func NewNotesService(githubService github.Service, renderer markdown.Renderer) NotesService {
+	return &notesService{
+		githubService: githubService,
+		renderer:      renderer,
+	}
+}
+
+// notesDocument represents the json document of the exported notes.
+type notesDocument struct {
+	Repository string       `json:"repository"`
+	Branch     string       `json:"branch"`
+	ExportedAt time.Time    `json:"exported_at"`
+	Notes      []noteRecord `json:"notes"`
+}
+
+type noteRecord struct {
+	Path    string `json:"path"`
+	SHA     string `json:"sha"`
+	Size    int    `json:"size"`
+	Content string `json:"content"`
+}
+
+// GetNotes lists the notes under the path of the repo without their content, all the notes are listed when path is empty.
+// Only the files matching the note file rules of the repo are listed, ErrNoNotes is returned when there are no notes under the path.
+func (s *notesService) GetNotes(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, path string) ([]github.GitFile, error) {
+	root := strings.Trim(path, "/")
+	r, err := repoDetails.NoteFiles.Regexp()
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.githubService.GetTree(ctx, ghToken, github.GitFileProps{RepoDetails: repoDetails})
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]github.GitFile, 0, len(files))
+	for _, file := range files {
+		if !file.IsDir && r.MatchString(file.Path) && (root == "" || strings.HasPrefix(file.Path, root+"/")) {
+			notes = append(notes, file)
+		}
+	}
+	if len(notes) == 0 {
+		return nil, ErrNoNotes
+	}
+	return notes, nil
+}
+
+// Write writes the notes to the writer in the format.
+// The content of the notes is fetched by blob sha concurrently with a few notes fetched ahead of the note being written,
+// so the export finishes sooner while the memory used does not grow with the size of the repo.
+// The document is marked incomplete when an error occurs midway: the zip archive is left without its central directory,
+// the markdown & html documents end with an error comment & the json document gets an error field.
+func (s *notesService) Write(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, notes []github.GitFile, format string,
+	w io.Writer) error {
+	fetch, stop := prefetch(ctx, notes, func(ctx context.Context, note github.GitFile) ([]byte, error) {
+		return s.githubService.GetBlob(ctx, ghToken, github.GitFileProps{SHA: note.SHA, Path: note.Path, RepoDetails: repoDetails})
+	})
+	defer stop()
+	switch format {
+	case FormatZip:
+		return writeZip(w, notes, fetch)
+	case FormatMarkdown:
+		return writeMarkdown(w, notes, fetch)
+	case FormatHTML:
+		return s.writeHTML(w, notes, fetch)
+	case FormatJSON:
+		return writeNotesJSON(w, repoDetails, notes, fetch)
+	}
+	return errors.New("unsupported export format")
+}
+
+type fetchFunc func(note github.GitFile) ([]byte, error)
+
+type fetchResult struct {
+	content []byte
+	err     error
+}
+
+// prefetch starts fetching the notes concurrently & returns the func returning the content of the notes in their order.
+// At most fetchConcurrency notes are fetched or held ahead of the note being written.
+// The returned func must be called for the notes in order, the returned stop func cancels the remaining fetches & waits for them.
+func prefetch(ctx context.Context, notes []github.GitFile,
+	fetch func(ctx context.Context, note github.GitFile) ([]byte, error)) (fetchFunc, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	results := make([]chan fetchResult, len(notes))
+	for i := range results {
+		results[i] = make(chan fetchResult, 1)
+	}
+	slots := make(chan struct{}, fetchConcurrency)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, note := range notes {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func(result chan<- fetchResult, note github.GitFile) {
+				defer wg.Done()
+				content, err := fetch(ctx, note)
+				result <- fetchResult{content: content, err: err}
+			}(results[i], note)
+		}
+	}()
+	stop := func() {
+		cancel()
+		wg.Wait()
+	}
+	next := 0
+	return func(note github.GitFile) ([]byte, error) {
+		var result fetchResult
+		select {
+		case result = <-results[next]:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		next++
+		<-slots
+		return result.content, result.err
+	}, stop
+}
+
+// writeZip writes the notes to the zip archive, the archive is closed only when all the notes are written.
+// The archive without the central directory can not be opened, so a partially written archive is not mistaken for a complete one.
+func writeZip(w io.Writer, notes []github.GitFile, fetch fetchFunc) error {
+	zw := zip.NewWriter(w)
+	for _, note := range notes {
+		content, err := fetch(note)
+		if err != nil {
+			return err
+		}
+		fw, err := zw.Create(note.Path)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeMarkdown writes the notes one after another, each note is preceded by a comment with its path.
+func writeMarkdown(w io.Writer, notes []github.GitFile, fetch fetchFunc) error {
+	for i, note := range notes {
+		content, err := fetch(note)
+		if err != nil {
+			io.WriteString(w, "\n\n<!-- "+incompleteMessage+" -->\n")
+			return err
+		}
+		separator := ""
+		if i > 0 {
+			separator = "\n\n"
+		}
+		if _, err := io.WriteString(w, separator+"<!-- "+note.Path+" -->\n\n"); err != nil {
+			return err
+		}
+		if _, err := w.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	htmlHeaderTemplate = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+</head>
+<body>
+`))
+	htmlNoteTemplate = template.Must(template.New("note").Parse(`<article id="{{.ID}}">
+<p class="note-path">{{.Path}}</p>
+{{.Content}}
+</article>
+`))
+)
+
+const htmlFooter = "</body>\n</html>\n"
+
+// writeHTML writes the rendered notes as the articles of a html document.
+// The links between the exported notes are rewritten to the links to their articles.
+func (s *notesService) writeHTML(w io.Writer, notes []github.GitFile, fetch fetchFunc) error {
+	exported := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		exported[note.Path] = true
+	}
+	linkURL := func(path string) string {
+		if exported[path] {
+			return "#" + noteAnchor(path)
+		}
+		return path
+	}
+	if err := htmlHeaderTemplate.Execute(w, "BatNoter notes"); err != nil {
+		return err
+	}
+	for _, note := range notes {
+		content, err := fetch(note)
+		if err != nil {
+			io.WriteString(w, "<!-- "+incompleteMessage+" -->\n"+htmlFooter)
+			return err
+		}
+		html, err := s.renderer.Render(content, markdown.Options{Path: note.Path, LinkURL: linkURL})
+		if err != nil {
+			return err
+		}
+		err = htmlNoteTemplate.Execute(w, struct {
+			ID      string
+			Path    string
+			Content template.HTML
+		}{noteAnchor(note.Path), note.Path, template.HTML(html)})
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, htmlFooter)
+	return err
+}
+
+// writeNotesJSON writes the json document of the notes, the notes are encoded one at a time.
+func writeNotesJSON(w io.Writer, repoDetails github.GitRepoProps, notes []github.GitFile, fetch fetchFunc) error {
+	repository, err := json.Marshal(repoDetails.Owner + "/" + repoDetails.Repository)
+	if err != nil {
+		return err
+	}
+	branch, err := json.Marshal(repoDetails.DefaultBranch)
+	if err != nil {
+		return err
+	}
+	exportedAt, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	// the fields of the document are written in the order of notesDocument & the notes array is left open so that the notes can be appended to it
+	header := `{"repository":` + string(repository) + `,"branch":` + string(branch) + `,"exported_at":` + string(exportedAt) + `,"notes":[`
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	for i, note := range notes {
+		content, err := fetch(note)
+		if err != nil {
+			writeIncompleteMarker(w, `],"error":"`+incompleteMessage+`"}`)
+			return err
+		}
+		record, err := json.Marshal(noteRecord{Path: note.Path, SHA: note.SHA, Size: len(content), Content: string(content)})
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			record = append([]byte(","), record...)
+		}
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "]}")
+	return err
+}
+
+// writeIncompleteMarker writes the marker of the incomplete document after the export failed midway.
+// The export has already failed so the failure to write the marker is only logged.
+func writeIncompleteMarker(w io.Writer, marker string) {
+	if _, err := io.WriteString(w, marker); err != nil {
+		logrus.WithError(err).Error("writing incomplete export marker failed")
+	}
+}
+
+var nonAnchorChars = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// noteAnchor returns the id of the article of the note in the html document.
+func noteAnchor(path string) string {
+	return "note-" + strings.Trim(nonAnchorChars.ReplaceAllString(strings.ToLower(path), "-"), "-")
+}
@@ -0,0 +1,291 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+var (
+	notesGhToken     = oauth2.Token{AccessToken: "gho_token"}
+	notesRepoDetails = github.GitRepoProps{Repository: "notes", DefaultBranch: "main", Owner: "johndoe"}
+	exportedNotes    = []github.GitFile{
+		{SHA: "sha1", Path: "docs/setup.md"},
+		{SHA: "sha2", Path: "docs/guides/deploy.md"},
+	}
+)
+
+func TestGetNotes(t *testing.T) {
+	t.Run("should return the notes under the path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		mockGithubService.EXPECT().GetTree(gomock.Any(), notesGhToken, github.GitFileProps{RepoDetails: notesRepoDetails}).Return([]github.GitFile{
+			{SHA: "sha1", Path: "docs/setup.md"},
+			{Path: "docs/guides", IsDir: true},
+			{SHA: "sha2", Path: "docs/guides/deploy.md"},
+			{SHA: "sha3", Path: "docsite/index.md"},
+			{SHA: "sha4", Path: "personal/diary.md"},
+		}, nil)
+
+		notes, err := service.GetNotes(context.Background(), notesGhToken, notesRepoDetails, "/docs/")
+		assert.NoError(t, err)
+		assert.Equal(t, exportedNotes, notes)
+	})
+
+	t.Run("should return all the notes when path is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		mockGithubService.EXPECT().GetTree(gomock.Any(), notesGhToken, github.GitFileProps{RepoDetails: notesRepoDetails}).
+			Return([]github.GitFile{{SHA: "sha1", Path: "todo.md"}, {SHA: "sha2", Path: "docs/setup.md"}}, nil)
+
+		notes, err := service.GetNotes(context.Background(), notesGhToken, notesRepoDetails, "")
+		assert.NoError(t, err)
+		assert.Len(t, notes, 2)
+	})
+
+	t.Run("should return only the files matching the note file rules of the repo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		repoDetails := notesRepoDetails
+		repoDetails.NoteFiles = github.NoteFileRules{Extensions: []string{"txt"}}
+		mockGithubService.EXPECT().GetTree(gomock.Any(), notesGhToken, github.GitFileProps{RepoDetails: repoDetails}).Return([]github.GitFile{
+			{SHA: "sha1", Path: "docs/setup.txt"},
+			{SHA: "sha2", Path: "docs/setup.md"},
+			{SHA: "sha3", Path: "docs/logo.png"},
+			{SHA: "sha4", Path: "docs/.hidden/notes.txt"},
+		}, nil)
+
+		notes, err := service.GetNotes(context.Background(), notesGhToken, repoDetails, "docs")
+		assert.NoError(t, err)
+		assert.Equal(t, []github.GitFile{{SHA: "sha1", Path: "docs/setup.txt"}}, notes)
+	})
+
+	t.Run("should return error when there are no notes under the path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		mockGithubService.EXPECT().GetTree(gomock.Any(), notesGhToken, github.GitFileProps{RepoDetails: notesRepoDetails}).
+			Return([]github.GitFile{{SHA: "sha1", Path: "todo.md"}}, nil)
+
+		_, err := service.GetNotes(context.Background(), notesGhToken, notesRepoDetails, "docs")
+		assert.ErrorIs(t, err, ErrNoNotes)
+	})
+
+	t.Run("should return error when retrieving the tree fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		mockGithubService.EXPECT().GetTree(gomock.Any(), notesGhToken, github.GitFileProps{RepoDetails: notesRepoDetails}).
+			Return(nil, errors.New("github error"))
+
+		_, err := service.GetNotes(context.Background(), notesGhToken, notesRepoDetails, "docs")
+		assert.EqualError(t, err, "github error")
+	})
+}
+
+func TestWrite(t *testing.T) {
+	expectBlobs := func(mockGithubService *github.MockService) {
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), notesGhToken, github.GitFileProps{SHA: "sha1", Path: "docs/setup.md", RepoDetails: notesRepoDetails}).
+			Return([]byte("# Setup\n\nSee [deploy](guides/deploy.md) & [diary](../personal/diary.md).\n"), nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), notesGhToken, github.GitFileProps{SHA: "sha2", Path: "docs/guides/deploy.md", RepoDetails: notesRepoDetails}).
+			Return([]byte("# Deploy\n"), nil)
+	}
+
+	t.Run("should write the notes as zip archive preserving the folder structure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		expectBlobs(mockGithubService)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatZip, &buf)
+		assert.NoError(t, err)
+		files := readArchive(t, buf.Bytes())
+		assert.ElementsMatch(t, []string{"docs/setup.md", "docs/guides/deploy.md"}, keys(files))
+		assert.Equal(t, "# Deploy\n", files["docs/guides/deploy.md"])
+	})
+
+	t.Run("should write the notes as a single markdown document", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		expectBlobs(mockGithubService)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatMarkdown, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "<!-- docs/setup.md -->\n\n# Setup\n\nSee [deploy](guides/deploy.md) & [diary](../personal/diary.md).\n"+
+			"\n\n<!-- docs/guides/deploy.md -->\n\n# Deploy\n", buf.String())
+	})
+
+	t.Run("should write the rendered notes as a single html document linking the exported notes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, markdown.NewRenderer())
+		expectBlobs(mockGithubService)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatHTML, &buf)
+		assert.NoError(t, err)
+		html := buf.String()
+		assert.Contains(t, html, "<!DOCTYPE html>")
+		assert.Contains(t, html, `<article id="note-docs-setup-md">`)
+		assert.Contains(t, html, `<article id="note-docs-guides-deploy-md">`)
+		assert.Contains(t, html, `<a href="#note-docs-guides-deploy-md" rel="nofollow">deploy</a>`)
+		assert.Contains(t, html, `<a href="personal/diary.md" rel="nofollow">diary</a>`)
+		assert.Contains(t, html, "</html>")
+	})
+
+	t.Run("should write the notes along with the metadata as json document", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		expectBlobs(mockGithubService)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatJSON, &buf)
+		assert.NoError(t, err)
+		var doc notesDocument
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "johndoe/notes", doc.Repository)
+		assert.Equal(t, "main", doc.Branch)
+		assert.False(t, doc.ExportedAt.IsZero())
+		assert.Equal(t, []noteRecord{
+			{Path: "docs/setup.md", SHA: "sha1", Size: 73, Content: "# Setup\n\nSee [deploy](guides/deploy.md) & [diary](../personal/diary.md).\n"},
+			{Path: "docs/guides/deploy.md", SHA: "sha2", Size: 9, Content: "# Deploy\n"},
+		}, doc.Notes)
+	})
+
+	t.Run("should write the json document with empty notes when there are no notes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, []github.GitFile{}, FormatJSON, &buf)
+		assert.NoError(t, err)
+		var doc notesDocument
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "johndoe/notes", doc.Repository)
+		assert.Equal(t, []noteRecord{}, doc.Notes)
+	})
+
+	expectFailedBlob := func(mockGithubService *github.MockService) {
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), notesGhToken, github.GitFileProps{SHA: "sha1", Path: "docs/setup.md", RepoDetails: notesRepoDetails}).
+			Return(nil, errors.New("github error"))
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), notesGhToken, github.GitFileProps{SHA: "sha2", Path: "docs/guides/deploy.md", RepoDetails: notesRepoDetails}).
+			Return([]byte("# Deploy\n"), nil).MaxTimes(1)
+	}
+
+	t.Run("should return error when retrieving the content of a note fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		expectFailedBlob(mockGithubService)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatJSON, &buf)
+		assert.EqualError(t, err, "github error")
+		var doc map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+		assert.Equal(t, "export incomplete: fetching the notes failed", doc["error"])
+	})
+
+	t.Run("should leave the zip archive without central directory when retrieving the content of a note fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		expectFailedBlob(mockGithubService)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatZip, &buf)
+		assert.EqualError(t, err, "github error")
+		_, err = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.Error(t, err)
+	})
+
+	t.Run("should mark the markdown document incomplete when retrieving the content of a note fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		expectFailedBlob(mockGithubService)
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatMarkdown, &buf)
+		assert.EqualError(t, err, "github error")
+		assert.Contains(t, buf.String(), "<!-- export incomplete: fetching the notes failed -->")
+	})
+
+	t.Run("should return the fetch error when writing the incomplete marker fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		expectFailedBlob(mockGithubService)
+
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, exportedNotes, FormatMarkdown, failingWriter{})
+		assert.EqualError(t, err, "github error")
+	})
+
+	t.Run("should write the notes in order when they are fetched concurrently", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewNotesService(mockGithubService, nil)
+		notes := make([]github.GitFile, 3*fetchConcurrency)
+		var want strings.Builder
+		for i := range notes {
+			notes[i] = github.GitFile{Path: fmt.Sprintf("note-%d.md", i), SHA: fmt.Sprintf("sha%d", i)}
+			content, delay := fmt.Sprintf("# Note %d\n", i), time.Duration(len(notes)-i)*time.Millisecond
+			mockGithubService.EXPECT().GetBlob(gomock.Any(), notesGhToken, github.GitFileProps{SHA: notes[i].SHA, Path: notes[i].Path, RepoDetails: notesRepoDetails}).
+				DoAndReturn(func(context.Context, oauth2.Token, github.GitFileProps) ([]byte, error) {
+					time.Sleep(delay)
+					return []byte(content), nil
+				})
+			if i > 0 {
+				want.WriteString("\n\n")
+			}
+			want.WriteString("<!-- " + notes[i].Path + " -->\n\n" + content)
+		}
+
+		var buf bytes.Buffer
+		err := service.Write(context.Background(), notesGhToken, notesRepoDetails, notes, FormatMarkdown, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, want.String(), buf.String())
+	})
+}
+
+// failingWriter fails all the writes.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
@@ -0,0 +1,70 @@
+package export
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Repo represents an export repository.
+// It provides methods to store & retrieve the personal data exports from the database.
+//go:generate mockgen -source=repo.go -package=export -destination=mock_repo.go
+type Repo interface {
+	Save(export Export) (Export, error)
+	GetLatestByUserID(userID uint) (Export, error)
+	GetAllUnfinished() ([]Export, error)
+	DeleteExpired(expiredBefore time.Time) (int64, error)
+}
+
+type repoImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates and returns a new instance of export repository.
+func NewRepository(db *gorm.DB) Repo {
+	return &repoImpl{
+		db: db,
+	}
+}
+
+// Save stores a given export record to database.
+func (r *repoImpl) Save(export Export) (Export, error) {
+	if err := r.db.Save(&export).Error; err != nil {
+		return export, errors.Wrap(err, "storing export to database failed")
+	}
+	return export, nil
+}
+
+// GetLatestByUserID returns the latest export record of a user.
+func (r *repoImpl) GetLatestByUserID(userID uint) (Export, error) {
+	var export Export
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").First(&export).Error
+	if err == gorm.ErrRecordNotFound {
+		return Export{}, nil
+	}
+	if err != nil {
+		return export, errors.Wrap(err, "retrieving user's export from database failed")
+	}
+	return export, nil
+}
+
+// GetAllUnfinished returns the export records which are either pending or running.
+func (r *repoImpl) GetAllUnfinished() ([]Export, error) {
+	var exports []Export
+	err := r.db.Where("status in ?", []string{StatusPending, StatusRunning}).Order("created_at").Find(&exports).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving unfinished exports from database failed")
+	}
+	return exports, nil
+}
+
+// DeleteExpired permanently deletes the export records expired before the given time.
+// It returns the count of deleted records.
+func (r *repoImpl) DeleteExpired(expiredBefore time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("expires_at < ?", expiredBefore).Delete(&Export{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "deleting expired exports from database failed")
+	}
+	return result.RowsAffected, nil
+}
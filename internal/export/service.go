@@ -0,0 +1,362 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/audit"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/mail"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrExportInProgress is returned when an export is requested while the previous export of the user is not finished.
+var ErrExportInProgress = errors.New("export is already in progress")
+
+// ErrExportNotReady is returned when the archive of the export is not available for download.
+var ErrExportNotReady = errors.New("export is not ready")
+
+// Service represents a personal data export service.
+// It builds the archive of the user's data in background & provides it for download.
+//go:generate mockgen -source=service.go -package=export -destination=mock_service.go
+type Service interface {
+	Request(userID uint, includeNotes bool) (Export, error)
+	GetLatest(userID uint) (Export, error)
+	GetArchive(userID uint) (Export, io.ReadCloser, error)
+	ResumeUnfinished() error
+	DeleteExpired() (int64, error)
+}
+
+const (
+	// archive of the completed export can be downloaded within this duration
+	archiveTTL = 7 * 24 * time.Hour
+
+	// export which takes longer than this duration is failed
+	exportTimeout = 30 * time.Minute
+
+	// exports are processed in background with limited concurrency since the notes are fetched from github
+	maxConcurrentExports = 2
+)
+
+type service struct {
+	repo          Repo
+	userService   user.Service
+	authService   auth.Service
+	auditService  audit.Service
+	githubService github.Service
+	tokenService  user.TokenService
+	store         ArchiveStore
+	mailer        mail.Mailer
+	clientURL     string
+
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewService creates and returns a new export service.
+// The archives of the exports are stored in the archive store. The users are notified by email when their export is
+// finished, the email refers to the client url to download the archive.
+func NewService(repo Repo, userService user.Service, authService auth.Service, auditService audit.Service,
+	githubService github.Service, tokenService user.TokenService, store ArchiveStore, mailer mail.Mailer, clientURL string) Service {
+	return &service{
+		repo:          repo,
+		userService:   userService,
+		authService:   authService,
+		auditService:  auditService,
+		githubService: githubService,
+		tokenService:  tokenService,
+		store:         store,
+		mailer:        mailer,
+		clientURL:     clientURL,
+		slots:         make(chan struct{}, maxConcurrentExports),
+	}
+}
+
+// Request creates a new export of the user's data & starts processing it in background.
+// The notes from the user's repo are included in the archive when includeNotes is set.
+// It returns the pending export along with any error occurred while creating it.
+func (s *service) Request(userID uint, includeNotes bool) (Export, error) {
+	latest, err := s.repo.GetLatestByUserID(userID)
+	if err != nil {
+		return Export{}, err
+	}
+	if latest.Status == StatusPending || latest.Status == StatusRunning {
+		return latest, ErrExportInProgress
+	}
+	export, err := s.repo.Save(Export{
+		UserID:       userID,
+		IncludeNotes: includeNotes,
+		Status:       StatusPending,
+	})
+	if err != nil {
+		return Export{}, err
+	}
+	s.start(export)
+	return export, nil
+}
+
+// GetLatest retrieves the latest export of the user without the archive.
+// The export is empty when the user has not requested any export.
+func (s *service) GetLatest(userID uint) (Export, error) {
+	return s.repo.GetLatestByUserID(userID)
+}
+
+// GetArchive retrieves the latest export of the user along with the reader of its archive. The caller must close the reader.
+// ErrExportNotReady is returned when the latest export is not completed or it is expired.
+func (s *service) GetArchive(userID uint) (Export, io.ReadCloser, error) {
+	export, err := s.repo.GetLatestByUserID(userID)
+	if err != nil {
+		return Export{}, nil, err
+	}
+	if !export.Ready() {
+		return export, nil, ErrExportNotReady
+	}
+	archive, err := s.store.Open(export.ID)
+	if err != nil {
+		return export, nil, err
+	}
+	return export, archive, nil
+}
+
+// ResumeUnfinished starts processing the exports which were not finished before the server was stopped.
+func (s *service) ResumeUnfinished() error {
+	exports, err := s.repo.GetAllUnfinished()
+	if err != nil {
+		return err
+	}
+	for _, export := range exports {
+		s.start(export)
+	}
+	return nil
+}
+
+// DeleteExpired permanently deletes the expired exports along with their archive.
+// Archives are deleted once they are older than their validity, this also covers the archives of the purged users.
+// It returns the count of deleted exports.
+func (s *service) DeleteExpired() (int64, error) {
+	count, err := s.repo.DeleteExpired(time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.store.DeleteBefore(time.Now().Add(-archiveTTL)); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// start processes the export in background.
+func (s *service) start(export Export) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.slots <- struct{}{}
+		defer func() { <-s.slots }()
+		s.process(export)
+	}()
+}
+
+// process builds the archive of the export & stores it. The export is marked as failed when building the archive fails.
+// The user is notified once the export is finished.
+func (s *service) process(export Export) {
+	log := logrus.WithField("user-id", export.UserID).WithField("export-id", export.ID)
+	export.Status = StatusRunning
+	export, err := s.repo.Save(export)
+	if err != nil {
+		log.WithError(err).Error("starting export failed")
+		return
+	}
+	log.Info("export started")
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	u, err := s.userService.Get(export.UserID)
+	var size int64
+	if err == nil {
+		size, err = s.store.Save(export.ID, func(w io.Writer) error {
+			return s.buildArchive(ctx, export, u, w)
+		})
+	}
+	if err != nil {
+		log.WithError(err).Error("building export archive failed")
+		export.Status = StatusFailed
+		export.Error = "building export archive failed"
+	} else {
+		now := time.Now().UTC()
+		expiresAt := now.Add(archiveTTL)
+		export.Status = StatusCompleted
+		export.Size = int(size)
+		export.CompletedAt = &now
+		export.ExpiresAt = &expiresAt
+	}
+	if _, err := s.repo.Save(export); err != nil {
+		log.WithError(err).Error("storing export failed")
+		return
+	}
+	log.WithField("status", export.Status).Info("export finished")
+	if u.Email != "" {
+		if err := s.notify(u.Email, export); err != nil {
+			log.WithError(err).Warn("notifying user about the finished export failed")
+		}
+	}
+}
+
+// notify sends an email to the user informing whether the archive of the export is ready to be downloaded.
+func (s *service) notify(email string, export Export) error {
+	if export.Status != StatusCompleted {
+		return s.mailer.Send(email, "Your BatNoter data export failed",
+			fmt.Sprintf("Your data export could not be completed. Please request a new export from the account settings at %s.\n", s.clientURL))
+	}
+	return s.mailer.Send(email, "Your BatNoter data export is ready",
+		fmt.Sprintf("Your data export is ready. Download it from the account settings at %s before %s.\n",
+			s.clientURL, export.ExpiresAt.Format("January 2, 2006")))
+}
+
+// buildArchive writes the zip archive containing the profile, preferences, sessions & audit trail of the user
+// as json files. The notes are added under the notes directory with their path in the repo.
+// The archive is streamed to the writer, so the notes are never held in memory all at once.
+func (s *service) buildArchive(ctx context.Context, export Export, u user.User, w io.Writer) error {
+	sessions, err := s.authService.GetSessionHistory(export.UserID)
+	if err != nil {
+		return err
+	}
+	events, err := s.auditService.GetByUserID(export.UserID)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeJSON(zw, "profile.json", makeProfile(u)); err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "preferences.json", makePreferences(u)); err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "sessions.json", makeSessions(sessions)); err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "audit.json", makeAuditEvents(events)); err != nil {
+		return err
+	}
+	if export.IncludeNotes && u.DefaultRepo != nil && u.DefaultRepo.Name != "" {
+		if err := s.writeNotes(ctx, zw, u); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeNotes adds all the notes from the user's repo to the archive.
+// The notes are listed from the repo tree & their content is fetched by blob sha to support large files.
+func (s *service) writeNotes(ctx context.Context, zw *zip.Writer, u user.User) error {
+	repoDetails := u.GetDefaultRepoDetails()
+	ghToken, err := s.tokenService.GetRepoToken(ctx, u, repoDetails)
+	if err != nil {
+		return err
+	}
+	files, err := s.githubService.GetTree(ctx, ghToken, github.GitFileProps{RepoDetails: repoDetails})
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		content, err := s.githubService.GetBlob(ctx, ghToken, github.GitFileProps{SHA: file.SHA, Path: file.Path, RepoDetails: repoDetails})
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(path.Join("notes", file.Path))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func makeProfile(u user.User) profileRecord {
+	return profileRecord{
+		ID:             u.ID,
+		Email:          u.Email,
+		Name:           u.Name,
+		Location:       u.Location,
+		AvatarURL:      u.AvatarURL,
+		DisplayName:    u.DisplayName,
+		Timezone:       u.Timezone,
+		Locale:         u.Locale,
+		CommitName:     u.CommitName,
+		CommitEmail:    u.CommitEmail,
+		GithubID:       u.GithubID,
+		GithubUsername: u.GithubUsername,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+		DisabledAt:     u.DisabledAt,
+		DisabledReason: u.DisabledReason,
+	}
+}
+
+func makePreferences(u user.User) []preferenceRecord {
+	preferences := []preferenceRecord{}
+	if u.DefaultRepo != nil && u.DefaultRepo.ID != 0 {
+		preferences = append(preferences, preferenceRecord{
+			Name:           u.DefaultRepo.Name,
+			Owner:          u.DefaultRepo.Owner,
+			Visibility:     u.DefaultRepo.Visibility,
+			DefaultBranch:  u.DefaultRepo.DefaultBranch,
+			InstallationID: u.DefaultRepo.InstallationID,
+			CreatedAt:      u.DefaultRepo.CreatedAt,
+			UpdatedAt:      u.DefaultRepo.UpdatedAt,
+		})
+	}
+	return preferences
+}
+
+func makeSessions(sessions []auth.Session) []sessionRecord {
+	result := make([]sessionRecord, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, sessionRecord{
+			Device:     s.Device,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			Scopes:     s.Scopes,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			RevokedAt:  s.RevokedAt,
+		})
+	}
+	return result
+}
+
+func makeAuditEvents(events []audit.Event) []auditEventRecord {
+	result := make([]auditEventRecord, 0, len(events))
+	for _, e := range events {
+		result = append(result, auditEventRecord{
+			ActorID:   e.ActorID,
+			Action:    e.Action,
+			Reason:    e.Reason,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,311 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/audit"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/mail"
+	"github.com/batnoter/batnoter-api/internal/preference"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	userID    = uint(1001)
+	exportID  = uint(41)
+	clientURL = "https://batnoter.com"
+)
+
+func TestRequest(t *testing.T) {
+	t.Run("should create the export & build the archive with user's data in background", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+		mockMailer := mail.NewMockMailer(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, mockUserService, mockAuthService, mockAuditService, nil, nil, store, mockMailer, clientURL)
+
+		var saved []Export
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Export{}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(export Export) (Export, error) {
+			export.ID = exportID
+			saved = append(saved, export)
+			return export, nil
+		}).Times(3)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockAuthService.EXPECT().GetSessionHistory(userID).Return([]auth.Session{{Device: "Chrome on macOS", IPAddress: "203.0.113.10"}}, nil)
+		mockAuditService.EXPECT().GetByUserID(userID).Return([]audit.Event{{Action: audit.ActionUserDisabled}}, nil)
+		mockMailer.EXPECT().Send("john.doe@example.com", "Your BatNoter data export is ready", gomock.Any()).
+			DoAndReturn(func(to string, subject string, body string) error {
+				assert.Contains(t, body, clientURL)
+				return nil
+			})
+
+		export, err := service.Request(userID, false)
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusPending, export.Status)
+		assert.Equal(t, []string{StatusPending, StatusRunning, StatusCompleted}, []string{saved[0].Status, saved[1].Status, saved[2].Status})
+
+		completed := saved[2]
+		assert.True(t, completed.Ready())
+		archive := readStoredArchive(t, store)
+		assert.Equal(t, len(archive), completed.Size)
+		files := readArchive(t, archive)
+		assert.ElementsMatch(t, []string{"profile.json", "preferences.json", "sessions.json", "audit.json"}, keys(files))
+		assert.Contains(t, files["profile.json"], `"email": "john.doe@example.com"`)
+		assert.NotContains(t, files["profile.json"], "gho_token")
+		assert.Contains(t, files["preferences.json"], `"name": "notes"`)
+		assert.Contains(t, files["sessions.json"], `"ip_address": "203.0.113.10"`)
+		assert.Contains(t, files["audit.json"], `"action": "user.disabled"`)
+	})
+
+	t.Run("should add the notes from the repo to the archive when notes are included", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockMailer := mail.NewMockMailer(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, mockUserService, mockAuthService, mockAuditService, mockGithubService, mockTokenService, store, mockMailer, clientURL)
+		u := validUser()
+		ghToken := oauth2.Token{AccessToken: "gho_token"}
+		repoDetails := github.GitRepoProps{Repository: "notes", DefaultBranch: "main", Owner: "johndoe"}
+
+		var completed Export
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Export{Status: StatusFailed}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(export Export) (Export, error) {
+			export.ID = exportID
+			completed = export
+			return export, nil
+		}).Times(3)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockAuthService.EXPECT().GetSessionHistory(userID).Return(nil, nil)
+		mockAuditService.EXPECT().GetByUserID(userID).Return(nil, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockGithubService.EXPECT().GetTree(gomock.Any(), ghToken, github.GitFileProps{RepoDetails: repoDetails}).
+			Return([]github.GitFile{{SHA: "sha1", Path: "todo.md"}, {SHA: "sha2", Path: "work/meeting.md"}}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha1", Path: "todo.md", RepoDetails: repoDetails}).Return([]byte("# Todo"), nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha2", Path: "work/meeting.md", RepoDetails: repoDetails}).Return([]byte("# Meeting"), nil)
+		mockMailer.EXPECT().Send("john.doe@example.com", "Your BatNoter data export is ready", gomock.Any()).Return(nil)
+
+		_, err := service.Request(userID, true)
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusCompleted, completed.Status)
+		files := readArchive(t, readStoredArchive(t, store))
+		assert.Equal(t, "# Todo", files["notes/todo.md"])
+		assert.Equal(t, "# Meeting", files["notes/work/meeting.md"])
+	})
+
+	t.Run("should mark the export as failed when building the archive fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		mockMailer := mail.NewMockMailer(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, mockUserService, mockAuthService, nil, nil, nil, store, mockMailer, clientURL)
+
+		var failed Export
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Export{}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(export Export) (Export, error) {
+			failed = export
+			return export, nil
+		}).Times(3)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockAuthService.EXPECT().GetSessionHistory(userID).Return(nil, errors.New("some error"))
+		mockMailer.EXPECT().Send("john.doe@example.com", "Your BatNoter data export failed", gomock.Any()).Return(nil)
+
+		_, err := service.Request(userID, false)
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Equal(t, "building export archive failed", failed.Error)
+		_, err = store.Open(exportID)
+		assert.Error(t, err)
+		entries, _ := os.ReadDir(store.(*fileStore).dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("should return error when the previous export is in progress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, nil, nil, nil, nil, nil, store, nil, clientURL)
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Export{Status: StatusRunning}, nil)
+
+		_, err := service.Request(userID, false)
+		assert.ErrorIs(t, err, ErrExportInProgress)
+	})
+}
+
+func TestGetArchive(t *testing.T) {
+	t.Run("should return the archive of the completed export", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, nil, nil, nil, nil, nil, store, nil, clientURL)
+		expiresAt := time.Now().Add(time.Hour)
+		completed := Export{Model: gorm.Model{ID: exportID}, UserID: userID, Status: StatusCompleted, ExpiresAt: &expiresAt}
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(completed, nil)
+		_, err := store.Save(exportID, func(w io.Writer) error {
+			_, err := w.Write([]byte("archive"))
+			return err
+		})
+		assert.NoError(t, err)
+
+		export, archive, err := service.GetArchive(userID)
+		assert.NoError(t, err)
+		defer archive.Close()
+		assert.Equal(t, completed, export)
+		content, _ := io.ReadAll(archive)
+		assert.Equal(t, "archive", string(content))
+	})
+
+	t.Run("should return export not ready error when the export is expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, nil, nil, nil, nil, nil, store, nil, clientURL)
+		expiresAt := time.Now().Add(-time.Hour)
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Export{Model: gorm.Model{ID: exportID}, Status: StatusCompleted, ExpiresAt: &expiresAt}, nil)
+
+		_, _, err := service.GetArchive(userID)
+		assert.ErrorIs(t, err, ErrExportNotReady)
+	})
+
+	t.Run("should return export not ready error when the export is running", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, nil, nil, nil, nil, nil, store, nil, clientURL)
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Export{Model: gorm.Model{ID: exportID}, Status: StatusRunning}, nil)
+
+		_, _, err := service.GetArchive(userID)
+		assert.ErrorIs(t, err, ErrExportNotReady)
+	})
+}
+
+func TestResumeUnfinished(t *testing.T) {
+	t.Run("should process the unfinished exports", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, mockUserService, nil, nil, nil, nil, store, nil, clientURL)
+		mockRepo.EXPECT().GetAllUnfinished().Return([]Export{{Model: gorm.Model{ID: exportID}, UserID: userID, Status: StatusRunning}}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(export Export) (Export, error) { return export, nil }).Times(2)
+		mockUserService.EXPECT().Get(userID).Return(user.User{}, errors.New("some error"))
+
+		err := service.ResumeUnfinished()
+		wait(service)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDeleteExpired(t *testing.T) {
+	t.Run("should delete the expired exports along with the archives older than their validity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		store := newFileStore(t)
+		service := NewService(mockRepo, nil, nil, nil, nil, nil, store, nil, clientURL)
+		for _, id := range []uint{exportID, exportID + 1} {
+			_, err := store.Save(id, func(w io.Writer) error { return nil })
+			assert.NoError(t, err)
+		}
+		oldTime := time.Now().Add(-archiveTTL - time.Hour)
+		assert.NoError(t, os.Chtimes(store.(*fileStore).path(exportID), oldTime, oldTime))
+		mockRepo.EXPECT().DeleteExpired(gomock.Any()).Return(int64(1), nil)
+
+		count, err := service.DeleteExpired()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		_, err = store.Open(exportID)
+		assert.Error(t, err)
+		archive, err := store.Open(exportID + 1)
+		assert.NoError(t, err)
+		archive.Close()
+	})
+}
+
+func validUser() user.User {
+	u := user.User{
+		Email:          "john.doe@example.com",
+		Name:           "John Doe",
+		GithubID:       12345,
+		GithubUsername: "johndoe",
+		GithubToken:    `{"access_token":"gho_token"}`,
+		DefaultRepo:    &preference.DefaultRepo{Name: "notes", Visibility: "private", DefaultBranch: "main"},
+	}
+	u.ID = userID
+	u.DefaultRepo.ID = 1
+	return u
+}
+
+func readArchive(t *testing.T, archive []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func keys(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
+
+func newFileStore(t *testing.T) ArchiveStore {
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	return store
+}
+
+func readStoredArchive(t *testing.T, store ArchiveStore) []byte {
+	r, err := store.Open(exportID)
+	assert.NoError(t, err)
+	defer r.Close()
+	archive, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	return archive
+}
+
+// wait waits until the exports started by the service are processed.
+func wait(s Service) {
+	s.(*service).wg.Wait()
+}
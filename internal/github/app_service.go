@@ -0,0 +1,237 @@
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/go-github/v43/github"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrInstallationRequired is returned when the github app is enabled but the repo is not linked with an app installation.
+// The user oauth2 token does not have the repo scope in this mode, so the repo can only be accessed once the app is installed.
+var ErrInstallationRequired = errors.New("github app installation required")
+
+// AppService represents a github app service.
+// It provides methods to authenticate as a github app installation scoped to the notes repository.
+//go:generate mockgen -source=app_service.go -package=github -destination=mock_app_service.go
+type AppService interface {
+	Enabled() bool
+	GetInstallURL(state string) string
+	GetInstallationToken(ctx context.Context, installationID int64, repo string) (oauth2.Token, error)
+	VerifyInstallation(ctx context.Context, ghToken oauth2.Token, installationID int64) error
+	FindInstallation(ctx context.Context, ghToken oauth2.Token, account string) (int64, error)
+	GetInstallationRepos(ctx context.Context, ghToken oauth2.Token, installationID int64) ([]GitRepo, error)
+}
+
+// AppConfig fields will be used to sign the github app jwt & mint the installation tokens.
+type AppConfig struct {
+	AppID      int64
+	Slug       string
+	PrivateKey *rsa.PrivateKey
+}
+
+type appService struct {
+	appConfig     AppConfig
+	clientBuilder ClientBuilder
+
+	mu     sync.Mutex
+	tokens map[installationRepo]oauth2.Token
+	group  singleflight.Group
+}
+
+// installationRepo identifies the installation tokens scoped to a repo of the installation.
+type installationRepo struct {
+	installationID int64
+	repo           string
+}
+
+const (
+	// installation tokens are valid for an hour, refresh them a bit early to avoid using expired tokens
+	installationTokenExpiryDelta = 5 * time.Minute
+	// github rejects app jwt valid for more than 10 minutes
+	appTokenTTL = 9 * time.Minute
+	// the installation token is minted independent of the request waiting for it, since the other requests share it
+	installationTokenTimeout = 30 * time.Second
+)
+
+// NewAppService creates and returns a new github app service.
+func NewAppService(appConfig AppConfig, clientBuilder ClientBuilder) AppService {
+	return &appService{
+		appConfig:     appConfig,
+		clientBuilder: clientBuilder,
+		tokens:        make(map[installationRepo]oauth2.Token),
+	}
+}
+
+// ParseAppPrivateKey parses the PEM encoded github app private key.
+func ParseAppPrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing github app private key failed")
+	}
+	return key, nil
+}
+
+// Enabled returns true if the github app authentication is configured.
+func (a *appService) Enabled() bool {
+	return a.appConfig.AppID != 0
+}
+
+// GetInstallURL returns the github app installation url containing provided state token.
+func (a *appService) GetInstallURL(state string) string {
+	return fmt.Sprintf("https://github.com/apps/%s/installations/new?state=%s", a.appConfig.Slug, state)
+}
+
+// GetInstallationToken returns the installation access token for a github app installation scoped to the repo.
+// The tokens are cached per installation & repo and minted again only when they are about to expire.
+// Concurrent requests of the same installation & repo share a single call to github, the others are not blocked.
+// The shared call is not cancelled along with the context of the request waiting for it.
+func (a *appService) GetInstallationToken(ctx context.Context, installationID int64, repo string) (oauth2.Token, error) {
+	key := installationRepo{installationID: installationID, repo: repo}
+	if token, ok := a.cachedToken(key); ok {
+		return token, nil
+	}
+	ch := a.group.DoChan(strconv.FormatInt(installationID, 10)+"/"+repo, func() (interface{}, error) {
+		// token may have been minted by another request while this one was checking the cache
+		if token, ok := a.cachedToken(key); ok {
+			return token, nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), installationTokenTimeout)
+		defer cancel()
+		token, err := a.createInstallationToken(ctx, key)
+		if err != nil {
+			return oauth2.Token{}, err
+		}
+		a.mu.Lock()
+		a.tokens[key] = token
+		a.mu.Unlock()
+		return token, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return oauth2.Token{}, res.Err
+		}
+		return res.Val.(oauth2.Token), nil
+	case <-ctx.Done():
+		return oauth2.Token{}, ctx.Err()
+	}
+}
+
+// cachedToken returns the cached installation token if it is not about to expire.
+func (a *appService) cachedToken(key installationRepo) (oauth2.Token, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	token, ok := a.tokens[key]
+	return token, ok && token.Expiry.After(time.Now().Add(installationTokenExpiryDelta))
+}
+
+// createInstallationToken mints a new installation access token on github which can only access the repo.
+func (a *appService) createInstallationToken(ctx context.Context, key installationRepo) (oauth2.Token, error) {
+	appToken, err := a.generateAppToken()
+	if err != nil {
+		return oauth2.Token{}, err
+	}
+	client := a.clientBuilder.Build(ctx, &oauth2.Token{AccessToken: appToken, TokenType: "Bearer"})
+	opts := &github.InstallationTokenOptions{Repositories: []string{key.repo}}
+	it, _, err := client.Apps.CreateInstallationToken(ctx, key.installationID, opts)
+	if err != nil {
+		return oauth2.Token{}, errors.Wrap(err, "creating installation token on github failed")
+	}
+	return oauth2.Token{
+		AccessToken: it.GetToken(),
+		TokenType:   "token",
+		Expiry:      it.GetExpiresAt(),
+	}, nil
+}
+
+// VerifyInstallation checks that the installation is accessible to the user owning the github oauth2 token.
+// It returns an error if the installation does not belong to the user.
+func (a *appService) VerifyInstallation(ctx context.Context, ghToken oauth2.Token, installationID int64) error {
+	client := a.clientBuilder.Build(ctx, &ghToken)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		installations, resp, err := client.Apps.ListUserInstallations(ctx, opts)
+		if err != nil {
+			return errors.Wrap(err, "retrieving user's app installations from github failed")
+		}
+		for _, installation := range installations {
+			if installation.GetID() == installationID && installation.GetAppID() == a.appConfig.AppID {
+				return nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return errors.New("app installation is not accessible to the user")
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// FindInstallation returns the id of the app installation on the github account (user or organization)
+// that is accessible to the user owning the github oauth2 token.
+// ErrInstallationRequired is returned when the app is not installed on the account.
+func (a *appService) FindInstallation(ctx context.Context, ghToken oauth2.Token, account string) (int64, error) {
+	client := a.clientBuilder.Build(ctx, &ghToken)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		installations, resp, err := client.Apps.ListUserInstallations(ctx, opts)
+		if err != nil {
+			return 0, errors.Wrap(err, "retrieving user's app installations from github failed")
+		}
+		for _, installation := range installations {
+			if installation.GetAppID() == a.appConfig.AppID && strings.EqualFold(installation.GetAccount().GetLogin(), account) {
+				return installation.GetID(), nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return 0, ErrInstallationRequired
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetInstallationRepos fetches the repos of an installation that are accessible to the user.
+// It returns the github repos with any error occurred while fetching it from github.
+func (a *appService) GetInstallationRepos(ctx context.Context, ghToken oauth2.Token, installationID int64) ([]GitRepo, error) {
+	client := a.clientBuilder.Build(ctx, &ghToken)
+	opts := &github.ListOptions{PerPage: 100}
+	var repos []GitRepo
+	for {
+		lr, resp, err := client.Apps.ListUserRepos(ctx, installationID, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "retrieving installation repos from github failed")
+		}
+		for _, gitRepo := range lr.Repositories {
+			repos = append(repos, makeGitRepo(gitRepo))
+		}
+		if resp.NextPage == 0 {
+			return repos, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (a *appService) generateAppToken() (string, error) {
+	now := time.Now()
+	claims := jwt.StandardClaims{
+		// issue the token a minute in the past to allow for clock drift with github
+		IssuedAt:  now.Add(-time.Minute).Unix(),
+		ExpiresAt: now.Add(appTokenTTL).Unix(),
+		Issuer:    strconv.FormatInt(a.appConfig.AppID, 10),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	t, err := token.SignedString(a.appConfig.PrivateKey)
+	if err != nil {
+		return "", errors.Wrap(err, "signing github app token failed")
+	}
+	return t, nil
+}
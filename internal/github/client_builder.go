@@ -12,6 +12,7 @@ import (
 //go:generate mockgen -source=client_builder.go -package=github -destination=mock_client_builder.go
 type ClientBuilder interface {
 	Build(ctx context.Context, token *oauth2.Token) *github.Client
+	BuildOAuth2AppClient(ctx context.Context) *github.Client
 	GetOAuth2Config() *oauth2.Config
 }
 
@@ -27,8 +28,19 @@ func NewClientBuilder(oauth2Config *oauth2.Config) ClientBuilder {
 }
 
 // Build creates and returns a github oauth2 client using oauth2 token.
+// The token is used as is, it is refreshed & persisted by the caller before building the client.
 func (c *clientBuilder) Build(ctx context.Context, token *oauth2.Token) *github.Client {
-	return github.NewClient(c.oauth2Config.Client(ctx, token))
+	return github.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(token)))
+}
+
+// BuildOAuth2AppClient creates and returns a github client authenticated as the oauth2 app using client id & secret.
+// It is used to manage the oauth2 grants of the users.
+func (c *clientBuilder) BuildOAuth2AppClient(ctx context.Context) *github.Client {
+	transport := github.BasicAuthTransport{
+		Username: c.oauth2Config.ClientID,
+		Password: c.oauth2Config.ClientSecret,
+	}
+	return github.NewClient(transport.Client())
 }
 
 // GetOAuth2Config returns oauth2 config.
@@ -0,0 +1,44 @@
+package github
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestBuild(t *testing.T) {
+	t.Run("should use the token as is without refreshing the expired token", func(t *testing.T) {
+		var refreshCount int32
+		var authorization string
+		router := gin.New()
+		router.POST("/login/oauth/access_token", func(c *gin.Context) {
+			atomic.AddInt32(&refreshCount, 1)
+			c.JSON(200, gin.H{"access_token": "gho_refreshed_token", "token_type": "bearer"})
+		})
+		router.GET("/user", func(c *gin.Context) {
+			authorization = c.GetHeader("Authorization")
+			c.JSON(200, gin.H{"login": "johndoe"})
+		})
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		clientBuilder := NewClientBuilder(&oauth2.Config{
+			Endpoint: oauth2.Endpoint{TokenURL: server.URL + "/login/oauth/access_token"},
+		})
+		token := &oauth2.Token{AccessToken: "gho_token", TokenType: "bearer", RefreshToken: "ghr_token", Expiry: time.Now().Add(-time.Hour)}
+		client := clientBuilder.Build(context.Background(), token)
+		client.BaseURL, _ = url.Parse(server.URL + "/")
+
+		_, _, err := client.Users.Get(context.Background(), "")
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer gho_token", authorization)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&refreshCount))
+	})
+}
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: app_service.go
+
+// Package github is a generated GoMock package.
+package github
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	oauth2 "golang.org/x/oauth2"
+)
+
+// MockAppService is a mock of AppService interface.
+type MockAppService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAppServiceMockRecorder
+}
+
+// MockAppServiceMockRecorder is the mock recorder for MockAppService.
+type MockAppServiceMockRecorder struct {
+	mock *MockAppService
+}
+
+// NewMockAppService creates a new mock instance.
+func NewMockAppService(ctrl *gomock.Controller) *MockAppService {
+	mock := &MockAppService{ctrl: ctrl}
+	mock.recorder = &MockAppServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAppService) EXPECT() *MockAppServiceMockRecorder {
+	return m.recorder
+}
+
+// Enabled mocks base method.
+func (m *MockAppService) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockAppServiceMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockAppService)(nil).Enabled))
+}
+
+// FindInstallation mocks base method.
+func (m *MockAppService) FindInstallation(ctx context.Context, ghToken oauth2.Token, account string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInstallation", ctx, ghToken, account)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInstallation indicates an expected call of FindInstallation.
+func (mr *MockAppServiceMockRecorder) FindInstallation(ctx, ghToken, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInstallation", reflect.TypeOf((*MockAppService)(nil).FindInstallation), ctx, ghToken, account)
+}
+
+// GetInstallURL mocks base method.
+func (m *MockAppService) GetInstallURL(state string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstallURL", state)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetInstallURL indicates an expected call of GetInstallURL.
+func (mr *MockAppServiceMockRecorder) GetInstallURL(state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallURL", reflect.TypeOf((*MockAppService)(nil).GetInstallURL), state)
+}
+
+// GetInstallationRepos mocks base method.
+func (m *MockAppService) GetInstallationRepos(ctx context.Context, ghToken oauth2.Token, installationID int64) ([]GitRepo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstallationRepos", ctx, ghToken, installationID)
+	ret0, _ := ret[0].([]GitRepo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstallationRepos indicates an expected call of GetInstallationRepos.
+func (mr *MockAppServiceMockRecorder) GetInstallationRepos(ctx, ghToken, installationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallationRepos", reflect.TypeOf((*MockAppService)(nil).GetInstallationRepos), ctx, ghToken, installationID)
+}
+
+// GetInstallationToken mocks base method.
+func (m *MockAppService) GetInstallationToken(ctx context.Context, installationID int64, repo string) (oauth2.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInstallationToken", ctx, installationID, repo)
+	ret0, _ := ret[0].(oauth2.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInstallationToken indicates an expected call of GetInstallationToken.
+func (mr *MockAppServiceMockRecorder) GetInstallationToken(ctx, installationID, repo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstallationToken", reflect.TypeOf((*MockAppService)(nil).GetInstallationToken), ctx, installationID, repo)
+}
+
+// VerifyInstallation mocks base method.
+func (m *MockAppService) VerifyInstallation(ctx context.Context, ghToken oauth2.Token, installationID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyInstallation", ctx, ghToken, installationID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyInstallation indicates an expected call of VerifyInstallation.
+func (mr *MockAppServiceMockRecorder) VerifyInstallation(ctx, ghToken, installationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyInstallation", reflect.TypeOf((*MockAppService)(nil).VerifyInstallation), ctx, ghToken, installationID)
+}
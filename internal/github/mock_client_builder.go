@@ -50,6 +50,20 @@ func (mr *MockClientBuilderMockRecorder) Build(ctx, token interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockClientBuilder)(nil).Build), ctx, token)
 }
 
+// BuildOAuth2AppClient mocks base method.
+func (m *MockClientBuilder) BuildOAuth2AppClient(ctx context.Context) *github.Client {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildOAuth2AppClient", ctx)
+	ret0, _ := ret[0].(*github.Client)
+	return ret0
+}
+
+// BuildOAuth2AppClient indicates an expected call of BuildOAuth2AppClient.
+func (mr *MockClientBuilderMockRecorder) BuildOAuth2AppClient(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildOAuth2AppClient", reflect.TypeOf((*MockClientBuilder)(nil).BuildOAuth2AppClient), ctx)
+}
+
 // GetOAuth2Config mocks base method.
 func (m *MockClientBuilder) GetOAuth2Config() *oauth2.Config {
 	m.ctrl.T.Helper()
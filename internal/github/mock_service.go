@@ -36,19 +36,34 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CommitFiles mocks base method.
+func (m *MockService) CommitFiles(ctx context.Context, ghToken oauth2.Token, commitProps GitCommitProps) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitFiles", ctx, ghToken, commitProps)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitFiles indicates an expected call of CommitFiles.
+func (mr *MockServiceMockRecorder) CommitFiles(ctx, ghToken, commitProps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitFiles", reflect.TypeOf((*MockService)(nil).CommitFiles), ctx, ghToken, commitProps)
+}
+
 // CreateRepo mocks base method.
-func (m *MockService) CreateRepo(ctx context.Context, ghToken oauth2.Token, repoName string) (GitRepo, error) {
+func (m *MockService) CreateRepo(ctx context.Context, ghToken oauth2.Token, org, repoName string) (GitRepo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRepo", ctx, ghToken, repoName)
+	ret := m.ctrl.Call(m, "CreateRepo", ctx, ghToken, org, repoName)
 	ret0, _ := ret[0].(GitRepo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateRepo indicates an expected call of CreateRepo.
-func (mr *MockServiceMockRecorder) CreateRepo(ctx, ghToken, repoName interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) CreateRepo(ctx, ghToken, org, repoName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepo", reflect.TypeOf((*MockService)(nil).CreateRepo), ctx, ghToken, repoName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepo", reflect.TypeOf((*MockService)(nil).CreateRepo), ctx, ghToken, org, repoName)
 }
 
 // DeleteFile mocks base method.
@@ -94,6 +109,21 @@ func (mr *MockServiceMockRecorder) GetAuthCodeURL(state interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthCodeURL", reflect.TypeOf((*MockService)(nil).GetAuthCodeURL), state)
 }
 
+// GetBlob mocks base method.
+func (m *MockService) GetBlob(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlob", ctx, ghToken, fileProps)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlob indicates an expected call of GetBlob.
+func (mr *MockServiceMockRecorder) GetBlob(ctx, ghToken, fileProps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlob", reflect.TypeOf((*MockService)(nil).GetBlob), ctx, ghToken, fileProps)
+}
+
 // GetFile mocks base method.
 func (m *MockService) GetFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) (GitFile, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +139,21 @@ func (mr *MockServiceMockRecorder) GetFile(ctx, ghToken, fileProps interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFile", reflect.TypeOf((*MockService)(nil).GetFile), ctx, ghToken, fileProps)
 }
 
+// GetRepo mocks base method.
+func (m *MockService) GetRepo(ctx context.Context, ghToken oauth2.Token, owner, repoName string) (GitRepo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepo", ctx, ghToken, owner, repoName)
+	ret0, _ := ret[0].(GitRepo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepo indicates an expected call of GetRepo.
+func (mr *MockServiceMockRecorder) GetRepo(ctx, ghToken, owner, repoName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepo", reflect.TypeOf((*MockService)(nil).GetRepo), ctx, ghToken, owner, repoName)
+}
+
 // GetRepos mocks base method.
 func (m *MockService) GetRepos(ctx context.Context, ghToken oauth2.Token) ([]GitRepo, error) {
 	m.ctrl.T.Helper()
@@ -169,6 +214,35 @@ func (mr *MockServiceMockRecorder) GetUser(ctx, ghToken interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockService)(nil).GetUser), ctx, ghToken)
 }
 
+// ReplaceBranch mocks base method.
+func (m *MockService) ReplaceBranch(ctx context.Context, ghToken oauth2.Token, commitProps GitCommitProps) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceBranch", ctx, ghToken, commitProps)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceBranch indicates an expected call of ReplaceBranch.
+func (mr *MockServiceMockRecorder) ReplaceBranch(ctx, ghToken, commitProps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceBranch", reflect.TypeOf((*MockService)(nil).ReplaceBranch), ctx, ghToken, commitProps)
+}
+
+// RevokeGrant mocks base method.
+func (m *MockService) RevokeGrant(ctx context.Context, ghToken oauth2.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeGrant", ctx, ghToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeGrant indicates an expected call of RevokeGrant.
+func (mr *MockServiceMockRecorder) RevokeGrant(ctx, ghToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeGrant", reflect.TypeOf((*MockService)(nil).RevokeGrant), ctx, ghToken)
+}
+
 // SaveFile mocks base method.
 func (m *MockService) SaveFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) (GitFile, error) {
 	m.ctrl.T.Helper()
@@ -199,3 +273,18 @@ func (mr *MockServiceMockRecorder) SearchFiles(ctx, ghToken, fileProps, query, p
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFiles", reflect.TypeOf((*MockService)(nil).SearchFiles), ctx, ghToken, fileProps, query, pageNo)
 }
+
+// StatFile mocks base method.
+func (m *MockService) StatFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) (GitFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatFile", ctx, ghToken, fileProps)
+	ret0, _ := ret[0].(GitFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatFile indicates an expected call of StatFile.
+func (mr *MockServiceMockRecorder) StatFile(ctx, ghToken, fileProps interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatFile", reflect.TypeOf((*MockService)(nil).StatFile), ctx, ghToken, fileProps)
+}
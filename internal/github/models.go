@@ -5,6 +5,19 @@ type GitRepoProps struct {
 	Repository    string
 	DefaultBranch string
 	Owner         string
+	NoteFiles     NoteFileRules // identifies the note files of the repo, the defaults are used when not set
+
+	// InstallationID is the id of github app installation used to access the repo.
+	// It is zero when the repo is accessed with the user's oauth2 token.
+	InstallationID int64
+}
+
+// NoteFileRules used to identify the note files of the repo.
+// Extensions are the file extensions of the notes without the leading dot, DefaultNoteExtensions are used when empty.
+// PathPattern is the regex the path of the notes (without the extension) must match, ValidNotePathRegex is used when empty.
+type NoteFileRules struct {
+	Extensions  []string
+	PathPattern string
 }
 
 // GitFileProps used to provide request details to github client
@@ -17,6 +30,16 @@ type GitFileProps struct {
 	RepoDetails GitRepoProps
 }
 
+// GitCommitProps used to provide the files committed to a branch to github client
+type GitCommitProps struct {
+	Branch      string
+	Message     string
+	Files       map[string]string // content of the files by their path
+	AuthorName  string
+	AuthorEmail string
+	RepoDetails GitRepoProps
+}
+
 // GitFile used to provide response to file request
 type GitFile struct {
 	SHA     string // this is a blob sha (not commit sha)
@@ -29,6 +52,8 @@ type GitFile struct {
 // GitRepo used to provide response to repos request
 type GitRepo struct {
 	Name          string
+	Owner         string
 	Visibility    string
 	DefaultBranch string
+	Writable      bool // whether the user can push to the repo
 }
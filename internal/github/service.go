@@ -2,14 +2,25 @@ package github
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"net/http"
+	"path"
 	"regexp"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/go-github/v43/github"
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2"
 )
 
+// ErrRepoNotFound is returned when the repo does not exist or it is not accessible to the user.
+var ErrRepoNotFound = errors.New("repo not found")
+
+// ErrFileNotFound is returned when the file does not exist in the repo.
+var ErrFileNotFound = errors.New("file not found")
+
 // Service represents a github service.
 // It provides methods to manage github resources using oauth2 api.
 //go:generate mockgen -source=service.go -package=github -destination=mock_service.go
@@ -17,15 +28,21 @@ type Service interface {
 	GetAuthCodeURL(state string) string
 	GetToken(ctx context.Context, code string) (oauth2.Token, error)
 	GetUser(ctx context.Context, ghToken oauth2.Token) (github.User, error)
+	RevokeGrant(ctx context.Context, ghToken oauth2.Token) error
 
 	GetRepos(ctx context.Context, ghToken oauth2.Token) ([]GitRepo, error)
-	CreateRepo(ctx context.Context, ghToken oauth2.Token, repoName string) (GitRepo, error)
+	GetRepo(ctx context.Context, ghToken oauth2.Token, owner string, repoName string) (GitRepo, error)
+	CreateRepo(ctx context.Context, ghToken oauth2.Token, org string, repoName string) (GitRepo, error)
 	SearchFiles(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps, query string, pageNo int) ([]GitFile, int, error)
 	GetTree(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) ([]GitFile, error)
 	GetAllFiles(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) ([]GitFile, error)
 	GetFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) (GitFile, error)
+	GetBlob(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) ([]byte, error)
+	StatFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) (GitFile, error)
 	SaveFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) (GitFile, error)
 	DeleteFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) error
+	ReplaceBranch(ctx context.Context, ghToken oauth2.Token, commitProps GitCommitProps) (string, error)
+	CommitFiles(ctx context.Context, ghToken oauth2.Token, commitProps GitCommitProps) (string, error)
 }
 
 type service struct {
@@ -42,16 +59,28 @@ func NewService(clientBuilder ClientBuilder) Service {
 const (
 	fileType      = "file"
 	blobType      = "blob"
+	blobFileMode  = "100644"
 	commitMessage = "Created with BatNoter"
-	affiliation   = "owner"
-	fileExtension = "md"
+	affiliation   = "owner,collaborator,organization_member"
+	reposPageSize = 100
 	pageSize      = 20
 
-	// ValidFilePathRegex validates the file path of the files stored/retrieved to/from github.
-	// We only allow markdown files with or without parent directories.
-	ValidFilePathRegex = `(?m)^([a-zA-Z0-9-]([/][a-zA-Z0-9-])?[^\S\r\n]?[a-zA-Z0-9-]?)+(\.md)$`
+	// the names of the folders start with a letter, digit, underscore or hyphen & may contain single spaces
+	validFolderNameRegex = `[\p{L}\p{N}_-](?:[\p{L}\p{M}\p{N}_-]| [\p{L}\p{M}\p{N}_-])*`
+
+	// ValidFolderPathRegex validates the path of the folders of the notes. Non-ascii letters are allowed.
+	ValidFolderPathRegex = validFolderNameRegex + `(?:/` + validFolderNameRegex + `)*`
+
+	// ValidNotePathRegex validates the path of the notes without the file extension.
+	// The names of the folders & the files start with a letter, digit, underscore or hyphen & may contain single spaces,
+	// the names of the files may also contain dots. Non-ascii letters are allowed.
+	ValidNotePathRegex = `(?:` + validFolderNameRegex + `/)*` +
+		`[\p{L}\p{N}_-](?:[\p{L}\p{M}\p{N}_.-]| [\p{L}\p{M}\p{N}_.-])*`
 )
 
+// DefaultNoteExtensions are the file extensions of the notes when the repo does not configure them.
+var DefaultNoteExtensions = []string{"md"}
+
 // GetAuthCodeURL generates and returns an auth code url containing provided state token.
 func (s *service) GetAuthCodeURL(state string) string {
 	// AuthCodeURL receive state that is a token to protect the user from CSRF attacks.
@@ -99,31 +128,75 @@ func (s *service) GetUser(ctx context.Context, ghToken oauth2.Token) (github.Use
 	return *githubUser, nil
 }
 
-// GetRepos fetches the user's repos from github provider using github oauth2 token.
+// RevokeGrant revokes the oauth2 grant of the user using github oauth2 token.
+// All the tokens issued to the app for the user are revoked & the app is removed from the user's authorized apps.
+// It returns any error occurred while revoking the grant, the grant which is already revoked is ignored.
+func (s *service) RevokeGrant(ctx context.Context, ghToken oauth2.Token) error {
+	if ghToken.AccessToken == "" {
+		return nil
+	}
+	client := s.clientBuilder.BuildOAuth2AppClient(ctx)
+	resp, err := client.Authorizations.DeleteGrant(ctx, s.clientBuilder.GetOAuth2Config().ClientID, ghToken.AccessToken)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// the grant is already revoked by the user from github settings
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "revoking user's oauth2 grant on github failed")
+	}
+	return nil
+}
+
+// GetRepos fetches the repos owned by the user, the repos of the user's organizations & the repos shared with the user
+// from github provider using github oauth2 token. Only the repos the user can write to are returned since the notes
+// are committed to the repo. All the pages are fetched from github.
 // It returns the github repos with any error occurred while fetching it from github.
 func (s *service) GetRepos(ctx context.Context, ghToken oauth2.Token) ([]GitRepo, error) {
 	client := s.clientBuilder.Build(ctx, &ghToken)
 	opts := &github.RepositoryListOptions{
 		Affiliation: affiliation,
+		ListOptions: github.ListOptions{Page: 1, PerPage: reposPageSize},
 	}
-	gitRepos, _, err := client.Repositories.List(ctx, "", opts)
-	if err != nil {
-		return nil, errors.Wrap(err, "retrieving user's repos from github failed")
-	}
-	repos := make([]GitRepo, 0, len(gitRepos))
-	for _, gitRepo := range gitRepos {
-		repos = append(repos, GitRepo{
-			Name:          gitRepo.GetName(),
-			Visibility:    gitRepo.GetVisibility(),
-			DefaultBranch: gitRepo.GetDefaultBranch(),
-		})
+	repos := make([]GitRepo, 0)
+	for {
+		gitRepos, resp, err := client.Repositories.List(ctx, "", opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "retrieving user's repos from github failed")
+		}
+		for _, gitRepo := range gitRepos {
+			repo := makeGitRepo(gitRepo)
+			if repo.Writable {
+				repos = append(repos, repo)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
 	return repos, nil
 }
 
+// GetRepo fetches the repo of the owner from github provider using github oauth2 token.
+// The owner can be the user, an organization or another user who shared the repo with the user.
+// It returns the github repo along with the user's write permission on it & any error occurred while fetching it from github.
+// ErrRepoNotFound is returned when the repo does not exist or the user can not access it.
+func (s *service) GetRepo(ctx context.Context, ghToken oauth2.Token, owner string, repoName string) (GitRepo, error) {
+	client := s.clientBuilder.Build(ctx, &ghToken)
+	gitRepo, resp, err := client.Repositories.Get(ctx, owner, repoName)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return GitRepo{}, ErrRepoNotFound
+	}
+	if err != nil {
+		return GitRepo{}, errors.Wrap(err, "retrieving repo from github failed")
+	}
+	return makeGitRepo(gitRepo), nil
+}
+
 // CreateRepo creates a new github repository using github oauth2 token and repo properties.
+// The repo is created under the organization when org is provided, otherwise under the user's account.
 // It returns any error occurred while creating new repo on github.
-func (s *service) CreateRepo(ctx context.Context, ghToken oauth2.Token, repoName string) (GitRepo, error) {
+func (s *service) CreateRepo(ctx context.Context, ghToken oauth2.Token, org string, repoName string) (GitRepo, error) {
 	client := s.clientBuilder.Build(ctx, &ghToken)
 	private := true
 	autoInit := true
@@ -132,15 +205,11 @@ func (s *service) CreateRepo(ctx context.Context, ghToken oauth2.Token, repoName
 		Private:  &private,
 		AutoInit: &autoInit,
 	}
-	gitRepo, _, err := client.Repositories.Create(ctx, "", opts)
+	gitRepo, _, err := client.Repositories.Create(ctx, org, opts)
 	if err != nil {
 		return GitRepo{}, errors.Wrap(err, "creating new repo on github failed")
 	}
-	return GitRepo{
-		Name:          gitRepo.GetName(),
-		Visibility:    gitRepo.GetVisibility(),
-		DefaultBranch: gitRepo.GetDefaultBranch(),
-	}, nil
+	return makeGitRepo(gitRepo), nil
 }
 
 // SearchFiles fetches the files from github using github oauth2 token and filtering criteria.
@@ -155,20 +224,31 @@ func (s *service) SearchFiles(ctx context.Context, ghToken oauth2.Token, filePro
 			PerPage: pageSize,
 		},
 	}
+	r, err := fileProps.RepoDetails.NoteFiles.Regexp()
+	if err != nil {
+		return nil, 0, err
+	}
 	pathQualifier := ""
 	if fileProps.Path != "" {
-		pathQualifier = "path:" + fileProps.Path
+		// the path is quoted so that its spaces do not end the qualifier, the quotes can not be escaped within the qualifier
+		pathQualifier = `path:"` + strings.ReplaceAll(fileProps.Path, `"`, "") + `"`
 	}
-	ghQuery := fmt.Sprintf("%s %s extension:md repo:%s/%s", query, pathQualifier, fileProps.RepoDetails.Owner, fileProps.RepoDetails.Repository)
+	// the search is narrowed down to the extensions of the notes, the results having invalid paths are filtered below
+	extensions := fileProps.RepoDetails.NoteFiles.GetExtensions()
+	extensionQualifiers := make([]string, 0, len(extensions))
+	for _, extension := range extensions {
+		extensionQualifiers = append(extensionQualifiers, "extension:"+extension)
+	}
+	ghQuery := fmt.Sprintf("%s %s %s repo:%s/%s", query, pathQualifier, strings.Join(extensionQualifiers, " OR "),
+		fileProps.RepoDetails.Owner, fileProps.RepoDetails.Repository)
 	cs, _, err := client.Search.Code(ctx, ghQuery, opts)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, "searching on github failed")
 	}
 	gitFiles := make([]GitFile, 0, len(cs.CodeResults))
-	r, _ := regexp.Compile(ValidFilePathRegex)
 	for _, item := range cs.CodeResults {
 		if !r.MatchString(item.GetPath()) {
-			// ignore non md files
+			// ignore the files with invalid paths
 			continue
 		}
 		gitFile, err := s.getFileInternal(ctx, client, fileProps.RepoDetails.Owner, fileProps.RepoDetails.Repository, fileProps.RepoDetails.DefaultBranch, item.GetPath())
@@ -182,8 +262,12 @@ func (s *service) SearchFiles(ctx context.Context, ghToken oauth2.Token, filePro
 
 // GetTree fetches the file tree from github repo using github oauth2 token and file properties.
 // It returns the file tree(without file contents) along with any error occurred while fetching it from github.
-// This api returns only markdown files having valid path, directories & non-markdown files are skipped.
+// This api returns only the note files having valid path, directories & non-note files are skipped.
 func (s *service) GetTree(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) ([]GitFile, error) {
+	r, err := fileProps.RepoDetails.NoteFiles.Regexp()
+	if err != nil {
+		return []GitFile{}, err
+	}
 	client := s.clientBuilder.Build(ctx, &ghToken)
 	sha := fileProps.SHA
 
@@ -203,10 +287,9 @@ func (s *service) GetTree(ctx context.Context, ghToken oauth2.Token, fileProps G
 	}
 
 	gitFiles := make([]GitFile, 0, len(tree.Entries))
-	r, _ := regexp.Compile(ValidFilePathRegex)
 	for _, item := range tree.Entries {
 		if !isFileType(item.GetType()) || !r.MatchString(item.GetPath()) {
-			// ignore directories & non md files
+			// ignore directories & non-note files
 			continue
 		}
 		gitFile := GitFile{
@@ -224,6 +307,10 @@ func (s *service) GetTree(ctx context.Context, ghToken oauth2.Token, fileProps G
 // GetAllFiles fetches all files in a directory path from github using github oauth2 token and file properties.
 // It returns files(with file contents) with any error occurred while fetching it from github.
 func (s *service) GetAllFiles(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) ([]GitFile, error) {
+	r, err := fileProps.RepoDetails.NoteFiles.Regexp()
+	if err != nil {
+		return []GitFile{}, err
+	}
 	client := s.clientBuilder.Build(ctx, &ghToken)
 
 	opts := &github.RepositoryContentGetOptions{
@@ -239,10 +326,9 @@ func (s *service) GetAllFiles(ctx context.Context, ghToken oauth2.Token, filePro
 	}
 
 	gitFiles := make([]GitFile, 0, len(dc))
-	r, _ := regexp.Compile(ValidFilePathRegex)
 	for _, item := range dc {
 		if !isFileType(item.GetType()) || !r.MatchString(item.GetPath()) {
-			// ignore directories & non md files
+			// ignore directories & non-note files
 			continue
 		}
 		gitFile, err := s.getFileInternal(ctx, client, fileProps.RepoDetails.Owner, fileProps.RepoDetails.Repository, fileProps.RepoDetails.DefaultBranch, item.GetPath())
@@ -268,6 +354,51 @@ func (s *service) GetFile(ctx context.Context, ghToken oauth2.Token, fileProps G
 	return gitFile, nil
 }
 
+// GetBlob fetches the raw content of a file from github repo using github oauth2 token & blob sha of the file properties.
+// Unlike GetFile, it supports the files larger than 1 MB.
+// It returns the file content along with any error occurred while fetching it from github.
+func (s *service) GetBlob(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) ([]byte, error) {
+	client := s.clientBuilder.Build(ctx, &ghToken)
+	content, _, err := client.Git.GetBlobRaw(ctx, fileProps.RepoDetails.Owner, fileProps.RepoDetails.Repository, fileProps.SHA)
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving blob from github failed")
+	}
+	return content, nil
+}
+
+// StatFile fetches the metadata of a file of any type (e.g. images) from github repo using github oauth2 token and file properties.
+// The file is looked up in the listing of its parent directory, so unlike GetFile it supports the files larger than 1 MB.
+// It returns the file without its content along with any error occurred while fetching it from github.
+// ErrFileNotFound is returned when the file or its parent directory does not exist.
+func (s *service) StatFile(ctx context.Context, ghToken oauth2.Token, fileProps GitFileProps) (GitFile, error) {
+	client := s.clientBuilder.Build(ctx, &ghToken)
+	opts := &github.RepositoryContentGetOptions{
+		Ref: fileProps.RepoDetails.DefaultBranch,
+	}
+	dir := path.Dir(fileProps.Path)
+	if dir == "." {
+		dir = ""
+	}
+	_, dirContents, resp, err := client.Repositories.GetContents(ctx, fileProps.RepoDetails.Owner, fileProps.RepoDetails.Repository, dir, opts)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return GitFile{}, ErrFileNotFound
+	}
+	if err != nil {
+		return GitFile{}, errors.Wrap(err, "retrieving directory from github failed")
+	}
+	for _, content := range dirContents {
+		if content.GetPath() == fileProps.Path && isFileType(content.GetType()) {
+			return GitFile{
+				SHA:   content.GetSHA(),
+				IsDir: false,
+				Size:  content.GetSize(),
+				Path:  content.GetPath(),
+			}, nil
+		}
+	}
+	return GitFile{}, ErrFileNotFound
+}
+
 func (*service) getFileInternal(ctx context.Context, client *github.Client, owner string, repo string, branch string, path string) (GitFile, error) {
 	opts := &github.RepositoryContentGetOptions{
 		Ref: branch,
@@ -345,6 +476,239 @@ func (s *service) DeleteFile(ctx context.Context, ghToken oauth2.Token, fileProp
 	return nil
 }
 
+// ReplaceBranch commits the files to the branch using github oauth2 token and commit properties.
+// The files replace the complete content of the branch, the files of the branch which are not provided are removed.
+// The branch is created without any history when it does not exist.
+// It returns the sha of the commit along with any error occurred while committing the files on github.
+func (s *service) ReplaceBranch(ctx context.Context, ghToken oauth2.Token, commitProps GitCommitProps) (string, error) {
+	client := s.clientBuilder.Build(ctx, &ghToken)
+	owner, repo := commitProps.RepoDetails.Owner, commitProps.RepoDetails.Repository
+	refName := fmt.Sprintf("refs/heads/%s", commitProps.Branch)
+
+	var parents []*github.Commit
+	ref, resp, err := client.Git.GetRef(ctx, owner, repo, refName)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return "", errors.Wrap(err, "retrieving branch ref failed")
+	}
+	if err == nil {
+		parents = []*github.Commit{{SHA: ref.Object.SHA}}
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(commitProps.Files))
+	for path, content := range commitProps.Files {
+		entries = append(entries, &github.TreeEntry{
+			Path:    github.String(path),
+			Mode:    github.String(blobFileMode),
+			Type:    github.String(blobType),
+			Content: github.String(content),
+		})
+	}
+	// the tree is created without base tree so that it contains only the provided files
+	tree, _, err := client.Git.CreateTree(ctx, owner, repo, "", entries)
+	if err != nil {
+		return "", errors.Wrap(err, "creating tree failed")
+	}
+	author := &github.CommitAuthor{Name: github.String(commitProps.AuthorName), Email: github.String(commitProps.AuthorEmail)}
+	commit, _, err := client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message:   github.String(commitProps.Message),
+		Tree:      &github.Tree{SHA: tree.SHA},
+		Parents:   parents,
+		Author:    author,
+		Committer: author,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "creating commit failed")
+	}
+
+	newRef := &github.Reference{Ref: github.String(refName), Object: &github.GitObject{SHA: commit.SHA}}
+	if parents == nil {
+		_, _, err = client.Git.CreateRef(ctx, owner, repo, newRef)
+	} else {
+		_, _, err = client.Git.UpdateRef(ctx, owner, repo, newRef, false)
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "updating branch ref failed")
+	}
+	return commit.GetSHA(), nil
+}
+
+// CommitFiles commits the files to the branch on top of its head in a single commit using github oauth2 token and commit properties.
+// The existing files of the branch are retained, the files with the same path are overwritten.
+// The text files are sent within the tree whereas the binary files (e.g. images) are uploaded as base64 encoded blobs.
+// It returns the sha of the commit along with any error occurred while committing the files on github.
+func (s *service) CommitFiles(ctx context.Context, ghToken oauth2.Token, commitProps GitCommitProps) (string, error) {
+	client := s.clientBuilder.Build(ctx, &ghToken)
+	owner, repo := commitProps.RepoDetails.Owner, commitProps.RepoDetails.Repository
+	refName := fmt.Sprintf("refs/heads/%s", commitProps.Branch)
+
+	ref, _, err := client.Git.GetRef(ctx, owner, repo, refName)
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving branch ref failed")
+	}
+	head, _, err := client.Git.GetCommit(ctx, owner, repo, ref.Object.GetSHA())
+	if err != nil {
+		return "", errors.Wrap(err, "retrieving head commit failed")
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(commitProps.Files))
+	for path, content := range commitProps.Files {
+		entry := &github.TreeEntry{
+			Path: github.String(path),
+			Mode: github.String(blobFileMode),
+			Type: github.String(blobType),
+		}
+		if utf8.ValidString(content) {
+			entry.Content = github.String(content)
+		} else {
+			blob, _, err := client.Git.CreateBlob(ctx, owner, repo, &github.Blob{
+				Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+				Encoding: github.String("base64"),
+			})
+			if err != nil {
+				return "", errors.Wrap(err, "creating blob failed")
+			}
+			entry.SHA = blob.SHA
+		}
+		entries = append(entries, entry)
+	}
+	tree, _, err := client.Git.CreateTree(ctx, owner, repo, head.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", errors.Wrap(err, "creating tree failed")
+	}
+	author := &github.CommitAuthor{Name: github.String(commitProps.AuthorName), Email: github.String(commitProps.AuthorEmail)}
+	commit, _, err := client.Git.CreateCommit(ctx, owner, repo, &github.Commit{
+		Message:   github.String(commitProps.Message),
+		Tree:      &github.Tree{SHA: tree.SHA},
+		Parents:   []*github.Commit{{SHA: head.SHA}},
+		Author:    author,
+		Committer: author,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "creating commit failed")
+	}
+	newRef := &github.Reference{Ref: github.String(refName), Object: &github.GitObject{SHA: commit.SHA}}
+	if _, _, err := client.Git.UpdateRef(ctx, owner, repo, newRef, false); err != nil {
+		return "", errors.Wrap(err, "updating branch ref failed")
+	}
+	return commit.GetSHA(), nil
+}
+
+func makeGitRepo(gitRepo *github.Repository) GitRepo {
+	permissions := gitRepo.GetPermissions()
+	return GitRepo{
+		Name:          gitRepo.GetName(),
+		Owner:         gitRepo.GetOwner().GetLogin(),
+		Visibility:    gitRepo.GetVisibility(),
+		DefaultBranch: gitRepo.GetDefaultBranch(),
+		Writable:      permissions["push"] || permissions["admin"],
+	}
+}
+
 func isFileType(typeProp string) bool {
 	return typeProp == fileType || typeProp == blobType
 }
+
+// GetExtensions returns the file extensions of the notes, DefaultNoteExtensions are returned when not set.
+func (r NoteFileRules) GetExtensions() []string {
+	if len(r.Extensions) == 0 {
+		return DefaultNoteExtensions
+	}
+	return r.Extensions
+}
+
+// HasNoteExtension checks whether the file with the path has one of the file extensions of the notes.
+func (r NoteFileRules) HasNoteExtension(filePath string) bool {
+	ext := strings.TrimPrefix(path.Ext(filePath), ".")
+	for _, extension := range r.GetExtensions() {
+		if strings.EqualFold(ext, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// FolderRegexp compiles & returns the regex matching the path of the folders of the notes.
+// When the path pattern of the notes is set, the folders are matched by the directory part of the pattern: the folders leading
+// to the notes & the folders below them are matched. ValidFolderPathRegex is used otherwise.
+func (r NoteFileRules) FolderRegexp() (*regexp.Regexp, error) {
+	pathPattern := ValidFolderPathRegex
+	if r.PathPattern != "" {
+		// the pattern is checked as a whole since the folders are built from its parts
+		if _, err := regexp.Compile(r.PathPattern); err != nil {
+			return nil, errors.Wrap(err, "compiling folder path pattern failed")
+		}
+		alternatives := splitPattern(r.PathPattern, '|')
+		folderPatterns := make([]string, 0, len(alternatives))
+		for _, alternative := range alternatives {
+			folderPatterns = append(folderPatterns, folderPattern(alternative))
+		}
+		pathPattern = strings.Join(folderPatterns, "|")
+	}
+	re, err := regexp.Compile(fmt.Sprintf(`^(?:%s)$`, pathPattern))
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling folder path pattern failed")
+	}
+	return re, nil
+}
+
+// Regexp compiles & returns the regex matching the complete path of the notes including the extension.
+func (r NoteFileRules) Regexp() (*regexp.Regexp, error) {
+	pathPattern := r.PathPattern
+	if pathPattern == "" {
+		pathPattern = ValidNotePathRegex
+	}
+	extensions := r.GetExtensions()
+	quoted := make([]string, 0, len(extensions))
+	for _, extension := range extensions {
+		quoted = append(quoted, regexp.QuoteMeta(extension))
+	}
+	re, err := regexp.Compile(fmt.Sprintf(`^(?:%s)\.(?:%s)$`, pathPattern, strings.Join(quoted, "|")))
+	if err != nil {
+		return nil, errors.Wrap(err, "compiling note path pattern failed")
+	}
+	return re, nil
+}
+
+// folderPattern returns the pattern matching the folders of the notes matched by the path pattern, the folders are built
+// from the directory part of the pattern e.g. `journal/\d{4}/[a-z-]+` gives `(?:journal)(?:/(?:\d{4})(?:/<folders>)?)?`.
+// The folders below the directory part are matched by ValidFolderPathRegex since the name part of the pattern may span folders.
+func folderPattern(notePathPattern string) string {
+	segments := splitPattern(notePathPattern, '/')
+	pattern := ValidFolderPathRegex
+	// the last segment is the name part of the pattern
+	for i := len(segments) - 2; i >= 0; i-- {
+		pattern = fmt.Sprintf(`(?:%s)(?:/%s)?`, segments[i], pattern)
+	}
+	return pattern
+}
+
+// splitPattern splits the regex pattern by the separator occurring outside of the groups, the character classes & the escapes.
+func splitPattern(pattern string, sep byte) []string {
+	var parts []string
+	start, depth, inClass := 0, 0, false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+			// the closing bracket right after the opening bracket (or its negation) is a literal
+			if strings.HasPrefix(pattern[i+1:], "^") {
+				i++
+			}
+			if strings.HasPrefix(pattern[i+1:], "]") {
+				i++
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, pattern[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, pattern[start:])
+}
@@ -246,8 +246,81 @@ func TestGetUser(t *testing.T) {
 	})
 }
 
+func TestRevokeGrant(t *testing.T) {
+	t.Run("should revoke the oauth2 grant of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+		var accessToken string
+		// to get the details of github request structure
+		// refer - https://docs.github.com/en/rest/apps/oauth-applications#delete-an-app-authorization
+		router.DELETE("/applications/testclient/grant", func(c *gin.Context) {
+			var body map[string]string
+			c.BindJSON(&body)
+			accessToken = body["access_token"]
+			c.Status(204)
+		})
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().BuildOAuth2AppClient(gomock.Any()).Return(githubClient)
+		mockClientBuilder.EXPECT().GetOAuth2Config().Return(&oauth2.Config{ClientID: "testclient"})
+
+		err := service.RevokeGrant(context.Background(), oauth2.Token{AccessToken: "gho_token"})
+		assert.NoError(t, err)
+		assert.Equal(t, "gho_token", accessToken)
+	})
+
+	t.Run("should not return error when the grant is already revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+		server := httptest.NewServer(nil)
+		defer server.Close()
+
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().BuildOAuth2AppClient(gomock.Any()).Return(githubClient)
+		mockClientBuilder.EXPECT().GetOAuth2Config().Return(&oauth2.Config{ClientID: "testclient"})
+
+		err := service.RevokeGrant(context.Background(), oauth2.Token{AccessToken: "gho_token"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when revoking the grant fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+		router.DELETE("/applications/testclient/grant", func(c *gin.Context) {
+			c.Status(500)
+		})
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().BuildOAuth2AppClient(gomock.Any()).Return(githubClient)
+		mockClientBuilder.EXPECT().GetOAuth2Config().Return(&oauth2.Config{ClientID: "testclient"})
+
+		err := service.RevokeGrant(context.Background(), oauth2.Token{AccessToken: "gho_token"})
+		assert.Error(t, err)
+	})
+}
+
 func TestGetRepos(t *testing.T) {
-	t.Run("should return repos when github token is provided", func(t *testing.T) {
+	t.Run("should return writable repos from all the pages when github token is provided", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockClientBuilder := NewMockClientBuilder(ctrl)
@@ -259,21 +332,40 @@ func TestGetRepos(t *testing.T) {
 		defer server.Close()
 
 		// to get the details of github response structure
-		// refer - https://docs.github.com/en/rest/reference/repos#list-organization-repositories
-		respJSON := `[
+		// refer - https://docs.github.com/en/rest/reference/repos#list-repositories-for-the-authenticated-user
+		firstPageJSON := `[
 			{
 				"name": "testrepo",
+				"owner": {"login": "johndoe"},
 				"visibility": "private",
-				"default_branch": "main"
+				"default_branch": "main",
+				"permissions": {"admin": true, "push": true, "pull": true}
 			},
 			{
-				"name": "sample-repo",
+				"name": "readonly-repo",
+				"owner": {"login": "janedoe"},
 				"visibility": "public",
-				"default_branch": "master"
+				"default_branch": "main",
+				"permissions": {"admin": false, "push": false, "pull": true}
+			}
+		]`
+		secondPageJSON := `[
+			{
+				"name": "team-notes",
+				"owner": {"login": "acme"},
+				"visibility": "internal",
+				"default_branch": "master",
+				"permissions": {"admin": false, "push": true, "pull": true}
 			}
 		]`
 		router.GET("/user/repos", func(c *gin.Context) {
-			c.Data(200, "application/json; charset=utf-8", []byte(respJSON))
+			assert.Equal(t, "owner,collaborator,organization_member", c.Query("affiliation"))
+			if c.Query("page") == "2" {
+				c.Data(200, "application/json; charset=utf-8", []byte(secondPageJSON))
+				return
+			}
+			c.Header("Link", fmt.Sprintf(`<%s/user/repos?page=2>; rel="next", <%s/user/repos?page=2>; rel="last"`, server.URL, server.URL))
+			c.Data(200, "application/json; charset=utf-8", []byte(firstPageJSON))
 		})
 		githubClient := github.NewClient(nil)
 		url, _ := url.Parse(server.URL + "/")
@@ -281,9 +373,11 @@ func TestGetRepos(t *testing.T) {
 		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
 
 		gitRepos, err := service.GetRepos(context.Background(), oauth2.Token{})
-		gitReposJSON, _ := json.Marshal(gitRepos)
 		assert.NoError(t, err)
-		assert.JSONEq(t, `[{"DefaultBranch":"main", "Name":"testrepo", "Visibility":"private"}, {"DefaultBranch":"master", "Name":"sample-repo", "Visibility":"public"}]`, string(gitReposJSON))
+		assert.Equal(t, []GitRepo{
+			{Name: "testrepo", Owner: "johndoe", Visibility: "private", DefaultBranch: "main", Writable: true},
+			{Name: "team-notes", Owner: "acme", Visibility: "internal", DefaultBranch: "master", Writable: true},
+		}, gitRepos)
 	})
 
 	t.Run("should return error when fetching repos failed", func(t *testing.T) {
@@ -304,6 +398,58 @@ func TestGetRepos(t *testing.T) {
 	})
 }
 
+func TestGetRepo(t *testing.T) {
+	t.Run("should return the repo of the owner with user's write permission", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// to get the details of github response structure
+		// refer - https://docs.github.com/en/rest/reference/repos#get-a-repository
+		respJSON := `{
+			"name": "team-notes",
+			"owner": {"login": "acme", "type": "Organization"},
+			"visibility": "private",
+			"default_branch": "main",
+			"permissions": {"admin": false, "push": true, "pull": true}
+		}`
+		router.GET("/repos/acme/team-notes", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(respJSON))
+		})
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		gitRepo, err := service.GetRepo(context.Background(), oauth2.Token{}, "acme", "team-notes")
+		assert.NoError(t, err)
+		assert.Equal(t, GitRepo{Name: "team-notes", Owner: "acme", Visibility: "private", DefaultBranch: "main", Writable: true}, gitRepo)
+	})
+
+	t.Run("should return repo not found error when the repo does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+		server := httptest.NewServer(nil)
+		defer server.Close()
+
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		_, err := service.GetRepo(context.Background(), oauth2.Token{}, "acme", "team-notes")
+		assert.ErrorIs(t, err, ErrRepoNotFound)
+	})
+}
+
 func TestCreateRepo(t *testing.T) {
 	t.Run("should create a new repo when request is valid", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -341,10 +487,42 @@ func TestCreateRepo(t *testing.T) {
 		githubClient.BaseURL = url
 		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
 
-		gitRepo, err := service.CreateRepo(context.Background(), oauth2.Token{}, "notes")
+		gitRepo, err := service.CreateRepo(context.Background(), oauth2.Token{}, "", "notes")
 		gitRepoJSON, _ := json.Marshal(gitRepo)
 		assert.NoError(t, err)
-		assert.JSONEq(t, `{"DefaultBranch":"main", "Name":"notes", "Visibility":"public"}`, string(gitRepoJSON))
+		assert.JSONEq(t, `{"DefaultBranch":"main", "Name":"notes", "Owner":"johndoe", "Visibility":"public", "Writable":false}`, string(gitRepoJSON))
+	})
+
+	t.Run("should create a new repo under the organization when org is provided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// refer - https://docs.github.com/en/rest/reference/repos#create-an-organization-repository
+		respJSON := `{
+			"name": "notes",
+			"owner": {"login": "acme", "type": "Organization"},
+			"default_branch": "main",
+			"visibility": "private",
+			"permissions": {"admin": true, "push": true, "pull": true}
+		}`
+		router.POST("/orgs/acme/repos", func(c *gin.Context) {
+			c.Data(201, "application/json; charset=utf-8", []byte(respJSON))
+		})
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		gitRepo, err := service.CreateRepo(context.Background(), oauth2.Token{}, "acme", "notes")
+		assert.NoError(t, err)
+		assert.Equal(t, GitRepo{Name: "notes", Owner: "acme", Visibility: "private", DefaultBranch: "main", Writable: true}, gitRepo)
 	})
 
 	t.Run("should return error when repo creation fails", func(t *testing.T) {
@@ -360,7 +538,7 @@ func TestCreateRepo(t *testing.T) {
 		githubClient.BaseURL = url
 		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
 
-		_, err := service.CreateRepo(context.Background(), oauth2.Token{}, "notes")
+		_, err := service.CreateRepo(context.Background(), oauth2.Token{}, "", "notes")
 		assert.Error(t, err)
 	})
 }
@@ -427,6 +605,62 @@ func TestSearchFiles(t *testing.T) {
 		assert.JSONEq(t, `[{"Content":"Birthdays", "IsDir":false, "Path":"foo/classes.md", "SHA":"d7212f9dee2dcc18f084d7df8f417b80846ded5a", "Size":5},{"Content":"Birthdays", "IsDir":false, "Path":"foo/bar/birthdays.md", "SHA":"c459a67dee2dc4726d2458a32f417699b46da3d9", "Size":14}]`, string(gitFilesJSON))
 	})
 
+	t.Run("should search the files having the note extensions of the repo under the quoted path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// the search is narrowed down to the extensions of the repo,
+		// from this response, entry at index 1 should be skipped since its path is not valid
+		respJSON := `{
+			"total_count": 2,
+			"incomplete_results": false,
+			"items": [{
+				"name": "todo.org",
+				"path": "foo/todo.org",
+				"sha": "d7212f9dee2dcc18f084d7df8f417b80846ded5a"
+			},{
+				"name": ".classes.txt",
+				"path": "foo/.classes.txt",
+				"sha": "c459a67dee2dc4726d2458a32f417699b46da3d9"
+			}]
+		}`
+		query := ""
+		router.GET("/search/code", func(c *gin.Context) {
+			query = c.Query("q")
+			c.Data(200, "application/json; charset=utf-8", []byte(respJSON))
+		})
+		router.GET("/repos/testowner/testrepo/contents/foo/todo.org", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`{
+				"sha": "d7212f9dee2dcc18f084d7df8f417b80846ded5a",
+				"type": "file",
+				"size": 5,
+				"path": "foo/todo.org",
+				"content": "Birthdays"
+			}`))
+		})
+		noteFiles := NoteFileRules{Extensions: []string{"txt", "org"}}
+		fp := GitFileProps{Path: `foo "bar"`, RepoDetails: GitRepoProps{Repository: "testrepo", Owner: "testowner", NoteFiles: noteFiles}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		gitFiles, total, err := service.SearchFiles(context.Background(), oauth2.Token{}, fp, "foo", 1)
+		gitFilesJSON, _ := json.Marshal(gitFiles)
+
+		assert.NoError(t, err)
+		assert.Equal(t, `foo path:"foo bar" extension:txt OR extension:org repo:testowner/testrepo`, query)
+		assert.Equal(t, 2, total)
+		assert.JSONEq(t, `[{"Content":"Birthdays", "IsDir":false, "Path":"foo/todo.org", "SHA":"d7212f9dee2dcc18f084d7df8f417b80846ded5a", "Size":5}]`, string(gitFilesJSON))
+	})
+
 	t.Run("should return error when retrieving file info against searched files fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -573,6 +807,44 @@ func TestGetTree(t *testing.T) {
 			]`, string(gitFilesJSON))
 	})
 
+	t.Run("should get the files having the note extensions & path pattern of the repo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// from this response, entry at index 1 should be skipped since the extension is not configured
+		// & entry at index 2 should be skipped since the path does not match the pattern
+		treeRespJSON := `{
+			"sha": "aa218f56b14c9653891f9e74264a383fa43fefbd",
+			"tree": [
+				{"path": "notes/todo.txt", "mode": "100644", "type": "blob", "sha": "44b4fc6d56897b048c772eb4087f854f46256132"},
+				{"path": "notes/test1.md", "mode": "100644", "type": "blob", "sha": "45b983be36b73c0788dc9cbcb76cbb80fc7bb057"},
+				{"path": "drafts/test2.org", "mode": "100644", "type": "blob", "sha": "333983be36b73c0788dc9cbcb76cbb80fc7bb888"}
+			],
+			"truncated": false
+		}`
+		router.GET("/repos/johndoe/testrepo/git/trees/aa218f56b14c9653891f9e74264a383fa43fefbd", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(treeRespJSON))
+		})
+		noteFiles := NoteFileRules{Extensions: []string{"txt", "org"}, PathPattern: `notes/[^/]+`}
+		fp := GitFileProps{SHA: "aa218f56b14c9653891f9e74264a383fa43fefbd", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "johndoe", NoteFiles: noteFiles}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		gitFiles, err := service.GetTree(context.Background(), oauth2.Token{}, fp)
+		gitFilesJSON, _ := json.Marshal(gitFiles)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"Content":"", "IsDir":false, "Path":"notes/todo.txt", "SHA":"44b4fc6d56897b048c772eb4087f854f46256132", "Size":0}]`, string(gitFilesJSON))
+	})
+
 	t.Run("should return error when tree api fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -631,6 +903,78 @@ func TestGetTree(t *testing.T) {
 	})
 }
 
+func TestNoteFileRules(t *testing.T) {
+	t.Run("should match the paths of the markdown notes when the rules are not set", func(t *testing.T) {
+		r, err := NoteFileRules{}.Regexp()
+		assert.NoError(t, err)
+		for _, path := range []string{"foo.md", "foo/bar.md", "foo bar/baz qux.md", "foo_bar/v1.2-baz.md", "réunions/compte rendu.md", "日記/今日.md"} {
+			assert.True(t, r.MatchString(path), path)
+		}
+		for _, path := range []string{"foo.txt", "foo/.md", ".hidden.md", "foo.bar/baz.md", "foo  bar.md", "foo /bar.md", "/foo.md", "foo.md\nbar.md"} {
+			assert.False(t, r.MatchString(path), path)
+		}
+	})
+
+	t.Run("should match the paths having the extensions & the path pattern", func(t *testing.T) {
+		r, err := NoteFileRules{Extensions: []string{"txt", "org"}, PathPattern: `notes/.+`}.Regexp()
+		assert.NoError(t, err)
+		assert.True(t, r.MatchString("notes/foo.txt"))
+		assert.True(t, r.MatchString("notes/foo/bar.org"))
+		assert.False(t, r.MatchString("notes/foo.md"))
+		assert.False(t, r.MatchString("drafts/foo.txt"))
+	})
+
+	t.Run("should return error when the path pattern is invalid", func(t *testing.T) {
+		_, err := NoteFileRules{PathPattern: `notes/[a-z`}.Regexp()
+		assert.Error(t, err)
+		_, err = NoteFileRules{PathPattern: `notes/[a-z`}.FolderRegexp()
+		assert.Error(t, err)
+	})
+
+	t.Run("should match the paths of the folders when the rules are not set", func(t *testing.T) {
+		r, err := NoteFileRules{}.FolderRegexp()
+		assert.NoError(t, err)
+		for _, path := range []string{"foo", "foo/bar", "foo bar/baz_qux", "réunions/2022-10", "日記"} {
+			assert.True(t, r.MatchString(path), path)
+		}
+		for _, path := range []string{"", ".hidden", "foo.bar", "foo  bar", "foo /bar", "/foo", "foo/"} {
+			assert.False(t, r.MatchString(path), path)
+		}
+	})
+
+	t.Run("should match the paths of the folders with the path pattern", func(t *testing.T) {
+		r, err := NoteFileRules{PathPattern: `notes/.+`}.FolderRegexp()
+		assert.NoError(t, err)
+		assert.True(t, r.MatchString("notes"))
+		assert.True(t, r.MatchString("notes/2022"))
+		assert.False(t, r.MatchString("drafts"))
+	})
+
+	t.Run("should match the folders leading to the notes & below them with the directory part of the path pattern", func(t *testing.T) {
+		r, err := NoteFileRules{PathPattern: `journal/\d{4}/[a-z-]+|work/(?:meetings|specs)/[^/]+`}.FolderRegexp()
+		assert.NoError(t, err)
+		for _, path := range []string{"journal", "journal/2022", "journal/2022/october", "work", "work/meetings", "work/specs/api"} {
+			assert.True(t, r.MatchString(path), path)
+		}
+		for _, path := range []string{"journal/22", "journal/2022/.hidden", "journal/2022/daily-standup.md", "work/drafts", "drafts", "journal/"} {
+			assert.False(t, r.MatchString(path), path)
+		}
+	})
+
+	t.Run("should not split the path pattern within the groups, the character classes & the escapes", func(t *testing.T) {
+		assert.Equal(t, []string{`(?:a/b|c)`, `[/|]`, `d\/e`}, splitPattern(`(?:a/b|c)/[/|]/d\/e`, '/'))
+		assert.Equal(t, []string{`a/(?:b|c)`, `[]|]`, `\|`}, splitPattern(`a/(?:b|c)|[]|]|\|`, '|'))
+	})
+
+	t.Run("should check the extension of the notes", func(t *testing.T) {
+		assert.True(t, NoteFileRules{}.HasNoteExtension("work/todo.md"))
+		assert.True(t, NoteFileRules{}.HasNoteExtension("README.MD"))
+		assert.False(t, NoteFileRules{}.HasNoteExtension("images/flow.png"))
+		assert.True(t, NoteFileRules{Extensions: []string{"md", "org"}}.HasNoteExtension("work/todo.org"))
+		assert.False(t, NoteFileRules{Extensions: []string{"org"}}.HasNoteExtension("work/todo.md"))
+	})
+}
+
 func TestGetFile(t *testing.T) {
 	t.Run("should get the file from git when get request is valid", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -721,6 +1065,134 @@ func TestGetFile(t *testing.T) {
 	})
 }
 
+func TestGetBlob(t *testing.T) {
+	t.Run("should return raw content of the blob", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+		// to get the details of github response structure
+		// refer - https://docs.github.com/en/rest/git/blobs#get-a-blob
+		router.GET("/repos/testowner/testrepo/git/blobs/3d21ec53a331a6f037a91c368710b99387d012c1", func(c *gin.Context) {
+			c.Data(200, "application/vnd.github.v3.raw", []byte("# Hello"))
+		})
+		fp := GitFileProps{SHA: "3d21ec53a331a6f037a91c368710b99387d012c1", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		content, err := service.GetBlob(context.Background(), oauth2.Token{}, fp)
+		assert.NoError(t, err)
+		assert.Equal(t, "# Hello", string(content))
+	})
+
+	t.Run("should return error when fetching blob fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+		server := httptest.NewServer(nil)
+		defer server.Close()
+		fp := GitFileProps{SHA: "3d21ec53a331a6f037a91c368710b99387d012c1", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		_, err := service.GetBlob(context.Background(), oauth2.Token{}, fp)
+		assert.Error(t, err)
+	})
+}
+
+func TestStatFile(t *testing.T) {
+	t.Run("should return the file from the listing of its directory", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// to get the details of github response structure
+		// refer - https://docs.github.com/en/rest/reference/repos#get-repository-content
+		respJSON := `[{
+			"sha": "3d21ec53a331a6f037a91c368710b99387d012c1",
+			"type": "file",
+			"size": 2097152,
+			"path": "attachments/screenshot.png"
+		}, {
+			"sha": "4e32fd64b442b7f148b02d479821c00498e123d2",
+			"type": "file",
+			"size": 5,
+			"path": "attachments/notes.md"
+		}]`
+		router.GET("/repos/testowner/testrepo/contents/attachments", func(c *gin.Context) {
+			assert.Equal(t, "main", c.Query("ref"))
+			c.Data(200, "application/json; charset=utf-8", []byte(respJSON))
+		})
+		fp := GitFileProps{Path: "attachments/screenshot.png", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		gitFile, err := service.StatFile(context.Background(), oauth2.Token{}, fp)
+		assert.NoError(t, err)
+		assert.Equal(t, GitFile{SHA: "3d21ec53a331a6f037a91c368710b99387d012c1", Path: "attachments/screenshot.png", Size: 2097152}, gitFile)
+	})
+
+	t.Run("should return file not found error when the file is not listed in its directory", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		router.GET("/repos/testowner/testrepo/contents/attachments", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`[]`))
+		})
+		fp := GitFileProps{Path: "attachments/screenshot.png", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		_, err := service.StatFile(context.Background(), oauth2.Token{}, fp)
+		assert.ErrorIs(t, err, ErrFileNotFound)
+	})
+
+	t.Run("should return file not found error when the directory does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		server := httptest.NewServer(nil)
+		defer server.Close()
+		fp := GitFileProps{Path: "attachments/screenshot.png", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		_, err := service.StatFile(context.Background(), oauth2.Token{}, fp)
+		assert.ErrorIs(t, err, ErrFileNotFound)
+	})
+}
+
 func TestSaveFile(t *testing.T) {
 	t.Run("should save a file when save request is valid", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -818,3 +1290,222 @@ func TestDeleteFile(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestReplaceBranch(t *testing.T) {
+	t.Run("should commit the files on top of the existing branch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// to get the details of github request & response structure
+		// refer - https://docs.github.com/en/rest/git/trees#create-a-tree
+		var treeReq, commitReq, refReq map[string]interface{}
+		router.GET("/repos/testowner/testrepo/git/ref/heads/gh-pages", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"ref":"refs/heads/gh-pages","object":{"sha":"parentsha","type":"commit"}}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/trees", func(c *gin.Context) {
+			c.BindJSON(&treeReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"treesha"}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/commits", func(c *gin.Context) {
+			c.BindJSON(&commitReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"commitsha"}`))
+		})
+		router.PATCH("/repos/testowner/testrepo/git/refs/heads/gh-pages", func(c *gin.Context) {
+			c.BindJSON(&refReq)
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"ref":"refs/heads/gh-pages","object":{"sha":"commitsha","type":"commit"}}`))
+		})
+		cp := GitCommitProps{Branch: "gh-pages", Message: "Publish site", Files: map[string]string{"index.html": "<h1>Hello</h1>"},
+			AuthorName: "John Doe", AuthorEmail: "john.doe@example.com", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		sha, err := service.ReplaceBranch(context.Background(), oauth2.Token{}, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, "commitsha", sha)
+		assert.NotContains(t, treeReq, "base_tree")
+		assert.Equal(t, []interface{}{map[string]interface{}{"path": "index.html", "mode": "100644", "type": "blob", "content": "<h1>Hello</h1>"}}, treeReq["tree"])
+		assert.Equal(t, "treesha", commitReq["tree"])
+		assert.Equal(t, []interface{}{"parentsha"}, commitReq["parents"])
+		assert.Equal(t, "commitsha", refReq["sha"])
+		assert.Equal(t, false, refReq["force"])
+	})
+
+	t.Run("should create the branch when it does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		var commitReq, refReq map[string]interface{}
+		router.POST("/repos/testowner/testrepo/git/trees", func(c *gin.Context) {
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"treesha"}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/commits", func(c *gin.Context) {
+			c.BindJSON(&commitReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"commitsha"}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/refs", func(c *gin.Context) {
+			c.BindJSON(&refReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"ref":"refs/heads/gh-pages","object":{"sha":"commitsha","type":"commit"}}`))
+		})
+		cp := GitCommitProps{Branch: "gh-pages", Message: "Publish site", Files: map[string]string{"index.html": "<h1>Hello</h1>"},
+			RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		sha, err := service.ReplaceBranch(context.Background(), oauth2.Token{}, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, "commitsha", sha)
+		assert.NotContains(t, commitReq, "parents")
+		assert.Equal(t, map[string]interface{}{"ref": "refs/heads/gh-pages", "sha": "commitsha"}, refReq)
+	})
+
+	t.Run("should return error when creating the tree fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+		server := httptest.NewServer(nil)
+		defer server.Close()
+
+		cp := GitCommitProps{Branch: "gh-pages", Files: map[string]string{"index.html": ""}, RepoDetails: GitRepoProps{Repository: "testrepo", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		_, err := service.ReplaceBranch(context.Background(), oauth2.Token{}, cp)
+		assert.Error(t, err)
+	})
+}
+
+func TestCommitFiles(t *testing.T) {
+	t.Run("should commit the text & binary files on top of the branch head", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// to get the details of github request & response structure
+		// refer - https://docs.github.com/en/rest/git/blobs#create-a-blob
+		var blobReq, treeReq, commitReq, refReq map[string]interface{}
+		router.GET("/repos/testowner/testrepo/git/ref/heads/main", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"ref":"refs/heads/main","object":{"sha":"headsha","type":"commit"}}`))
+		})
+		router.GET("/repos/testowner/testrepo/git/commits/headsha", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"sha":"headsha","tree":{"sha":"basetreesha"}}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/blobs", func(c *gin.Context) {
+			c.BindJSON(&blobReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"blobsha"}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/trees", func(c *gin.Context) {
+			c.BindJSON(&treeReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"treesha"}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/commits", func(c *gin.Context) {
+			c.BindJSON(&commitReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"commitsha"}`))
+		})
+		router.PATCH("/repos/testowner/testrepo/git/refs/heads/main", func(c *gin.Context) {
+			c.BindJSON(&refReq)
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"ref":"refs/heads/main","object":{"sha":"commitsha","type":"commit"}}`))
+		})
+		cp := GitCommitProps{Branch: "main", Message: "Import notes", Files: map[string]string{"image.png": "\x89PNG\x00\xff"},
+			AuthorName: "John Doe", AuthorEmail: "john.doe@example.com", RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		sha, err := service.CommitFiles(context.Background(), oauth2.Token{}, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, "commitsha", sha)
+		assert.Equal(t, map[string]interface{}{"content": "iVBORwD/", "encoding": "base64"}, blobReq)
+		assert.Equal(t, "basetreesha", treeReq["base_tree"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"path": "image.png", "mode": "100644", "type": "blob", "sha": "blobsha"}}, treeReq["tree"])
+		assert.Equal(t, "treesha", commitReq["tree"])
+		assert.Equal(t, []interface{}{"headsha"}, commitReq["parents"])
+		assert.Equal(t, "commitsha", refReq["sha"])
+		assert.Equal(t, false, refReq["force"])
+	})
+
+	t.Run("should send the text files within the tree", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		var treeReq map[string]interface{}
+		router.GET("/repos/testowner/testrepo/git/ref/heads/main", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"ref":"refs/heads/main","object":{"sha":"headsha","type":"commit"}}`))
+		})
+		router.GET("/repos/testowner/testrepo/git/commits/headsha", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"sha":"headsha","tree":{"sha":"basetreesha"}}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/trees", func(c *gin.Context) {
+			c.BindJSON(&treeReq)
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"treesha"}`))
+		})
+		router.POST("/repos/testowner/testrepo/git/commits", func(c *gin.Context) {
+			c.Data(201, "application/json; charset=utf-8", []byte(`{"sha":"commitsha"}`))
+		})
+		router.PATCH("/repos/testowner/testrepo/git/refs/heads/main", func(c *gin.Context) {
+			c.Data(200, "application/json; charset=utf-8", []byte(`{"ref":"refs/heads/main","object":{"sha":"commitsha","type":"commit"}}`))
+		})
+		cp := GitCommitProps{Branch: "main", Files: map[string]string{"notes/todo.md": "# Todo"},
+			RepoDetails: GitRepoProps{Repository: "testrepo", DefaultBranch: "main", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		_, err := service.CommitFiles(context.Background(), oauth2.Token{}, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"path": "notes/todo.md", "mode": "100644", "type": "blob", "content": "# Todo"}}, treeReq["tree"])
+	})
+
+	t.Run("should return error when the branch does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockClientBuilder := NewMockClientBuilder(ctrl)
+		service := NewService(mockClientBuilder)
+		server := httptest.NewServer(nil)
+		defer server.Close()
+
+		cp := GitCommitProps{Branch: "main", Files: map[string]string{"todo.md": ""}, RepoDetails: GitRepoProps{Repository: "testrepo", Owner: "testowner"}}
+		githubClient := github.NewClient(nil)
+		url, _ := url.Parse(server.URL + "/")
+		githubClient.BaseURL = url
+		mockClientBuilder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(githubClient)
+
+		_, err := service.CommitFiles(context.Background(), oauth2.Token{}, cp)
+		assert.Error(t, err)
+	})
+}
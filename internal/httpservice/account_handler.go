@@ -0,0 +1,82 @@
+package httpservice
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/preference"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/sirupsen/logrus"
+)
+
+// AccountHandler represents http handler for the account actions of the user.
+type AccountHandler struct {
+	userService       user.Service
+	tokenService      user.TokenService
+	githubService     github.Service
+	preferenceService preference.Service
+	authService       auth.Service
+}
+
+// NewAccountHandler creates and returns a new account handler.
+func NewAccountHandler(userService user.Service, tokenService user.TokenService, githubService github.Service,
+	preferenceService preference.Service, authService auth.Service) *AccountHandler {
+	return &AccountHandler{
+		userService:       userService,
+		tokenService:      tokenService,
+		githubService:     githubService,
+		preferenceService: preferenceService,
+		authService:       authService,
+	}
+}
+
+// DeleteAccount deletes the account of the user.
+// The user record is marked as deleted first & the user is logged out from all the devices. The github oauth2 grant
+// of the user is then revoked & the preferences are deleted, failures of these steps are only logged since the account
+// is already deleted & its remaining data is permanently deleted after the grace period.
+// Api tokens are not allowed to delete the account.
+func (a *AccountHandler) DeleteAccount(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if isAPITokenRequest(c) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	logrus.WithField("user-id", userID).Info("request to delete account started")
+	dbUser, err := a.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+
+	// the token is retrieved before the deletion since the stored tokens are removed along with the user.
+	// the grant may already be revoked from github settings, in that case the stored token can not be refreshed
+	ghToken, err := a.tokenService.GetGithubToken(c, dbUser)
+	if err != nil {
+		logrus.WithField("user-id", userID).WithError(err).Warn("retrieving github token failed. revoking the grant with stored token")
+		ghToken = user.ParseGithubToken(dbUser.GithubToken)
+	}
+	if err := a.userService.Delete(userID, userID, ""); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	if _, err := a.authService.RevokeAllSessions(userID); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	if err := a.githubService.RevokeGrant(c, ghToken); err != nil {
+		logrus.WithField("user-id", userID).WithError(err).Error("revoking github grant of the deleted account failed")
+	}
+	if err := a.preferenceService.DeleteByUserID(userID); err != nil {
+		logrus.WithField("user-id", userID).WithError(err).Error("deleting preferences of the deleted account failed")
+	}
+	clearRefreshTokenCookie(c)
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", userID).Info("request to delete account successful")
+}
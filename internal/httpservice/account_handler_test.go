@@ -0,0 +1,148 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/preference"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestDeleteAccount(t *testing.T) {
+	t.Run("should delete the user, revoke the sessions & the github grant & delete the preferences", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		dbUser := user.User{Email: email}
+		ghToken := oauth2.Token{AccessToken: "gho_token"}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAccountHandler(mockUserService, mockTokenService, mockGithubService, mockPreferenceService, mockAuthService)
+		gomock.InOrder(
+			mockUserService.EXPECT().Get(userID).Return(dbUser, nil),
+			mockTokenService.EXPECT().GetGithubToken(gomock.Any(), dbUser).Return(ghToken, nil),
+			mockUserService.EXPECT().Delete(userID, userID, "").Return(nil),
+			mockAuthService.EXPECT().RevokeAllSessions(userID).Return(2, nil),
+			mockGithubService.EXPECT().RevokeGrant(gomock.Any(), ghToken).Return(nil),
+			mockPreferenceService.EXPECT().DeleteByUserID(userID).Return(nil),
+		)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/me", getClaimsHandler(), handler.DeleteAccount)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/me", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Header().Get("Set-Cookie"), refreshTokenCookie+"=;")
+	})
+
+	t.Run("should revoke the github grant with stored token when the token can not be refreshed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		dbUser := user.User{Email: email, GithubToken: `{"access_token":"gho_expired_token"}`}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAccountHandler(mockUserService, mockTokenService, mockGithubService, mockPreferenceService, mockAuthService)
+		mockUserService.EXPECT().Get(userID).Return(dbUser, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), dbUser).Return(oauth2.Token{}, errors.New("some error"))
+		mockGithubService.EXPECT().RevokeGrant(gomock.Any(), oauth2.Token{AccessToken: "gho_expired_token"}).Return(nil)
+		mockPreferenceService.EXPECT().DeleteByUserID(userID).Return(nil)
+		mockUserService.EXPECT().Delete(userID, userID, "").Return(nil)
+		mockAuthService.EXPECT().RevokeAllSessions(userID).Return(0, nil)
+
+		router.DELETE("/api/v1/user/me", getClaimsHandler(), handler.DeleteAccount)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/me", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should delete the account even when revoking the github grant fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		dbUser := user.User{Email: email}
+		ghToken := oauth2.Token{AccessToken: "gho_token"}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAccountHandler(mockUserService, mockTokenService, mockGithubService, mockPreferenceService, mockAuthService)
+		mockUserService.EXPECT().Get(userID).Return(dbUser, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), dbUser).Return(ghToken, nil)
+		mockUserService.EXPECT().Delete(userID, userID, "").Return(nil)
+		mockAuthService.EXPECT().RevokeAllSessions(userID).Return(1, nil)
+		mockGithubService.EXPECT().RevokeGrant(gomock.Any(), ghToken).Return(errors.New("some error"))
+		mockPreferenceService.EXPECT().DeleteByUserID(userID).Return(nil)
+
+		router.DELETE("/api/v1/user/me", getClaimsHandler(), handler.DeleteAccount)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/me", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with internal server error & keep the github grant when deleting the user fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		dbUser := user.User{Email: email}
+		ghToken := oauth2.Token{AccessToken: "gho_token"}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAccountHandler(mockUserService, mockTokenService, mockGithubService, nil, nil)
+		mockUserService.EXPECT().Get(userID).Return(dbUser, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), dbUser).Return(ghToken, nil)
+		mockUserService.EXPECT().Delete(userID, userID, "").Return(errors.New("some error"))
+
+		router.DELETE("/api/v1/user/me", getClaimsHandler(), handler.DeleteAccount)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/me", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+
+	t.Run("should fail with forbidden status when the request is authorized with an api token", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAccountHandler(nil, nil, nil, nil, nil)
+		claims := jwt.MapClaims{"sub": "1012", "scope": "preferences:write", "api_token_id": float64(apiTokenID)}
+
+		router.DELETE("/api/v1/user/me", func(c *gin.Context) { c.Set("claims", claims) }, handler.DeleteAccount)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/me", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
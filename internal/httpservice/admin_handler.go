@@ -0,0 +1,259 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/audit"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+)
+
+// AccountStatusPayload represents the http request payload to disable or enable a user account.
+type AccountStatusPayload struct {
+	Reason string `json:"reason"`
+}
+
+// Validate validates the account status http request payload.
+func (a AccountStatusPayload) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Reason, validation.Required, validation.Length(1, 255)),
+	)
+}
+
+// AuditEventResponsePayload represents the http response payload of audit event entity.
+type AuditEventResponsePayload struct {
+	ActorID   uint      `json:"actor_id"`
+	Action    string    `json:"action"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AdminUserResponsePayload represents the http response payload of user entity for the admins.
+// Repo is the default repo linked by the user & it is omitted until the repo is linked.
+type AdminUserResponsePayload struct {
+	ID             uint                      `json:"id"`
+	Email          string                    `json:"email"`
+	Name           string                    `json:"name,omitempty"`
+	GithubID       int64                     `json:"github_id"`
+	GithubUsername string                    `json:"github_username"`
+	Admin          bool                      `json:"admin"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	DisabledAt     *time.Time                `json:"disabled_at,omitempty"`
+	DisabledReason string                    `json:"disabled_reason,omitempty"`
+	DeletedAt      *time.Time                `json:"deleted_at,omitempty"`
+	Repo           *AdminRepoResponsePayload `json:"repo,omitempty"`
+}
+
+// AdminRepoResponsePayload represents the http response payload of the repo linked by a user.
+// InstallationID is set when the repo is accessed with a github app installation.
+type AdminRepoResponsePayload struct {
+	Name           string `json:"name"`
+	Visibility     string `json:"visibility"`
+	DefaultBranch  string `json:"default_branch"`
+	InstallationID int64  `json:"installation_id,omitempty"`
+}
+
+// AdminUserSearchResponsePayload represents the http response payload for the user search operation.
+// Total is the count of total users found & Users are the requested page of the result.
+type AdminUserSearchResponsePayload struct {
+	Total   int64                      `json:"total"`
+	Page    int                        `json:"page"`
+	PerPage int                        `json:"per_page"`
+	Users   []AdminUserResponsePayload `json:"users"`
+}
+
+const (
+	defaultUsersPerPage = 20
+	maxUsersPerPage     = 100
+)
+
+// AdminHandler represents http handler for administrative actions on user accounts.
+type AdminHandler struct {
+	userService  user.Service
+	authService  auth.Service
+	auditService audit.Service
+}
+
+// NewAdminHandler creates and returns a new admin handler.
+func NewAdminHandler(userService user.Service, authService auth.Service, auditService audit.Service) *AdminHandler {
+	return &AdminHandler{
+		userService:  userService,
+		authService:  authService,
+		auditService: auditService,
+	}
+}
+
+// SearchUsers returns a page of the users (including deleted ones) matching the query along with their linked repo.
+// Query, page & per_page are read from the query-params, all the users are returned when the query is empty.
+func (a *AdminHandler) SearchUsers(c *gin.Context) {
+	query := c.Query("query")
+	page, _ := strconv.Atoi(c.Query("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(c.Query("per_page"))
+	if perPage < 1 || perPage > maxUsersPerPage {
+		perPage = defaultUsersPerPage
+	}
+	logrus.WithField("query", query).WithField("page", page).Info("request to search users started")
+	users, total, err := a.userService.Search(query, page, perPage)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	usersResp := make([]AdminUserResponsePayload, 0, len(users))
+	for _, u := range users {
+		usersResp = append(usersResp, a.adminUserResponse(u))
+	}
+	c.JSON(http.StatusOK, AdminUserSearchResponsePayload{
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+		Users:   usersResp,
+	})
+	logrus.WithField("query", query).WithField("page", page).Info("request to search users successful")
+}
+
+// DisableUser disables a user account & records the action in the audit trail of the user.
+// The disabled user can not login & the existing tokens of the user are rejected.
+func (a *AdminHandler) DisableUser(c *gin.Context) {
+	a.changeAccountStatus(c, audit.ActionUserDisabled, func(actorID uint, userID uint, reason string) error {
+		return a.userService.Disable(actorID, userID, reason)
+	})
+}
+
+// EnableUser re-enables a disabled user account & records the action in the audit trail of the user.
+func (a *AdminHandler) EnableUser(c *gin.Context) {
+	a.changeAccountStatus(c, audit.ActionUserEnabled, func(actorID uint, userID uint, reason string) error {
+		return a.userService.Enable(actorID, userID, reason)
+	})
+}
+
+// DeleteUser deletes a user account, revokes all the sessions of the user & records the action in the audit trail of the user.
+func (a *AdminHandler) DeleteUser(c *gin.Context) {
+	a.changeAccountStatus(c, audit.ActionUserDeleted, func(actorID uint, userID uint, reason string) error {
+		if err := a.userService.CheckStatus(userID); err != nil && !errors.Is(err, user.ErrUserDisabled) {
+			return err
+		}
+		if err := a.userService.Delete(actorID, userID, reason); err != nil {
+			return err
+		}
+		_, err := a.authService.RevokeAllSessions(userID)
+		return err
+	})
+}
+
+// RevokeSessions revokes all the active sessions of a user & records the action in the audit trail of the user.
+// The user is logged out from all the devices, the api tokens of the user are not affected.
+func (a *AdminHandler) RevokeSessions(c *gin.Context) {
+	a.changeAccountStatus(c, audit.ActionSessionsRevoked, func(actorID uint, userID uint, reason string) error {
+		if err := a.userService.CheckStatus(userID); err != nil && !errors.Is(err, user.ErrUserDisabled) {
+			return err
+		}
+		count, err := a.authService.RevokeAllSessions(userID)
+		if err != nil {
+			return err
+		}
+		logrus.WithField("user-id", userID).Infof("%d sessions revoked", count)
+		return a.auditService.Record(actorID, userID, audit.ActionSessionsRevoked, reason)
+	})
+}
+
+// GetAuditTrail returns the audit trail of a user account.
+func (a *AdminHandler) GetAuditTrail(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid user id"))
+		return
+	}
+	logrus.WithField("user-id", userID).Info("request to retrieve audit trail started")
+	events, err := a.auditService.GetByUserID(uint(userID))
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	eventsResp := make([]AuditEventResponsePayload, 0, len(events))
+	for _, event := range events {
+		eventsResp = append(eventsResp, AuditEventResponsePayload{
+			ActorID:   event.ActorID,
+			Action:    event.Action,
+			Reason:    event.Reason,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, eventsResp)
+	logrus.WithField("user-id", userID).Info("request to retrieve audit trail successful")
+}
+
+// changeAccountStatus performs the change of the action on the user account of the request.
+// The change is responsible for recording the action in the audit trail of the user.
+func (a *AdminHandler) changeAccountStatus(c *gin.Context, action string, change func(actorID uint, userID uint, reason string) error) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid user id"))
+		return
+	}
+	if uint(userID) == actorID {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "own account status can not be changed"))
+		return
+	}
+	var statusPayload AccountStatusPayload
+	c.BindJSON(&statusPayload)
+	if err := statusPayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+
+	logrus.WithField("actor-id", actorID).WithField("user-id", userID).Infof("request to change account status: %s", action)
+	err = change(actorID, uint(userID), statusPayload.Reason)
+	if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, user.ErrUserDeleted) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "user not found"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("actor-id", actorID).WithField("user-id", userID).Infof("request to change account status successful: %s", action)
+}
+
+func (a *AdminHandler) adminUserResponse(u user.User) AdminUserResponsePayload {
+	userResp := AdminUserResponsePayload{
+		ID:             u.ID,
+		Email:          u.Email,
+		Name:           u.Name,
+		GithubID:       u.GithubID,
+		GithubUsername: u.GithubUsername,
+		Admin:          a.userService.IsAdmin(u),
+		CreatedAt:      u.CreatedAt,
+		DisabledAt:     u.DisabledAt,
+		DisabledReason: u.DisabledReason,
+	}
+	if u.DeletedAt.Valid {
+		deletedAt := u.DeletedAt.Time
+		userResp.DeletedAt = &deletedAt
+	}
+	// default repo record may only hold the github app installation until the repo is linked
+	if u.DefaultRepo != nil && u.DefaultRepo.Name != "" {
+		userResp.Repo = &AdminRepoResponsePayload{
+			Name:           u.DefaultRepo.Name,
+			Visibility:     u.DefaultRepo.Visibility,
+			DefaultBranch:  u.DefaultRepo.DefaultBranch,
+			InstallationID: u.DefaultRepo.InstallationID,
+		}
+	}
+	return userResp
+}
@@ -0,0 +1,303 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/audit"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/preference"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const targetUserID = uint(2024)
+
+func TestDisableUser(t *testing.T) {
+	t.Run("should disable the user & record the audit event when the request is valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+		mockUserService.EXPECT().Disable(userID, targetUserID, "spam").Return(nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/admin/users/:id/disable", getClaimsHandler(), handler.DisableUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2024/disable", strings.NewReader(`{"reason":"spam"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the reason is missing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+
+		router.POST("/api/v1/admin/users/:id/disable", getClaimsHandler(), handler.DisableUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2024/disable", strings.NewReader(`{}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("should fail with bad request when the admin disables own account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+
+		router.POST("/api/v1/admin/users/:id/disable", getClaimsHandler(), handler.DisableUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/1012/disable", strings.NewReader(`{"reason":"spam"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("should fail with bad request when the user does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+		mockUserService.EXPECT().Disable(userID, targetUserID, "spam").Return(user.ErrUserNotFound)
+
+		router.POST("/api/v1/admin/users/:id/disable", getClaimsHandler(), handler.DisableUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2024/disable", strings.NewReader(`{"reason":"spam"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestEnableUser(t *testing.T) {
+	t.Run("should enable the user & record the audit event when the request is valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+		mockUserService.EXPECT().Enable(userID, targetUserID, "appeal accepted").Return(nil)
+
+		router.POST("/api/v1/admin/users/:id/enable", getClaimsHandler(), handler.EnableUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2024/enable", strings.NewReader(`{"reason":"appeal accepted"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with internal server error when enabling the user fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+		mockUserService.EXPECT().Enable(userID, targetUserID, "appeal accepted").Return(errors.New("some error"))
+
+		router.POST("/api/v1/admin/users/:id/enable", getClaimsHandler(), handler.EnableUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2024/enable", strings.NewReader(`{"reason":"appeal accepted"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+}
+
+func TestSearchUsers(t *testing.T) {
+	t.Run("should return the requested page of matching users along with their linked repo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, nil)
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		linked := user.User{Email: "john.doe@example.com", GithubID: 12345, GithubUsername: "johndoe",
+			DefaultRepo: &preference.DefaultRepo{Name: "notes", Visibility: "private", DefaultBranch: "main", InstallationID: 8765}}
+		linked.ID = targetUserID
+		linked.CreatedAt = createdAt
+		deleted := user.User{Email: "john.roe@example.com", GithubID: 12346, GithubUsername: "johnroe"}
+		deleted.ID = targetUserID + 1
+		deleted.CreatedAt = createdAt
+		deleted.DeletedAt = gorm.DeletedAt{Time: createdAt, Valid: true}
+		mockUserService.EXPECT().Search("john", 2, 2).Return([]user.User{linked, deleted}, int64(4), nil)
+		mockUserService.EXPECT().IsAdmin(gomock.Any()).Return(false).Times(2)
+
+		router.GET("/api/v1/admin/users", getClaimsHandler(), handler.SearchUsers)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/users?query=john&page=2&per_page=2", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"total":4,"page":2,"per_page":2,"users":[
+			{"id":2024,"email":"john.doe@example.com","github_id":12345,"github_username":"johndoe","admin":false,"created_at":"2022-10-18T10:00:00Z",
+				"repo":{"name":"notes","visibility":"private","default_branch":"main","installation_id":8765}},
+			{"id":2025,"email":"john.roe@example.com","github_id":12346,"github_username":"johnroe","admin":false,"created_at":"2022-10-18T10:00:00Z",
+				"deleted_at":"2022-10-18T10:00:00Z"}]}`, response.Body.String())
+	})
+
+	t.Run("should use the default page size when the requested page size is too large", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, nil)
+		mockUserService.EXPECT().Search("", 1, defaultUsersPerPage).Return(nil, int64(0), nil)
+
+		router.GET("/api/v1/admin/users", getClaimsHandler(), handler.SearchUsers)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/users?per_page=1000", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"total":0,"page":1,"per_page":20,"users":[]}`, response.Body.String())
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	t.Run("should delete the user, revoke the sessions & record the audit event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, mockAuthService, mockAuditService)
+		mockUserService.EXPECT().CheckStatus(targetUserID).Return(user.ErrUserDisabled)
+		mockUserService.EXPECT().Delete(userID, targetUserID, "requested by user").Return(nil)
+		mockAuthService.EXPECT().RevokeAllSessions(targetUserID).Return(1, nil)
+
+		router.DELETE("/api/v1/admin/users/:id", getClaimsHandler(), handler.DeleteUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/users/2024", strings.NewReader(`{"reason":"requested by user"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the user is already deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+		mockUserService.EXPECT().CheckStatus(targetUserID).Return(user.ErrUserDeleted)
+
+		router.DELETE("/api/v1/admin/users/:id", getClaimsHandler(), handler.DeleteUser)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/users/2024", strings.NewReader(`{"reason":"requested by user"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestRevokeSessions(t *testing.T) {
+	t.Run("should revoke all the sessions of the user & record the audit event", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, mockAuthService, mockAuditService)
+		mockUserService.EXPECT().CheckStatus(targetUserID).Return(nil)
+		mockAuthService.EXPECT().RevokeAllSessions(targetUserID).Return(2, nil)
+		mockAuditService.EXPECT().Record(userID, targetUserID, audit.ActionSessionsRevoked, "lost device").Return(nil)
+
+		router.POST("/api/v1/admin/users/:id/sessions/revoke", getClaimsHandler(), handler.RevokeSessions)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2024/sessions/revoke", strings.NewReader(`{"reason":"lost device"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with internal server error when revoking the sessions fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuthService := auth.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, mockAuthService, mockAuditService)
+		mockUserService.EXPECT().CheckStatus(targetUserID).Return(nil)
+		mockAuthService.EXPECT().RevokeAllSessions(targetUserID).Return(0, errors.New("some error"))
+
+		router.POST("/api/v1/admin/users/:id/sessions/revoke", getClaimsHandler(), handler.RevokeSessions)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2024/sessions/revoke", strings.NewReader(`{"reason":"lost device"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+}
+
+func TestGetAuditTrail(t *testing.T) {
+	t.Run("should return the audit trail of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockAuditService := audit.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAdminHandler(mockUserService, nil, mockAuditService)
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		mockAuditService.EXPECT().GetByUserID(targetUserID).Return([]audit.Event{{ID: 1, CreatedAt: createdAt, ActorID: userID, UserID: targetUserID, Action: audit.ActionUserDisabled, Reason: "spam"}}, nil)
+
+		router.GET("/api/v1/admin/users/:id/audit", getClaimsHandler(), handler.GetAuditTrail)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/users/2024/audit", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"actor_id":1012,"action":"user.disabled","reason":"spam","created_at":"2022-10-18T10:00:00Z"}]`, response.Body.String())
+	})
+}
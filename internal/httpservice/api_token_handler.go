@@ -0,0 +1,180 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+)
+
+// APITokenRequestPayload represents the http request payload to create an api token.
+// The token never expires when expires in days is not provided.
+type APITokenRequestPayload struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// Validate validates the api token http request payload.
+func (a APITokenRequestPayload) Validate() error {
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&a.Scopes, validation.Required, validation.Each(validation.In(auth.Scopes...))),
+		validation.Field(&a.ExpiresInDays, validation.Min(0), validation.Max(365)),
+	)
+}
+
+// APITokenResponsePayload represents the http response payload of api token entity.
+// Token is returned only once when the api token is created.
+type APITokenResponsePayload struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// APITokenHandler represents http handler for managing user generated api tokens.
+type APITokenHandler struct {
+	apiTokenService auth.APITokenService
+}
+
+// NewAPITokenHandler creates and returns a new api token handler.
+func NewAPITokenHandler(apiTokenService auth.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+	}
+}
+
+// CreateToken creates a new api token for logged in user.
+// Api tokens can only be managed from the browser session, not with another api token.
+func (a *APITokenHandler) CreateToken(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if isAPITokenRequest(c) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	var apiTokenPayload APITokenRequestPayload
+	c.BindJSON(&apiTokenPayload)
+	if err := apiTokenPayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+	// api token can not be granted the scopes which are not granted to the current session
+	if !auth.HasScopes(getScopeFromContext(c), apiTokenPayload.Scopes...) {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, "scopes: must be granted to the current session."))
+		return
+	}
+
+	logrus.WithField("user-id", userID).Infof("request to create api token: %s", apiTokenPayload.Name)
+	var expiresAt *time.Time
+	if apiTokenPayload.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, apiTokenPayload.ExpiresInDays).UTC()
+		expiresAt = &t
+	}
+	apiToken, token, err := a.apiTokenService.Create(userID, apiTokenPayload.Name, apiTokenPayload.Scopes, expiresAt)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	apiTokenResp := apiTokenResponse(apiToken)
+	apiTokenResp.Token = token
+	c.JSON(http.StatusCreated, apiTokenResp)
+	logrus.WithField("user-id", userID).WithField("api-token-id", apiToken.ID).Info("request to create api token successful")
+}
+
+// GetTokens returns the api tokens of logged in user.
+func (a *APITokenHandler) GetTokens(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	logrus.WithField("user-id", userID).Info("request to retrieve api tokens started")
+	apiTokens, err := a.apiTokenService.GetAll(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	apiTokensResp := make([]APITokenResponsePayload, 0, len(apiTokens))
+	for _, apiToken := range apiTokens {
+		apiTokensResp = append(apiTokensResp, apiTokenResponse(apiToken))
+	}
+	c.JSON(http.StatusOK, apiTokensResp)
+	logrus.WithField("user-id", userID).Info("request to retrieve api tokens successful")
+}
+
+// RevokeToken revokes an api token of logged in user.
+func (a *APITokenHandler) RevokeToken(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if isAPITokenRequest(c) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	apiTokenID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid api token id"))
+		return
+	}
+	logrus.WithField("user-id", userID).WithField("api-token-id", apiTokenID).Info("request to revoke api token started")
+	err = a.apiTokenService.Revoke(userID, uint(apiTokenID))
+	if errors.Is(err, auth.ErrAPITokenNotFound) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "api token not found"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", userID).WithField("api-token-id", apiTokenID).Info("request to revoke api token successful")
+}
+
+func apiTokenResponse(apiToken auth.APIToken) APITokenResponsePayload {
+	return APITokenResponsePayload{
+		ID:         apiToken.ID,
+		Name:       apiToken.Name,
+		Scopes:     auth.SplitScopes(apiToken.Scopes),
+		CreatedAt:  apiToken.CreatedAt,
+		ExpiresAt:  apiToken.ExpiresAt,
+		LastUsedAt: apiToken.LastUsedAt,
+	}
+}
+
+func getScopeFromContext(c *gin.Context) string {
+	claims, _ := c.Get("claims")
+	if claims == nil {
+		return ""
+	}
+	scope, _ := claims.(jwt.MapClaims)["scope"].(string)
+	return scope
+}
+
+// isAPITokenRequest checks whether the request is authorized with a user generated api token.
+func isAPITokenRequest(c *gin.Context) bool {
+	claims, _ := c.Get("claims")
+	if claims == nil {
+		return false
+	}
+	_, ok := claims.(jwt.MapClaims)["api_token_id"]
+	return ok
+}
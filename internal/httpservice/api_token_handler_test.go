@@ -0,0 +1,198 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	apiTokenID     = uint(31)
+	apiTokenString = "bnp_dGhpcy1pcy1hbi1hcGktdG9rZW4"
+)
+
+func TestCreateToken(t *testing.T) {
+	t.Run("should create api token & return the token only once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+		mockAPITokenService.EXPECT().Create(userID, "cron", []string{"notes:read", "notes:write"}, gomock.Not(gomock.Nil())).
+			DoAndReturn(func(userID uint, name string, scopes []string, expiresAt *time.Time) (auth.APIToken, string, error) {
+				apiToken := auth.APIToken{UserID: userID, Name: name, Scopes: "notes:read notes:write", ExpiresAt: expiresAt}
+				apiToken.ID = apiTokenID
+				return apiToken, apiTokenString, nil
+			})
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/tokens", getSessionClaimsHandler(), handler.CreateToken)
+		response := httptest.NewRecorder()
+		body := `{"name":"cron", "scopes":["notes:read", "notes:write"], "expires_in_days": 30}`
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/tokens", strings.NewReader(body))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.Contains(t, response.Body.String(), `"token":"bnp_dGhpcy1pcy1hbi1hcGktdG9rZW4"`)
+		assert.Contains(t, response.Body.String(), `"scopes":["notes:read","notes:write"]`)
+	})
+
+	t.Run("should fail with validation error when the scope is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/tokens", getSessionClaimsHandler(), handler.CreateToken)
+		response := httptest.NewRecorder()
+		body := `{"name":"cron", "scopes":["repo:delete"]}`
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/tokens", strings.NewReader(body))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.Contains(t, response.Body.String(), `"code":"validation_failed"`)
+	})
+
+	t.Run("should fail with validation error when the scope is not granted to the current session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/tokens", getSessionClaimsHandler(), handler.CreateToken)
+		response := httptest.NewRecorder()
+		body := `{"name":"cron", "scopes":["admin"]}`
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/tokens", strings.NewReader(body))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"scopes: must be granted to the current session."}`, response.Body.String())
+	})
+
+	t.Run("should fail with forbidden status when the request is authorized with an api token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+		claims := jwt.MapClaims{"sub": strconv.FormatUint(uint64(userID), 10), "scope": "notes:write", "api_token_id": float64(apiTokenID)}
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/tokens", func(c *gin.Context) { c.Set("claims", claims) }, handler.CreateToken)
+		response := httptest.NewRecorder()
+		body := `{"name":"cron", "scopes":["admin"]}`
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/tokens", strings.NewReader(body))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
+
+func TestGetTokens(t *testing.T) {
+	t.Run("should return api tokens of the user without the token value", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		apiToken := auth.APIToken{UserID: userID, Name: "cron", TokenHash: "hash", Scopes: "notes:read"}
+		apiToken.ID = apiTokenID
+		apiToken.CreatedAt = createdAt
+		mockAPITokenService.EXPECT().GetAll(userID).Return([]auth.APIToken{apiToken}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/tokens", getSessionClaimsHandler(), handler.GetTokens)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/tokens", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"id":31, "name":"cron", "scopes":["notes:read"], "created_at":"2022-10-18T10:00:00Z"}]`, response.Body.String())
+	})
+
+	t.Run("should fail with internal server error when retrieving api tokens fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+		mockAPITokenService.EXPECT().GetAll(userID).Return(nil, errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/tokens", getSessionClaimsHandler(), handler.GetTokens)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/tokens", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func TestRevokeToken(t *testing.T) {
+	t.Run("should revoke the api token of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+		mockAPITokenService.EXPECT().Revoke(userID, apiTokenID).Return(nil)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/tokens/:id", getSessionClaimsHandler(), handler.RevokeToken)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/tokens/31", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the api token does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAPITokenService := auth.NewMockAPITokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewAPITokenHandler(mockAPITokenService)
+		mockAPITokenService.EXPECT().Revoke(userID, apiTokenID).Return(auth.ErrAPITokenNotFound)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/tokens/:id", getSessionClaimsHandler(), handler.RevokeToken)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/tokens/31", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"api token not found"}`, response.Body.String())
+	})
+}
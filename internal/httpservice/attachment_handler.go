@@ -0,0 +1,214 @@
+package httpservice
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/attachment"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// AttachmentResponsePayload represents the http response payload of attachment entity.
+// Path is the path of the attachment in the repo, the notes embed the attachment using this path.
+type AttachmentResponsePayload struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	SHA         string `json:"sha"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// AttachmentHandler represents http handler for managing the files (e.g. images) attached to the notes.
+// The attachments are stored in the default repo of the user, or in the repo of the notebook when the route is scoped to a notebook.
+type AttachmentHandler struct {
+	attachmentService attachment.Service
+	userService       user.Service
+	tokenService      user.TokenService
+	notebookService   notebook.Service
+}
+
+// NewAttachmentHandler creates and returns a new attachment handler.
+func NewAttachmentHandler(attachmentService attachment.Service, userService user.Service, tokenService user.TokenService,
+	notebookService notebook.Service) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		userService:       userService,
+		tokenService:      tokenService,
+		notebookService:   notebookService,
+	}
+}
+
+// UploadAttachment stores the file uploaded as the file field of a multipart form & returns its metadata as a http response.
+// The images (png, jpeg, gif, webp & bmp) and pdf documents are supported, their type is detected from the content.
+func (a *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	maxSize := a.attachmentService.MaxSize()
+	// the multipart headers are small, the limit is slightly larger than the maximum size of the attachment
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+(1<<20))
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, fmt.Sprintf("file is required & it must not exceed %s", formatSize(maxSize))))
+		return
+	}
+	if fileHeader.Size > maxSize {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("file: must not exceed %s", formatSize(maxSize))))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	u, _, repoDetails, ghToken, ok := a.resolveRepo(c, notebook.RoleEditor)
+	if !ok {
+		return
+	}
+	log := logrus.WithField("user-id", u.ID).WithField("size", len(content))
+	log.Info("request to upload attachment started")
+	att, err := a.attachmentService.Upload(c, ghToken, repoDetails, content, u.GetCommitName(), u.GetCommitEmail())
+	switch {
+	case errors.Is(err, attachment.ErrEmptyAttachment):
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, "file: cannot be blank"))
+		return
+	case errors.Is(err, attachment.ErrAttachmentTooLarge):
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("file: must not exceed %s", formatSize(maxSize))))
+		return
+	case errors.Is(err, attachment.ErrUnsupportedType):
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, "file: must be a png, jpeg, gif, webp or bmp image or a pdf document"))
+		return
+	case errors.Is(err, attachment.ErrInvalidImage):
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("file: %s", err.Error())))
+		return
+	case err != nil:
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, attachmentResponse(att))
+	log.WithField("attachment", att.Name).Info("request to upload attachment successful")
+}
+
+// GetAttachment streams the content of the attachment with its content type as a http response.
+// The resized variant of the image is sent when the size (thumb or web) is provided using the query-param.
+// The attachments are named after the hash of their content, so the clients may cache them indefinitely.
+func (a *AttachmentHandler) GetAttachment(c *gin.Context) {
+	name := c.Param("name")
+	size := c.Query("size")
+	if err := validation.Validate(size, validation.In(attachment.Sizes...)); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("size: %s", err.Error())))
+		return
+	}
+	u, repoUser, repoDetails, ghToken, ok := a.resolveRepo(c, notebook.RoleViewer)
+	if !ok {
+		return
+	}
+	log := logrus.WithField("user-id", u.ID).WithField("attachment", name).WithField("size", size)
+	log.Info("request to retrieve attachment started")
+	att, err := a.attachmentService.Get(c, ghToken, repoUser.ID, repoDetails, name, size)
+	if errors.Is(err, attachment.ErrAttachmentNotFound) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, attachment.ErrVariantNotSupported) {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("size: %s", err.Error())))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	if size == "" {
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, att.Name))
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, att.ContentType, att.Content)
+	log.Info("request to retrieve attachment successful")
+}
+
+// DeleteAttachment deletes the attachment, the notes embedding the attachment are not updated.
+func (a *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	name := c.Param("name")
+	u, repoUser, repoDetails, ghToken, ok := a.resolveRepo(c, notebook.RoleEditor)
+	if !ok {
+		return
+	}
+	logrus.WithField("user-id", u.ID).WithField("attachment", name).Info("request to delete attachment started")
+	err := a.attachmentService.Delete(c, ghToken, repoUser.ID, repoDetails, name, u.GetCommitName(), u.GetCommitEmail())
+	if errors.Is(err, attachment.ErrAttachmentNotFound) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", u.ID).WithField("attachment", name).Info("request to delete attachment successful")
+}
+
+// resolveRepo returns the user along with the user owning the repo the attachments are stored in, the repo & the github
+// token used to access it. The request is aborted when the repo can not be resolved.
+func (a *AttachmentHandler) resolveRepo(c *gin.Context, requiredRole string) (user.User, user.User, github.GitRepoProps, oauth2.Token, bool) {
+	notebookID, err := getNotebookIDParam(c)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return user.User{}, user.User{}, github.GitRepoProps{}, oauth2.Token{}, false
+	}
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return user.User{}, user.User{}, github.GitRepoProps{}, oauth2.Token{}, false
+	}
+	u, err := a.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return user.User{}, user.User{}, github.GitRepoProps{}, oauth2.Token{}, false
+	}
+	repoDetails, repoUser, err := resolveNoteRepo(a.userService, a.notebookService, u, notebookID, requiredRole)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return user.User{}, user.User{}, github.GitRepoProps{}, oauth2.Token{}, false
+	}
+	ghToken, err := a.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return user.User{}, user.User{}, github.GitRepoProps{}, oauth2.Token{}, false
+	}
+	return u, repoUser, repoDetails, ghToken, true
+}
+
+func attachmentResponse(att attachment.Attachment) AttachmentResponsePayload {
+	return AttachmentResponsePayload{
+		Name:        att.Name,
+		Path:        att.Path,
+		SHA:         att.SHA,
+		ContentType: att.ContentType,
+		Size:        att.Size,
+	}
+}
+
+// formatSize formats the size in bytes using the largest unit the size is a multiple of.
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%d MB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%d KB", size>>10)
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
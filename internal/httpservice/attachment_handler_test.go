@@ -0,0 +1,378 @@
+package httpservice
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/batnoter/batnoter-api/internal/attachment"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const attachmentName = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824.png"
+
+func TestUploadAttachment(t *testing.T) {
+	t.Run("should store the uploaded attachment & return its metadata", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		repoDetails := github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}
+		mockAttachmentService.EXPECT().MaxSize().Return(int64(10 << 20))
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Upload(gomock.Any(), ghToken, repoDetails, []byte("\x89PNG"), authorName, authorEmail).
+			Return(attachment.Attachment{Name: attachmentName, Path: "attachments/" + attachmentName, SHA: "blobsha", ContentType: "image/png", Size: 4}, nil)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.POST("/api/v1/attachments", getClaimsHandler(), handler.UploadAttachment)
+		response := httptest.NewRecorder()
+		req := attachmentRequest(t, "/api/v1/attachments", "screenshot.png", "\x89PNG")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.JSONEq(t, `{"name":"`+attachmentName+`","path":"attachments/`+attachmentName+`","sha":"blobsha","content_type":"image/png","size":4}`,
+			response.Body.String())
+	})
+
+	t.Run("should return bad request when the type of the attachment is not supported", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockAttachmentService.EXPECT().MaxSize().Return(int64(10 << 20))
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Upload(gomock.Any(), ghToken, gomock.Any(), gomock.Any(), authorName, authorEmail).
+			Return(attachment.Attachment{}, attachment.ErrUnsupportedType)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.POST("/api/v1/attachments", getClaimsHandler(), handler.UploadAttachment)
+		response := httptest.NewRecorder()
+		req := attachmentRequest(t, "/api/v1/attachments", "drawing.svg", "<svg></svg>")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"file: must be a png, jpeg, gif, webp or bmp image or a pdf document"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the attachment exceeds the maximum size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+
+		router := getRouter()
+		mockAttachmentService.EXPECT().MaxSize().Return(int64(2 << 10))
+		handler := NewAttachmentHandler(mockAttachmentService, nil, nil, nil)
+
+		router.POST("/api/v1/attachments", getClaimsHandler(), handler.UploadAttachment)
+		response := httptest.NewRecorder()
+		req := attachmentRequest(t, "/api/v1/attachments", "photo.jpg", string(make([]byte, 3<<10)))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"file: must not exceed 2 KB"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the file is not uploaded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+
+		router := getRouter()
+		mockAttachmentService.EXPECT().MaxSize().Return(int64(10 << 20))
+		handler := NewAttachmentHandler(mockAttachmentService, nil, nil, nil)
+
+		router.POST("/api/v1/attachments", getClaimsHandler(), handler.UploadAttachment)
+		response := httptest.NewRecorder()
+		req := attachmentRequest(t, "/api/v1/attachments", "", "")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"file is required & it must not exceed 10 MB"}`, response.Body.String())
+	})
+
+	t.Run("should return forbidden when the user is a viewer of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		nb := validNotebook()
+		nb.Role = notebook.RoleViewer
+		mockAttachmentService.EXPECT().MaxSize().Return(int64(10 << 20))
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, nil, mockNotebookService)
+
+		router.POST("/api/v1/notebooks/:notebook/attachments", getClaimsHandler(), handler.UploadAttachment)
+		response := httptest.NewRecorder()
+		req := attachmentRequest(t, "/api/v1/notebooks/7/attachments", "screenshot.png", "\x89PNG")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
+
+func TestGetAttachment(t *testing.T) {
+	t.Run("should return the content of the attachment with its content type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		repoDetails := github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Get(gomock.Any(), ghToken, userID, repoDetails, attachmentName, "").
+			Return(attachment.Attachment{Name: attachmentName, ContentType: "image/png", Content: []byte("\x89PNG")}, nil)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/attachments/:name", getClaimsHandler(), handler.GetAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/attachments/"+attachmentName, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "image/png", response.Header().Get("Content-Type"))
+		assert.Equal(t, "nosniff", response.Header().Get("X-Content-Type-Options"))
+		assert.Contains(t, response.Header().Get("Cache-Control"), "immutable")
+		assert.Equal(t, "\x89PNG", response.Body.String())
+	})
+
+	t.Run("should return the thumbnail of the image", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Get(gomock.Any(), ghToken, userID, gomock.Any(), attachmentName, attachment.SizeThumb).
+			Return(attachment.Attachment{Name: attachmentName, ContentType: "image/jpeg", Content: []byte("thumb")}, nil)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/attachments/:name", getClaimsHandler(), handler.GetAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/attachments/"+attachmentName+"?size=thumb", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "image/jpeg", response.Header().Get("Content-Type"))
+		assert.Equal(t, "thumb", response.Body.String())
+	})
+
+	t.Run("should return the thumbnail of the image of the notebook cached for the creator of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		creator := validUser()
+		creator.ID = 2024
+		nb := validNotebook()
+		nb.UserID = creator.ID
+		nb.Role = notebook.RoleViewer
+		ghToken := getOAuth2Token(creator.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+		mockUserService.EXPECT().Get(creator.ID).Return(creator, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), creator, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Get(gomock.Any(), ghToken, creator.ID, gomock.Any(), attachmentName, attachment.SizeThumb).
+			Return(attachment.Attachment{Name: attachmentName, ContentType: "image/jpeg", Content: []byte("thumb")}, nil)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, mockNotebookService)
+
+		router.GET("/api/v1/notebooks/:notebook/attachments/:name", getClaimsHandler(), handler.GetAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/7/attachments/"+attachmentName+"?size=thumb", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "thumb", response.Body.String())
+	})
+
+	t.Run("should return bad request when the size is invalid", func(t *testing.T) {
+		router := getRouter()
+		handler := NewAttachmentHandler(nil, nil, nil, nil)
+
+		router.GET("/api/v1/attachments/:name", getClaimsHandler(), handler.GetAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/attachments/"+attachmentName+"?size=huge", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"size: must be a valid value"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the variant of a pdf document is requested", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Get(gomock.Any(), ghToken, userID, gomock.Any(), "document.pdf", attachment.SizeWeb).
+			Return(attachment.Attachment{}, attachment.ErrVariantNotSupported)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/attachments/:name", getClaimsHandler(), handler.GetAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/attachments/document.pdf?size=web", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"size: variants are only available for images"}`, response.Body.String())
+	})
+
+	t.Run("should return not found when the attachment does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Get(gomock.Any(), ghToken, userID, gomock.Any(), "notes.md", "").Return(attachment.Attachment{}, attachment.ErrAttachmentNotFound)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/attachments/:name", getClaimsHandler(), handler.GetAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/attachments/notes.md", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return internal server error when retrieving the attachment fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Get(gomock.Any(), ghToken, userID, gomock.Any(), attachmentName, "").Return(attachment.Attachment{}, errors.New("some error"))
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/attachments/:name", getClaimsHandler(), handler.GetAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/attachments/"+attachmentName, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	t.Run("should delete the attachment of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		nb := validNotebook()
+		nb.Role = notebook.RoleEditor
+		ghToken := getOAuth2Token(u.GithubToken)
+		repoDetails := github.GitRepoProps{Repository: "work-notes", DefaultBranch: "main", Owner: owner}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Delete(gomock.Any(), ghToken, userID, repoDetails, attachmentName, authorName, authorEmail).Return(nil)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, mockNotebookService)
+
+		router.DELETE("/api/v1/notebooks/:notebook/attachments/:name", getClaimsHandler(), handler.DeleteAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/notebooks/7/attachments/"+attachmentName, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return not found when the attachment does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAttachmentService := attachment.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockAttachmentService.EXPECT().Delete(gomock.Any(), ghToken, userID, gomock.Any(), attachmentName, authorName, authorEmail).Return(attachment.ErrAttachmentNotFound)
+		handler := NewAttachmentHandler(mockAttachmentService, mockUserService, mockTokenService, nil)
+
+		router.DELETE("/api/v1/attachments/:name", getClaimsHandler(), handler.DeleteAttachment)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/attachments/"+attachmentName, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
+
+// attachmentRequest creates the multipart request uploading the file, the file is not added when its name is empty.
+func attachmentRequest(t *testing.T, url string, fileName string, content string) *http.Request {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	if fileName != "" {
+		fw, err := w.CreateFormFile("file", fileName)
+		assert.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	req, _ := http.NewRequest(http.MethodPost, url, body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
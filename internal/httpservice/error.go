@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/github"
 	"github.com/sirupsen/logrus"
 )
 
@@ -56,12 +57,22 @@ const (
 
 	// ErrorCodeInternalServerError error code for internal server error.
 	ErrorCodeInternalServerError = "internal_server_error"
+
+	// ErrorCodeInstallationRequired error code for the repo access requiring the github app to be installed.
+	// The client should start the github app installation flow when receiving it.
+	ErrorCodeInstallationRequired = "installation_required"
 )
 
 func abortRequestWithError(c *gin.Context, err error) {
 	var appErr *AppError
 	errors.As(err, &appErr)
-	if appErr != nil {
+	if errors.Is(err, github.ErrInstallationRequired) {
+		logrus.Warn("github app installation required to access the repo")
+		c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{
+			Code:    ErrorCodeInstallationRequired,
+			Message: "github app installation is required to access the repo",
+		})
+	} else if appErr != nil {
 		logrus.WithField("error_code", appErr.code).WithField("error_message", appErr.message).Error("bad request")
 		c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
 			Code:    appErr.code,
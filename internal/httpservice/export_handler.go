@@ -0,0 +1,140 @@
+package httpservice
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/export"
+	"github.com/sirupsen/logrus"
+)
+
+// exportDownloadPath is the path of the endpoint to download the archive of the latest export.
+const exportDownloadPath = "/api/v1/user/me/export/download"
+
+// ExportRequestPayload represents the http request payload to request a personal data export.
+type ExportRequestPayload struct {
+	IncludeNotes bool `json:"include_notes"`
+}
+
+// ExportResponsePayload represents the http response payload of export entity.
+// DownloadURL is set once the archive of the export is ready.
+type ExportResponsePayload struct {
+	ID           uint       `json:"id"`
+	Status       string     `json:"status"`
+	IncludeNotes bool       `json:"include_notes"`
+	Size         int        `json:"size,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	DownloadURL  string     `json:"download_url,omitempty"`
+}
+
+// ExportHandler represents http handler for the personal data exports of the user.
+type ExportHandler struct {
+	exportService export.Service
+}
+
+// NewExportHandler creates and returns a new export handler.
+func NewExportHandler(exportService export.Service) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport starts a new export of the user's data in background.
+// The client should poll the export status until the archive is ready to be downloaded, the user is also notified by email.
+func (e *ExportHandler) RequestExport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	// request payload is optional
+	var exportPayload ExportRequestPayload
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&exportPayload); err != nil && !errors.Is(err, io.EOF) {
+			abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid request payload"))
+			return
+		}
+	}
+	logrus.WithField("user-id", userID).WithField("include-notes", exportPayload.IncludeNotes).Info("request to export data started")
+	exp, err := e.exportService.Request(userID, exportPayload.IncludeNotes)
+	if errors.Is(err, export.ErrExportInProgress) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "export is already in progress"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, exportResponse(exp))
+	logrus.WithField("user-id", userID).Info("request to export data successful")
+}
+
+// GetExport returns the status of the latest export of the user.
+func (e *ExportHandler) GetExport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	exp, err := e.exportService.GetLatest(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	if exp.ID == 0 {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, exportResponse(exp))
+}
+
+// DownloadExport sends the zip archive of the latest export of the user as an attachment.
+func (e *ExportHandler) DownloadExport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	logrus.WithField("user-id", userID).Info("request to download export started")
+	exp, archive, err := e.exportService.GetArchive(userID)
+	if errors.Is(err, export.ErrExportNotReady) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	defer archive.Close()
+	c.DataFromReader(http.StatusOK, int64(exp.Size), "application/zip", archive, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="batnoter-export-%s.zip"`, exp.CompletedAt.Format("2006-01-02")),
+	})
+	logrus.WithField("user-id", userID).Info("request to download export successful")
+}
+
+func exportResponse(exp export.Export) ExportResponsePayload {
+	exportResp := ExportResponsePayload{
+		ID:           exp.ID,
+		Status:       exp.Status,
+		IncludeNotes: exp.IncludeNotes,
+		Size:         exp.Size,
+		Error:        exp.Error,
+		CreatedAt:    exp.CreatedAt,
+		CompletedAt:  exp.CompletedAt,
+		ExpiresAt:    exp.ExpiresAt,
+	}
+	if exp.Ready() {
+		exportResp.DownloadURL = exportDownloadPath
+	}
+	return exportResp
+}
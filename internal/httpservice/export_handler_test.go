@@ -0,0 +1,209 @@
+package httpservice
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/export"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const exportID = uint(41)
+
+func TestRequestExport(t *testing.T) {
+	t.Run("should start the export of user's data", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		pending := export.Export{Model: gorm.Model{ID: exportID, CreatedAt: createdAt}, UserID: userID, IncludeNotes: true, Status: export.StatusPending}
+		mockExportService.EXPECT().Request(userID, true).Return(pending, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/me/export", getClaimsHandler(), handler.RequestExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/me/export", strings.NewReader(`{"include_notes":true}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+		assert.JSONEq(t, `{"id":41,"status":"pending","include_notes":true,"created_at":"2022-10-18T10:00:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should start the export without notes when request payload is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		mockExportService.EXPECT().Request(userID, false).Return(export.Export{Model: gorm.Model{ID: exportID}, Status: export.StatusPending}, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/me/export", getClaimsHandler(), handler.RequestExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/me/export", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+	})
+
+	t.Run("should fail with bad request when the previous export is in progress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		mockExportService.EXPECT().Request(userID, false).Return(export.Export{Status: export.StatusRunning}, export.ErrExportInProgress)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/me/export", getClaimsHandler(), handler.RequestExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/me/export", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"export is already in progress"}`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when request payload is invalid", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/me/export", getClaimsHandler(), handler.RequestExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/me/export", strings.NewReader(`{"include_notes":"yes"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestGetExport(t *testing.T) {
+	t.Run("should return the latest export with download url when the archive is ready", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		completedAt := createdAt.Add(time.Minute)
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		completed := export.Export{Model: gorm.Model{ID: exportID, CreatedAt: createdAt}, UserID: userID, Status: export.StatusCompleted,
+			Size: 2048, CompletedAt: &completedAt, ExpiresAt: &expiresAt}
+		mockExportService.EXPECT().GetLatest(userID).Return(completed, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/me/export", getClaimsHandler(), handler.GetExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/me/export", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"id":41,"status":"completed","include_notes":false,"size":2048,"created_at":"2022-10-18T10:00:00Z",
+			"completed_at":"2022-10-18T10:01:00Z","expires_at":"`+expiresAt.Format(time.RFC3339)+`","download_url":"/api/v1/user/me/export/download"}`, response.Body.String())
+	})
+
+	t.Run("should fail with not found when user has not requested any export", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		mockExportService.EXPECT().GetLatest(userID).Return(export.Export{}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/me/export", getClaimsHandler(), handler.GetExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/me/export", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should fail with internal server error when retrieving export fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		mockExportService.EXPECT().GetLatest(userID).Return(export.Export{}, errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/me/export", getClaimsHandler(), handler.GetExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/me/export", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func TestDownloadExport(t *testing.T) {
+	t.Run("should send the archive of the latest export as attachment", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		completedAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		completed := export.Export{Model: gorm.Model{ID: exportID}, Status: export.StatusCompleted, Size: 7, CompletedAt: &completedAt}
+		mockExportService.EXPECT().GetArchive(userID).Return(completed, io.NopCloser(strings.NewReader("archive")), nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/me/export/download", getClaimsHandler(), handler.DownloadExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/me/export/download", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/zip", response.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="batnoter-export-2022-10-18.zip"`, response.Header().Get("Content-Disposition"))
+		assert.Equal(t, "7", response.Header().Get("Content-Length"))
+		assert.Equal(t, "archive", response.Body.String())
+	})
+
+	t.Run("should fail with not found when the archive is not ready", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockExportService := export.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewExportHandler(mockExportService)
+		mockExportService.EXPECT().GetArchive(userID).Return(export.Export{Status: export.StatusRunning}, nil, export.ErrExportNotReady)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/me/export/download", getClaimsHandler(), handler.DownloadExport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/me/export/download", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
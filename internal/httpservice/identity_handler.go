@@ -0,0 +1,143 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// githubLinkPath is the path of the endpoint initiating the oauth2 flow to link a github account.
+const githubLinkPath = "/api/v1/oauth2/link/github"
+
+// IdentityResponsePayload represents the http response payload of identity entity.
+// Primary is set for the identity owning the notes repo of the user.
+type IdentityResponsePayload struct {
+	ID             uint      `json:"id"`
+	Provider       string    `json:"provider"`
+	ProviderUserID string    `json:"provider_user_id"`
+	Username       string    `json:"username,omitempty"`
+	Email          string    `json:"email,omitempty"`
+	Primary        bool      `json:"primary"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// IdentityLinkResponsePayload represents the http response payload to link an identity.
+// The client should submit a form with the token as post param to the url to link the identity,
+// the token is not sent in the url so that it does not end up in the access logs.
+type IdentityLinkResponsePayload struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// IdentityHandler represents http handler for managing the identities linked to the user.
+type IdentityHandler struct {
+	userService   user.Service
+	githubService github.Service
+	authService   auth.Service
+}
+
+// NewIdentityHandler creates and returns a new identity handler.
+func NewIdentityHandler(userService user.Service, githubService github.Service, authService auth.Service) *IdentityHandler {
+	return &IdentityHandler{
+		userService:   userService,
+		githubService: githubService,
+		authService:   authService,
+	}
+}
+
+// GetIdentities returns the identities linked to the user.
+func (i *IdentityHandler) GetIdentities(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	dbUser, err := i.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	identities, err := i.userService.GetIdentities(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	identitiesResp := make([]IdentityResponsePayload, 0, len(identities))
+	for _, identity := range identities {
+		identitiesResp = append(identitiesResp, IdentityResponsePayload{
+			ID:             identity.ID,
+			Provider:       identity.Provider,
+			ProviderUserID: identity.ProviderUserID,
+			Username:       identity.Username,
+			Email:          identity.Email,
+			Primary:        dbUser.IsPrimaryIdentity(identity),
+			CreatedAt:      identity.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, identitiesResp)
+}
+
+// LinkGithub starts linking a github account to the user.
+// It returns the url of the oauth2 flow & a short-lived link token, the client should post the token to the url as form.
+func (i *IdentityHandler) LinkGithub(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	logrus.WithField("user-id", userID).Info("request to link github account started")
+	linkToken, err := i.authService.GenerateToken(userID, uuid.NewString(), []string{auth.ScopeIdentityLink})
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, IdentityLinkResponsePayload{URL: githubLinkPath, Token: linkToken})
+}
+
+// UnlinkIdentity unlinks the identity from the user, the user can not login with the unlinked identity anymore.
+// The primary identity can not be unlinked. The github authorization of the unlinked account is revoked.
+func (i *IdentityHandler) UnlinkIdentity(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	identityID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid identity id"))
+		return
+	}
+	logrus.WithField("user-id", userID).WithField("identity-id", identityID).Info("request to unlink identity started")
+	identity, err := i.userService.UnlinkIdentity(userID, uint(identityID))
+	if errors.Is(err, user.ErrIdentityNotFound) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "identity not found"))
+		return
+	}
+	if errors.Is(err, user.ErrPrimaryIdentity) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "primary identity can not be unlinked"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	if identity.Provider == user.ProviderGithub {
+		// the identity is already unlinked, so failing to revoke the authorization does not fail the request
+		if err := i.githubService.RevokeGrant(c, user.ParseGithubToken(identity.Token)); err != nil {
+			logrus.WithField("user-id", userID).Warnf("revoking github authorization of unlinked identity failed: %s", err.Error())
+		}
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", userID).WithField("identity-id", identityID).Info("request to unlink identity successful")
+}
@@ -0,0 +1,170 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const identityID = uint(51)
+
+func TestGetIdentities(t *testing.T) {
+	t.Run("should return the identities linked to the user & mark the primary identity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewIdentityHandler(mockUserService, nil, nil)
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		identities := []user.Identity{
+			{Model: gorm.Model{ID: identityID, CreatedAt: createdAt}, UserID: userID, Provider: user.ProviderGithub, ProviderUserID: "12345", Username: "johndoe", Email: email},
+			{Model: gorm.Model{ID: identityID + 1, CreatedAt: createdAt}, UserID: userID, Provider: user.ProviderGithub, ProviderUserID: "54321", Username: "johndoe-work"},
+		}
+		mockUserService.EXPECT().Get(userID).Return(user.User{Email: email, GithubID: 12345}, nil)
+		mockUserService.EXPECT().GetIdentities(userID).Return(identities, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/identities", getClaimsHandler(), handler.GetIdentities)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/identities", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"id":51,"provider":"github","provider_user_id":"12345","username":"johndoe","email":"john.doe@example.com","primary":true,"created_at":"2022-10-18T10:00:00Z"},
+			{"id":52,"provider":"github","provider_user_id":"54321","username":"johndoe-work","primary":false,"created_at":"2022-10-18T10:00:00Z"}]`, response.Body.String())
+	})
+
+	t.Run("should fail with internal server error when retrieving identities fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewIdentityHandler(mockUserService, nil, nil)
+		mockUserService.EXPECT().Get(userID).Return(user.User{Email: email, GithubID: 12345}, nil)
+		mockUserService.EXPECT().GetIdentities(userID).Return(nil, errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/identities", getClaimsHandler(), handler.GetIdentities)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/identities", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func TestLinkGithub(t *testing.T) {
+	t.Run("should return the url to link github account & the link token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAuthService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewIdentityHandler(nil, nil, mockAuthService)
+		mockAuthService.EXPECT().GenerateToken(userID, gomock.Any(), []string{auth.ScopeIdentityLink}).Return("link.token", nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/identities/github", getClaimsHandler(), handler.LinkGithub)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/identities/github", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"url":"/api/v1/oauth2/link/github","token":"link.token"}`, response.Body.String())
+	})
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	t.Run("should unlink the identity & revoke the github authorization of it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewIdentityHandler(mockUserService, mockGithubService, nil)
+		identity := user.Identity{Model: gorm.Model{ID: identityID}, Provider: user.ProviderGithub, ProviderUserID: "54321", Token: `{"access_token":"gho_work_token"}`}
+		mockUserService.EXPECT().UnlinkIdentity(userID, identityID).Return(identity, nil)
+		mockGithubService.EXPECT().RevokeGrant(gomock.Any(), user.ParseGithubToken(identity.Token)).Return(errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/identities/:id", getClaimsHandler(), handler.UnlinkIdentity)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/identities/51", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when unlinking the primary identity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewIdentityHandler(mockUserService, nil, nil)
+		mockUserService.EXPECT().UnlinkIdentity(userID, identityID).Return(user.Identity{}, user.ErrPrimaryIdentity)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/identities/:id", getClaimsHandler(), handler.UnlinkIdentity)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/identities/51", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"primary identity can not be unlinked"}`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when the identity is not linked to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewIdentityHandler(mockUserService, nil, nil)
+		mockUserService.EXPECT().UnlinkIdentity(userID, identityID).Return(user.Identity{}, user.ErrIdentityNotFound)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/identities/:id", getClaimsHandler(), handler.UnlinkIdentity)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/identities/51", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"identity not found"}`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when the identity id is invalid", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewIdentityHandler(nil, nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/identities/:id", getClaimsHandler(), handler.UnlinkIdentity)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/identities/abc", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"invalid identity id"}`, response.Body.String())
+	})
+}
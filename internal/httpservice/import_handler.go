@@ -0,0 +1,188 @@
+package httpservice
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/importer"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+)
+
+// maxImportUploadSize is the maximum size of the export uploaded to be imported.
+const maxImportUploadSize = 50 << 20
+
+// ImportResponsePayload represents the http response payload of import entity.
+// The client polls the import until its status is either completed or failed.
+type ImportResponsePayload struct {
+	ID          uint                   `json:"id"`
+	Source      string                 `json:"source"`
+	FileName    string                 `json:"file_name"`
+	Path        string                 `json:"path"`
+	Status      string                 `json:"status"`
+	Total       int                    `json:"total"`
+	Processed   int                    `json:"processed"`
+	Notes       int                    `json:"notes"`
+	Attachments int                    `json:"attachments"`
+	Skipped     []importer.SkippedItem `json:"skipped"`
+	CommitSHA   string                 `json:"commit_sha,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+}
+
+// ImportHandler represents http handler for importing the notes from the other note taking apps.
+type ImportHandler struct {
+	importService importer.Service
+	userService   user.Service
+}
+
+// NewImportHandler creates and returns a new import handler.
+func NewImportHandler(importService importer.Service, userService user.Service) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+		userService:   userService,
+	}
+}
+
+// RequestImport starts a new import of the uploaded export in background. The export is uploaded as the file field
+// of a multipart form along with its source (obsidian, notion or evernote) & optionally the folder the notes are imported to.
+// The client should poll the import until the notes are committed to the repo.
+func (i *ImportHandler) RequestImport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	// the form fields are small, the limit is slightly larger than the maximum size of the upload
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportUploadSize+(1<<20))
+	source := c.PostForm("source")
+	path := strings.Trim(c.PostForm("path"), "/")
+	if err := validation.Validate(source, validation.Required, validation.In(importer.Sources...)); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("source: %s", err.Error())))
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "file is required & it must not exceed 50 MB"))
+		return
+	}
+	if fileHeader.Size > maxImportUploadSize {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, "file: must not exceed 50 MB"))
+		return
+	}
+	u, err := i.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	// the notes are imported to the default repo, so the folder must match the rules of the note files of the repo
+	if err := validateFolderPath(path, u.GetDefaultRepoDetails().NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	defer file.Close()
+	upload, err := io.ReadAll(file)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+
+	logrus.WithField("user-id", userID).WithField("source", source).WithField("size", len(upload)).Info("request to import notes started")
+	imp, err := i.importService.Request(importer.Import{
+		UserID:   userID,
+		Source:   source,
+		FileName: fileHeader.Filename,
+		Path:     path,
+		Upload:   upload,
+	})
+	if errors.Is(err, importer.ErrImportInProgress) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "import is already in progress"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, importResponse(imp))
+	logrus.WithField("user-id", userID).WithField("import-id", imp.ID).Info("request to import notes successful")
+}
+
+// GetImport returns the progress of the import of the user.
+func (i *ImportHandler) GetImport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	importID, err := strconv.ParseUint(c.Param("import"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid import id"))
+		return
+	}
+	imp, err := i.importService.Get(userID, uint(importID))
+	if errors.Is(err, importer.ErrImportNotFound) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, importResponse(imp))
+}
+
+// GetLatestImport returns the progress of the latest import of the user.
+func (i *ImportHandler) GetLatestImport(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	imp, err := i.importService.GetLatest(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	if imp.ID == 0 {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, importResponse(imp))
+}
+
+func importResponse(imp importer.Import) ImportResponsePayload {
+	skipped := make([]importer.SkippedItem, 0, len(imp.Skipped))
+	skipped = append(skipped, imp.Skipped...)
+	return ImportResponsePayload{
+		ID:          imp.ID,
+		Source:      imp.Source,
+		FileName:    imp.FileName,
+		Path:        imp.Path,
+		Status:      imp.Status,
+		Total:       imp.Total,
+		Processed:   imp.Processed,
+		Notes:       imp.Notes,
+		Attachments: imp.Attachments,
+		Skipped:     skipped,
+		CommitSHA:   imp.CommitSHA,
+		Error:       imp.Error,
+		CreatedAt:   imp.CreatedAt,
+		CompletedAt: imp.CompletedAt,
+	}
+}
@@ -0,0 +1,263 @@
+package httpservice
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/importer"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const importID = uint(61)
+
+func TestRequestImport(t *testing.T) {
+	t.Run("should start the import of the uploaded export", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockImportService := importer.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(mockImportService, mockUserService)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		createdAt := time.Date(2022, 10, 19, 10, 0, 0, 0, time.UTC)
+		mockImportService.EXPECT().Request(importer.Import{UserID: userID, Source: importer.SourceEvernote, FileName: "work.enex", Path: "Work/Evernote", Upload: []byte("<en-export/>")}).
+			Return(importer.Import{Model: gorm.Model{ID: importID, CreatedAt: createdAt}, Source: importer.SourceEvernote, FileName: "work.enex",
+				Path: "Work/Evernote", Status: importer.StatusPending}, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/import", getClaimsHandler(), handler.RequestImport)
+		response := httptest.NewRecorder()
+		req := importRequest(t, map[string]string{"source": "evernote", "path": "/Work/Evernote/"}, "work.enex", "<en-export/>")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+		assert.JSONEq(t, `{"id":61,"source":"evernote","file_name":"work.enex","path":"Work/Evernote","status":"pending","total":0,"processed":0,
+			"notes":0,"attachments":0,"skipped":[],"created_at":"2022-10-19T10:00:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the previous import is in progress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockImportService := importer.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(mockImportService, mockUserService)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockImportService.EXPECT().Request(gomock.Any()).Return(importer.Import{}, importer.ErrImportInProgress)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/import", getClaimsHandler(), handler.RequestImport)
+		response := httptest.NewRecorder()
+		req := importRequest(t, map[string]string{"source": "obsidian"}, "vault.zip", "PK")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"import is already in progress"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the source is not supported", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/import", getClaimsHandler(), handler.RequestImport)
+		response := httptest.NewRecorder()
+		req := importRequest(t, map[string]string{"source": "onenote"}, "notes.zip", "PK")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"source: must be a valid value"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the file is not uploaded", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/import", getClaimsHandler(), handler.RequestImport)
+		response := httptest.NewRecorder()
+		req := importRequest(t, map[string]string{"source": "notion"}, "", "")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"file is required & it must not exceed 50 MB"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the path is not valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(nil, mockUserService)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/import", getClaimsHandler(), handler.RequestImport)
+		response := httptest.NewRecorder()
+		req := importRequest(t, map[string]string{"source": "obsidian", "path": "Work/.vault"}, "vault.zip", "PK")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"path: must be in a valid format"}`, response.Body.String())
+	})
+
+	t.Run("should return internal server error when creating the import fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockImportService := importer.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(mockImportService, mockUserService)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockImportService.EXPECT().Request(gomock.Any()).Return(importer.Import{}, errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/import", getClaimsHandler(), handler.RequestImport)
+		response := httptest.NewRecorder()
+		req := importRequest(t, map[string]string{"source": "notion"}, "export.zip", "PK")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func TestGetImport(t *testing.T) {
+	t.Run("should return the progress of the import", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockImportService := importer.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(mockImportService, nil)
+		createdAt := time.Date(2022, 10, 19, 10, 0, 0, 0, time.UTC)
+		completedAt := createdAt.Add(time.Minute)
+		mockImportService.EXPECT().Get(userID, importID).Return(importer.Import{Model: gorm.Model{ID: importID, CreatedAt: createdAt}, Source: importer.SourceObsidian,
+			FileName: "vault.zip", Path: "Obsidian", Status: importer.StatusCompleted, Total: 12, Processed: 12, Notes: 10, Attachments: 1,
+			Skipped: importer.SkippedItems{{Name: "video.mp4", Reason: "attachment is larger than 10 MB"}}, CommitSHA: "commitsha", CompletedAt: &completedAt}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/import/:import", getClaimsHandler(), handler.GetImport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/import/61", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"id":61,"source":"obsidian","file_name":"vault.zip","path":"Obsidian","status":"completed","total":12,"processed":12,
+			"notes":10,"attachments":1,"skipped":[{"name":"video.mp4","reason":"attachment is larger than 10 MB"}],"commit_sha":"commitsha",
+			"created_at":"2022-10-19T10:00:00Z","completed_at":"2022-10-19T10:01:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should return not found when the import does not belong to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockImportService := importer.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(mockImportService, nil)
+		mockImportService.EXPECT().Get(userID, importID).Return(importer.Import{}, importer.ErrImportNotFound)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/import/:import", getClaimsHandler(), handler.GetImport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/import/61", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return bad request when the import id is invalid", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/import/:import", getClaimsHandler(), handler.GetImport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/import/abc", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func TestGetLatestImport(t *testing.T) {
+	t.Run("should return not found when the user has not imported any notes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockImportService := importer.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(mockImportService, nil)
+		mockImportService.EXPECT().GetLatest(userID).Return(importer.Import{}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/import", getClaimsHandler(), handler.GetLatestImport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/import", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return the latest import of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockImportService := importer.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewImportHandler(mockImportService, nil)
+		mockImportService.EXPECT().GetLatest(userID).Return(importer.Import{Model: gorm.Model{ID: importID}, Status: importer.StatusRunning, Total: 40, Processed: 25}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/import", getClaimsHandler(), handler.GetLatestImport)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/import", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), `"processed":25`)
+	})
+}
+
+// importRequest creates the multipart request uploading the file with the form fields, the file is not added when its name is empty.
+func importRequest(t *testing.T, fields map[string]string, fileName string, content string) *http.Request {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	for k, v := range fields {
+		assert.NoError(t, w.WriteField(k, v))
+	}
+	if fileName != "" {
+		fw, err := w.CreateFormFile("file", fileName)
+		assert.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/import", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
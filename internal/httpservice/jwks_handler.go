@@ -0,0 +1,27 @@
+package httpservice
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+)
+
+// JWKSHandler represents http handler for serving the public keys used to verify the app tokens.
+type JWKSHandler struct {
+	authService auth.Service
+}
+
+// NewJWKSHandler creates and returns a new jwks handler.
+func NewJWKSHandler(authService auth.Service) *JWKSHandler {
+	return &JWKSHandler{
+		authService: authService,
+	}
+}
+
+// GetJWKS returns the public signing keys in json web key set format.
+// The response can be cached by the clients, new keys are published before they are used for signing.
+func (j *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, j.authService.GetJWKS())
+}
@@ -0,0 +1,33 @@
+package httpservice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetJWKS(t *testing.T) {
+	t.Run("should return public signing keys in json web key set format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAuthService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewJWKSHandler(mockAuthService)
+		mockAuthService.EXPECT().GetJWKS().Return(auth.JWKS{Keys: []auth.JWK{{Kty: "OKP", Kid: "key1", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}}})
+
+		router.GET("/.well-known/jwks.json", handler.GetJWKS)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"keys":[{"kty":"OKP","kid":"key1","use":"sig","alg":"EdDSA","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}]}`, response.Body.String())
+	})
+}
@@ -1,40 +1,70 @@
 package httpservice
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/batnoter/batnoter-api/internal/auth"
 	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/preference"
 	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	gh "github.com/google/go-github/v43/github"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// refresh token cookie is sent only to the refresh & logout endpoints
+	refreshTokenCookie     = "refresh_token"
+	refreshTokenCookiePath = "/api/v1/auth"
+
+	// link token cookie is sent only to the oauth2 endpoints, it marks the oauth2 flow of linking an identity
+	// the cookie holds the state of the flow along with the link token, so it is used only by the flow it is set for
+	linkTokenCookie     = "link_token"
+	linkTokenCookiePath = "/api/v1/oauth2"
+	linkTokenSeparator  = ":"
+
+	// state of the link flow is suffixed with the id of the linking user
+	linkStateSeparator = "."
 )
 
 // LoginHandler represents http handler for serving user login actions.
 type LoginHandler struct {
-	authService   auth.Service
-	githubService github.Service
-	userService   user.Service
-	clientURL     string
+	authService       auth.Service
+	githubService     github.Service
+	githubAppService  github.AppService
+	userService       user.Service
+	preferenceService preference.Service
+	clientURL         string
 }
 
 // NewLoginHandler creates and returns a new login handler.
-func NewLoginHandler(authService auth.Service, githubService github.Service, userService user.Service, clientURL string) *LoginHandler {
+func NewLoginHandler(authService auth.Service, githubService github.Service, githubAppService github.AppService,
+	userService user.Service, preferenceService preference.Service, clientURL string) *LoginHandler {
 	return &LoginHandler{
-		authService:   authService,
-		githubService: githubService,
-		userService:   userService,
-		clientURL:     clientURL,
+		authService:       authService,
+		githubService:     githubService,
+		githubAppService:  githubAppService,
+		userService:       userService,
+		preferenceService: preferenceService,
+		clientURL:         clientURL,
 	}
 }
 
 // GithubLogin initiates oauth2 login flow with github provider.
 func (l *LoginHandler) GithubLogin(c *gin.Context) {
 	state := uuid.NewString()
-	c.SetCookie("state", state, 600, "/", "", true, true)
+	setStateCookie(c, state)
+	clearLinkTokenCookie(c)
 
 	url := l.githubService.GetAuthCodeURL(state)
 
@@ -42,6 +72,47 @@ func (l *LoginHandler) GithubLogin(c *gin.Context) {
 	c.Redirect(http.StatusTemporaryRedirect, url)
 }
 
+// GithubInstall initiates github app installation flow.
+// The app requests user authorization during installation, so github redirects to the oauth2 callback
+// with the installation id once the app is installed.
+func (l *LoginHandler) GithubInstall(c *gin.Context) {
+	if !l.githubAppService.Enabled() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	state := uuid.NewString()
+	setStateCookie(c, state)
+	clearLinkTokenCookie(c)
+
+	c.Redirect(http.StatusTemporaryRedirect, l.githubAppService.GetInstallURL(state))
+}
+
+// GithubLink initiates oauth2 flow with github provider to link the github account to the logged in user.
+// The short-lived link token is posted as form param since the browser is navigated to this endpoint with a form submit,
+// so the form is accepted only when it is submitted from the client. The link token is stored as cookie so that
+// the callback links the account only in the browser the flow is started from.
+// The state of the flow carries the id of the linking user, the callback links the account only to the same user.
+func (l *LoginHandler) GithubLink(c *gin.Context) {
+	if !l.isClientOrigin(c.GetHeader("Origin")) {
+		logrus.Warn("link request from another origin rejected")
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	linkToken := c.PostForm("token")
+	userID, err := l.getLinkingUserID(linkToken)
+	if err != nil {
+		logrus.Warn("invalid link token")
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/settings/identities?success=false&error=invalid-link-token")
+		return
+	}
+	state := uuid.NewString() + linkStateSeparator + strconv.FormatUint(uint64(userID), 10)
+	setStateCookie(c, state)
+	c.SetCookie(linkTokenCookie, state+linkTokenSeparator+linkToken, 600, linkTokenCookiePath, "", true, true)
+
+	// see other makes the browser follow the redirect with get instead of re-posting the form
+	c.Redirect(http.StatusSeeOther, l.githubService.GetAuthCodeURL(state))
+}
+
 // GithubOAuth2Callback processes github oauth2 callback.
 // It validates the state, fetch token and user from github, stores the user to db, generates app token.
 // The app token will be sent as token cookie with a redirect to client url.
@@ -71,30 +142,84 @@ func (l *LoginHandler) GithubOAuth2Callback(c *gin.Context) {
 		return
 	}
 
-	// get user from db if exists
-	dbUser, err := l.userService.GetByEmail(*githubUser.Email)
+	// callback is a result of linking the github account to the logged in user
+	// the link token cookie left over from an abandoned link flow is ignored since the state does not match
+	if linkCookie, err := c.Cookie(linkTokenCookie); err == nil && linkCookie != "" {
+		clearLinkTokenCookie(c)
+		if linkState, linkToken, ok := strings.Cut(linkCookie, linkTokenSeparator); ok && linkState == state {
+			l.linkGithubIdentity(c, state, linkToken, githubToken, githubUser)
+			return
+		}
+		logrus.Warn("link token cookie of another oauth2 flow ignored")
+	}
+
+	// get user from db by the linked identity, an identity is linked to an existing user only by the link flow
+	dbUser, err := l.userService.GetByIdentity(user.ProviderGithub, strconv.FormatInt(githubUser.GetID(), 10))
+	if err == nil && dbUser.ID == 0 && githubUser.GetEmail() != "" {
+		// a new user is created for the identity, the email must not belong to another user
+		var emailUser user.User
+		if emailUser, err = l.userService.GetByEmail(githubUser.GetEmail()); err == nil && emailUser.ID != 0 {
+			logrus.WithField("user-id", emailUser.ID).Warn("login with a github account not linked to the user of the email rejected")
+			c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=email-already-registered")
+			return
+		}
+	}
+	if errors.Is(err, user.ErrUserDeleted) {
+		logrus.Warn("login attempt of a deleted user rejected")
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=account-deleted")
+		return
+	}
 	if err != nil {
-		logrus.Errorf("retrieving user from db using email failed: %s", err.Error())
+		logrus.Errorf("retrieving user from db failed: %s", err.Error())
 		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=internal-error")
 		return
 	}
+	if dbUser.DisabledAt != nil {
+		logrus.WithField("user-id", dbUser.ID).Warn("login attempt of a disabled user rejected")
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=account-disabled")
+		return
+	}
 	githubTokenJSON, err := json.Marshal(githubToken)
 	if err != nil {
 		logrus.Errorf("converting github token to json failed: %s", err.Error())
 		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=internal-error")
 		return
 	}
-	mapUserAttributes(&dbUser, string(githubTokenJSON), githubUser)
+	userID := dbUser.ID
+	if dbUser.ID != 0 && dbUser.GithubID != 0 && dbUser.GithubID != githubUser.GetID() {
+		// login with a github account other than the primary one, only the linked identity is updated
+		if err := l.userService.LinkIdentity(dbUser.ID, makeGithubIdentity(string(githubTokenJSON), githubUser)); err != nil {
+			logrus.Errorf("saving user's identity to db failed: %s", err.Error())
+			c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=internal-error")
+			return
+		}
+	} else {
+		mapUserAttributes(&dbUser, string(githubTokenJSON), githubUser)
 
-	// create/update the user record
-	userID, err := l.userService.Save(dbUser)
-	if err != nil {
-		logrus.Errorf("saving user to db failed: %s", err.Error())
-		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=internal-error")
-		return
+		// create/update the user record
+		if userID, err = l.userService.Save(dbUser); err != nil {
+			logrus.Errorf("saving user to db failed: %s", err.Error())
+			c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=internal-error")
+			return
+		}
 	}
 
-	appToken, err := l.authService.GenerateToken(userID)
+	// installation id is sent along with the authorization code when the callback is a result of app installation
+	installationID, _ := strconv.ParseInt(c.Query("installation_id"), 10, 64)
+	if installationID != 0 && l.githubAppService.Enabled() {
+		if err := l.linkInstallation(c, githubToken, githubUser.GetLogin(), userID, installationID); err != nil {
+			logrus.Errorf("linking github app installation failed: %s", err.Error())
+			c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=installation-failure")
+			return
+		}
+	}
+
+	// admin scope is granted only to the users with admin role
+	scopes := auth.UserScopes
+	if l.userService.IsAdmin(dbUser) {
+		scopes = append(append([]string{}, auth.UserScopes...), auth.ScopeAdmin)
+	}
+	tokens, err := l.authService.IssueTokens(userID, auth.Client{UserAgent: c.Request.UserAgent(), IPAddress: c.ClientIP()}, scopes)
 	if err != nil {
 		logrus.Errorf("token generation failed: %s", err.Error())
 		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/login?success=false&error=internal-error")
@@ -103,7 +228,8 @@ func (l *LoginHandler) GithubOAuth2Callback(c *gin.Context) {
 
 	// set the token cookie
 	c.SetSameSite(http.SameSiteStrictMode)
-	c.SetCookie("token", appToken, 60, "/", c.Request.URL.Hostname(), true, true)
+	c.SetCookie("token", tokens.AccessToken, 60, "/", c.Request.URL.Hostname(), true, true)
+	setRefreshTokenCookie(c, tokens)
 
 	// redirect to client
 	c.Redirect(http.StatusFound, l.clientURL+"/login?success=true")
@@ -128,6 +254,181 @@ func (l *LoginHandler) TokenPayload(c *gin.Context) {
 	c.String(http.StatusOK, token)
 }
 
+// RefreshToken exchanges the refresh token from request cookie for a new app token.
+// The refresh token is rotated & the new app token is sent as response payload.
+func (l *LoginHandler) RefreshToken(c *gin.Context) {
+	refreshToken, err := c.Cookie(refreshTokenCookie)
+	if err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	tokens, err := l.authService.RefreshTokens(refreshToken, l.isUserActive)
+	if errors.Is(err, auth.ErrInvalidRefreshToken) {
+		logrus.Warn("refreshing token failed due to invalid refresh token")
+		clearRefreshTokenCookie(c)
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	setRefreshTokenCookie(c, tokens)
+
+	// send token as response payload
+	c.String(http.StatusOK, tokens.AccessToken)
+}
+
+// Logout revokes the refresh token from request cookie along with all the tokens rotated from it.
+func (l *LoginHandler) Logout(c *gin.Context) {
+	refreshToken, err := c.Cookie(refreshTokenCookie)
+	if err == nil {
+		if err := l.authService.RevokeRefreshToken(refreshToken); err != nil && !errors.Is(err, auth.ErrInvalidRefreshToken) {
+			abortRequestWithError(c, err)
+			return
+		}
+	}
+	clearRefreshTokenCookie(c)
+	c.Status(http.StatusOK)
+}
+
+func setRefreshTokenCookie(c *gin.Context, tokens auth.Tokens) {
+	maxAge := int(time.Until(tokens.RefreshTokenExpiresAt).Seconds())
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshTokenCookie, tokens.RefreshToken, maxAge, refreshTokenCookiePath, c.Request.URL.Hostname(), true, true)
+}
+
+func clearRefreshTokenCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshTokenCookie, "", -1, refreshTokenCookiePath, c.Request.URL.Hostname(), true, true)
+}
+
+// linkGithubIdentity links the github account to the user the link token is issued for & redirects to the client.
+// The user of the link token must be the user the link flow is started for.
+func (l *LoginHandler) linkGithubIdentity(c *gin.Context, state string, linkToken string, githubToken oauth2.Token, githubUser gh.User) {
+	userID, err := l.getLinkingUserID(linkToken)
+	if err != nil {
+		logrus.Warn("invalid link token")
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/settings/identities?success=false&error=invalid-link-token")
+		return
+	}
+	if _, stateUserID, _ := strings.Cut(state, linkStateSeparator); stateUserID != strconv.FormatUint(uint64(userID), 10) {
+		logrus.WithField("user-id", userID).Warn("link token of another user rejected")
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/settings/identities?success=false&error=invalid-link-token")
+		return
+	}
+	githubTokenJSON, err := json.Marshal(githubToken)
+	if err != nil {
+		logrus.Errorf("converting github token to json failed: %s", err.Error())
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/settings/identities?success=false&error=internal-error")
+		return
+	}
+	err = l.userService.LinkIdentity(userID, makeGithubIdentity(string(githubTokenJSON), githubUser))
+	if errors.Is(err, user.ErrIdentityLinked) {
+		logrus.WithField("user-id", userID).Warn("linking github account of another user rejected")
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/settings/identities?success=false&error=identity-already-linked")
+		return
+	}
+	if err != nil {
+		logrus.Errorf("linking github account failed: %s", err.Error())
+		c.Redirect(http.StatusTemporaryRedirect, l.clientURL+"/settings/identities?success=false&error=internal-error")
+		return
+	}
+	c.Redirect(http.StatusFound, l.clientURL+"/settings/identities?success=true")
+	logrus.WithField("user-id", userID).Info("github account linked")
+}
+
+// setStateCookie stores the state of the oauth2 flow. The lax cookie is sent along with the redirect from github.
+func setStateCookie(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie("state", state, 600, "/", "", true, true)
+}
+
+func clearLinkTokenCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(linkTokenCookie, "", -1, linkTokenCookiePath, "", true, true)
+}
+
+// isClientOrigin reports whether the origin of the request is the client url.
+func (l *LoginHandler) isClientOrigin(origin string) bool {
+	clientURL, err := url.Parse(l.clientURL)
+	if err != nil || origin == "" {
+		return false
+	}
+	return origin == clientURL.Scheme+"://"+clientURL.Host
+}
+
+// getLinkingUserID validates the link token & returns the id of the user it is issued for.
+func (l *LoginHandler) getLinkingUserID(linkToken string) (uint, error) {
+	if linkToken == "" {
+		return 0, errors.New("link token is missing")
+	}
+	token, err := l.authService.ValidateToken(linkToken)
+	if err != nil {
+		return 0, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return 0, errors.New("invalid link token")
+	}
+	granted, _ := claims["scope"].(string)
+	if !auth.HasScopes(granted, auth.ScopeIdentityLink) {
+		return 0, errors.New("token is not a link token")
+	}
+	subject, _ := claims["sub"].(string)
+	userID, err := strconv.ParseUint(subject, 10, 64)
+	if err != nil {
+		return 0, errors.New("parsing user-id from link token failed")
+	}
+	if err := l.userService.CheckStatus(uint(userID)); err != nil {
+		return 0, err
+	}
+	return uint(userID), nil
+}
+
+// isUserActive reports whether the user with given user id is allowed to access the app.
+func (l *LoginHandler) isUserActive(userID uint) (bool, error) {
+	err := l.userService.CheckStatus(userID)
+	if isInactiveUserError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// linkInstallation stores the github app installation used to access the notes repo of the user.
+// The installation is stored as is until the notes repo is chosen, so that the repos granted to it can be listed.
+// Afterwards the installation on the account owning the notes repo is retained, an installation on another account does not replace it.
+func (l *LoginHandler) linkInstallation(ctx context.Context, githubToken oauth2.Token, githubLogin string, userID uint, installationID int64) error {
+	if err := l.githubAppService.VerifyInstallation(ctx, githubToken, installationID); err != nil {
+		return err
+	}
+	defaultRepo, err := l.preferenceService.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if defaultRepo.Name != "" {
+		owner := defaultRepo.Owner
+		if owner == "" {
+			owner = githubLogin
+		}
+		installationID, err = l.githubAppService.FindInstallation(ctx, githubToken, owner)
+		if errors.Is(err, github.ErrInstallationRequired) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if defaultRepo.InstallationID == installationID {
+		return nil
+	}
+	defaultRepo.UserID = userID
+	defaultRepo.InstallationID = installationID
+	return l.preferenceService.Save(defaultRepo)
+}
+
+// mapUserAttributes syncs the user attributes from github.
+// The profile attributes edited by the user (display name, timezone, locale & commit identity) are retained.
 func mapUserAttributes(dbUser *user.User, ghToken string, githubUser gh.User) {
 	dbUser.GithubToken = ghToken
 	dbUser.Email = githubUser.GetEmail()
@@ -137,3 +438,13 @@ func mapUserAttributes(dbUser *user.User, ghToken string, githubUser gh.User) {
 	dbUser.GithubID = githubUser.GetID()
 	dbUser.GithubUsername = githubUser.GetLogin()
 }
+
+func makeGithubIdentity(ghToken string, githubUser gh.User) user.Identity {
+	return user.Identity{
+		Provider:       user.ProviderGithub,
+		ProviderUserID: strconv.FormatInt(githubUser.GetID(), 10),
+		Username:       githubUser.GetLogin(),
+		Email:          githubUser.GetEmail(),
+		Token:          ghToken,
+	}
+}
@@ -2,12 +2,17 @@ package httpservice
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/batnoter/batnoter-api/internal/auth"
 	"github.com/batnoter/batnoter-api/internal/github"
@@ -29,6 +34,7 @@ const (
 	avatarURL       = "http://example.com/avatar"
 	clientURL       = "http://example.com/ui"
 	oauth2TokenJSON = `{"access_token":"gho_token","token_type":"bearer","expiry":"0001-01-01T00:00:00Z"}`
+	installationID  = int64(8765)
 )
 
 const ()
@@ -41,7 +47,7 @@ func TestGithubLogin(t *testing.T) {
 
 		gin.SetMode(gin.TestMode)
 		router := gin.Default()
-		handler := NewLoginHandler(nil, githubService, nil, "")
+		handler := NewLoginHandler(nil, githubService, nil, nil, nil, "")
 		githubService.EXPECT().GetAuthCodeURL(gomock.Any()).Return("/")
 
 		router.GET("/api/v1/oauth2/login/github", handler.GithubLogin)
@@ -50,11 +56,445 @@ func TestGithubLogin(t *testing.T) {
 
 		router.ServeHTTP(response, req)
 		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Contains(t, response.Header().Values("Set-Cookie")[0], "HttpOnly; Secure; SameSite=Lax")
+		assert.Contains(t, response.Header().Values("Set-Cookie")[1], "link_token=; Path=/api/v1/oauth2; Max-Age=0;")
+	})
+}
+
+func TestGithubInstall(t *testing.T) {
+	t.Run("should redirect to app installation when the github app is enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		githubAppService := github.NewMockAppService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, nil, githubAppService, nil, nil, "")
+		githubAppService.EXPECT().Enabled().Return(true)
+		githubAppService.EXPECT().GetInstallURL(gomock.Any()).Return("/install")
+
+		router.GET("/api/v1/oauth2/install/github", handler.GithubInstall)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth2/install/github", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, "/install", response.Header().Get("Location"))
+	})
+
+	t.Run("should return not found when the github app is not enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		githubAppService := github.NewMockAppService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, nil, githubAppService, nil, nil, "")
+		githubAppService.EXPECT().Enabled().Return(false)
+
+		router.GET("/api/v1/oauth2/install/github", handler.GithubInstall)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/oauth2/install/github", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
 	})
 }
 
 func TestGithubOAuth2Callback(t *testing.T) {
 	t.Run("should save the user(with token) & redirect with token cookie when callback invoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		githubAppService := github.NewMockAppService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		appToken := "app_token"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		newUser := makeDBUser(githubUser, oauth2TokenJSON)
+		newUser.Model = gorm.Model{}
+		newUser.DefaultRepo = nil
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, githubAppService, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(user.User{}, nil)
+		userService.EXPECT().GetByEmail(email).Return(user.User{}, nil)
+		userService.EXPECT().Save(newUser).Return(uint(1), nil)
+		userService.EXPECT().IsAdmin(gomock.Any()).Return(false)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), auth.UserScopes).Return(auth.Tokens{AccessToken: appToken, RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		cookie := http.Cookie{
+			Name:     "state",
+			Value:    state,
+			Path:     "/",
+			Expires:  time.Now().Add(10 * time.Minute),
+			HttpOnly: true,
+		}
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&cookie)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Contains(t, response.Header().Get("Set-Cookie"), appToken)
+		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+	})
+	t.Run("should grant admin scope to the tokens when the user is an admin", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		githubAppService := github.NewMockAppService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		appToken := "app_token"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		dbUser := makeDBUser(githubUser, oauth2TokenJSON)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, githubAppService, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+		userService.EXPECT().Save(dbUser).Return(uint(1), nil)
+		userService.EXPECT().IsAdmin(dbUser).Return(true)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), []string{auth.ScopeNotesRead, auth.ScopeNotesWrite, auth.ScopePreferencesWrite, auth.ScopeAdmin}).Return(auth.Tokens{AccessToken: appToken, RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		cookie := http.Cookie{
+			Name:     "state",
+			Value:    state,
+			Path:     "/",
+			Expires:  time.Now().Add(10 * time.Minute),
+			HttpOnly: true,
+		}
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&cookie)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Contains(t, response.Header().Get("Set-Cookie"), appToken)
+		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+	})
+	t.Run("should link the github app installation with user when callback is invoked after installation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		githubAppService := github.NewMockAppService(ctrl)
+		userService := user.NewMockService(ctrl)
+		preferenceService := preference.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		appToken := "app_token"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		dbUser := makeDBUser(githubUser, oauth2TokenJSON)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, githubAppService, userService, preferenceService, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+		userService.EXPECT().Save(dbUser).Return(uint(1), nil)
+		githubAppService.EXPECT().Enabled().Return(true)
+		githubAppService.EXPECT().VerifyInstallation(gomock.Any(), oauthToken, installationID).Return(nil)
+		preferenceService.EXPECT().GetByUserID(uint(1)).Return(preference.DefaultRepo{}, nil)
+		preferenceService.EXPECT().Save(preference.DefaultRepo{UserID: 1, InstallationID: installationID}).Return(nil)
+		userService.EXPECT().IsAdmin(gomock.Any()).Return(false)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), auth.UserScopes).Return(auth.Tokens{AccessToken: appToken, RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		cookie := http.Cookie{
+			Name:     "state",
+			Value:    state,
+			Path:     "/",
+			Expires:  time.Now().Add(10 * time.Minute),
+			HttpOnly: true,
+		}
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s&installation_id=%d&setup_action=install", authCode, state, installationID), nil)
+		req.AddCookie(&cookie)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+	})
+
+	t.Run("should retain the installation on the account owning the notes repo when another account installs the app", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		githubAppService := github.NewMockAppService(ctrl)
+		userService := user.NewMockService(ctrl)
+		preferenceService := preference.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		dbUser := makeDBUser(githubUser, oauth2TokenJSON)
+		defaultRepo := preference.DefaultRepo{UserID: 1, Name: "notes", Owner: "acme", InstallationID: 77}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, githubAppService, userService, preferenceService, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+		userService.EXPECT().Save(dbUser).Return(uint(1), nil)
+		githubAppService.EXPECT().Enabled().Return(true)
+		githubAppService.EXPECT().VerifyInstallation(gomock.Any(), oauthToken, installationID).Return(nil)
+		preferenceService.EXPECT().GetByUserID(uint(1)).Return(defaultRepo, nil)
+		githubAppService.EXPECT().FindInstallation(gomock.Any(), oauthToken, "acme").Return(int64(77), nil)
+		userService.EXPECT().IsAdmin(gomock.Any()).Return(false)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), auth.UserScopes).Return(auth.Tokens{AccessToken: "app_token", RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s&installation_id=%d&setup_action=install", authCode, state, installationID), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+	})
+	t.Run("should store the installation on the account owning the notes repo when it is installed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		githubAppService := github.NewMockAppService(ctrl)
+		userService := user.NewMockService(ctrl)
+		preferenceService := preference.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		dbUser := makeDBUser(githubUser, oauth2TokenJSON)
+		defaultRepo := preference.DefaultRepo{UserID: 1, Name: "notes"}
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, githubAppService, userService, preferenceService, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+		userService.EXPECT().Save(dbUser).Return(uint(1), nil)
+		githubAppService.EXPECT().Enabled().Return(true)
+		githubAppService.EXPECT().VerifyInstallation(gomock.Any(), oauthToken, installationID).Return(nil)
+		preferenceService.EXPECT().GetByUserID(uint(1)).Return(defaultRepo, nil)
+		githubAppService.EXPECT().FindInstallation(gomock.Any(), oauthToken, "johndoe").Return(installationID, nil)
+		preferenceService.EXPECT().Save(preference.DefaultRepo{UserID: 1, Name: "notes", InstallationID: installationID}).Return(nil)
+		userService.EXPECT().IsAdmin(gomock.Any()).Return(false)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), auth.UserScopes).Return(auth.Tokens{AccessToken: "app_token", RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s&installation_id=%d&setup_action=install", authCode, state, installationID), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+	})
+
+	t.Run("should redirect with error when the installation is not accessible to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		githubService := github.NewMockService(ctrl)
+		githubAppService := github.NewMockAppService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		dbUser := makeDBUser(githubUser, oauth2TokenJSON)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, githubService, githubAppService, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+		userService.EXPECT().Save(dbUser).Return(uint(1), nil)
+		githubAppService.EXPECT().Enabled().Return(true)
+		githubAppService.EXPECT().VerifyInstallation(gomock.Any(), oauthToken, installationID).Return(errors.New("some error"))
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		cookie := http.Cookie{
+			Name:     "state",
+			Value:    state,
+			Path:     "/",
+			Expires:  time.Now().Add(10 * time.Minute),
+			HttpOnly: true,
+		}
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s&installation_id=%d", authCode, state, installationID), nil)
+		req.AddCookie(&cookie)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/login?success=false&error=installation-failure", response.Header().Get("Location"))
+	})
+	t.Run("should redirect with error when the user account is disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		dbUser := makeDBUser(githubUser, oauth2TokenJSON)
+		disabledAt := time.Now()
+		dbUser.DisabledAt = &disabledAt
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		cookie := http.Cookie{
+			Name:     "state",
+			Value:    state,
+			Path:     "/",
+			Expires:  time.Now().Add(10 * time.Minute),
+			HttpOnly: true,
+		}
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&cookie)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/login?success=false&error=account-disabled", response.Header().Get("Location"))
+	})
+	t.Run("should redirect with error when the email of the unlinked github account belongs to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		otherUser := makeDBUser(githubUser, oauth2TokenJSON)
+		otherUser.GithubID = 54321
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(user.User{}, nil)
+		userService.EXPECT().GetByEmail(email).Return(otherUser, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/login?success=false&error=email-already-registered", response.Header().Get("Location"))
+		assert.Empty(t, response.Header().Values("Set-Cookie"))
+	})
+	t.Run("should redirect with error when the user account is deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(user.User{}, nil)
+		userService.EXPECT().GetByEmail(email).Return(user.User{}, user.ErrUserDeleted)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		cookie := http.Cookie{
+			Name:     "state",
+			Value:    state,
+			Path:     "/",
+			Expires:  time.Now().Add(10 * time.Minute),
+			HttpOnly: true,
+		}
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&cookie)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/login?success=false&error=account-deleted", response.Header().Get("Location"))
+	})
+	t.Run("should login the user of the linked identity without matching the email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		changedEmail := "john.doe@new.example.com"
+		githubUser.Email = &changedEmail
+		dbUser := makeDBUser(validGithubUser(), oauth2TokenJSON)
+		savedUser := dbUser
+		savedUser.Email = changedEmail
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+		userService.EXPECT().Save(savedUser).Return(uint(1), nil)
+		userService.EXPECT().IsAdmin(gomock.Any()).Return(false)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), auth.UserScopes).Return(auth.Tokens{AccessToken: "app_token", RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+	})
+	t.Run("should update only the linked identity when the user logs in with a secondary github account", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		authService := auth.NewMockService(ctrl)
@@ -62,37 +502,247 @@ func TestGithubOAuth2Callback(t *testing.T) {
 		userService := user.NewMockService(ctrl)
 		state := uuid.NewString()
 		authCode := "abcd"
-		appToken := "app_token"
 		var oauthToken oauth2.Token
 		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
 		githubUser := validGithubUser()
 		dbUser := makeDBUser(githubUser, oauth2TokenJSON)
+		dbUser.GithubID = 54321
+		identity := user.Identity{Provider: user.ProviderGithub, ProviderUserID: "12345", Username: "johndoe", Email: email, Token: oauth2TokenJSON}
 
 		gin.SetMode(gin.TestMode)
 		router := gin.Default()
-		handler := NewLoginHandler(authService, githubService, userService, clientURL)
+		handler := NewLoginHandler(authService, githubService, nil, userService, nil, clientURL)
 		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
 		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
-		userService.EXPECT().GetByEmail(email).Return(dbUser, nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
+		userService.EXPECT().LinkIdentity(uint(1), identity).Return(nil)
+		userService.EXPECT().IsAdmin(gomock.Any()).Return(false)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), auth.UserScopes).Return(auth.Tokens{AccessToken: "app_token", RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+	})
+	t.Run("should login the user & ignore the link token cookie set for another oauth2 flow", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		dbUser := makeDBUser(validGithubUser(), oauth2TokenJSON)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(validGithubUser(), nil)
+		userService.EXPECT().GetByIdentity(user.ProviderGithub, "12345").Return(dbUser, nil)
 		userService.EXPECT().Save(dbUser).Return(uint(1), nil)
-		authService.EXPECT().GenerateToken(uint(1)).Return(appToken, nil)
+		userService.EXPECT().IsAdmin(gomock.Any()).Return(false)
+		authService.EXPECT().IssueTokens(uint(1), gomock.Any(), auth.UserScopes).Return(auth.Tokens{AccessToken: "app_token", RefreshToken: "refresh_token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
 
 		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
 		response := httptest.NewRecorder()
-		cookie := http.Cookie{
-			Name:     "state",
-			Value:    state,
-			Path:     "/",
-			Expires:  time.Now().Add(10 * time.Minute),
-			HttpOnly: true,
-		}
 		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
-		req.AddCookie(&cookie)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+		req.AddCookie(&http.Cookie{Name: linkTokenCookie, Value: uuid.NewString() + ":link_token"})
 
 		router.ServeHTTP(response, req)
 		assert.Equal(t, http.StatusFound, response.Code)
-		assert.Contains(t, response.Header().Get("Set-Cookie"), appToken)
 		assert.Equal(t, clientURL+"/login?success=true", response.Header().Get("Location"))
+		assert.Contains(t, response.Header().Values("Set-Cookie")[0], "link_token=; Path=/api/v1/oauth2; Max-Age=0;")
+	})
+	t.Run("should link the github account to the user of the link token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		githubUser := validGithubUser()
+		identity := user.Identity{Provider: user.ProviderGithub, ProviderUserID: "12345", Username: "johndoe", Email: email, Token: oauth2TokenJSON}
+		state = fmt.Sprintf("%s.%d", state, userID)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(githubUser, nil)
+		authService.EXPECT().ValidateToken("link_token").Return(linkToken(auth.ScopeIdentityLink), nil)
+		userService.EXPECT().CheckStatus(userID).Return(nil)
+		userService.EXPECT().LinkIdentity(userID, identity).Return(nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+		req.AddCookie(&http.Cookie{Name: linkTokenCookie, Value: state + ":link_token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusFound, response.Code)
+		assert.Equal(t, clientURL+"/settings/identities?success=true", response.Header().Get("Location"))
+	})
+	t.Run("should redirect with error when the github account is linked to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := uuid.NewString()
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+		state = fmt.Sprintf("%s.%d", state, userID)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(validGithubUser(), nil)
+		authService.EXPECT().ValidateToken("link_token").Return(linkToken(auth.ScopeIdentityLink), nil)
+		userService.EXPECT().CheckStatus(userID).Return(nil)
+		userService.EXPECT().LinkIdentity(userID, gomock.Any()).Return(user.ErrIdentityLinked)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+		req.AddCookie(&http.Cookie{Name: linkTokenCookie, Value: state + ":link_token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/settings/identities?success=false&error=identity-already-linked", response.Header().Get("Location"))
+	})
+	t.Run("should redirect with error when the link token is issued for another user than the state", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		state := fmt.Sprintf("%s.%d", uuid.NewString(), 2024)
+		authCode := "abcd"
+		var oauthToken oauth2.Token
+		json.Unmarshal([]byte(oauth2TokenJSON), &oauthToken)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, nil, userService, nil, clientURL)
+		githubService.EXPECT().GetToken(gomock.Any(), authCode).Return(oauthToken, nil)
+		githubService.EXPECT().GetUser(gomock.Any(), oauthToken).Return(validGithubUser(), nil)
+		authService.EXPECT().ValidateToken("link_token").Return(linkToken(auth.ScopeIdentityLink), nil)
+		userService.EXPECT().CheckStatus(userID).Return(nil)
+
+		router.GET("/oauth2/github/callback", handler.GithubOAuth2Callback)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/oauth2/github/callback?code=%s&state=%s", authCode, state), nil)
+		req.AddCookie(&http.Cookie{Name: "state", Value: state})
+		req.AddCookie(&http.Cookie{Name: linkTokenCookie, Value: state + ":link_token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/settings/identities?success=false&error=invalid-link-token", response.Header().Get("Location"))
+	})
+}
+
+func TestGithubLink(t *testing.T) {
+	t.Run("should store the link token & redirect to the github authorization url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		githubService := github.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		authService.EXPECT().ValidateToken("link_token").Return(linkToken(auth.ScopeIdentityLink), nil)
+		userService.EXPECT().CheckStatus(userID).Return(nil)
+		githubService.EXPECT().GetAuthCodeURL(gomock.Any()).Return("https://github.com/login/oauth/authorize")
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, githubService, nil, userService, nil, clientURL)
+		router.POST("/api/v1/oauth2/link/github", handler.GithubLink)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth2/link/github", strings.NewReader("token=link_token"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Origin", "http://example.com")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusSeeOther, response.Code)
+		assert.Equal(t, "https://github.com/login/oauth/authorize", response.Header().Get("Location"))
+		state := strings.TrimPrefix(strings.Split(response.Header().Values("Set-Cookie")[0], ";")[0], "state=")
+		assert.True(t, strings.HasSuffix(state, fmt.Sprintf(".%d", userID)))
+		assert.Contains(t, response.Header().Values("Set-Cookie")[0], "HttpOnly; Secure; SameSite=Lax")
+		assert.Contains(t, response.Header().Values("Set-Cookie")[1], "link_token="+url.QueryEscape(state+":link_token")+"; Path=/api/v1/oauth2;")
+		assert.Contains(t, response.Header().Values("Set-Cookie")[1], "HttpOnly; Secure; SameSite=Lax")
+	})
+
+	t.Run("should return forbidden error when the link form is not submitted from the client", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, nil, nil, nil, nil, clientURL)
+		router.POST("/api/v1/oauth2/link/github", handler.GithubLink)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth2/link/github", strings.NewReader("token=link_token"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Origin", "http://attacker.example.com")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		assert.Empty(t, response.Header().Values("Set-Cookie"))
+	})
+
+	t.Run("should redirect with error when the token is not a link token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		authService.EXPECT().ValidateToken("app_token").Return(linkToken(auth.ScopeNotesRead), nil)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, nil, nil, nil, nil, clientURL)
+		router.POST("/api/v1/oauth2/link/github", handler.GithubLink)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth2/link/github", strings.NewReader("token=app_token"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Origin", "http://example.com")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/settings/identities?success=false&error=invalid-link-token", response.Header().Get("Location"))
+		assert.Empty(t, response.Header().Values("Set-Cookie"))
+	})
+
+	t.Run("should redirect with error when the user of the link token is disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+		authService.EXPECT().ValidateToken("link_token").Return(linkToken(auth.ScopeIdentityLink), nil)
+		userService.EXPECT().CheckStatus(userID).Return(user.ErrUserDisabled)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, nil, nil, userService, nil, clientURL)
+		router.POST("/api/v1/oauth2/link/github", handler.GithubLink)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/oauth2/link/github", strings.NewReader("token=link_token"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Origin", "http://example.com")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTemporaryRedirect, response.Code)
+		assert.Equal(t, clientURL+"/settings/identities?success=false&error=invalid-link-token", response.Header().Get("Location"))
+		assert.Empty(t, response.Header().Values("Set-Cookie"))
 	})
 }
 
@@ -103,7 +753,7 @@ func TestTokenPayload(t *testing.T) {
 
 		gin.SetMode(gin.TestMode)
 		router := gin.Default()
-		handler := NewLoginHandler(nil, nil, nil, "")
+		handler := NewLoginHandler(nil, nil, nil, nil, nil, "")
 
 		router.GET("/auth/token", handler.TokenPayload)
 		response := httptest.NewRecorder()
@@ -128,7 +778,7 @@ func TestTokenPayload(t *testing.T) {
 
 		gin.SetMode(gin.TestMode)
 		router := gin.Default()
-		handler := NewLoginHandler(nil, nil, nil, "")
+		handler := NewLoginHandler(nil, nil, nil, nil, nil, "")
 
 		router.GET("/auth/token", handler.TokenPayload)
 		response := httptest.NewRecorder()
@@ -139,6 +789,131 @@ func TestTokenPayload(t *testing.T) {
 	})
 }
 
+func TestRefreshToken(t *testing.T) {
+	t.Run("should rotate the refresh token & return new token when request contains valid refresh token cookie", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, nil, nil, nil, nil, "")
+		authService.EXPECT().RefreshTokens("old-refresh-token", gomock.Any()).Return(auth.Tokens{AccessToken: "new-token", RefreshToken: "new-refresh-token", RefreshTokenExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		router.POST("/auth/refresh", handler.RefreshToken)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/refresh", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old-refresh-token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "new-token", response.Body.String())
+		assert.Contains(t, response.Header().Get("Set-Cookie"), "refresh_token=new-refresh-token")
+	})
+
+	t.Run("should return unauthorized error response & clear the cookie when refresh token is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, nil, nil, nil, nil, "")
+		authService.EXPECT().RefreshTokens("old-refresh-token", gomock.Any()).Return(auth.Tokens{}, auth.ErrInvalidRefreshToken)
+
+		router.POST("/auth/refresh", handler.RefreshToken)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/refresh", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old-refresh-token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Contains(t, response.Header().Get("Set-Cookie"), "refresh_token=;")
+	})
+
+	t.Run("should report the disabled user as inactive while refreshing the token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+		userService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, nil, nil, userService, nil, "")
+		userService.EXPECT().CheckStatus(userID).Return(user.ErrUserDisabled)
+		authService.EXPECT().RefreshTokens("old-refresh-token", gomock.Any()).DoAndReturn(func(_ string, isUserActive auth.UserActiveFunc) (auth.Tokens, error) {
+			active, err := isUserActive(userID)
+			assert.NoError(t, err)
+			assert.False(t, active)
+			return auth.Tokens{}, auth.ErrInvalidRefreshToken
+		})
+
+		router.POST("/auth/refresh", handler.RefreshToken)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/refresh", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old-refresh-token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Contains(t, response.Header().Get("Set-Cookie"), "refresh_token=;")
+	})
+
+	t.Run("should return unauthorized error response when request doesn't contain refresh token cookie", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(nil, nil, nil, nil, nil, "")
+
+		router.POST("/auth/refresh", handler.RefreshToken)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/refresh", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	t.Run("should revoke the refresh token & clear the cookie when request contains refresh token cookie", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, nil, nil, nil, nil, "")
+		authService.EXPECT().RevokeRefreshToken("refresh-token").Return(nil)
+
+		router.POST("/auth/logout", handler.Logout)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/logout", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh-token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Header().Get("Set-Cookie"), "refresh_token=;")
+	})
+
+	t.Run("should return internal server error when revoking refresh token fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewLoginHandler(authService, nil, nil, nil, nil, "")
+		authService.EXPECT().RevokeRefreshToken("refresh-token").Return(errors.New("some error"))
+
+		router.POST("/auth/logout", handler.Logout)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/auth/logout", nil)
+		req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh-token"})
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
 func makeDBUser(githubUser gh.User, token string) user.User {
 	return user.User{
 		Model: gorm.Model{
@@ -183,3 +958,8 @@ func validGithubUser() gh.User {
 		AvatarURL: &testAvatarURL,
 	}
 }
+
+func linkToken(scope string) *jwt.Token {
+	claims := jwt.MapClaims{"sub": strconv.FormatUint(uint64(userID), 10), "scope": scope}
+	return &jwt.Token{Claims: claims, Valid: true}
+}
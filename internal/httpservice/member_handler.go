@@ -0,0 +1,318 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/sirupsen/logrus"
+)
+
+// MemberInviteRequestPayload represents the http request payload to invite a member to the notebook.
+// The invitee is identified either by email or by github login.
+type MemberInviteRequestPayload struct {
+	Email       string `json:"email"`
+	GithubLogin string `json:"github_login"`
+	Role        string `json:"role"`
+}
+
+// Validate validates the member invite http request payload.
+func (m MemberInviteRequestPayload) Validate() error {
+	if err := validation.ValidateStruct(&m,
+		validation.Field(&m.Email, validation.Length(0, 255), is.Email),
+		validation.Field(&m.GithubLogin, validation.Length(0, 39)),
+		validation.Field(&m.Role, validation.Required, validation.In(notebook.Roles...)),
+	); err != nil {
+		return err
+	}
+	if (m.Email == "") == (m.GithubLogin == "") {
+		return validation.Errors{"email": errors.New("either email or github_login is required")}
+	}
+	return nil
+}
+
+// MemberRoleRequestPayload represents the http request payload to change the role of the member.
+type MemberRoleRequestPayload struct {
+	Role string `json:"role"`
+}
+
+// Validate validates the member role http request payload.
+func (m MemberRoleRequestPayload) Validate() error {
+	return validation.ValidateStruct(&m,
+		validation.Field(&m.Role, validation.Required, validation.In(notebook.Roles...)),
+	)
+}
+
+// MemberResponsePayload represents the http response payload of notebook member entity.
+// UserID is set once the invitee accepts the invitation.
+type MemberResponsePayload struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"user_id,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	GithubLogin string    `json:"github_login,omitempty"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InvitationResponsePayload represents the http response payload of the pending invitation to a notebook.
+type InvitationResponsePayload struct {
+	ID           uint      `json:"id"`
+	NotebookID   uint      `json:"notebook_id"`
+	NotebookName string    `json:"notebook_name"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// MemberHandler represents http handler for managing the members of the shared notebooks & the invitations of the user.
+type MemberHandler struct {
+	notebookService notebook.Service
+	userService     user.Service
+}
+
+// NewMemberHandler creates and returns a new member handler.
+func NewMemberHandler(notebookService notebook.Service, userService user.Service) *MemberHandler {
+	return &MemberHandler{
+		notebookService: notebookService,
+		userService:     userService,
+	}
+}
+
+// GetMembers returns the members of the notebook including the pending invitations.
+func (m *MemberHandler) GetMembers(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	notebookID, err := strconv.ParseUint(c.Param("notebook"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid notebook id"))
+		return
+	}
+	members, err := m.notebookService.GetMembers(userID, uint(notebookID))
+	if err != nil {
+		abortMemberRequestWithError(c, err)
+		return
+	}
+	membersResp := make([]MemberResponsePayload, 0, len(members))
+	for _, member := range members {
+		membersResp = append(membersResp, memberResponse(member))
+	}
+	c.JSON(http.StatusOK, membersResp)
+}
+
+// InviteMember invites the user with requested email or github login to the notebook with requested role.
+// The invitee becomes the member of the notebook after accepting the invitation.
+func (m *MemberHandler) InviteMember(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	notebookID, err := strconv.ParseUint(c.Param("notebook"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid notebook id"))
+		return
+	}
+	var invitePayload MemberInviteRequestPayload
+	c.BindJSON(&invitePayload)
+	if err := invitePayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).Info("request to invite notebook member started")
+	member, err := m.notebookService.Invite(userID, uint(notebookID), notebook.Member{
+		Role:         invitePayload.Role,
+		InviteeEmail: invitePayload.Email,
+		InviteeLogin: invitePayload.GithubLogin,
+	})
+	if err != nil {
+		abortMemberRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, memberResponse(member))
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).Info("request to invite notebook member successful")
+}
+
+// UpdateMember changes the role of the member of the notebook.
+func (m *MemberHandler) UpdateMember(c *gin.Context) {
+	userID, notebookID, memberID, ok := getMemberParams(c)
+	if !ok {
+		return
+	}
+	var rolePayload MemberRoleRequestPayload
+	c.BindJSON(&rolePayload)
+	if err := rolePayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).WithField("member-id", memberID).
+		Infof("request to change role of notebook member to %s", rolePayload.Role)
+	member, err := m.notebookService.UpdateMemberRole(userID, notebookID, memberID, rolePayload.Role)
+	if err != nil {
+		abortMemberRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, memberResponse(member))
+}
+
+// RemoveMember removes the member from the notebook or revokes the pending invitation.
+// The members can remove themselves to leave the notebook.
+func (m *MemberHandler) RemoveMember(c *gin.Context) {
+	userID, notebookID, memberID, ok := getMemberParams(c)
+	if !ok {
+		return
+	}
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).WithField("member-id", memberID).
+		Info("request to remove notebook member started")
+	if err := m.notebookService.RemoveMember(userID, notebookID, memberID); err != nil {
+		abortMemberRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).WithField("member-id", memberID).
+		Info("request to remove notebook member successful")
+}
+
+// GetInvitations returns the pending invitations sent to the email or github login of the user.
+func (m *MemberHandler) GetInvitations(c *gin.Context) {
+	u, err := m.getUser(c)
+	if err != nil {
+		logrus.Errorf("fetching user from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	invitations, err := m.notebookService.GetInvitations(u.Email, u.GithubUsername)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	invitationsResp := make([]InvitationResponsePayload, 0, len(invitations))
+	for _, invitation := range invitations {
+		invitationsResp = append(invitationsResp, InvitationResponsePayload{
+			ID:           invitation.ID,
+			NotebookID:   invitation.NotebookID,
+			NotebookName: invitation.Notebook.Name,
+			Role:         invitation.Role,
+			CreatedAt:    invitation.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, invitationsResp)
+}
+
+// AcceptInvitation makes the user a member of the notebook of the invitation.
+func (m *MemberHandler) AcceptInvitation(c *gin.Context) {
+	u, err := m.getUser(c)
+	if err != nil {
+		logrus.Errorf("fetching user from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	invitationID, err := strconv.ParseUint(c.Param("invitation"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid invitation id"))
+		return
+	}
+	logrus.WithField("user-id", u.ID).WithField("invitation-id", invitationID).Info("request to accept notebook invitation started")
+	member, err := m.notebookService.AcceptInvitation(u.ID, u.Email, u.GithubUsername, uint(invitationID))
+	if err != nil {
+		abortMemberRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, memberResponse(member))
+	logrus.WithField("user-id", u.ID).WithField("invitation-id", invitationID).Info("request to accept notebook invitation successful")
+}
+
+// DeclineInvitation deletes the invitation sent to the user.
+func (m *MemberHandler) DeclineInvitation(c *gin.Context) {
+	u, err := m.getUser(c)
+	if err != nil {
+		logrus.Errorf("fetching user from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	invitationID, err := strconv.ParseUint(c.Param("invitation"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid invitation id"))
+		return
+	}
+	logrus.WithField("user-id", u.ID).WithField("invitation-id", invitationID).Info("request to decline notebook invitation started")
+	if err := m.notebookService.DeclineInvitation(u.Email, u.GithubUsername, uint(invitationID)); err != nil {
+		abortMemberRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", u.ID).WithField("invitation-id", invitationID).Info("request to decline notebook invitation successful")
+}
+
+func (m *MemberHandler) getUser(c *gin.Context) (user.User, error) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		return user.User{}, err
+	}
+	return m.userService.Get(userID)
+}
+
+// getMemberParams parses the user id from the context & the notebook id & member id from the path params.
+// The request is aborted when any of them is invalid.
+func getMemberParams(c *gin.Context) (uint, uint, uint, bool) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return 0, 0, 0, false
+	}
+	notebookID, err := strconv.ParseUint(c.Param("notebook"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid notebook id"))
+		return 0, 0, 0, false
+	}
+	memberID, err := strconv.ParseUint(c.Param("member"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid member id"))
+		return 0, 0, 0, false
+	}
+	return userID, uint(notebookID), uint(memberID), true
+}
+
+// abortMemberRequestWithError maps the errors of the notebook service to the http response.
+func abortMemberRequestWithError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, notebook.ErrInsufficientRole):
+		c.AbortWithStatus(http.StatusForbidden)
+	case errors.Is(err, notebook.ErrNotebookNotFound):
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "notebook not found"))
+	case errors.Is(err, notebook.ErrMemberNotFound):
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "member not found"))
+	case errors.Is(err, notebook.ErrMemberExists):
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "member is already invited to the notebook"))
+	case errors.Is(err, notebook.ErrInvitationNotFound):
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invitation not found"))
+	default:
+		abortRequestWithError(c, err)
+	}
+}
+
+func memberResponse(member notebook.Member) MemberResponsePayload {
+	return MemberResponsePayload{
+		ID:          member.ID,
+		UserID:      member.UserID,
+		Email:       member.InviteeEmail,
+		GithubLogin: member.InviteeLogin,
+		Role:        member.Role,
+		Status:      member.Status,
+		CreatedAt:   member.CreatedAt,
+	}
+}
@@ -0,0 +1,322 @@
+package httpservice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const memberID = uint(11)
+
+func TestGetMembers(t *testing.T) {
+	t.Run("should return the members of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, nil)
+		mockNotebookService.EXPECT().GetMembers(userID, notebookID).Return([]notebook.Member{validMember()}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/notebooks/:notebook/members", getClaimsHandler(), handler.GetMembers)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/7/members", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"id":11,"github_login":"janedoe","role":"editor","status":"invited","created_at":"2022-10-18T10:00:00Z"}]`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when the notebook is not accessible to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, nil)
+		mockNotebookService.EXPECT().GetMembers(userID, notebookID).Return(nil, notebook.ErrNotebookNotFound)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/notebooks/:notebook/members", getClaimsHandler(), handler.GetMembers)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/7/members", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"notebook not found"}`, response.Body.String())
+	})
+}
+
+func TestInviteMember(t *testing.T) {
+	t.Run("should invite the member by github login", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, nil)
+		mockNotebookService.EXPECT().Invite(userID, notebookID, notebook.Member{Role: notebook.RoleEditor, InviteeLogin: "janedoe"}).Return(validMember(), nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks/:notebook/members", getClaimsHandler(), handler.InviteMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks/7/members", strings.NewReader(`{"github_login":"janedoe","role":"editor"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with forbidden when the user is not an owner of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, nil)
+		mockNotebookService.EXPECT().Invite(userID, notebookID, gomock.Any()).Return(notebook.Member{}, notebook.ErrInsufficientRole)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks/:notebook/members", getClaimsHandler(), handler.InviteMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks/7/members", strings.NewReader(`{"email":"jane.doe@example.com","role":"viewer"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("should fail with bad request when neither email nor github login is provided", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks/:notebook/members", getClaimsHandler(), handler.InviteMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks/7/members", strings.NewReader(`{"role":"viewer"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"email: either email or github_login is required."}`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when the role is invalid", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks/:notebook/members", getClaimsHandler(), handler.InviteMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks/7/members", strings.NewReader(`{"github_login":"janedoe","role":"admin"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"role: must be a valid value."}`, response.Body.String())
+	})
+}
+
+func TestUpdateMember(t *testing.T) {
+	t.Run("should change the role of the member", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, nil)
+		member := validMember()
+		member.Role = notebook.RoleViewer
+		mockNotebookService.EXPECT().UpdateMemberRole(userID, notebookID, memberID, notebook.RoleViewer).Return(member, nil)
+
+		// simulate auth middleware with custom handler
+		router.PUT("/api/v1/notebooks/:notebook/members/:member", getClaimsHandler(), handler.UpdateMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/notebooks/7/members/11", strings.NewReader(`{"role":"viewer"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the member does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, nil)
+		mockNotebookService.EXPECT().UpdateMemberRole(userID, notebookID, memberID, notebook.RoleOwner).Return(notebook.Member{}, notebook.ErrMemberNotFound)
+
+		// simulate auth middleware with custom handler
+		router.PUT("/api/v1/notebooks/:notebook/members/:member", getClaimsHandler(), handler.UpdateMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/notebooks/7/members/11", strings.NewReader(`{"role":"owner"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"member not found"}`, response.Body.String())
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("should remove the member from the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, nil)
+		mockNotebookService.EXPECT().RemoveMember(userID, notebookID, memberID).Return(nil)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/notebooks/:notebook/members/:member", getClaimsHandler(), handler.RemoveMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/notebooks/7/members/11", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the member id is invalid", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/notebooks/:notebook/members/:member", getClaimsHandler(), handler.RemoveMember)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/notebooks/7/members/janedoe", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"invalid member id"}`, response.Body.String())
+	})
+}
+
+func TestGetInvitations(t *testing.T) {
+	t.Run("should return the pending invitations sent to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, mockUserService)
+		u := validUser()
+		invitation := validMember()
+		invitation.Notebook = validNotebook()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetInvitations(u.Email, u.GithubUsername).Return([]notebook.Member{invitation}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/invitations", getClaimsHandler(), handler.GetInvitations)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/invitations", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"id":11,"notebook_id":7,"notebook_name":"Work","role":"editor","created_at":"2022-10-18T10:00:00Z"}]`, response.Body.String())
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	t.Run("should accept the invitation sent to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, mockUserService)
+		u := validUser()
+		member := validMember()
+		member.UserID = userID
+		member.Status = notebook.StatusAccepted
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().AcceptInvitation(userID, u.Email, u.GithubUsername, memberID).Return(member, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/invitations/:invitation/accept", getClaimsHandler(), handler.AcceptInvitation)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/invitations/11/accept", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"id":11,"user_id":1012,"github_login":"janedoe","role":"editor","status":"accepted","created_at":"2022-10-18T10:00:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when the invitation is not sent to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, mockUserService)
+		u := validUser()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().AcceptInvitation(userID, u.Email, u.GithubUsername, memberID).Return(notebook.Member{}, notebook.ErrInvitationNotFound)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/invitations/:invitation/accept", getClaimsHandler(), handler.AcceptInvitation)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/invitations/11/accept", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"invitation not found"}`, response.Body.String())
+	})
+}
+
+func TestDeclineInvitation(t *testing.T) {
+	t.Run("should decline the invitation sent to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewMemberHandler(mockNotebookService, mockUserService)
+		u := validUser()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().DeclineInvitation(u.Email, u.GithubUsername, memberID).Return(nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/user/invitations/:invitation/decline", getClaimsHandler(), handler.DeclineInvitation)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/invitations/11/decline", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
+
+func validMember() notebook.Member {
+	return notebook.Member{
+		Model:        gorm.Model{ID: memberID, CreatedAt: time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)},
+		NotebookID:   notebookID,
+		Role:         notebook.RoleEditor,
+		Status:       notebook.StatusInvited,
+		InviteeLogin: "janedoe",
+		InvitedBy:    userID,
+	}
+}
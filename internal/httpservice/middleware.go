@@ -1,24 +1,64 @@
 package httpservice
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/sirupsen/logrus"
 )
 
+type connContextKey struct{}
+
+// storeConn stores the connection in the context of its requests, so the deadlines of the connection can be extended per route.
+func storeConn(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// ExtendDeadlines extends the read & write deadlines of the connection beyond the timeouts of the server.
+// It is used by the routes accepting large uploads which can not be read within the read timeout of the server.
+// The server resets the deadlines when it reads the next request of the connection.
+func ExtendDeadlines(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conn, ok := c.Request.Context().Value(connContextKey{}).(net.Conn)
+		if !ok {
+			c.Next()
+			return
+		}
+		deadline := time.Now().Add(timeout)
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			logrus.WithError(err).Error("extending read deadline of connection failed")
+		}
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			logrus.WithError(err).Error("extending write deadline of connection failed")
+		}
+		c.Next()
+	}
+}
+
 // Middleware represents a http middleware used primarily for authorization.
 type Middleware struct {
-	authService auth.Service
+	authService     auth.Service
+	apiTokenService auth.APITokenService
+	userService     user.Service
 }
 
 // NewMiddleware creates and return the middleware.
-func NewMiddleware(authService auth.Service) *Middleware {
-	return &Middleware{authService: authService}
+func NewMiddleware(authService auth.Service, apiTokenService auth.APITokenService, userService user.Service) *Middleware {
+	return &Middleware{authService: authService, apiTokenService: apiTokenService, userService: userService}
 }
 
 // AuthorizeToken retrieves and validates app token from authorization header of http request.
+// The app token can either be a jwt token or a user generated api token.
+// Tokens of the revoked sessions & tokens of the disabled or deleted users are rejected.
 func (m *Middleware) AuthorizeToken() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -28,6 +68,11 @@ func (m *Middleware) AuthorizeToken() gin.HandlerFunc {
 		}
 		tokenString := authHeader[len("Bearer "):]
 
+		if strings.HasPrefix(tokenString, auth.APITokenPrefix) {
+			m.authorizeAPIToken(c, tokenString)
+			return
+		}
+
 		token, err := m.authService.ValidateToken(tokenString)
 
 		if err != nil || !token.Valid {
@@ -36,7 +81,109 @@ func (m *Middleware) AuthorizeToken() gin.HandlerFunc {
 		}
 
 		claims := token.Claims.(jwt.MapClaims)
+		tokenID, _ := claims["jti"].(string)
+		if err := m.authService.ValidateSession(tokenID); err != nil {
+			if !errors.Is(err, auth.ErrInvalidSession) {
+				abortRequestWithError(c, err)
+				return
+			}
+			logrus.WithError(err).Warn("token of an invalid session rejected")
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		subject, _ := claims["sub"].(string)
+		userID, err := strconv.ParseUint(subject, 10, 64)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !m.checkUserStatus(c, uint(userID)) {
+			return
+		}
 		c.Set("claims", claims)
 		c.Next()
 	}
 }
+
+// RequireScopes checks that the app token of the request is granted all the required scopes.
+// It must be used after the AuthorizeToken middleware.
+func (m *Middleware) RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, _ := c.Get("claims")
+		if claims == nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		granted, _ := claims.(jwt.MapClaims)["scope"].(string)
+		if !auth.HasScopes(granted, scopes...) {
+			logrus.WithField("required-scopes", scopes).Warn("token without required scopes rejected")
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin checks that the user of the request is still granted the admin role.
+// The admin scope of the token is not sufficient since the admin role can be withdrawn before the token expires.
+// It must be used after the AuthorizeToken middleware.
+func (m *Middleware) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserIDFromContext(c)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		dbUser, err := m.userService.Get(userID)
+		if err != nil {
+			abortRequestWithError(c, err)
+			return
+		}
+		if !m.userService.IsAdmin(dbUser) {
+			logrus.WithField("user-id", userID).Warn("admin request of a non admin user rejected")
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// authorizeAPIToken validates the api token & stores the claims equivalent to the jwt token in context.
+func (m *Middleware) authorizeAPIToken(c *gin.Context, tokenString string) {
+	apiToken, err := m.apiTokenService.Validate(tokenString)
+	if err != nil {
+		logrus.WithError(err).Warn("invalid api token rejected")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	if !m.checkUserStatus(c, apiToken.UserID) {
+		return
+	}
+	c.Set("claims", jwt.MapClaims{
+		"sub":          strconv.FormatUint(uint64(apiToken.UserID), 10),
+		"scope":        apiToken.Scopes,
+		"api_token_id": float64(apiToken.ID),
+	})
+	c.Next()
+}
+
+// checkUserStatus aborts the request when the user is not allowed to access the app.
+// It returns true when the request can proceed.
+func (m *Middleware) checkUserStatus(c *gin.Context, userID uint) bool {
+	err := m.userService.CheckStatus(userID)
+	if isInactiveUserError(err) {
+		logrus.WithField("user-id", userID).WithError(err).Warn("token of an inactive user rejected")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return false
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return false
+	}
+	return true
+}
+
+// isInactiveUserError reports whether the error is returned for a user who is not allowed to access the app.
+func isInactiveUserError(err error) bool {
+	return errors.Is(err, user.ErrUserDisabled) || errors.Is(err, user.ErrUserDeleted) || errors.Is(err, user.ErrUserNotFound)
+}
@@ -0,0 +1,108 @@
+package httpservice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/export"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+)
+
+// exportContentTypes are the content types of the exported notes by the export format.
+var exportContentTypes = map[string]string{
+	export.FormatZip:      "application/zip",
+	export.FormatMarkdown: "text/markdown; charset=utf-8",
+	export.FormatHTML:     "text/html; charset=utf-8",
+	export.FormatJSON:     "application/json; charset=utf-8",
+}
+
+// NoteExportHandler represents http handler for exporting the notes for offline use.
+type NoteExportHandler struct {
+	notesService    export.NotesService
+	userService     user.Service
+	tokenService    user.TokenService
+	notebookService notebook.Service
+}
+
+// NewNoteExportHandler creates and returns a new note export handler.
+func NewNoteExportHandler(notesService export.NotesService, userService user.Service, tokenService user.TokenService,
+	notebookService notebook.Service) *NoteExportHandler {
+	return &NoteExportHandler{
+		notesService:    notesService,
+		userService:     userService,
+		tokenService:    tokenService,
+		notebookService: notebookService,
+	}
+}
+
+// ExportNotes streams the notes under the path as an attachment in the requested format (zip, md, html or json).
+// All the notes are exported when the path is not provided & zip is used when the format is not provided.
+// The response is streamed while the notes are fetched, so an error occurring midway leaves the attachment incomplete.
+func (n *NoteExportHandler) ExportNotes(c *gin.Context) {
+	path := strings.Trim(c.Query("path"), "/")
+	format := c.DefaultQuery("format", export.FormatZip)
+	if err := validation.Validate(format, validation.In(export.Formats...)); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("format: %s", err.Error())))
+		return
+	}
+	notebookID, err := getNotebookIDParam(c)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	u, err := n.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	log := logrus.WithField("user-id", userID).WithField("path", path).WithField("format", format)
+	log.Info("request to export notes started")
+	repoDetails, repoUser, err := resolveNoteRepo(n.userService, n.notebookService, u, notebookID, notebook.RoleViewer)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateFolderPath(path, repoDetails.NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	ghToken, err := n.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	notes, err := n.notesService.GetNotes(c, ghToken, repoDetails, path)
+	if errors.Is(err, export.ErrNoNotes) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="batnoter-notes-%s.%s"`, time.Now().UTC().Format("2006-01-02"), format))
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Status(http.StatusOK)
+	if err := n.notesService.Write(c, ghToken, repoDetails, notes, format, c.Writer); err != nil {
+		// the response is already being sent, so the status can not be changed anymore
+		log.WithError(err).Error("streaming exported notes failed")
+		c.Abort()
+		return
+	}
+	log.WithField("notes", len(notes)).Info("request to export notes successful")
+}
@@ -0,0 +1,187 @@
+package httpservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/batnoter/batnoter-api/internal/export"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestExportNotes(t *testing.T) {
+	t.Run("should stream the notes of the path in the requested format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotesService := export.NewMockNotesService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		repoDetails := github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}
+		notes := []github.GitFile{{SHA: "sha1", Path: "docs/setup.md"}}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockNotesService.EXPECT().GetNotes(gomock.Any(), ghToken, repoDetails, "docs").Return(notes, nil)
+		mockNotesService.EXPECT().Write(gomock.Any(), ghToken, repoDetails, notes, export.FormatMarkdown, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ oauth2.Token, _ github.GitRepoProps, _ []github.GitFile, _ string, w io.Writer) error {
+				_, err := io.WriteString(w, "<!-- docs/setup.md -->\n\n# Setup\n")
+				return err
+			})
+		handler := NewNoteExportHandler(mockNotesService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/export", getClaimsHandler(), handler.ExportNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/export?path=docs/&format=md", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/markdown; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Header().Get("Content-Disposition"), `attachment; filename="batnoter-notes-`)
+		assert.Contains(t, response.Header().Get("Content-Disposition"), `.md"`)
+		assert.Equal(t, "<!-- docs/setup.md -->\n\n# Setup\n", response.Body.String())
+	})
+
+	t.Run("should stream all the notes of the notebook as zip when path & format are not provided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotesService := export.NewMockNotesService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		nb := validNotebook()
+		nb.Role = notebook.RoleViewer
+		ghToken := getOAuth2Token(u.GithubToken)
+		repoDetails := github.GitRepoProps{Repository: "work-notes", DefaultBranch: "main", Owner: owner}
+		notes := []github.GitFile{{SHA: "sha1", Path: "todo.md"}}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockNotesService.EXPECT().GetNotes(gomock.Any(), ghToken, repoDetails, "").Return(notes, nil)
+		mockNotesService.EXPECT().Write(gomock.Any(), ghToken, repoDetails, notes, export.FormatZip, gomock.Any()).Return(nil)
+		handler := NewNoteExportHandler(mockNotesService, mockUserService, mockTokenService, mockNotebookService)
+
+		router.GET("/api/v1/notebooks/:notebook/export", getClaimsHandler(), handler.ExportNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/7/export", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/zip", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Header().Get("Content-Disposition"), `.zip"`)
+	})
+
+	t.Run("should return bad request when there are no notes under the path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotesService := export.NewMockNotesService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockNotesService.EXPECT().GetNotes(gomock.Any(), ghToken, gomock.Any(), "empty").Return(nil, export.ErrNoNotes)
+		handler := NewNoteExportHandler(mockNotesService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/export", getClaimsHandler(), handler.ExportNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/export?path=empty&format=json", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"no notes found in the path"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the format is not supported", func(t *testing.T) {
+		router := getRouter()
+		handler := NewNoteExportHandler(nil, nil, nil, nil)
+
+		router.GET("/api/v1/export", getClaimsHandler(), handler.ExportNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/export?format=pdf", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"format: must be a valid value"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request when the path is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		router := getRouter()
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		handler := NewNoteExportHandler(nil, mockUserService, nil, nil)
+
+		router.GET("/api/v1/export", getClaimsHandler(), handler.ExportNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/export?path=../secrets", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("should return bad request when the path does not match the path pattern of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		nb := validNotebook()
+		nb.Role = notebook.RoleOwner
+		nb.NotePathPattern = `notes/.+`
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+		handler := NewNoteExportHandler(nil, mockUserService, nil, mockNotebookService)
+
+		router.GET("/api/v1/notebooks/:notebook/export", getClaimsHandler(), handler.ExportNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/7/export?path=drafts", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"path: must be in a valid format"}`, response.Body.String())
+	})
+
+	t.Run("should return internal server error when listing the notes fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotesService := export.NewMockNotesService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockNotesService.EXPECT().GetNotes(gomock.Any(), ghToken, gomock.Any(), "").Return(nil, errors.New("github error"))
+		handler := NewNoteExportHandler(mockNotesService, mockUserService, mockTokenService, nil)
+
+		router.GET("/api/v1/export", getClaimsHandler(), handler.ExportNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/export", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
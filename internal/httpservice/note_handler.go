@@ -1,18 +1,20 @@
 package httpservice
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/batnoter/batnoter-api/internal/notebook"
 	"github.com/batnoter/batnoter-api/internal/user"
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/oauth2"
 )
 
 // NoteRequestPayload represents the http request payload of note entity.
@@ -39,14 +41,23 @@ type NoteSearchResponsePayload struct {
 }
 
 // NoteHandler represents http handler for managing note entities.
+// The notes are stored in the default repo of the user, or in the repo of the notebook when the route is scoped to a notebook.
+// The attachment folder is the folder of the repo the attachments are stored in, the images of the notes stored in it
+// are served by the attachment api.
 type NoteHandler struct {
-	githubService github.Service
-	userService   user.Service
+	githubService    github.Service
+	userService      user.Service
+	tokenService     user.TokenService
+	notebookService  notebook.Service
+	renderer         markdown.Renderer
+	attachmentFolder string
 }
 
 // NewNoteHandler creates and returns a new note handler.
-func NewNoteHandler(githubService github.Service, userService user.Service) *NoteHandler {
-	return &NoteHandler{githubService: githubService, userService: userService}
+func NewNoteHandler(githubService github.Service, userService user.Service, tokenService user.TokenService,
+	notebookService notebook.Service, renderer markdown.Renderer, attachmentFolder string) *NoteHandler {
+	return &NoteHandler{githubService: githubService, userService: userService, tokenService: tokenService, notebookService: notebookService,
+		renderer: renderer, attachmentFolder: attachmentFolder}
 }
 
 // SearchNotes performs a note search operation with specified filter criteria.
@@ -64,8 +75,23 @@ func (n *NoteHandler) SearchNotes(c *gin.Context) {
 	}
 	logrus.WithField("user-id", user.ID).WithField("note_path", path).
 		WithField("query", query).WithField("page", page).Info("request to search & retrieve notes")
-	fileProps := makeFileProps(user, NoteRequestPayload{}, path)
-	gitFiles, total, err := n.githubService.SearchFiles(c, parseOAuth2Token(user.GithubToken), fileProps, query, page)
+	repoDetails, repoUser, err := n.getRepoDetails(c, user, notebook.RoleViewer)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateFolderPath(path, repoDetails.NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	fileProps := makeFileProps(user, repoDetails, NoteRequestPayload{}, path)
+	ghToken, err := n.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	gitFiles, total, err := n.githubService.SearchFiles(c, ghToken, fileProps, query, page)
 	if err != nil {
 		logrus.Errorf("searching notes on github failed")
 		abortRequestWithError(c, err)
@@ -86,8 +112,19 @@ func (n *NoteHandler) GetNotesTree(c *gin.Context) {
 		return
 	}
 	logrus.WithField("user-id", user.ID).Info("request to retrieve tree")
-	fileProps := makeFileProps(user, NoteRequestPayload{}, "")
-	gitFiles, err := n.githubService.GetTree(c, parseOAuth2Token(user.GithubToken), fileProps)
+	repoDetails, repoUser, err := n.getRepoDetails(c, user, notebook.RoleViewer)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	fileProps := makeFileProps(user, repoDetails, NoteRequestPayload{}, "")
+	ghToken, err := n.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	gitFiles, err := n.githubService.GetTree(c, ghToken, fileProps)
 	if err != nil {
 		logrus.WithField("user-id", user.ID).Errorf("retrieving tree on github failed")
 		abortRequestWithError(c, err)
@@ -112,8 +149,23 @@ func (n *NoteHandler) GetAllNotes(c *gin.Context) {
 		return
 	}
 	logrus.WithField("user-id", user.ID).WithField("note_path", path).Info("request to retrieve notes started")
-	fileProps := makeFileProps(user, NoteRequestPayload{}, path)
-	gitFiles, err := n.githubService.GetAllFiles(c, parseOAuth2Token(user.GithubToken), fileProps)
+	repoDetails, repoUser, err := n.getRepoDetails(c, user, notebook.RoleViewer)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateFolderPath(path, repoDetails.NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	fileProps := makeFileProps(user, repoDetails, NoteRequestPayload{}, path)
+	ghToken, err := n.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	gitFiles, err := n.githubService.GetAllFiles(c, ghToken, fileProps)
 	if err != nil {
 		logrus.Errorf("retrieving notes from github failed")
 		abortRequestWithError(c, err)
@@ -129,12 +181,18 @@ func (n *NoteHandler) GetAllNotes(c *gin.Context) {
 }
 
 // GetNote returns a note with requested path as a http response.
+// The note is rendered to html when the client accepts html instead of json.
 func (n *NoteHandler) GetNote(c *gin.Context) {
+	n.getNote(c, c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML)
+}
+
+// GetNoteHTML returns a note with requested path rendered to sanitized html as a http response.
+func (n *NoteHandler) GetNoteHTML(c *gin.Context) {
+	n.getNote(c, true)
+}
+
+func (n *NoteHandler) getNote(c *gin.Context, renderHTML bool) {
 	path := c.Param("path")
-	if err := validation.Validate(path, validation.Required, validation.Match(regexp.MustCompile(github.ValidFilePathRegex))); err != nil {
-		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("path: %s", err.Error())))
-		return
-	}
 	user, err := n.getUser(c)
 	if err != nil {
 		logrus.Errorf("fetching user from context failed")
@@ -142,24 +200,41 @@ func (n *NoteHandler) GetNote(c *gin.Context) {
 		return
 	}
 	logrus.WithField("user-id", user.ID).WithField("note_path", path).Info("request to retrieve note started")
-	fileProps := makeFileProps(user, NoteRequestPayload{}, path)
-	gitFile, err := n.githubService.GetFile(c, parseOAuth2Token(user.GithubToken), fileProps)
+	repoDetails, repoUser, err := n.getRepoDetails(c, user, notebook.RoleViewer)
 	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateNotePath(path, repoDetails.NoteFiles); err != nil {
 		abortRequestWithError(c, err)
 		return
 	}
-	note := makeNoteResponsePayload(gitFile)
-	c.JSON(http.StatusOK, note)
+	fileProps := makeFileProps(user, repoDetails, NoteRequestPayload{}, path)
+	ghToken, err := n.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	gitFile, err := n.githubService.GetFile(c, ghToken, fileProps)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	if renderHTML {
+		if !writeNoteHTML(c, n.renderer, gitFile, noteRenderOptions(c, path, repoDetails.NoteFiles, n.attachmentFolder)) {
+			return
+		}
+	} else {
+		note := makeNoteResponsePayload(gitFile)
+		c.JSON(http.StatusOK, note)
+	}
 	logrus.WithField("user-id", user.ID).WithField("note_path", path).Info("request to retrieve note successful")
 }
 
 // SaveNote stores the note and returns the metadata as a http response.
 func (n *NoteHandler) SaveNote(c *gin.Context) {
 	path := c.Param("path")
-	if err := validation.Validate(path, validation.Required, validation.Match(regexp.MustCompile(github.ValidFilePathRegex))); err != nil {
-		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("path: %s", err.Error())))
-		return
-	}
 	var noteReqPayload NoteRequestPayload
 	c.BindJSON(&noteReqPayload)
 	if err := validation.Validate(noteReqPayload.Content, validation.Required); err != nil {
@@ -173,8 +248,23 @@ func (n *NoteHandler) SaveNote(c *gin.Context) {
 		return
 	}
 	logrus.WithField("user-id", user.ID).WithField("note_path", path).Info("request to save note started")
-	fileProps := makeFileProps(user, noteReqPayload, path)
-	gitFile, err := n.githubService.SaveFile(c, parseOAuth2Token(user.GithubToken), fileProps)
+	repoDetails, repoUser, err := n.getRepoDetails(c, user, notebook.RoleEditor)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateNotePath(path, repoDetails.NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	fileProps := makeFileProps(user, repoDetails, noteReqPayload, path)
+	ghToken, err := n.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	gitFile, err := n.githubService.SaveFile(c, ghToken, fileProps)
 	note := makeNoteResponsePayload(gitFile)
 	if err != nil {
 		abortRequestWithError(c, err)
@@ -187,10 +277,6 @@ func (n *NoteHandler) SaveNote(c *gin.Context) {
 // DeleteNote deletes a note with requested path.
 func (n *NoteHandler) DeleteNote(c *gin.Context) {
 	path := c.Param("path")
-	if err := validation.Validate(path, validation.Required, validation.Match(regexp.MustCompile(github.ValidFilePathRegex))); err != nil {
-		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("path: %s", err.Error())))
-		return
-	}
 	var noteReqPayload NoteRequestPayload
 	c.BindJSON(&noteReqPayload)
 	if err := validation.Validate(noteReqPayload.SHA, validation.Required); err != nil {
@@ -204,8 +290,23 @@ func (n *NoteHandler) DeleteNote(c *gin.Context) {
 		return
 	}
 	logrus.WithField("user-id", user.ID).WithField("note_path", path).Info("request to delete note started")
-	fileProps := makeFileProps(user, noteReqPayload, path)
-	err = n.githubService.DeleteFile(c, parseOAuth2Token(user.GithubToken), fileProps)
+	repoDetails, repoUser, err := n.getRepoDetails(c, user, notebook.RoleEditor)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateNotePath(path, repoDetails.NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	fileProps := makeFileProps(user, repoDetails, noteReqPayload, path)
+	ghToken, err := n.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	err = n.githubService.DeleteFile(c, ghToken, fileProps)
 	if err != nil {
 		abortRequestWithError(c, err)
 		return
@@ -214,6 +315,111 @@ func (n *NoteHandler) DeleteNote(c *gin.Context) {
 	logrus.WithField("user-id", user.ID).WithField("note_path", path).Info("request to delete note successful")
 }
 
+// getRepoDetails returns the repo of the notebook from the notebook path param along with the user whose github token
+// is used to access the repo. The default repo of the user is returned when the route is not scoped to a notebook.
+func (n *NoteHandler) getRepoDetails(c *gin.Context, u user.User, requiredRole string) (github.GitRepoProps, user.User, error) {
+	notebookID, err := getNotebookIDParam(c)
+	if err != nil {
+		return github.GitRepoProps{}, user.User{}, err
+	}
+	return resolveNoteRepo(n.userService, n.notebookService, u, notebookID, requiredRole)
+}
+
+// getNotebookIDParam parses the notebook id from the notebook path param, it is zero when the route is not scoped to a notebook.
+func getNotebookIDParam(c *gin.Context) (uint, error) {
+	notebookParam := c.Param("notebook")
+	if notebookParam == "" {
+		return 0, nil
+	}
+	notebookID, err := strconv.ParseUint(notebookParam, 10, 64)
+	if err != nil || notebookID == 0 {
+		return 0, NewAppError(ErrorCodeInvalidRequest, "invalid notebook id")
+	}
+	return uint(notebookID), nil
+}
+
+// resolveNoteRepo returns the repo of the notebook along with the user whose github token is used to access the repo.
+// The default repo of the user is returned when the notebook id is zero. The notebooks shared with the user are accessed
+// with the token of the user who created the notebook, the role of the user on the notebook must grant the permissions
+// of the required role.
+func resolveNoteRepo(userService user.Service, notebookService notebook.Service, u user.User, notebookID uint,
+	requiredRole string) (github.GitRepoProps, user.User, error) {
+	if notebookID == 0 {
+		return u.GetDefaultRepoDetails(), u, nil
+	}
+	nb, err := notebookService.GetAccessible(u.ID, notebookID)
+	if errors.Is(err, notebook.ErrNotebookNotFound) {
+		return github.GitRepoProps{}, user.User{}, NewAppError(ErrorCodeInvalidRequest, "notebook not found")
+	}
+	if err != nil {
+		return github.GitRepoProps{}, user.User{}, err
+	}
+	if !notebook.HasRole(nb.Role, requiredRole) {
+		return github.GitRepoProps{}, user.User{}, notebook.ErrInsufficientRole
+	}
+	repoUser := u
+	if nb.UserID != u.ID {
+		if repoUser, err = userService.Get(nb.UserID); err != nil {
+			return github.GitRepoProps{}, user.User{}, err
+		}
+	}
+	owner := nb.Owner
+	if owner == "" {
+		owner = repoUser.GithubUsername
+	}
+	return github.GitRepoProps{
+		Repository:     nb.Repository,
+		DefaultBranch:  nb.Branch,
+		Owner:          owner,
+		NoteFiles:      noteFileRules(nb),
+		InstallationID: nb.InstallationID,
+	}, repoUser, nil
+}
+
+// noteFileRules returns the rules identifying the note files of the repo of the notebook.
+func noteFileRules(nb notebook.Notebook) github.NoteFileRules {
+	rules := github.NoteFileRules{PathPattern: nb.NotePathPattern}
+	if nb.NoteExtensions != "" {
+		rules.Extensions = strings.Fields(nb.NoteExtensions)
+	}
+	return rules
+}
+
+// validateNotePath validates the path of the note against the rules identifying the note files of the repo.
+func validateNotePath(path string, rules github.NoteFileRules) error {
+	r, err := rules.Regexp()
+	if err != nil {
+		return err
+	}
+	if err := validation.Validate(path, validation.Required, validation.Match(r)); err != nil {
+		return NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("path: %s", err.Error()))
+	}
+	return nil
+}
+
+// validateFolderPath validates the path of the folder of the notes against the rules identifying the note files of the repo.
+// The empty path (root folder of the repo) is valid.
+func validateFolderPath(path string, rules github.NoteFileRules) error {
+	r, err := rules.FolderRegexp()
+	if err != nil {
+		return err
+	}
+	if err := validation.Validate(path, validation.Match(r)); err != nil {
+		return NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("path: %s", err.Error()))
+	}
+	return nil
+}
+
+// abortRepoRequestWithError aborts the request with forbidden status when the role of the user on the notebook
+// does not permit the request, otherwise it aborts the request with the error.
+func abortRepoRequestWithError(c *gin.Context, err error) {
+	if errors.Is(err, notebook.ErrInsufficientRole) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	abortRequestWithError(c, err)
+}
+
 func (n *NoteHandler) getUser(c *gin.Context) (user.User, error) {
 	userID, err := getUserIDFromContext(c)
 	if err != nil {
@@ -223,22 +429,14 @@ func (n *NoteHandler) getUser(c *gin.Context) (user.User, error) {
 	return n.userService.Get(userID)
 }
 
-func makeFileProps(user user.User, noteReqPayload NoteRequestPayload, path string) github.GitFileProps {
-	authorName := user.Name
-	if authorName == "" {
-		authorName = user.GithubUsername
-	}
+func makeFileProps(user user.User, repoDetails github.GitRepoProps, noteReqPayload NoteRequestPayload, path string) github.GitFileProps {
 	return github.GitFileProps{
 		SHA:         noteReqPayload.SHA,
 		Content:     noteReqPayload.Content,
 		Path:        path,
-		AuthorName:  authorName,
-		AuthorEmail: user.Email,
-		RepoDetails: github.GitRepoProps{
-			Repository:    user.DefaultRepo.Name,
-			DefaultBranch: user.DefaultRepo.DefaultBranch,
-			Owner:         user.GithubUsername,
-		},
+		AuthorName:  user.GetCommitName(),
+		AuthorEmail: user.GetCommitEmail(),
+		RepoDetails: repoDetails,
 	}
 }
 
@@ -254,6 +452,59 @@ func makeNoteSearchResponsePayload(gitFiles []github.GitFile, total int) NoteSea
 	}
 }
 
+// writeNoteHTML renders the note to html & writes it as a http response.
+// It returns false when the rendering fails & the request is aborted.
+func writeNoteHTML(c *gin.Context, renderer markdown.Renderer, gitFile github.GitFile, opts markdown.Options) bool {
+	html, err := renderer.Render([]byte(gitFile.Content), opts)
+	if err != nil {
+		logrus.WithField("note_path", gitFile.Path).Errorf("rendering note failed")
+		abortRequestWithError(c, err)
+		return false
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(html))
+	return true
+}
+
+// noteRenderOptions returns the render options rewriting the relative links & images of the note to the absolute api urls
+// of the files of the same repo, so that they resolve to the api when the html is embedded in the client.
+// The links to the other notes are rewritten to their html urls & the images of the attachments to the attachment urls.
+// The api urls require the bearer token, the client follows the links & loads the images through the api with its token.
+func noteRenderOptions(c *gin.Context, notePath string, rules github.NoteFileRules, attachmentFolder string) markdown.Options {
+	baseURL := apiBaseURL(c) + "/api/v1"
+	if notebookID := c.Param("notebook"); notebookID != "" {
+		baseURL += "/notebooks/" + notebookID
+	}
+	fileURL := func(path string) string {
+		return baseURL + "/notes/" + url.PathEscape(path)
+	}
+	return markdown.Options{
+		Path: notePath,
+		LinkURL: func(path string) string {
+			if rules.HasNoteExtension(path) {
+				return fileURL(path) + "/html"
+			}
+			return fileURL(path)
+		},
+		ImageURL: func(path string) string {
+			folder := strings.Trim(attachmentFolder, "/")
+			if name := strings.TrimPrefix(path, folder+"/"); folder != "" && name != path && !strings.Contains(name, "/") {
+				return baseURL + "/attachments/" + url.PathEscape(name)
+			}
+			return fileURL(path)
+		},
+	}
+}
+
+// apiBaseURL returns the scheme & host the api is requested with.
+// The scheme of the proxy in front of the api is taken from the X-Forwarded-Proto header.
+func apiBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
 func makeNoteResponsePayload(gitFile github.GitFile) NoteResponsePayload {
 	return NoteResponsePayload{
 		SHA:     gitFile.SHA,
@@ -263,11 +514,3 @@ func makeNoteResponsePayload(gitFile github.GitFile) NoteResponsePayload {
 		IsDir:   gitFile.IsDir,
 	}
 }
-
-func parseOAuth2Token(ghToken string) oauth2.Token {
-	oauth2Token := oauth2.Token{}
-	if err := json.Unmarshal([]byte(ghToken), &oauth2Token); err != nil {
-		logrus.Warn("failed to parse token json to oauth2 token")
-	}
-	return oauth2Token
-}
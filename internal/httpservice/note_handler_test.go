@@ -14,6 +14,8 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/batnoter/batnoter-api/internal/notebook"
 	"github.com/batnoter/batnoter-api/internal/preference"
 	"github.com/batnoter/batnoter-api/internal/user"
 	"github.com/golang/mock/gomock"
@@ -27,6 +29,7 @@ const (
 	content               = "Hello"
 	size                  = 5
 	notePath              = "foo/bar.md"
+	folderPath            = "foo"
 	repository            = "testrepo"
 	visibility            = "private"
 	owner                 = "johndoe"
@@ -37,6 +40,8 @@ const (
 	pageNumber            = 2
 	token                 = "token"
 	internalServerErrJSON = `{"code":"internal_server_error", "message":"something went wrong."}`
+	attachmentFolder      = "attachments"
+	apiURL                = "http://api.example.com"
 )
 
 func TestSearchNotes(t *testing.T) {
@@ -45,10 +50,11 @@ func TestSearchNotes(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
-		fp := github.GitFileProps{SHA: "", Path: notePath, Content: "", AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
+		fp := github.GitFileProps{SHA: "", Path: folderPath, Content: "", AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
 		gitFiles := []github.GitFile{{
 			SHA:     sha,
 			Path:    notePath,
@@ -57,12 +63,13 @@ func TestSearchNotes(t *testing.T) {
 			IsDir:   false,
 		}}
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().SearchFiles(gomock.Any(), getOAuth2Token(u.GithubToken), fp, searchQuery, pageNumber).Return(gitFiles, 1, nil)
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/note", getClaimsHandler(), handler.SearchNotes)
 		response := httptest.NewRecorder()
-		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note?path=%s&query=%s&page=%d", notePath, searchQuery, pageNumber), nil)
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note?path=%s&query=%s&page=%d", folderPath, searchQuery, pageNumber), nil)
 
 		router.ServeHTTP(response, req)
 		assert.Equal(t, http.StatusOK, response.Code)
@@ -74,21 +81,61 @@ func TestSearchNotes(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().SearchFiles(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]github.GitFile{}, 0, errors.New("some error"))
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/note", getClaimsHandler(), handler.SearchNotes)
 		response := httptest.NewRecorder()
-		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note?path=%s&query=%s&page=%d", notePath, searchQuery, pageNumber), nil)
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note?path=%s&query=%s&page=%d", folderPath, searchQuery, pageNumber), nil)
 
 		router.ServeHTTP(response, req)
 		assert.Equal(t, http.StatusInternalServerError, response.Code)
 		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
 	})
+
+	t.Run("should return bad request when the path is not a valid folder path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		router := getRouter()
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		handler := NewNoteHandler(nil, mockUserService, nil, nil, nil, "")
+
+		router.GET("/api/v1/note", getClaimsHandler(), handler.SearchNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/note?path=foo+extension%3Ajs&query=birthday", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"path: must be in a valid format"}`, response.Body.String())
+	})
+}
+
+func TestGetAllNotes(t *testing.T) {
+	t.Run("should return bad request when the path is not a valid folder path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		router := getRouter()
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		handler := NewNoteHandler(nil, mockUserService, nil, nil, nil, "")
+
+		router.GET("/api/v1/notes", getClaimsHandler(), handler.GetAllNotes)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notes?path=foo/../bar", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"path: must be in a valid format"}`, response.Body.String())
+	})
 }
 
 func TestGetNotesTree(t *testing.T) {
@@ -97,14 +144,16 @@ func TestGetNotesTree(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
 		fp := github.GitFileProps{AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
 		gitFiles := validGitFiles()
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().GetTree(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(gitFiles, nil)
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/tree/notes", getClaimsHandler(), handler.GetNotesTree)
 		response := httptest.NewRecorder()
@@ -120,12 +169,14 @@ func TestGetNotesTree(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().GetTree(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/tree/notes", getClaimsHandler(), handler.GetNotesTree)
 		response := httptest.NewRecorder()
@@ -141,10 +192,11 @@ func TestGetNotesTree(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		mockUserService.EXPECT().Get(gomock.Any()).Return(user.User{}, errors.New("some error"))
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/tree/notes", getClaimsHandler(), handler.GetNotesTree)
 		response := httptest.NewRecorder()
@@ -161,14 +213,16 @@ func TestGetNote(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
 		fp := github.GitFileProps{SHA: "", Path: notePath, Content: "", AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
 		f := validGitFile()
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(f, nil)
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/note/:path", getClaimsHandler(), handler.GetNote)
 		response := httptest.NewRecorder()
@@ -179,15 +233,38 @@ func TestGetNote(t *testing.T) {
 		assert.JSONEq(t, fmt.Sprintf(`{"content":"%s", "is_dir":%t, "path":"%s", "sha":"%s", "size":%d}`, f.Content, f.IsDir, f.Path, f.SHA, f.Size), response.Body.String())
 	})
 
+	t.Run("should return internal server error when retrieving github token fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(oauth2.Token{}, errors.New("some error"))
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
+
+		router.GET("/api/v1/note/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+
 	t.Run("should return error when retrieving a note fails due to missing user", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		mockUserService.EXPECT().Get(gomock.Any()).Return(user.User{}, errors.New("some error"))
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/note/:path", getClaimsHandler(), handler.GetNote)
 		response := httptest.NewRecorder()
@@ -203,12 +280,14 @@ func TestGetNote(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
 		mockUserService.EXPECT().Get(gomock.Any()).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().GetFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(github.GitFile{}, errors.New("some error"))
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.GET("/api/v1/note/:path", getClaimsHandler(), handler.GetNote)
 		response := httptest.NewRecorder()
@@ -224,10 +303,12 @@ func TestGetNote(t *testing.T) {
 			t.Run("with invalid path: "+invalidPath, func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				defer ctrl.Finish()
-				handler := NewNoteHandler(nil, nil)
+				mockUserService := user.NewMockService(ctrl)
+				mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+				handler := NewNoteHandler(nil, mockUserService, nil, nil, nil, "")
 
 				router := getRouter()
-				router.GET("/api/v1/note/:path", handler.GetNote)
+				router.GET("/api/v1/note/:path", getClaimsHandler(), handler.GetNote)
 				response := httptest.NewRecorder()
 				req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(invalidPath)), nil)
 
@@ -237,6 +318,324 @@ func TestGetNote(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("should return a note from the repo of the notebook when the notebook is requested", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		fp := github.GitFileProps{SHA: "", Path: notePath, Content: "", AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: github.GitRepoProps{Repository: "work-notes", DefaultBranch: "develop", Owner: owner}}
+		f := validGitFile()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: userID, Name: "Work", Repository: "work-notes", Branch: "develop", Role: notebook.RoleOwner}, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, mockNotebookService, nil, "")
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/notebooks/7/notes/%s", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should access the repo of the notebook with the github app installation of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		u.DefaultRepo.InstallationID = installationID
+		repoDetails := github.GitRepoProps{Repository: "team-notes", DefaultBranch: "main", Owner: "acme", InstallationID: 1111}
+		installationToken := oauth2.Token{AccessToken: "ghs_token", TokenType: "token"}
+		fp := github.GitFileProps{Path: notePath, AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: repoDetails}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: userID, Name: "Team", Owner: "acme",
+			Repository: "team-notes", Branch: "main", InstallationID: 1111, Role: notebook.RoleOwner}, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, repoDetails).Return(installationToken, nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), installationToken, fp).Return(validGitFile(), nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, mockNotebookService, nil, "")
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/notebooks/7/notes/%s", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return a note with the file extension configured for the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		noteFiles := github.NoteFileRules{Extensions: []string{"txt", "org"}}
+		fp := github.GitFileProps{Path: "foo/todo.org", AuthorName: authorName, AuthorEmail: authorEmail,
+			RepoDetails: github.GitRepoProps{Repository: "work-notes", DefaultBranch: "develop", Owner: owner, NoteFiles: noteFiles}}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: userID, Name: "Work", Repository: "work-notes",
+			Branch: "develop", NoteExtensions: "txt org", Role: notebook.RoleOwner}, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(github.GitFile{SHA: sha, Path: "foo/todo.org"}, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, mockNotebookService, nil, "")
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/notebooks/7/notes/%s", url.QueryEscape("foo/todo.org")), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return bad request when the file extension is not configured for the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: userID, Name: "Work", Repository: "work-notes",
+			Branch: "develop", NoteExtensions: "txt org", Role: notebook.RoleOwner}, nil)
+		handler := NewNoteHandler(nil, mockUserService, nil, mockNotebookService, nil, "")
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/notebooks/7/notes/%s", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"path: must be in a valid format"}`, response.Body.String())
+	})
+
+	t.Run("should return a note having underscores, dots & non-ascii letters in the path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		path := "réunions_2022/v1.2 compte rendu.md"
+		fp := github.GitFileProps{Path: path, AuthorName: authorName, AuthorEmail: authorEmail,
+			RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(github.GitFile{SHA: sha, Path: path}, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
+
+		router.GET("/api/v1/note/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note/%s", url.PathEscape(path)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return a note from the repo of the organization when the default repo is owned by an organization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		u.DefaultRepo.Owner = "acme"
+		fp := github.GitFileProps{SHA: "", Path: notePath, Content: "", AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: "acme"}}
+		f := validGitFile()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
+
+		router.GET("/api/v1/note/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return bad request when the notebook does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{}, notebook.ErrNotebookNotFound)
+		handler := NewNoteHandler(nil, mockUserService, nil, mockNotebookService, nil, "")
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/notebooks/7/notes/%s", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"notebook not found"}`, response.Body.String())
+	})
+}
+
+func TestGetNoteHTML(t *testing.T) {
+	t.Run("should return the note rendered to html with the relative links & images rewritten to the api urls", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		f := validGitFile()
+		f.Content = "# Hello\n\n[other](other.md) ![chart](chart.png)\n\n<script>alert(1)</script>\n"
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), gomock.Any()).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, markdown.NewRenderer(), attachmentFolder)
+
+		router.GET("/api/v1/notes/:path/html", getClaimsHandler(), handler.GetNoteHTML)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf(apiURL+"/api/v1/notes/%s/html", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/html; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Body.String(), `<h1 id="hello">Hello</h1>`)
+		assert.Contains(t, response.Body.String(), `<a href="`+apiURL+`/api/v1/notes/foo%2Fother.md/html" rel="nofollow">other</a>`)
+		assert.Contains(t, response.Body.String(), `<img src="`+apiURL+`/api/v1/notes/foo%2Fchart.png" alt="chart">`)
+		assert.NotContains(t, response.Body.String(), "<script>")
+	})
+
+	t.Run("should return the note rendered to html with the images of the attachments rewritten to the attachment urls", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		f := validGitFile()
+		image := strings.Repeat("ab", 32) + ".png"
+		f.Content = "![diagram](../attachments/" + image + ") [spec](../specs/api.md)\n"
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: userID, Repository: "work-notes", Branch: "main", Role: notebook.RoleViewer}, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), gomock.Any()).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, mockNotebookService, markdown.NewRenderer(), attachmentFolder)
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path/html", getClaimsHandler(), handler.GetNoteHTML)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/notebooks/7/notes/%s/html", url.QueryEscape("docs/design.md")), nil)
+		req.Host = "api.example.com"
+		req.Header.Set("X-Forwarded-Proto", "https")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), `<img src="https://api.example.com/api/v1/notebooks/7/attachments/`+image+`" alt="diagram">`)
+		assert.Contains(t, response.Body.String(), `<a href="https://api.example.com/api/v1/notebooks/7/notes/specs%2Fapi.md/html" rel="nofollow">spec</a>`)
+	})
+
+	t.Run("should rewrite the links to the notes having the extensions of the notebook to their html urls", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		f := validGitFile()
+		f.Content = "[todo](todo.org) [readme](readme.md)\n"
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: userID, Repository: "work-notes", Branch: "main",
+			Role: notebook.RoleViewer, NoteExtensions: "org txt"}, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), gomock.Any()).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, mockNotebookService, markdown.NewRenderer(), attachmentFolder)
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path/html", getClaimsHandler(), handler.GetNoteHTML)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf(apiURL+"/api/v1/notebooks/7/notes/%s/html", url.QueryEscape("docs/design.txt")), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), `<a href="`+apiURL+`/api/v1/notebooks/7/notes/docs%2Ftodo.org/html" rel="nofollow">todo</a>`)
+		assert.Contains(t, response.Body.String(), `<a href="`+apiURL+`/api/v1/notebooks/7/notes/docs%2Freadme.md" rel="nofollow">readme</a>`)
+	})
+
+	t.Run("should return the note rendered to html when the client accepts html", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		f := validGitFile()
+		f.Content = "[other](other.md)"
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: userID, Name: "Work", Repository: "work-notes", Branch: "develop", Role: notebook.RoleOwner}, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), getOAuth2Token(u.GithubToken), gomock.Any()).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, mockNotebookService, markdown.NewRenderer(), attachmentFolder)
+
+		router.GET("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.GetNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf(apiURL+"/api/v1/notebooks/7/notes/%s", url.QueryEscape(notePath)), nil)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, `<p><a href="`+apiURL+`/api/v1/notebooks/7/notes/foo%2Fother.md/html" rel="nofollow">other</a></p>`+"\n", response.Body.String())
+	})
+
+	t.Run("should return internal server error when rendering the note fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockRenderer := markdown.NewMockRenderer(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(validGitFile(), nil)
+		mockRenderer.EXPECT().Render([]byte(content), gomock.Any()).Return("", errors.New("some error"))
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, mockRenderer, "")
+
+		router.GET("/api/v1/notes/:path/html", getClaimsHandler(), handler.GetNoteHTML)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/notes/%s/html", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
 }
 
 func TestSaveNote(t *testing.T) {
@@ -245,6 +644,7 @@ func TestSaveNote(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
@@ -255,8 +655,9 @@ func TestSaveNote(t *testing.T) {
 		}
 		noteJSON, _ := json.Marshal(n)
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().SaveFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(f, nil)
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.POST("/api/v1/note/:path", getClaimsHandler(), handler.SaveNote)
 		response := httptest.NewRecorder()
@@ -267,11 +668,39 @@ func TestSaveNote(t *testing.T) {
 		assert.JSONEq(t, fmt.Sprintf(`{"content":"%s", "is_dir":%t, "path":"%s", "sha":"%s", "size":%d}`, f.Content, f.IsDir, f.Path, f.SHA, f.Size), response.Body.String())
 	})
 
+	t.Run("should save the note with the commit identity chosen by the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		u.CommitName = "John at Work"
+		u.CommitEmail = "john.doe@work.example.com"
+		fp := github.GitFileProps{SHA: "", Path: notePath, Content: content, AuthorName: "John at Work", AuthorEmail: "john.doe@work.example.com", RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
+		f := validGitFile()
+		noteJSON, _ := json.Marshal(NoteRequestPayload{Content: content})
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().SaveFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
+
+		router.POST("/api/v1/note/:path", getClaimsHandler(), handler.SaveNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(notePath)), strings.NewReader(string(noteJSON)))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
 	t.Run("should save(update) a new note when the save request payload has the sha value", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
@@ -283,8 +712,9 @@ func TestSaveNote(t *testing.T) {
 		}
 		noteJSON, _ := json.Marshal(n)
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().SaveFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(f, nil)
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.POST("/api/v1/note/:path", getClaimsHandler(), handler.SaveNote)
 		response := httptest.NewRecorder()
@@ -300,6 +730,7 @@ func TestSaveNote(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
@@ -308,8 +739,9 @@ func TestSaveNote(t *testing.T) {
 		}
 		noteJSON, _ := json.Marshal(n)
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().SaveFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(github.GitFile{}, errors.New("some error"))
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.POST("/api/v1/note/:path", getClaimsHandler(), handler.SaveNote)
 		response := httptest.NewRecorder()
@@ -323,7 +755,7 @@ func TestSaveNote(t *testing.T) {
 	t.Run("should return bad request error when save request payload validation fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		handler := NewNoteHandler(nil, nil)
+		handler := NewNoteHandler(nil, nil, nil, nil, nil, "")
 
 		router := getRouter()
 		router.POST("/api/v1/note/:path", handler.SaveNote)
@@ -340,12 +772,14 @@ func TestSaveNote(t *testing.T) {
 			t.Run("with invalid path: "+invalidPath, func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				defer ctrl.Finish()
-				handler := NewNoteHandler(nil, nil)
+				mockUserService := user.NewMockService(ctrl)
+				mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+				handler := NewNoteHandler(nil, mockUserService, nil, nil, nil, "")
 
 				router := getRouter()
-				router.POST("/api/v1/note/:path", handler.SaveNote)
+				router.POST("/api/v1/note/:path", getClaimsHandler(), handler.SaveNote)
 				response := httptest.NewRecorder()
-				req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(invalidPath)), nil)
+				req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(invalidPath)), strings.NewReader(`{"content":"Hello"}`))
 
 				router.ServeHTTP(response, req)
 				assert.Equal(t, http.StatusBadRequest, response.Code)
@@ -353,6 +787,56 @@ func TestSaveNote(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("should save the note of the shared notebook with the token of its creator & the acting member as author", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		creator := user.User{Model: gorm.Model{ID: 2001}, GithubUsername: "janedoe", GithubToken: `{"access_token":"gho_creator"}`}
+		creatorToken := oauth2.Token{AccessToken: "gho_creator"}
+		fp := github.GitFileProps{SHA: "", Path: notePath, Content: content, AuthorName: authorName, AuthorEmail: authorEmail, RepoDetails: github.GitRepoProps{Repository: "team-notes", DefaultBranch: branch, Owner: "janedoe"}}
+		f := validGitFile()
+		noteJSON, _ := json.Marshal(NoteRequestPayload{Content: content})
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: 2001, Name: "Team", Repository: "team-notes", Branch: branch, Role: notebook.RoleEditor}, nil)
+		mockUserService.EXPECT().Get(uint(2001)).Return(creator, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), creator, gomock.Any()).Return(creatorToken, nil)
+		mockGithubService.EXPECT().SaveFile(gomock.Any(), creatorToken, fp).Return(f, nil)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, mockNotebookService, nil, "")
+
+		router.POST("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.SaveNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/notebooks/7/notes/%s", url.QueryEscape(notePath)), strings.NewReader(string(noteJSON)))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return forbidden when the member of the shared notebook is a viewer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		noteJSON, _ := json.Marshal(NoteRequestPayload{Content: content})
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, uint(7)).Return(notebook.Notebook{UserID: 2001, Repository: "team-notes", Role: notebook.RoleViewer}, nil)
+		handler := NewNoteHandler(nil, mockUserService, nil, mockNotebookService, nil, "")
+
+		router.POST("/api/v1/notebooks/:notebook/notes/:path", getClaimsHandler(), handler.SaveNote)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/notebooks/7/notes/%s", url.QueryEscape(notePath)), strings.NewReader(string(noteJSON)))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
 }
 
 func TestDeleteNote(t *testing.T) {
@@ -361,6 +845,7 @@ func TestDeleteNote(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
@@ -370,8 +855,9 @@ func TestDeleteNote(t *testing.T) {
 		}
 		noteJSON, _ := json.Marshal(n)
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().DeleteFile(gomock.Any(), getOAuth2Token(u.GithubToken), fp).Return(nil)
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.DELETE("/api/v1/note/:path", getClaimsHandler(), handler.DeleteNote)
 		response := httptest.NewRecorder()
@@ -387,6 +873,7 @@ func TestDeleteNote(t *testing.T) {
 		defer ctrl.Finish()
 		mockGithubService := github.NewMockService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
@@ -395,8 +882,9 @@ func TestDeleteNote(t *testing.T) {
 		}
 		noteJSON, _ := json.Marshal(n)
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().DeleteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("some error"))
-		handler := NewNoteHandler(mockGithubService, mockUserService)
+		handler := NewNoteHandler(mockGithubService, mockUserService, mockTokenService, nil, nil, "")
 
 		router.DELETE("/api/v1/note/:path", getClaimsHandler(), handler.DeleteNote)
 		response := httptest.NewRecorder()
@@ -410,7 +898,7 @@ func TestDeleteNote(t *testing.T) {
 	t.Run("should return bad request error when delete request payload validation fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		handler := NewNoteHandler(nil, nil)
+		handler := NewNoteHandler(nil, nil, nil, nil, nil, "")
 
 		router := getRouter()
 		router.DELETE("/api/v1/note/:path", handler.DeleteNote)
@@ -427,12 +915,14 @@ func TestDeleteNote(t *testing.T) {
 			t.Run("with invalid path: "+invalidPath, func(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				defer ctrl.Finish()
-				handler := NewNoteHandler(nil, nil)
+				mockUserService := user.NewMockService(ctrl)
+				mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+				handler := NewNoteHandler(nil, mockUserService, nil, nil, nil, "")
 
 				router := getRouter()
-				router.DELETE("/api/v1/note/:path", handler.DeleteNote)
+				router.DELETE("/api/v1/note/:path", getClaimsHandler(), handler.DeleteNote)
 				response := httptest.NewRecorder()
-				req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(invalidPath)), nil)
+				req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/api/v1/note/%s", url.QueryEscape(invalidPath)), strings.NewReader(`{"sha":"5ab2f8a4323abafb10abb68657d9d39f1a775057"}`))
 
 				router.ServeHTTP(response, req)
 				assert.Equal(t, http.StatusBadRequest, response.Code)
@@ -484,7 +974,8 @@ func validGitFiles() []github.GitFile {
 }
 
 func getInvalidNotePaths() []string {
-	return []string{".md", "/", "/foo", "/.md", "/bar.md", "foo", "foo/bar", "foo/.md", "foo/bar.md/foo", "foo/bar.md/foo.md"}
+	return []string{".md", "/", "/foo", "/.md", "/bar.md", "foo", "foo/bar", "foo/.md", "foo/bar.md/foo", "foo/bar.md/foo.md",
+		"foo/.hidden.md", "foo/bar.txt", "foo  bar.md", "foo/bar .md"}
 }
 
 func getRouter() *gin.Engine {
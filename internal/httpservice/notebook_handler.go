@@ -0,0 +1,258 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+)
+
+// NotebookRequestPayload represents the http request payload of notebook entity.
+// Owner is the github organization or user owning the repo, it is empty for the repos of the user's github account.
+// NoteExtensions are the file extensions of the notes without the leading dot & NotePathPattern is the regex the path
+// of the notes (without the extension) must match, the defaults are used when they are not provided.
+type NotebookRequestPayload struct {
+	Name            string   `json:"name"`
+	Owner           string   `json:"owner"`
+	Repository      string   `json:"repository"`
+	Visibility      string   `json:"visibility"`
+	Branch          string   `json:"branch"`
+	NoteExtensions  []string `json:"note_extensions"`
+	NotePathPattern string   `json:"note_path_pattern"`
+}
+
+var noteExtensionRegex = regexp.MustCompile(`^[a-z0-9]{1,10}$`)
+
+// Validate validates the notebook http request payload.
+func (n NotebookRequestPayload) Validate() error {
+	return validation.ValidateStruct(&n,
+		validation.Field(&n.Name, validation.Required, validation.Length(1, 50)),
+		validation.Field(&n.Owner, validation.Length(1, 39)),
+		validation.Field(&n.Repository, validation.Required, validation.Length(1, 100)),
+		validation.Field(&n.Visibility, validation.Required, validation.Length(1, 20)),
+		validation.Field(&n.Branch, validation.Required, validation.Length(1, 255)),
+		validation.Field(&n.NoteExtensions, validation.Length(1, 10), validation.Each(validation.Match(noteExtensionRegex))),
+		validation.Field(&n.NotePathPattern, validation.Length(1, 255), validation.By(validateRegex)),
+	)
+}
+
+// validateRegex checks whether the value is a valid regular expression.
+func validateRegex(value interface{}) error {
+	pattern, _ := value.(string)
+	if _, err := regexp.Compile(pattern); err != nil {
+		return errors.New("must be a valid regular expression")
+	}
+	return nil
+}
+
+// NotebookResponsePayload represents the http response payload of notebook entity.
+// NoteExtensions are the file extensions of the notes including the defaults when the notebook does not configure them.
+type NotebookResponsePayload struct {
+	ID              uint      `json:"id"`
+	Name            string    `json:"name"`
+	Owner           string    `json:"owner,omitempty"`
+	Repository      string    `json:"repository"`
+	Visibility      string    `json:"visibility"`
+	Branch          string    `json:"branch"`
+	NoteExtensions  []string  `json:"note_extensions"`
+	NotePathPattern string    `json:"note_path_pattern,omitempty"`
+	Role            string    `json:"role,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// NotebookHandler represents http handler for managing the notebooks of the user.
+type NotebookHandler struct {
+	notebookService  notebook.Service
+	githubService    github.Service
+	githubAppService github.AppService
+	userService      user.Service
+	tokenService     user.TokenService
+}
+
+// NewNotebookHandler creates and returns a new notebook handler.
+func NewNotebookHandler(notebookService notebook.Service, githubService github.Service, githubAppService github.AppService,
+	userService user.Service, tokenService user.TokenService) *NotebookHandler {
+	return &NotebookHandler{
+		notebookService:  notebookService,
+		githubService:    githubService,
+		githubAppService: githubAppService,
+		userService:      userService,
+		tokenService:     tokenService,
+	}
+}
+
+// GetNotebooks returns all the notebooks of the user including the notebooks shared with the user.
+func (n *NotebookHandler) GetNotebooks(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	notebooks, err := n.notebookService.GetAll(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	notebooksResp := make([]NotebookResponsePayload, 0, len(notebooks))
+	for _, nb := range notebooks {
+		notebooksResp = append(notebooksResp, notebookResponse(nb))
+	}
+	c.JSON(http.StatusOK, notebooksResp)
+}
+
+// GetNotebook returns the notebook of the user or the notebook shared with the user with requested id.
+func (n *NotebookHandler) GetNotebook(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	notebookID, err := strconv.ParseUint(c.Param("notebook"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid notebook id"))
+		return
+	}
+	nb, err := n.notebookService.GetAccessible(userID, uint(notebookID))
+	if errors.Is(err, notebook.ErrNotebookNotFound) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, notebookResponse(nb))
+}
+
+// CreateNotebook creates a new notebook linking the requested repo & branch.
+func (n *NotebookHandler) CreateNotebook(c *gin.Context) {
+	n.saveNotebook(c, 0)
+}
+
+// UpdateNotebook updates the name, repo & branch of the notebook with requested id.
+func (n *NotebookHandler) UpdateNotebook(c *gin.Context) {
+	notebookID, err := strconv.ParseUint(c.Param("notebook"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid notebook id"))
+		return
+	}
+	n.saveNotebook(c, uint(notebookID))
+}
+
+// DeleteNotebook deletes the notebook with requested id. The notes in the repo of the notebook are not deleted.
+func (n *NotebookHandler) DeleteNotebook(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	notebookID, err := strconv.ParseUint(c.Param("notebook"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid notebook id"))
+		return
+	}
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).Info("request to delete notebook started")
+	err = n.notebookService.Delete(userID, uint(notebookID))
+	if errors.Is(err, notebook.ErrNotebookNotFound) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "notebook not found"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).Info("request to delete notebook successful")
+}
+
+// saveNotebook creates the notebook when the notebook id is zero, otherwise updates the notebook with the id.
+func (n *NotebookHandler) saveNotebook(c *gin.Context, notebookID uint) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	var notebookPayload NotebookRequestPayload
+	c.BindJSON(&notebookPayload)
+	if err := notebookPayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+
+	logrus.WithField("user-id", userID).WithField("notebook-id", notebookID).Infof("request to save notebook: %s", notebookPayload.Name)
+	owner := ""
+	installationID := int64(0)
+	appEnabled := n.githubAppService.Enabled()
+	if appEnabled || notebookPayload.Owner != "" {
+		u, err := n.userService.Get(userID)
+		if err != nil {
+			abortRequestWithError(c, err)
+			return
+		}
+		if appEnabled {
+			// the repo is accessed with the token of the installation on the account owning the repo
+			owner, installationID, err = resolveInstallationRepo(c, n.githubAppService, n.tokenService, u, notebookPayload.Owner, notebookPayload.Repository)
+		} else {
+			owner, err = resolveRepoOwner(c, n.githubService, n.tokenService, u, notebookPayload.Owner, notebookPayload.Repository)
+		}
+		if err != nil {
+			abortRequestWithError(c, err)
+			return
+		}
+	}
+	nb := notebook.Notebook{
+		UserID:          userID,
+		Name:            notebookPayload.Name,
+		Owner:           owner,
+		Repository:      notebookPayload.Repository,
+		Visibility:      notebookPayload.Visibility,
+		Branch:          notebookPayload.Branch,
+		NoteExtensions:  strings.Join(notebookPayload.NoteExtensions, " "),
+		NotePathPattern: notebookPayload.NotePathPattern,
+		InstallationID:  installationID,
+	}
+	nb.ID = notebookID
+	nb, err = n.notebookService.Save(nb)
+	if errors.Is(err, notebook.ErrNotebookNotFound) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "notebook not found"))
+		return
+	}
+	if errors.Is(err, notebook.ErrNotebookNameTaken) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "notebook name is already taken"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, notebookResponse(nb))
+	logrus.WithField("user-id", userID).WithField("notebook-id", nb.ID).Info("request to save notebook successful")
+}
+
+func notebookResponse(nb notebook.Notebook) NotebookResponsePayload {
+	return NotebookResponsePayload{
+		ID:              nb.ID,
+		Name:            nb.Name,
+		Owner:           nb.Owner,
+		Repository:      nb.Repository,
+		Visibility:      nb.Visibility,
+		Branch:          nb.Branch,
+		NoteExtensions:  noteFileRules(nb).GetExtensions(),
+		NotePathPattern: nb.NotePathPattern,
+		Role:            nb.Role,
+		CreatedAt:       nb.CreatedAt,
+	}
+}
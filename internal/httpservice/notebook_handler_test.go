@@ -0,0 +1,428 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const notebookID = uint(7)
+
+func TestGetNotebooks(t *testing.T) {
+	t.Run("should return all the notebooks of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, nil, nil, nil)
+		mockNotebookService.EXPECT().GetAll(userID).Return([]notebook.Notebook{validNotebook()}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/notebooks", getClaimsHandler(), handler.GetNotebooks)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"id":7,"name":"Work","repository":"work-notes","visibility":"private","branch":"main",
+			"note_extensions":["md"],"created_at":"2022-10-18T10:00:00Z"}]`, response.Body.String())
+	})
+
+	t.Run("should fail with internal server error when retrieving notebooks fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, nil, nil, nil)
+		mockNotebookService.EXPECT().GetAll(userID).Return(nil, errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/notebooks", getClaimsHandler(), handler.GetNotebooks)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func TestGetNotebook(t *testing.T) {
+	t.Run("should return the notebook with requested id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, nil, nil, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(validNotebook(), nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/notebooks/:notebook", getClaimsHandler(), handler.GetNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/7", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with not found when the notebook does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, nil, nil, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(notebook.Notebook{}, notebook.ErrNotebookNotFound)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/notebooks/:notebook", getClaimsHandler(), handler.GetNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/7", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should fail with bad request when the notebook id is invalid", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(nil, nil, nil, nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/notebooks/:notebook", getClaimsHandler(), handler.GetNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notebooks/work", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"invalid notebook id"}`, response.Body.String())
+	})
+}
+
+func TestCreateNotebook(t *testing.T) {
+	t.Run("should create the notebook when the request payload is valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, mockGithubAppService, nil, nil)
+		nb := validNotebook()
+		mockGithubAppService.EXPECT().Enabled().Return(false)
+		mockNotebookService.EXPECT().Save(notebook.Notebook{UserID: userID, Name: "Work", Repository: "work-notes", Visibility: "private", Branch: "main"}).Return(nb, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","repository":"work-notes","visibility":"private","branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"id":7,"name":"Work","repository":"work-notes","visibility":"private","branch":"main",
+			"note_extensions":["md"],"created_at":"2022-10-18T10:00:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should create the notebook in the repo of an organization when the user can write to it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
+		u := validUser()
+		nb := validNotebook()
+		nb.Owner = "acme"
+		mockGithubAppService.EXPECT().Enabled().Return(false)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetRepo(gomock.Any(), getOAuth2Token(u.GithubToken), "acme", "work-notes").
+			Return(github.GitRepo{Name: "work-notes", Owner: "acme", Writable: true}, nil)
+		mockNotebookService.EXPECT().Save(notebook.Notebook{UserID: userID, Name: "Work", Owner: "acme", Repository: "work-notes", Visibility: "private", Branch: "main"}).Return(nb, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","owner":"acme","repository":"work-notes","visibility":"private","branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"id":7,"name":"Work","owner":"acme","repository":"work-notes","visibility":"private","branch":"main",
+			"note_extensions":["md"],"created_at":"2022-10-18T10:00:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should create the notebook with the github app installation on the account owning the repo when the app is enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, mockGithubAppService, mockUserService, mockTokenService)
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		nb := validNotebook()
+		nb.Owner = "acme"
+		mockGithubAppService.EXPECT().Enabled().Return(true)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(ghToken, nil)
+		mockGithubAppService.EXPECT().FindInstallation(gomock.Any(), ghToken, "acme").Return(int64(1111), nil)
+		mockGithubAppService.EXPECT().GetInstallationRepos(gomock.Any(), ghToken, int64(1111)).
+			Return([]github.GitRepo{{Name: "work-notes", Owner: "acme", Writable: true}}, nil)
+		mockNotebookService.EXPECT().Save(notebook.Notebook{UserID: userID, Name: "Work", Owner: "acme", Repository: "work-notes", Visibility: "private", Branch: "main",
+			InstallationID: 1111}).Return(nb, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","owner":"acme","repository":"work-notes","visibility":"private","branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with forbidden when the github app is not installed on the account owning the repo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(nil, nil, mockGithubAppService, mockUserService, mockTokenService)
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		mockGithubAppService.EXPECT().Enabled().Return(true)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(ghToken, nil)
+		mockGithubAppService.EXPECT().FindInstallation(gomock.Any(), ghToken, "acme").Return(int64(0), github.ErrInstallationRequired)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","owner":"acme","repository":"work-notes","visibility":"private","branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("should fail with bad request when the notebook name is already taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, mockGithubAppService, nil, nil)
+		mockGithubAppService.EXPECT().Enabled().Return(false)
+		mockNotebookService.EXPECT().Save(gomock.Any()).Return(notebook.Notebook{}, notebook.ErrNotebookNameTaken)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","repository":"work-notes","visibility":"private","branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"notebook name is already taken"}`, response.Body.String())
+	})
+
+	t.Run("should create the notebook with the note file extensions & path pattern", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, mockGithubAppService, nil, nil)
+		nb := validNotebook()
+		nb.NoteExtensions = "md txt org"
+		nb.NotePathPattern = `notes/[^/]+`
+		mockGithubAppService.EXPECT().Enabled().Return(false)
+		mockNotebookService.EXPECT().Save(notebook.Notebook{UserID: userID, Name: "Work", Repository: "work-notes", Visibility: "private", Branch: "main",
+			NoteExtensions: "md txt org", NotePathPattern: `notes/[^/]+`}).Return(nb, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","repository":"work-notes","visibility":"private",
+			"branch":"main","note_extensions":["md","txt","org"],"note_path_pattern":"notes/[^/]+"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"id":7,"name":"Work","repository":"work-notes","visibility":"private","branch":"main",
+			"note_extensions":["md","txt","org"],"note_path_pattern":"notes/[^/]+","created_at":"2022-10-18T10:00:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when the note file extensions or path pattern are invalid", func(t *testing.T) {
+		tests := []struct {
+			payload string
+			message string
+		}{
+			{`"note_extensions":[".md"]`, "note_extensions: (0: must be in a valid format.)."},
+			{`"note_extensions":["md","Markdown-Notes"]`, "note_extensions: (1: must be in a valid format.)."},
+			{`"note_path_pattern":"notes/[a-z"`, "note_path_pattern: must be a valid regular expression."},
+		}
+		for _, test := range tests {
+			gin.SetMode(gin.TestMode)
+			router := gin.Default()
+			handler := NewNotebookHandler(nil, nil, nil, nil, nil)
+
+			// simulate auth middleware with custom handler
+			router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+			response := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","repository":"work-notes","visibility":"private",
+				"branch":"main",`+test.payload+`}`))
+
+			router.ServeHTTP(response, req)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.JSONEq(t, `{"code":"validation_failed", "message":"`+test.message+`"}`, response.Body.String())
+		}
+	})
+
+	t.Run("should fail with bad request when the request payload is invalid", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(nil, nil, nil, nil, nil)
+
+		// simulate auth middleware with custom handler
+		router.POST("/api/v1/notebooks", getClaimsHandler(), handler.CreateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks", strings.NewReader(`{"name":"Work","visibility":"private","branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"repository: cannot be blank."}`, response.Body.String())
+	})
+}
+
+func TestUpdateNotebook(t *testing.T) {
+	t.Run("should update the notebook with requested id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, mockGithubAppService, nil, nil)
+		nb := validNotebook()
+		nb.Branch = "develop"
+		expected := notebook.Notebook{UserID: userID, Name: "Work", Repository: "work-notes", Visibility: "private", Branch: "develop"}
+		expected.ID = notebookID
+		mockGithubAppService.EXPECT().Enabled().Return(false)
+		mockNotebookService.EXPECT().Save(expected).Return(nb, nil)
+
+		// simulate auth middleware with custom handler
+		router.PUT("/api/v1/notebooks/:notebook", getClaimsHandler(), handler.UpdateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/notebooks/7", strings.NewReader(`{"name":"Work","repository":"work-notes","visibility":"private","branch":"develop"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the notebook does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, mockGithubAppService, nil, nil)
+		mockGithubAppService.EXPECT().Enabled().Return(false)
+		mockNotebookService.EXPECT().Save(gomock.Any()).Return(notebook.Notebook{}, notebook.ErrNotebookNotFound)
+
+		// simulate auth middleware with custom handler
+		router.PUT("/api/v1/notebooks/:notebook", getClaimsHandler(), handler.UpdateNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPut, "/api/v1/notebooks/7", strings.NewReader(`{"name":"Work","repository":"work-notes","visibility":"private","branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"notebook not found"}`, response.Body.String())
+	})
+}
+
+func TestDeleteNotebook(t *testing.T) {
+	t.Run("should delete the notebook with requested id", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, nil, nil, nil)
+		mockNotebookService.EXPECT().Delete(userID, notebookID).Return(nil)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/notebooks/:notebook", getClaimsHandler(), handler.DeleteNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/notebooks/7", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the notebook does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewNotebookHandler(mockNotebookService, nil, nil, nil, nil)
+		mockNotebookService.EXPECT().Delete(userID, notebookID).Return(notebook.ErrNotebookNotFound)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/notebooks/:notebook", getClaimsHandler(), handler.DeleteNotebook)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/notebooks/7", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"notebook not found"}`, response.Body.String())
+	})
+}
+
+func validNotebook() notebook.Notebook {
+	return notebook.Notebook{
+		Model:      gorm.Model{ID: notebookID, CreatedAt: time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)},
+		UserID:     userID,
+		Name:       "Work",
+		Repository: "work-notes",
+		Visibility: "private",
+		Branch:     "main",
+	}
+}
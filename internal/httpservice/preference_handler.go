@@ -1,8 +1,10 @@
 package httpservice
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/batnoter/batnoter-api/internal/github"
@@ -13,8 +15,10 @@ import (
 )
 
 // RepoPayload represents the http request/response payload of repository entity.
+// Owner is the github organization or user owning the repo, it is empty for the repos of the user's github account.
 type RepoPayload struct {
 	Name          string `json:"name"`
+	Owner         string `json:"owner,omitempty"`
 	Visibility    string `json:"visibility"`
 	DefaultBranch string `json:"default_branch"`
 }
@@ -23,6 +27,7 @@ type RepoPayload struct {
 func (r RepoPayload) Validate() error {
 	return validation.ValidateStruct(&r,
 		validation.Field(&r.Name, validation.Required, validation.Length(1, 50)),
+		validation.Field(&r.Owner, validation.Length(1, 39)),
 		validation.Field(&r.Visibility, validation.Required, validation.Length(1, 20)),
 	)
 }
@@ -31,15 +36,20 @@ func (r RepoPayload) Validate() error {
 type PreferenceHandler struct {
 	preferenceService preference.Service
 	githubService     github.Service
+	githubAppService  github.AppService
 	userService       user.Service
+	tokenService      user.TokenService
 }
 
 // NewPreferenceHandler creates and returns a new preference handler.
-func NewPreferenceHandler(preferenceService preference.Service, githubService github.Service, userService user.Service) *PreferenceHandler {
+func NewPreferenceHandler(preferenceService preference.Service, githubService github.Service, githubAppService github.AppService,
+	userService user.Service, tokenService user.TokenService) *PreferenceHandler {
 	return &PreferenceHandler{
 		preferenceService: preferenceService,
 		githubService:     githubService,
+		githubAppService:  githubAppService,
 		userService:       userService,
+		tokenService:      tokenService,
 	}
 }
 
@@ -52,7 +62,19 @@ func (p *PreferenceHandler) GetRepos(c *gin.Context) {
 		return
 	}
 	logrus.WithField("user-id", user.ID).Info("request to retrieve repos started")
-	gitRepos, err := p.githubService.GetRepos(c, parseOAuth2Token(user.GithubToken))
+	ghToken, err := p.tokenService.GetGithubToken(c, user)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	var gitRepos []github.GitRepo
+	if user.DefaultRepo != nil && user.DefaultRepo.InstallationID != 0 {
+		// only the repos granted to the github app installation can be used as notes repo
+		gitRepos, err = p.githubAppService.GetInstallationRepos(c, ghToken, user.DefaultRepo.InstallationID)
+	} else {
+		gitRepos, err = p.githubService.GetRepos(c, ghToken)
+	}
 	if err != nil {
 		logrus.Errorf("retrieving repos from github failed")
 		abortRequestWithError(c, err)
@@ -63,6 +85,7 @@ func (p *PreferenceHandler) GetRepos(c *gin.Context) {
 	for _, gitRepo := range gitRepos {
 		repo := RepoPayload{
 			Name:          gitRepo.Name,
+			Owner:         gitRepo.Owner,
 			Visibility:    gitRepo.Visibility,
 			DefaultBranch: gitRepo.DefaultBranch,
 		}
@@ -90,6 +113,25 @@ func (p *PreferenceHandler) SaveDefaultRepo(c *gin.Context) {
 	}
 
 	logrus.WithField("user-id", userID).Infof("request to link default repo: %s", repoPayload.Name)
+	owner := ""
+	installationID := int64(0)
+	if p.githubAppService.Enabled() || repoPayload.Owner != "" {
+		u, err := p.userService.Get(userID)
+		if err != nil {
+			abortRequestWithError(c, err)
+			return
+		}
+		if p.githubAppService.Enabled() {
+			// the repo is accessed with the token of the installation on the account owning the repo
+			owner, installationID, err = resolveInstallationRepo(c, p.githubAppService, p.tokenService, u, repoPayload.Owner, repoPayload.Name)
+		} else {
+			owner, err = resolveRepoOwner(c, p.githubService, p.tokenService, u, repoPayload.Owner, repoPayload.Name)
+		}
+		if err != nil {
+			abortRequestWithError(c, err)
+			return
+		}
+	}
 	dbDefaultRepo, err := p.preferenceService.GetByUserID(userID)
 	if err != nil {
 		logrus.Errorf("retrieving user's default repo failed")
@@ -99,8 +141,12 @@ func (p *PreferenceHandler) SaveDefaultRepo(c *gin.Context) {
 
 	dbDefaultRepo.UserID = userID
 	dbDefaultRepo.Name = repoPayload.Name
+	dbDefaultRepo.Owner = owner
 	dbDefaultRepo.Visibility = repoPayload.Visibility
 	dbDefaultRepo.DefaultBranch = repoPayload.DefaultBranch
+	if p.githubAppService.Enabled() {
+		dbDefaultRepo.InstallationID = installationID
+	}
 
 	if err := p.preferenceService.Save(dbDefaultRepo); err != nil {
 		logrus.Errorf("saving user's default repo failed")
@@ -112,8 +158,10 @@ func (p *PreferenceHandler) SaveDefaultRepo(c *gin.Context) {
 }
 
 // AutoSetupRepo creates a new notes repo in user's github account and stores it as user's default repo preference.
+// The repo is created under the organization when the org query param is provided.
 func (p *PreferenceHandler) AutoSetupRepo(c *gin.Context) {
 	repoName := c.Query("repoName")
+	org := c.Query("org")
 	if err := validation.Validate(repoName, validation.Required); err != nil {
 		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, fmt.Sprintf("repoName: %s", err.Error())))
 		return
@@ -126,21 +174,39 @@ func (p *PreferenceHandler) AutoSetupRepo(c *gin.Context) {
 	}
 
 	logrus.WithField("user-id", user.ID).Infof("request to auto setup default repo")
-	gitRepo, err := p.githubService.CreateRepo(c, parseOAuth2Token(user.GithubToken), repoName)
+	if p.githubAppService.Enabled() {
+		// the oauth2 token does not have the repo scope & the installation tokens can not create the repos of the user
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest,
+			"auto setup is not available with the github app, create the repo on github & grant the app access to it"))
+		return
+	}
+	ghToken, err := p.tokenService.GetGithubToken(c, user)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	dbDefaultRepo, err := p.preferenceService.GetByUserID(user.ID)
+	if err != nil {
+		logrus.Errorf("retrieving user's default repo failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	gitRepo, err := p.githubService.CreateRepo(c, ghToken, org, repoName)
 	if err != nil {
 		logrus.Errorf("creating a new notes repo in github failed")
 		abortRequestWithError(c, err)
 		return
 	}
 
-	defaultRepo := preference.DefaultRepo{
-		UserID:        user.ID,
-		Name:          gitRepo.Name,
-		Visibility:    gitRepo.Visibility,
-		DefaultBranch: gitRepo.DefaultBranch,
-	}
+	// the existing record is updated so that it is not duplicated
+	dbDefaultRepo.UserID = user.ID
+	dbDefaultRepo.Name = gitRepo.Name
+	dbDefaultRepo.Owner = org
+	dbDefaultRepo.Visibility = gitRepo.Visibility
+	dbDefaultRepo.DefaultBranch = gitRepo.DefaultBranch
 
-	if err := p.preferenceService.Save(defaultRepo); err != nil {
+	if err := p.preferenceService.Save(dbDefaultRepo); err != nil {
 		logrus.Errorf("saving user's default repo failed")
 		abortRequestWithError(c, err)
 		return
@@ -157,3 +223,63 @@ func (p *PreferenceHandler) getUser(c *gin.Context) (user.User, error) {
 	}
 	return p.userService.Get(userID)
 }
+
+// resolveRepoOwner returns the owner to be stored along with the notes repo of the user.
+// The owner is empty when the repo is owned by the user's github account. Otherwise the repo is fetched
+// from github to verify that the user can push the notes to it.
+func resolveRepoOwner(c *gin.Context, githubService github.Service, tokenService user.TokenService, u user.User,
+	owner string, repoName string) (string, error) {
+	if owner == "" || strings.EqualFold(owner, u.GithubUsername) {
+		return "", nil
+	}
+	ghToken, err := tokenService.GetGithubToken(c, u)
+	if err != nil {
+		return "", err
+	}
+	gitRepo, err := githubService.GetRepo(c, ghToken, owner, repoName)
+	if errors.Is(err, github.ErrRepoNotFound) {
+		return "", NewAppError(ErrorCodeInvalidRequest, "repo not found")
+	}
+	if err != nil {
+		return "", err
+	}
+	if !gitRepo.Writable {
+		return "", NewAppError(ErrorCodeInvalidRequest, "write permission to the repo is required")
+	}
+	return gitRepo.Owner, nil
+}
+
+// resolveInstallationRepo returns the owner to be stored along with the repo & the id of the github app installation
+// used to access the repo. The app must be installed on the account owning the repo, the repo must be granted to the
+// installation & writable by the user. github.ErrInstallationRequired is returned when the app is not installed.
+func resolveInstallationRepo(c *gin.Context, githubAppService github.AppService, tokenService user.TokenService, u user.User,
+	owner string, repoName string) (string, int64, error) {
+	if owner == "" {
+		owner = u.GithubUsername
+	}
+	ghToken, err := tokenService.GetGithubToken(c, u)
+	if err != nil {
+		return "", 0, err
+	}
+	installationID, err := githubAppService.FindInstallation(c, ghToken, owner)
+	if err != nil {
+		return "", 0, err
+	}
+	gitRepos, err := githubAppService.GetInstallationRepos(c, ghToken, installationID)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, gitRepo := range gitRepos {
+		if !strings.EqualFold(gitRepo.Owner, owner) || !strings.EqualFold(gitRepo.Name, repoName) {
+			continue
+		}
+		if !gitRepo.Writable {
+			return "", 0, NewAppError(ErrorCodeInvalidRequest, "write permission to the repo is required")
+		}
+		if strings.EqualFold(gitRepo.Owner, u.GithubUsername) {
+			return "", installationID, nil
+		}
+		return gitRepo.Owner, installationID, nil
+	}
+	return "", 0, NewAppError(ErrorCodeInvalidRequest, "repo is not granted to the github app installation")
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/batnoter/batnoter-api/internal/user"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestGetRepos(t *testing.T) {
@@ -21,7 +22,9 @@ func TestGetRepos(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
@@ -34,9 +37,10 @@ func TestGetRepos(t *testing.T) {
 			Visibility:    visibility,
 			DefaultBranch: branch + "2",
 		}}
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().GetRepos(gomock.Any(), getOAuth2Token(u.GithubToken)).Return(repos, nil)
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.GET("/api/v1/user/preference/repo", getClaimsHandler(), handler.GetRepos)
 		response := httptest.NewRecorder()
@@ -47,18 +51,52 @@ func TestGetRepos(t *testing.T) {
 		assert.JSONEq(t, `[{"default_branch":"main", "name":"testrepo", "visibility":"private"},{"default_branch":"main2", "name":"testrepo2", "visibility":"private"}]`, response.Body.String())
 	})
 
+	t.Run("should return repos of github app installation when the user has linked an installation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		u.DefaultRepo.InstallationID = installationID
+		repos := []github.GitRepo{{
+			Name:          repository,
+			Visibility:    visibility,
+			DefaultBranch: branch,
+		}}
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubAppService.EXPECT().GetInstallationRepos(gomock.Any(), getOAuth2Token(u.GithubToken), installationID).Return(repos, nil)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.GET("/api/v1/user/preference/repo", getClaimsHandler(), handler.GetRepos)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/preference/repo", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"default_branch":"main", "name":"testrepo", "visibility":"private"}]`, response.Body.String())
+	})
+
 	t.Run("should return internal server error when fatching repos fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
 		mockGithubService.EXPECT().GetRepos(gomock.Any(), getOAuth2Token(u.GithubToken)).Return([]github.GitRepo{}, errors.New("some error"))
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.GET("/api/v1/user/preference/repo", getClaimsHandler(), handler.GetRepos)
 		response := httptest.NewRecorder()
@@ -74,11 +112,13 @@ func TestGetRepos(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		mockUserService.EXPECT().Get(userID).Return(user.User{}, errors.New("some error"))
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.GET("/api/v1/user/preference/repo", getClaimsHandler(), handler.GetRepos)
 		response := httptest.NewRecorder()
@@ -96,7 +136,10 @@ func TestSaveDefaultRepo(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		repoPayload := fmt.Sprintf(`{
@@ -112,7 +155,48 @@ func TestSaveDefaultRepo(t *testing.T) {
 		}
 		mockPreferenceService.EXPECT().GetByUserID(userID).Return(dbDefaultRepo, nil)
 		mockPreferenceService.EXPECT().Save(dbDefaultRepo).Return(nil)
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/preference/repo", strings.NewReader(repoPayload))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should save the repo of an organization as default repo when the user can write to it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		repoPayload := fmt.Sprintf(`{
+			"name":"%s",
+			"owner":"acme",
+			"visibility":"%s",
+			"default_branch":"%s"
+		}`, repository, visibility, branch)
+		dbDefaultRepo := preference.DefaultRepo{
+			UserID:        userID,
+			Name:          repository,
+			Owner:         "acme",
+			Visibility:    visibility,
+			DefaultBranch: branch,
+		}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetRepo(gomock.Any(), getOAuth2Token(u.GithubToken), "acme", repository).
+			Return(github.GitRepo{Name: repository, Owner: "acme", Writable: true}, nil)
+		mockPreferenceService.EXPECT().GetByUserID(userID).Return(preference.DefaultRepo{}, nil)
+		mockPreferenceService.EXPECT().Save(dbDefaultRepo).Return(nil)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
 		response := httptest.NewRecorder()
@@ -122,12 +206,70 @@ func TestSaveDefaultRepo(t *testing.T) {
 		assert.Equal(t, http.StatusOK, response.Code)
 	})
 
+	t.Run("should return bad request error when the user can not write to the repo of the owner", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		repoPayload := fmt.Sprintf(`{"name":"%s", "owner":"janedoe", "visibility":"%s", "default_branch":"%s"}`, repository, visibility, branch)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetRepo(gomock.Any(), getOAuth2Token(u.GithubToken), "janedoe", repository).
+			Return(github.GitRepo{Name: repository, Owner: "janedoe", Writable: false}, nil)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/preference/repo", strings.NewReader(repoPayload))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"write permission to the repo is required"}`, response.Body.String())
+	})
+
+	t.Run("should return bad request error when the repo of the owner does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		repoPayload := fmt.Sprintf(`{"name":"%s", "owner":"acme", "visibility":"%s", "default_branch":"%s"}`, repository, visibility, branch)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetRepo(gomock.Any(), getOAuth2Token(u.GithubToken), "acme", repository).Return(github.GitRepo{}, github.ErrRepoNotFound)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/preference/repo", strings.NewReader(repoPayload))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"repo not found"}`, response.Body.String())
+	})
+
 	t.Run("should return internal server error when saving default repo fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		repoPayload := fmt.Sprintf(`{
@@ -143,7 +285,7 @@ func TestSaveDefaultRepo(t *testing.T) {
 		}
 		mockPreferenceService.EXPECT().GetByUserID(userID).Return(dbDefaultRepo, nil)
 		mockPreferenceService.EXPECT().Save(dbDefaultRepo).Return(errors.New("some error"))
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
 		response := httptest.NewRecorder()
@@ -159,7 +301,10 @@ func TestSaveDefaultRepo(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		repoPayload := fmt.Sprintf(`{
@@ -168,7 +313,7 @@ func TestSaveDefaultRepo(t *testing.T) {
 			"default_branch":"%s"
 		}`, repository, visibility, branch)
 		mockPreferenceService.EXPECT().GetByUserID(userID).Return(preference.DefaultRepo{}, errors.New("some error"))
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
 		response := httptest.NewRecorder()
@@ -184,14 +329,17 @@ func TestSaveDefaultRepo(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		repoPayload := fmt.Sprintf(`{
 			"visibility":"%s",
 			"default_branch":"%s"
 		}`, visibility, branch)
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
 		response := httptest.NewRecorder()
@@ -207,7 +355,10 @@ func TestSaveDefaultRepo(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		repoPayload := fmt.Sprintf(`{
@@ -215,7 +366,7 @@ func TestSaveDefaultRepo(t *testing.T) {
 			"visibility":"%s",
 			"default_branch":"%s"
 		}`, repository, visibility, branch)
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo", handler.SaveDefaultRepo)
 		response := httptest.NewRecorder()
@@ -225,6 +376,90 @@ func TestSaveDefaultRepo(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, response.Code)
 		assert.Equal(t, "", response.Body.String())
 	})
+
+	t.Run("should save the repo along with the github app installation on the account owning the repo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(true).AnyTimes()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		u.DefaultRepo = &preference.DefaultRepo{Model: gorm.Model{ID: 3}, UserID: userID, InstallationID: 77}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubAppService.EXPECT().FindInstallation(gomock.Any(), getOAuth2Token(u.GithubToken), "acme").Return(int64(88), nil)
+		mockGithubAppService.EXPECT().GetInstallationRepos(gomock.Any(), getOAuth2Token(u.GithubToken), int64(88)).
+			Return([]github.GitRepo{{Name: "other", Owner: "acme", Writable: true}, {Name: repository, Owner: "acme", Writable: true}}, nil)
+		mockPreferenceService.EXPECT().GetByUserID(userID).Return(*u.DefaultRepo, nil)
+		mockPreferenceService.EXPECT().Save(preference.DefaultRepo{Model: gorm.Model{ID: 3}, UserID: userID, Name: repository, Owner: "acme",
+			Visibility: visibility, DefaultBranch: branch, InstallationID: 88}).Return(nil)
+		handler := NewPreferenceHandler(mockPreferenceService, nil, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/preference/repo",
+			strings.NewReader(`{"name":"testrepo","owner":"acme","visibility":"private","default_branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return bad request error when the repo is not granted to the github app installation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(true).AnyTimes()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		u.DefaultRepo = &preference.DefaultRepo{UserID: userID, InstallationID: 77}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubAppService.EXPECT().FindInstallation(gomock.Any(), getOAuth2Token(u.GithubToken), u.GithubUsername).Return(int64(77), nil)
+		mockGithubAppService.EXPECT().GetInstallationRepos(gomock.Any(), getOAuth2Token(u.GithubToken), int64(77)).
+			Return([]github.GitRepo{{Name: "other", Owner: owner, Writable: true}}, nil)
+		handler := NewPreferenceHandler(nil, nil, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/preference/repo",
+			strings.NewReader(`{"name":"testrepo","visibility":"private","default_branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"repo is not granted to the github app installation"}`, response.Body.String())
+	})
+
+	t.Run("should return installation required error when the github app is enabled but not installed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(true).AnyTimes()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubAppService.EXPECT().FindInstallation(gomock.Any(), getOAuth2Token(u.GithubToken), u.GithubUsername).Return(int64(0), github.ErrInstallationRequired)
+		handler := NewPreferenceHandler(nil, nil, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.POST("/api/v1/user/preference/repo", getClaimsHandler(), handler.SaveDefaultRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/user/preference/repo",
+			strings.NewReader(`{"name":"testrepo","visibility":"private","default_branch":"main"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		assert.JSONEq(t, `{"code":"installation_required", "message":"github app installation is required to access the repo"}`, response.Body.String())
+	})
 }
 
 func TestAutoSetupRepo(t *testing.T) {
@@ -233,24 +468,31 @@ func TestAutoSetupRepo(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
+		existingRepo := preference.DefaultRepo{Model: gorm.Model{ID: 3}, UserID: userID, Name: "old-notes"}
 		dbDefaultRepo := preference.DefaultRepo{
+			Model:         gorm.Model{ID: 3},
 			UserID:        userID,
 			Name:          repository,
 			Visibility:    visibility,
 			DefaultBranch: branch,
 		}
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
-		mockGithubService.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), repository).Return(github.GitRepo{
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockPreferenceService.EXPECT().GetByUserID(userID).Return(existingRepo, nil)
+		mockGithubService.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), "", repository).Return(github.GitRepo{
 			Name:          repository,
 			Visibility:    visibility,
 			DefaultBranch: branch,
 		}, nil)
 		mockPreferenceService.EXPECT().Save(dbDefaultRepo).Return(nil)
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo/auto", getClaimsHandler(), handler.AutoSetupRepo)
 		response := httptest.NewRecorder()
@@ -260,15 +502,58 @@ func TestAutoSetupRepo(t *testing.T) {
 		assert.Equal(t, http.StatusOK, response.Code)
 	})
 
+	t.Run("should auto setup notes repo under the organization when org query param is provided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPreferenceService := preference.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		dbDefaultRepo := preference.DefaultRepo{
+			UserID:        userID,
+			Name:          repository,
+			Owner:         "acme",
+			Visibility:    visibility,
+			DefaultBranch: branch,
+		}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockPreferenceService.EXPECT().GetByUserID(userID).Return(preference.DefaultRepo{}, nil)
+		mockGithubService.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), "acme", repository).Return(github.GitRepo{
+			Name:          repository,
+			Owner:         "acme",
+			Visibility:    visibility,
+			DefaultBranch: branch,
+			Writable:      true,
+		}, nil)
+		mockPreferenceService.EXPECT().Save(dbDefaultRepo).Return(nil)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
+
+		router.POST("/api/v1/user/preference/repo/auto", getClaimsHandler(), handler.AutoSetupRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/user/preference/repo/auto?repoName=%s&org=acme", repository), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
 	t.Run("should return bad request error response when repo name query param is not provided", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo/auto", getClaimsHandler(), handler.AutoSetupRepo)
 		response := httptest.NewRecorder()
@@ -284,13 +569,18 @@ func TestAutoSetupRepo(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
-		mockGithubService.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), repository).Return(github.GitRepo{}, errors.New("some error"))
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockPreferenceService.EXPECT().GetByUserID(userID).Return(preference.DefaultRepo{}, nil)
+		mockGithubService.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), "", repository).Return(github.GitRepo{}, errors.New("some error"))
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo/auto", getClaimsHandler(), handler.AutoSetupRepo)
 		response := httptest.NewRecorder()
@@ -306,7 +596,10 @@ func TestAutoSetupRepo(t *testing.T) {
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		u := validUser()
@@ -317,13 +610,15 @@ func TestAutoSetupRepo(t *testing.T) {
 			DefaultBranch: branch,
 		}
 		mockUserService.EXPECT().Get(userID).Return(u, nil)
-		mockGithubService.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), repository).Return(github.GitRepo{
+		mockTokenService.EXPECT().GetGithubToken(gomock.Any(), u).Return(getOAuth2Token(u.GithubToken), nil)
+		mockPreferenceService.EXPECT().GetByUserID(userID).Return(preference.DefaultRepo{}, nil)
+		mockGithubService.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), "", repository).Return(github.GitRepo{
 			Name:          repository,
 			Visibility:    visibility,
 			DefaultBranch: branch,
 		}, nil)
 		mockPreferenceService.EXPECT().Save(dbDefaultRepo).Return(errors.New(("some error")))
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo/auto", getClaimsHandler(), handler.AutoSetupRepo)
 		response := httptest.NewRecorder()
@@ -334,16 +629,39 @@ func TestAutoSetupRepo(t *testing.T) {
 		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
 	})
 
+	t.Run("should return bad request error when the github app is enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(true)
+		mockUserService := user.NewMockService(ctrl)
+
+		router := getRouter()
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		handler := NewPreferenceHandler(nil, nil, mockGithubAppService, mockUserService, nil)
+
+		router.POST("/api/v1/user/preference/repo/auto", getClaimsHandler(), handler.AutoSetupRepo)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/user/preference/repo/auto?repoName=%s", repository), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"auto setup is not available with the github app, create the repo on github & grant the app access to it"}`, response.Body.String())
+	})
+
 	t.Run("should return unauthorized error when retrieving user fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockPreferenceService := preference.NewMockService(ctrl)
 		mockGithubService := github.NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		mockGithubAppService.EXPECT().Enabled().Return(false).AnyTimes()
 		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
 
 		router := getRouter()
 		mockUserService.EXPECT().Get(userID).Return(user.User{}, errors.New("some error"))
-		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockUserService)
+		handler := NewPreferenceHandler(mockPreferenceService, mockGithubService, mockGithubAppService, mockUserService, mockTokenService)
 
 		router.POST("/api/v1/user/preference/repo/auto", getClaimsHandler(), handler.AutoSetupRepo)
 		response := httptest.NewRecorder()
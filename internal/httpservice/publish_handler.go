@@ -0,0 +1,157 @@
+package httpservice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/publish"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// publishTargetZip publishes the site as a zip archive downloaded by the client.
+	publishTargetZip = "zip"
+
+	// publishTargetPages publishes the site to the pages branch of the repo.
+	publishTargetPages = "gh-pages"
+)
+
+// PublishRequestPayload represents the http request payload to publish a folder of notes as a static website.
+// All the notes of the repo are published when path is not provided.
+type PublishRequestPayload struct {
+	Path   string `json:"path"`
+	Title  string `json:"title"`
+	Target string `json:"target"`
+}
+
+// Validate validates the publish http request payload.
+// The path is validated against the rules of the repo once the repo is resolved.
+func (p PublishRequestPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Title, validation.Length(0, 100)),
+		validation.Field(&p.Target, validation.Required, validation.In(publishTargetZip, publishTargetPages)),
+	)
+}
+
+// PublishResponsePayload represents the http response payload of the site published to the pages branch.
+type PublishResponsePayload struct {
+	Branch    string `json:"branch"`
+	CommitSHA string `json:"commit_sha"`
+	Pages     int    `json:"pages"`
+}
+
+// PublishHandler represents http handler for publishing the notes as a static website.
+type PublishHandler struct {
+	publishService  publish.Service
+	userService     user.Service
+	tokenService    user.TokenService
+	notebookService notebook.Service
+}
+
+// NewPublishHandler creates and returns a new publish handler.
+func NewPublishHandler(publishService publish.Service, userService user.Service, tokenService user.TokenService,
+	notebookService notebook.Service) *PublishHandler {
+	return &PublishHandler{
+		publishService:  publishService,
+		userService:     userService,
+		tokenService:    tokenService,
+		notebookService: notebookService,
+	}
+}
+
+// Publish builds a static website from the notes of the folder.
+// The site is sent as a zip archive or it is committed to the pages branch of the repo as per the requested target.
+// Owner role is required to publish the notes of a shared notebook to the pages branch, since the pages branch
+// is overwritten & the site is public even when the repo is private.
+func (p *PublishHandler) Publish(c *gin.Context) {
+	var publishPayload PublishRequestPayload
+	if err := c.ShouldBindJSON(&publishPayload); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid request payload"))
+		return
+	}
+	publishPayload.Path = strings.Trim(publishPayload.Path, "/")
+	if err := publishPayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+	notebookID, err := getNotebookIDParam(c)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	u, err := p.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	log := logrus.WithField("user-id", userID).WithField("path", publishPayload.Path).WithField("target", publishPayload.Target)
+	log.Info("request to publish notes started")
+	requiredRole := notebook.RoleViewer
+	if publishPayload.Target == publishTargetPages {
+		requiredRole = notebook.RoleOwner
+	}
+	repoDetails, repoUser, err := resolveNoteRepo(p.userService, p.notebookService, u, notebookID, requiredRole)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateFolderPath(publishPayload.Path, repoDetails.NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	ghToken, err := p.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	site, err := p.publishService.Build(c, ghToken, repoDetails, publish.Options{Path: publishPayload.Path, Title: publishPayload.Title})
+	if errors.Is(err, publish.ErrNoNotes) || errors.Is(err, publish.ErrTooManyNotes) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+
+	if publishPayload.Target == publishTargetZip {
+		archive, err := site.Archive()
+		if err != nil {
+			abortRequestWithError(c, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="batnoter-site-%s.zip"`, time.Now().UTC().Format("2006-01-02")))
+		c.Data(http.StatusOK, "application/zip", archive)
+		log.WithField("pages", site.Pages).Info("request to publish notes successful")
+		return
+	}
+	commitSHA, err := p.publishService.Deploy(c, ghToken, repoDetails, site, u.GetCommitName(), u.GetCommitEmail())
+	if errors.Is(err, publish.ErrPagesBranchConflict) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, PublishResponsePayload{
+		Branch:    publish.PagesBranch,
+		CommitSHA: commitSHA,
+		Pages:     site.Pages,
+	})
+	log.WithField("pages", site.Pages).Info("request to publish notes successful")
+}
@@ -0,0 +1,182 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/publish"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublish(t *testing.T) {
+	t.Run("should send the site of the folder as zip archive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPublishService := publish.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		repoDetails := github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockPublishService.EXPECT().Build(gomock.Any(), ghToken, repoDetails, publish.Options{Path: "docs", Title: "Team Docs"}).
+			Return(publish.Site{Pages: 1, Files: map[string]string{"index.html": "<h1>Hello</h1>"}}, nil)
+		handler := NewPublishHandler(mockPublishService, mockUserService, mockTokenService, nil)
+
+		router.POST("/api/v1/publish", getClaimsHandler(), handler.Publish)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/publish", strings.NewReader(`{"path":"docs/","title":"Team Docs","target":"zip"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/zip", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Header().Get("Content-Disposition"), `attachment; filename="batnoter-site-`)
+		assert.NotEmpty(t, response.Body.Bytes())
+	})
+
+	t.Run("should commit the site of the notebook to the pages branch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPublishService := publish.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		nb := validNotebook()
+		nb.Role = notebook.RoleOwner
+		ghToken := getOAuth2Token(u.GithubToken)
+		repoDetails := github.GitRepoProps{Repository: "work-notes", DefaultBranch: "main", Owner: owner}
+		site := publish.Site{Pages: 3}
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockPublishService.EXPECT().Build(gomock.Any(), ghToken, repoDetails, publish.Options{}).Return(site, nil)
+		mockPublishService.EXPECT().Deploy(gomock.Any(), ghToken, repoDetails, site, authorName, authorEmail).Return("commitsha", nil)
+		handler := NewPublishHandler(mockPublishService, mockUserService, mockTokenService, mockNotebookService)
+
+		router.POST("/api/v1/notebooks/:notebook/publish", getClaimsHandler(), handler.Publish)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks/7/publish", strings.NewReader(`{"target":"gh-pages"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"branch":"gh-pages","commit_sha":"commitsha","pages":3}`, response.Body.String())
+	})
+
+	t.Run("should return forbidden when a viewer or an editor of the notebook publishes to the pages branch", func(t *testing.T) {
+		for _, role := range []string{notebook.RoleViewer, notebook.RoleEditor} {
+			ctrl := gomock.NewController(t)
+			mockUserService := user.NewMockService(ctrl)
+			mockNotebookService := notebook.NewMockService(ctrl)
+
+			router := getRouter()
+			nb := validNotebook()
+			nb.UserID = userID + 1
+			nb.Role = role
+			mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+			mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+			handler := NewPublishHandler(nil, mockUserService, nil, mockNotebookService)
+
+			router.POST("/api/v1/notebooks/:notebook/publish", getClaimsHandler(), handler.Publish)
+			response := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/notebooks/7/publish", strings.NewReader(`{"target":"gh-pages"}`))
+
+			router.ServeHTTP(response, req)
+			assert.Equal(t, http.StatusForbidden, response.Code)
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("should return bad request when the folder does not contain notes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPublishService := publish.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockPublishService.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(publish.Site{}, publish.ErrNoNotes)
+		handler := NewPublishHandler(mockPublishService, mockUserService, mockTokenService, nil)
+
+		router.POST("/api/v1/publish", getClaimsHandler(), handler.Publish)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/publish", strings.NewReader(`{"path":"docs","target":"zip"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"no notes found in the path"}`, response.Body.String())
+	})
+
+	t.Run("should return internal server error when deploying the site fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockPublishService := publish.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockPublishService.EXPECT().Build(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(publish.Site{}, nil)
+		mockPublishService.EXPECT().Deploy(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("", errors.New("some error"))
+		handler := NewPublishHandler(mockPublishService, mockUserService, mockTokenService, nil)
+
+		router.POST("/api/v1/publish", getClaimsHandler(), handler.Publish)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/publish", strings.NewReader(`{"target":"gh-pages"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+
+	t.Run("should return bad request when the request payload is invalid", func(t *testing.T) {
+		for _, payload := range []string{`{"target":"ftp"}`, `{}`} {
+			t.Run("with payload: "+payload, func(t *testing.T) {
+				router := getRouter()
+				handler := NewPublishHandler(nil, nil, nil, nil)
+
+				router.POST("/api/v1/publish", getClaimsHandler(), handler.Publish)
+				response := httptest.NewRecorder()
+				req, _ := http.NewRequest(http.MethodPost, "/api/v1/publish", strings.NewReader(payload))
+
+				router.ServeHTTP(response, req)
+				assert.Equal(t, http.StatusBadRequest, response.Code)
+			})
+		}
+	})
+
+	t.Run("should return bad request when the path is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		router := getRouter()
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		handler := NewPublishHandler(nil, mockUserService, nil, nil)
+
+		router.POST("/api/v1/publish", getClaimsHandler(), handler.Publish)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/publish", strings.NewReader(`{"path":"../secrets","target":"zip"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"path: must be in a valid format"}`, response.Body.String())
+	})
+}
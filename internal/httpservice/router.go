@@ -10,9 +10,16 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/batnoter/batnoter-api/internal/applicationconfig"
+	"github.com/batnoter/batnoter-api/internal/auth"
 	"github.com/sirupsen/logrus"
 )
 
+// the uploads of the exports & the attachments can not be read within the read timeout of the server over slow connections
+const uploadTimeout = 10 * time.Minute
+
+// the exports of the notes are streamed while the notes are fetched from github which takes longer than the write timeout of the server
+const exportTimeout = 30 * time.Minute
+
 // Run starts the http server.
 func Run(applicationconfig *applicationconfig.ApplicationConfig) error {
 	gin.SetMode(gin.ReleaseMode)
@@ -27,27 +34,140 @@ func Run(applicationconfig *applicationconfig.ApplicationConfig) error {
 	router.Use(cors.New(corsConfig(clientBaseURL)))
 	logrus.Infof("allowing cors for %s", clientBaseURL)
 
-	noteHandler := NewNoteHandler(applicationconfig.GithubService, applicationconfig.UserService)
-	loginHandler := NewLoginHandler(applicationconfig.AuthService, applicationconfig.GithubService, applicationconfig.UserService, applicationconfig.Config.App.ClientURL)
+	noteHandler := NewNoteHandler(applicationconfig.GithubService, applicationconfig.UserService, applicationconfig.TokenService,
+		applicationconfig.NotebookService, applicationconfig.MarkdownRenderer, applicationconfig.Config.Attachments.Folder)
+	memberHandler := NewMemberHandler(applicationconfig.NotebookService, applicationconfig.UserService)
+	notebookHandler := NewNotebookHandler(applicationconfig.NotebookService, applicationconfig.GithubService, applicationconfig.GithubAppService,
+		applicationconfig.UserService, applicationconfig.TokenService)
+	loginHandler := NewLoginHandler(applicationconfig.AuthService, applicationconfig.GithubService, applicationconfig.GithubAppService,
+		applicationconfig.UserService, applicationconfig.PreferenceService, applicationconfig.Config.App.ClientURL)
 	userHandler := NewUserHandler(applicationconfig.UserService)
-	preferenceHandler := NewPreferenceHandler(applicationconfig.PreferenceService, applicationconfig.GithubService, applicationconfig.UserService)
-	authMiddleware := NewMiddleware(applicationconfig.AuthService)
+	identityHandler := NewIdentityHandler(applicationconfig.UserService, applicationconfig.GithubService, applicationconfig.AuthService)
+	sessionHandler := NewSessionHandler(applicationconfig.AuthService)
+	apiTokenHandler := NewAPITokenHandler(applicationconfig.APITokenService)
+	jwksHandler := NewJWKSHandler(applicationconfig.AuthService)
+	accountHandler := NewAccountHandler(applicationconfig.UserService, applicationconfig.TokenService, applicationconfig.GithubService,
+		applicationconfig.PreferenceService, applicationconfig.AuthService)
+	shareHandler := NewShareHandler(applicationconfig.ShareService, applicationconfig.GithubService, applicationconfig.UserService,
+		applicationconfig.TokenService, applicationconfig.NotebookService, applicationconfig.MarkdownRenderer)
+	noteExportHandler := NewNoteExportHandler(applicationconfig.NotesExportService, applicationconfig.UserService, applicationconfig.TokenService,
+		applicationconfig.NotebookService)
+	publishHandler := NewPublishHandler(applicationconfig.PublishService, applicationconfig.UserService, applicationconfig.TokenService,
+		applicationconfig.NotebookService)
+	attachmentHandler := NewAttachmentHandler(applicationconfig.AttachmentService, applicationconfig.UserService, applicationconfig.TokenService,
+		applicationconfig.NotebookService)
+	exportHandler := NewExportHandler(applicationconfig.ExportService)
+	importHandler := NewImportHandler(applicationconfig.ImportService, applicationconfig.UserService)
+	adminHandler := NewAdminHandler(applicationconfig.UserService, applicationconfig.AuthService, applicationconfig.AuditService)
+	preferenceHandler := NewPreferenceHandler(applicationconfig.PreferenceService, applicationconfig.GithubService, applicationconfig.GithubAppService,
+		applicationconfig.UserService, applicationconfig.TokenService)
+	authMiddleware := NewMiddleware(applicationconfig.AuthService, applicationconfig.APITokenService, applicationconfig.UserService)
+
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+	router.GET(shareURLPrefix+":token", shareHandler.ViewShare)  // view shared note (public, no auth, html when accepted by the client)
+	router.POST(shareURLPrefix+":token", shareHandler.ViewShare) // view protected shared note with the password posted by the form
 
 	v1 := router.Group("api/v1")
-	v1.GET("/user/me", authMiddleware.AuthorizeToken(), userHandler.Profile)
-	v1.GET("/user/preference/repo", authMiddleware.AuthorizeToken(), preferenceHandler.GetRepos)
-	v1.POST("/user/preference/repo", authMiddleware.AuthorizeToken(), preferenceHandler.SaveDefaultRepo)
-	v1.POST("/user/preference/auto/repo", authMiddleware.AuthorizeToken(), preferenceHandler.AutoSetupRepo)
-
-	v1.GET("/search/notes", authMiddleware.AuthorizeToken(), noteHandler.SearchNotes)  // search notes (provide filters using query-params)
-	v1.GET("/tree/notes", authMiddleware.AuthorizeToken(), noteHandler.GetNotesTree)   // get complete notes repo tree
-	v1.GET("/notes", authMiddleware.AuthorizeToken(), noteHandler.GetAllNotes)         // get all notes from path (provide filters using query-params)
-	v1.GET("/notes/:path", authMiddleware.AuthorizeToken(), noteHandler.GetNote)       // get single note
-	v1.POST("/notes/:path", authMiddleware.AuthorizeToken(), noteHandler.SaveNote)     // create/update single note
-	v1.DELETE("/notes/:path", authMiddleware.AuthorizeToken(), noteHandler.DeleteNote) // delete single note
+
+	// authorized routes are grouped by the scope required to access them
+	authorized := v1.Group("", authMiddleware.AuthorizeToken())
+	notesRead := authorized.Group("", authMiddleware.RequireScopes(auth.ScopeNotesRead))
+	notesWrite := authorized.Group("", authMiddleware.RequireScopes(auth.ScopeNotesWrite))
+	preferencesWrite := authorized.Group("", authMiddleware.RequireScopes(auth.ScopePreferencesWrite))
+	admin := authorized.Group("/admin", authMiddleware.RequireScopes(auth.ScopeAdmin), authMiddleware.RequireAdmin())
+
+	notesRead.GET("/user/me", userHandler.Profile)
+	notesRead.GET("/user/identities", identityHandler.GetIdentities)
+	notesRead.GET("/user/preference/repo", preferenceHandler.GetRepos)
+	notesRead.GET("/user/sessions", sessionHandler.GetSessions)
+	notesRead.GET("/user/tokens", apiTokenHandler.GetTokens)
+	preferencesWrite.PATCH("/user/me", userHandler.UpdateProfile)
+	preferencesWrite.DELETE("/user/me", accountHandler.DeleteAccount)
+	preferencesWrite.POST("/user/identities/github", identityHandler.LinkGithub)
+	preferencesWrite.DELETE("/user/identities/:id", identityHandler.UnlinkIdentity)
+	preferencesWrite.POST("/user/preference/repo", preferenceHandler.SaveDefaultRepo)
+	preferencesWrite.POST("/user/preference/auto/repo", preferenceHandler.AutoSetupRepo)
+	preferencesWrite.DELETE("/user/sessions/:id", sessionHandler.RevokeSession)
+	preferencesWrite.POST("/user/tokens", apiTokenHandler.CreateToken)
+	preferencesWrite.DELETE("/user/tokens/:id", apiTokenHandler.RevokeToken)
+
+	// personal data export holds the sessions & the audit trail of the user, so the read-only tokens can not access it
+	preferencesWrite.GET("/user/me/export", exportHandler.GetExport)
+	preferencesWrite.GET("/user/me/export/download", exportHandler.DownloadExport)
+	preferencesWrite.POST("/user/me/export", exportHandler.RequestExport)
+
+	notesRead.GET("/search/notes", noteHandler.SearchNotes)     // search notes (provide filters using query-params)
+	notesRead.GET("/tree/notes", noteHandler.GetNotesTree)      // get complete notes repo tree
+	notesRead.GET("/notes", noteHandler.GetAllNotes)            // get all notes from path (provide filters using query-params)
+	notesRead.GET("/notes/:path", noteHandler.GetNote)          // get single note
+	notesRead.GET("/notes/:path/html", noteHandler.GetNoteHTML) // get single note rendered to html
+	notesWrite.POST("/notes/:path", noteHandler.SaveNote)       // create/update single note
+	notesWrite.DELETE("/notes/:path", noteHandler.DeleteNote)   // delete single note
+
+	notesRead.GET("/notebooks", notebookHandler.GetNotebooks)                       // get all notebooks
+	notesRead.GET("/notebooks/:notebook", notebookHandler.GetNotebook)              // get single notebook
+	preferencesWrite.POST("/notebooks", notebookHandler.CreateNotebook)             // create notebook
+	preferencesWrite.PUT("/notebooks/:notebook", notebookHandler.UpdateNotebook)    // update notebook
+	preferencesWrite.DELETE("/notebooks/:notebook", notebookHandler.DeleteNotebook) // delete notebook (notes are retained in the repo)
+
+	// note routes scoped to a notebook, the routes above use the default repo of the user
+	notesRead.GET("/notebooks/:notebook/search/notes", noteHandler.SearchNotes)     // search notes of notebook
+	notesRead.GET("/notebooks/:notebook/tree/notes", noteHandler.GetNotesTree)      // get complete notes tree of notebook
+	notesRead.GET("/notebooks/:notebook/notes", noteHandler.GetAllNotes)            // get all notes of notebook from path
+	notesRead.GET("/notebooks/:notebook/notes/:path", noteHandler.GetNote)          // get single note of notebook
+	notesRead.GET("/notebooks/:notebook/notes/:path/html", noteHandler.GetNoteHTML) // get single note of notebook rendered to html
+	notesWrite.POST("/notebooks/:notebook/notes/:path", noteHandler.SaveNote)       // create/update single note of notebook
+	notesWrite.DELETE("/notebooks/:notebook/notes/:path", noteHandler.DeleteNote)   // delete single note of notebook
+
+	// members of the shared notebooks, the owners of the notebook invite the members by email or github login
+	notesRead.GET("/notebooks/:notebook/members", memberHandler.GetMembers)                         // get members of notebook
+	preferencesWrite.POST("/notebooks/:notebook/members", memberHandler.InviteMember)               // invite member to notebook
+	preferencesWrite.PUT("/notebooks/:notebook/members/:member", memberHandler.UpdateMember)        // change role of member
+	preferencesWrite.DELETE("/notebooks/:notebook/members/:member", memberHandler.RemoveMember)     // remove member (or leave notebook)
+	notesRead.GET("/user/invitations", memberHandler.GetInvitations)                                // get pending notebook invitations
+	preferencesWrite.POST("/user/invitations/:invitation/accept", memberHandler.AcceptInvitation)   // accept notebook invitation
+	preferencesWrite.POST("/user/invitations/:invitation/decline", memberHandler.DeclineInvitation) // decline notebook invitation
+
+	// images & pdfs embedded in the notes, stored in the attachments folder of the repo & named after the hash of their content
+	notesWrite.POST("/attachments", ExtendDeadlines(uploadTimeout), attachmentHandler.UploadAttachment)                     // upload attachment (multipart form)
+	notesRead.GET("/attachments/:name", attachmentHandler.GetAttachment)                                                    // get content of attachment (provide size using query-param)
+	notesWrite.DELETE("/attachments/:name", attachmentHandler.DeleteAttachment)                                             // delete attachment
+	notesWrite.POST("/notebooks/:notebook/attachments", ExtendDeadlines(uploadTimeout), attachmentHandler.UploadAttachment) // upload attachment to notebook
+	notesRead.GET("/notebooks/:notebook/attachments/:name", attachmentHandler.GetAttachment)                                // get content of attachment of notebook
+	notesWrite.DELETE("/notebooks/:notebook/attachments/:name", attachmentHandler.DeleteAttachment)                         // delete attachment of notebook
+
+	// public read-only share links of the notes, the shared note is viewed with the share url without auth
+	notesWrite.POST("/notes/:path/share", shareHandler.CreateShare)                     // create share link of note
+	notesWrite.POST("/notebooks/:notebook/notes/:path/share", shareHandler.CreateShare) // create share link of note of notebook
+	notesRead.GET("/shares", shareHandler.GetShares)                                    // get share links
+	notesWrite.DELETE("/shares/:share", shareHandler.RevokeShare)                       // revoke share link
+
+	// bulk export of the notes under a path for offline use (provide path & format using query-params)
+	notesRead.GET("/export", ExtendDeadlines(exportTimeout), noteExportHandler.ExportNotes)                     // export notes of default repo
+	notesRead.GET("/notebooks/:notebook/export", ExtendDeadlines(exportTimeout), noteExportHandler.ExportNotes) // export notes of notebook
+
+	// static website built from a folder of notes, downloaded as zip or committed to the gh-pages branch of the repo
+	notesWrite.POST("/publish", publishHandler.Publish)                     // publish notes of default repo
+	notesWrite.POST("/notebooks/:notebook/publish", publishHandler.Publish) // publish notes of notebook
+
+	// import of the exports of obsidian, notion & evernote processed in background (poll the import for progress)
+	notesWrite.POST("/import", ExtendDeadlines(uploadTimeout), importHandler.RequestImport) // upload export to be imported (multipart form)
+	notesRead.GET("/import", importHandler.GetLatestImport)                                 // get latest import
+	notesRead.GET("/import/:import", importHandler.GetImport)                               // get single import
+
+	admin.GET("/users", adminHandler.SearchUsers)                         // search users (provide filters using query-params)
+	admin.DELETE("/users/:id", adminHandler.DeleteUser)                   // delete user & revoke the sessions
+	admin.POST("/users/:id/disable", adminHandler.DisableUser)            // disable user
+	admin.POST("/users/:id/enable", adminHandler.EnableUser)              // re-enable disabled user
+	admin.POST("/users/:id/sessions/revoke", adminHandler.RevokeSessions) // revoke all the sessions of user
+	admin.GET("/users/:id/audit", adminHandler.GetAuditTrail)             // get audit trail of user
 
 	v1.GET("/auth/token", loginHandler.TokenPayload)
+	v1.POST("/auth/refresh", loginHandler.RefreshToken)
+	v1.POST("/auth/logout", loginHandler.Logout)
 	v1.GET("/oauth2/login/github", loginHandler.GithubLogin)
+	v1.GET("/oauth2/install/github", loginHandler.GithubInstall)
+	v1.POST("/oauth2/link/github", loginHandler.GithubLink)
 	v1.GET("/oauth2/github/callback", loginHandler.GithubOAuth2Callback)
 
 	address := net.JoinHostPort(applicationconfig.Config.HTTPServer.Host, applicationconfig.Config.HTTPServer.Port)
@@ -57,6 +177,7 @@ func Run(applicationconfig *applicationconfig.ApplicationConfig) error {
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   2 * time.Minute,
 		MaxHeaderBytes: 1 << 20,
+		ConnContext:    storeConn,
 	}
 	return server.ListenAndServe()
 }
@@ -64,7 +185,7 @@ func Run(applicationconfig *applicationconfig.ApplicationConfig) error {
 func corsConfig(clientBaseURL string) cors.Config {
 	return cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Length", "Content-Type"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Length", "Content-Type", sharePasswordHeader},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			return origin == clientBaseURL
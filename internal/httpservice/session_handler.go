@@ -0,0 +1,105 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+// SessionResponsePayload represents the http response payload of session entity.
+type SessionResponsePayload struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// SessionHandler represents http handler for managing user sessions.
+type SessionHandler struct {
+	authService auth.Service
+}
+
+// NewSessionHandler creates and returns a new session handler.
+func NewSessionHandler(authService auth.Service) *SessionHandler {
+	return &SessionHandler{
+		authService: authService,
+	}
+}
+
+// GetSessions returns the active sessions of logged in user.
+// The session of the current request is marked as current.
+func (s *SessionHandler) GetSessions(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	logrus.WithField("user-id", userID).Info("request to retrieve sessions started")
+	sessions, err := s.authService.GetSessions(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	tokenID := getTokenIDFromContext(c)
+	sessionsResp := make([]SessionResponsePayload, 0, len(sessions))
+	for _, session := range sessions {
+		sessionsResp = append(sessionsResp, SessionResponsePayload{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.TokenID == tokenID,
+		})
+	}
+	c.JSON(http.StatusOK, sessionsResp)
+	logrus.WithField("user-id", userID).Info("request to retrieve sessions successful")
+}
+
+// RevokeSession revokes a session of logged in user.
+// Tokens issued for the session can not be used once the session is revoked.
+func (s *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid session id"))
+		return
+	}
+	logrus.WithField("user-id", userID).WithField("session-id", sessionID).Info("request to revoke session started")
+	err = s.authService.RevokeSession(userID, uint(sessionID))
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "session not found"))
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", userID).WithField("session-id", sessionID).Info("request to revoke session successful")
+}
+
+func getTokenIDFromContext(c *gin.Context) string {
+	claims, _ := c.Get("claims")
+	if claims == nil {
+		return ""
+	}
+	tokenID, _ := claims.(jwt.MapClaims)["jti"].(string)
+	return tokenID
+}
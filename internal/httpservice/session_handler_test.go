@@ -0,0 +1,135 @@
+package httpservice
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/auth"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	tokenID   = "0b5ff8f2-4f4f-4b8d-9a5d-1d2c3b4a5e6f"
+	sessionID = uint(21)
+)
+
+func TestGetSessions(t *testing.T) {
+	t.Run("should return active sessions of the user & mark the current session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAuthService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewSessionHandler(mockAuthService)
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		current := auth.Session{TokenID: tokenID, Device: "Chrome on macOS", UserAgent: "Mozilla/5.0", IPAddress: "203.0.113.10", LastSeenAt: createdAt}
+		current.ID = sessionID
+		current.CreatedAt = createdAt
+		other := auth.Session{TokenID: "another-token-id", Device: "Firefox on Linux", UserAgent: "Mozilla/5.0", IPAddress: "203.0.113.11", LastSeenAt: createdAt}
+		other.ID = sessionID + 1
+		other.CreatedAt = createdAt
+		mockAuthService.EXPECT().GetSessions(userID).Return([]auth.Session{current, other}, nil)
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/sessions", getSessionClaimsHandler(), handler.GetSessions)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/sessions", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"id":21,"device":"Chrome on macOS","user_agent":"Mozilla/5.0","ip_address":"203.0.113.10","created_at":"2022-10-18T10:00:00Z","last_seen_at":"2022-10-18T10:00:00Z","current":true},
+			{"id":22,"device":"Firefox on Linux","user_agent":"Mozilla/5.0","ip_address":"203.0.113.11","created_at":"2022-10-18T10:00:00Z","last_seen_at":"2022-10-18T10:00:00Z","current":false}]`, response.Body.String())
+	})
+
+	t.Run("should fail with internal server error when retrieving sessions fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAuthService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewSessionHandler(mockAuthService)
+		mockAuthService.EXPECT().GetSessions(userID).Return(nil, errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.GET("/api/v1/user/sessions", getSessionClaimsHandler(), handler.GetSessions)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/sessions", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func TestRevokeSession(t *testing.T) {
+	t.Run("should revoke the session of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAuthService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewSessionHandler(mockAuthService)
+		mockAuthService.EXPECT().RevokeSession(userID, sessionID).Return(nil)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/sessions/:id", getSessionClaimsHandler(), handler.RevokeSession)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/sessions/21", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with bad request when the session does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAuthService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewSessionHandler(mockAuthService)
+		mockAuthService.EXPECT().RevokeSession(userID, sessionID).Return(auth.ErrSessionNotFound)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/sessions/:id", getSessionClaimsHandler(), handler.RevokeSession)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/sessions/21", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"session not found"}`, response.Body.String())
+	})
+
+	t.Run("should fail with bad request when the session id is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockAuthService := auth.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewSessionHandler(mockAuthService)
+
+		// simulate auth middleware with custom handler
+		router.DELETE("/api/v1/user/sessions/:id", getSessionClaimsHandler(), handler.RevokeSession)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/user/sessions/abc", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func getSessionClaimsHandler() gin.HandlerFunc {
+	claims := jwt.MapClaims{"sub": strconv.FormatUint(uint64(userID), 10), "jti": tokenID, "scope": "notes:read notes:write preferences:write"}
+	return func(c *gin.Context) { c.Set("claims", claims) }
+}
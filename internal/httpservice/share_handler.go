@@ -0,0 +1,362 @@
+package httpservice
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/share"
+	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/sirupsen/logrus"
+)
+
+// shareURLPrefix is the path prefix of the public share urls, the share token is appended to it.
+const shareURLPrefix = "/s/"
+
+// sharePasswordHeader is the request header carrying the password of a password protected share.
+const sharePasswordHeader = "X-Share-Password"
+
+// shareContentSecurityPolicy restricts the shared note pages served from the api origin. The scripts are not allowed,
+// so the html of a note can not run in the api origin. The inline styles & the images of the note are allowed.
+const shareContentSecurityPolicy = "default-src 'none'; img-src https: data:; style-src 'unsafe-inline'"
+
+// sharePasswordForm is the html page asking the password of a password protected share.
+// The form posts the password to the share url it is served from.
+var sharePasswordForm = template.Must(template.New("share-password").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Protected note</title>
+</head>
+<body>
+<form method="post">
+{{if .}}<p>{{.}}</p>{{end}}
+<label for="password">Password</label>
+<input type="password" id="password" name="password" autofocus required>
+<button type="submit">View note</button>
+</form>
+</body>
+</html>
+`))
+
+// ShareRequestPayload represents the http request payload to create a share link of a note.
+// The share never expires when expires in days is not provided & is not protected when password is not provided.
+type ShareRequestPayload struct {
+	Password      string `json:"password"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+// Validate validates the share http request payload.
+func (s ShareRequestPayload) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.Password, validation.Length(0, 72)),
+		validation.Field(&s.ExpiresInDays, validation.Min(0), validation.Max(365)),
+	)
+}
+
+// ShareResponsePayload represents the http response payload of share entity.
+// URL contains the share token & is returned only once when the share is created.
+type ShareResponsePayload struct {
+	ID           uint       `json:"id"`
+	NotebookID   uint       `json:"notebook_id,omitempty"`
+	Path         string     `json:"path"`
+	URL          string     `json:"url,omitempty"`
+	HasPassword  bool       `json:"has_password"`
+	ViewCount    int64      `json:"view_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+}
+
+// SharedNoteResponsePayload represents the http response payload of a note viewed with a share link.
+type SharedNoteResponsePayload struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Size    int    `json:"size"`
+}
+
+// ShareHandler represents http handler for managing the public read-only share links of the notes.
+type ShareHandler struct {
+	shareService    share.Service
+	githubService   github.Service
+	userService     user.Service
+	tokenService    user.TokenService
+	notebookService notebook.Service
+	renderer        markdown.Renderer
+}
+
+// NewShareHandler creates and returns a new share handler.
+func NewShareHandler(shareService share.Service, githubService github.Service, userService user.Service, tokenService user.TokenService,
+	notebookService notebook.Service, renderer markdown.Renderer) *ShareHandler {
+	return &ShareHandler{
+		shareService:    shareService,
+		githubService:   githubService,
+		userService:     userService,
+		tokenService:    tokenService,
+		notebookService: notebookService,
+		renderer:        renderer,
+	}
+}
+
+// CreateShare creates a public share link of the note with requested path.
+// Editor role is required to share the notes of a shared notebook.
+func (s *ShareHandler) CreateShare(c *gin.Context) {
+	path := c.Param("path")
+	notebookID, err := getNotebookIDParam(c)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	// request payload is optional
+	var sharePayload ShareRequestPayload
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&sharePayload); err != nil && !errors.Is(err, io.EOF) {
+			abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid request payload"))
+			return
+		}
+	}
+	if err := sharePayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	u, err := s.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	logrus.WithField("user-id", userID).WithField("note_path", path).Info("request to share note started")
+	repoDetails, repoUser, err := resolveNoteRepo(s.userService, s.notebookService, u, notebookID, notebook.RoleEditor)
+	if err != nil {
+		abortRepoRequestWithError(c, err)
+		return
+	}
+	if err := validateNotePath(path, repoDetails.NoteFiles); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	// only existing notes can be shared
+	ghToken, err := s.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	if _, err := s.githubService.GetFile(c, ghToken, github.GitFileProps{Path: path, RepoDetails: repoDetails}); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+
+	var expiresAt *time.Time
+	if sharePayload.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, sharePayload.ExpiresInDays).UTC()
+		expiresAt = &t
+	}
+	noteShare, token, err := s.shareService.Create(share.Share{
+		UserID:     userID,
+		NotebookID: notebookID,
+		Path:       path,
+		ExpiresAt:  expiresAt,
+	}, sharePayload.Password)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	shareResp := shareResponse(noteShare)
+	shareResp.URL = shareURLPrefix + token
+	c.JSON(http.StatusCreated, shareResp)
+	logrus.WithField("user-id", userID).WithField("share-id", noteShare.ID).Info("request to share note successful")
+}
+
+// GetShares returns the share links of logged in user which are not revoked.
+func (s *ShareHandler) GetShares(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	logrus.WithField("user-id", userID).Info("request to retrieve shares started")
+	shares, err := s.shareService.GetAll(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	sharesResp := make([]ShareResponsePayload, 0, len(shares))
+	for _, noteShare := range shares {
+		sharesResp = append(sharesResp, shareResponse(noteShare))
+	}
+	c.JSON(http.StatusOK, sharesResp)
+	logrus.WithField("user-id", userID).Info("request to retrieve shares successful")
+}
+
+// RevokeShare revokes the share link of logged in user, the note can not be viewed with the link anymore.
+func (s *ShareHandler) RevokeShare(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	shareID, err := strconv.ParseUint(c.Param("share"), 10, 64)
+	if err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid share id"))
+		return
+	}
+	logrus.WithField("user-id", userID).WithField("share-id", shareID).Info("request to revoke share started")
+	if err := s.shareService.Revoke(userID, uint(shareID)); err != nil {
+		if errors.Is(err, share.ErrShareNotFound) {
+			abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "share not found"))
+			return
+		}
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+	logrus.WithField("user-id", userID).WithField("share-id", shareID).Info("request to revoke share successful")
+}
+
+// ViewShare returns the note of the share link as a http response, authentication is not required.
+// The password of a protected share is provided with the X-Share-Password header or posted as the password form field,
+// a form asking the password is returned to the clients accepting html. The password attempts are rate limited by the share service.
+// The note is rendered to html when the client accepts html instead of json, the relative links are not rewritten
+// since the other notes are not shared.
+// The note is read with the github token of the user who shared it, the share stops working when the user loses access
+// to the note, the role of the user in the notebook drops below editor or the account of the user is disabled or deleted.
+func (s *ShareHandler) ViewShare(c *gin.Context) {
+	c.Header("Content-Security-Policy", shareContentSecurityPolicy)
+	c.Header("X-Content-Type-Options", "nosniff")
+	acceptsHTML := c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+	password := c.GetHeader(sharePasswordHeader)
+	if password == "" {
+		password = c.PostForm("password")
+	}
+	noteShare, err := s.shareService.Open(c.Param("token"), password)
+	if errors.Is(err, share.ErrInvalidShare) {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, share.ErrPasswordRequired) || errors.Is(err, share.ErrInvalidPassword) {
+		abortSharePassword(c, http.StatusUnauthorized, err, acceptsHTML)
+		return
+	}
+	if errors.Is(err, share.ErrTooManyAttempts) {
+		abortSharePassword(c, http.StatusTooManyRequests, err, acceptsHTML)
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	log := logrus.WithField("user-id", noteShare.UserID).WithField("share-id", noteShare.ID)
+	log.Info("request to view shared note started")
+	if err := s.userService.CheckStatus(noteShare.UserID); err != nil {
+		abortUnavailableShare(c, log, err)
+		return
+	}
+	u, err := s.userService.Get(noteShare.UserID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	repoDetails, repoUser, err := resolveNoteRepo(s.userService, s.notebookService, u, noteShare.NotebookID, notebook.RoleEditor)
+	if err != nil {
+		log.WithError(err).Warn("resolving repo of shared note failed")
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	// the note of a shared notebook is read with the token of the user who created the notebook
+	if repoUser.ID != u.ID {
+		if err := s.userService.CheckStatus(repoUser.ID); err != nil {
+			abortUnavailableShare(c, log, err)
+			return
+		}
+	}
+	ghToken, err := s.tokenService.GetRepoToken(c, repoUser, repoDetails)
+	if err != nil {
+		logrus.Errorf("retrieving github token failed")
+		abortRequestWithError(c, err)
+		return
+	}
+	gitFile, err := s.githubService.GetFile(c, ghToken, github.GitFileProps{Path: noteShare.Path, RepoDetails: repoDetails})
+	if errors.Is(err, github.ErrFileNotFound) || errors.Is(err, github.ErrRepoNotFound) {
+		log.WithError(err).Warn("shared note not found")
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	if acceptsHTML {
+		if writeNoteHTML(c, s.renderer, gitFile, markdown.Options{Path: noteShare.Path}) {
+			log.Info("request to view shared note successful")
+		}
+		return
+	}
+	c.JSON(http.StatusOK, SharedNoteResponsePayload{
+		Path:    gitFile.Path,
+		Content: gitFile.Content,
+		Size:    gitFile.Size,
+	})
+	log.Info("request to view shared note successful")
+}
+
+// abortSharePassword aborts the request to view the protected share. The clients accepting html get the form asking
+// the password along with the error, the other clients get the error as json.
+func abortSharePassword(c *gin.Context, status int, err error, acceptsHTML bool) {
+	if !acceptsHTML {
+		c.AbortWithStatusJSON(status, ErrorResponse{Code: ErrorCodeInvalidRequest, Message: err.Error()})
+		return
+	}
+	message := ""
+	if !errors.Is(err, share.ErrPasswordRequired) {
+		message = err.Error()
+	}
+	var buf bytes.Buffer
+	if err := sharePasswordForm.Execute(&buf, message); err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+	c.Abort()
+}
+
+// abortUnavailableShare aborts the request with not found status when the user who shared the note is not allowed
+// to access the app anymore, otherwise it aborts the request with the error.
+func abortUnavailableShare(c *gin.Context, log *logrus.Entry, err error) {
+	if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, user.ErrUserDeleted) || errors.Is(err, user.ErrUserDisabled) {
+		log.WithError(err).Warn("share of an unavailable user rejected")
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	abortRequestWithError(c, err)
+}
+
+func shareResponse(noteShare share.Share) ShareResponsePayload {
+	return ShareResponsePayload{
+		ID:           noteShare.ID,
+		NotebookID:   noteShare.NotebookID,
+		Path:         noteShare.Path,
+		HasPassword:  noteShare.HasPassword(),
+		ViewCount:    noteShare.ViewCount,
+		CreatedAt:    noteShare.CreatedAt,
+		ExpiresAt:    noteShare.ExpiresAt,
+		LastViewedAt: noteShare.LastViewedAt,
+	}
+}
@@ -0,0 +1,502 @@
+package httpservice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/batnoter/batnoter-api/internal/notebook"
+	"github.com/batnoter/batnoter-api/internal/share"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const (
+	shareID    = uint(51)
+	shareToken = "c2hhcmUtdG9rZW4"
+)
+
+func TestCreateShare(t *testing.T) {
+	t.Run("should create share link of the note & return the share url", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		fp := github.GitFileProps{Path: notePath, RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
+		createdAt := time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), ghToken, fp).Return(validGitFile(), nil)
+		mockShareService.EXPECT().Create(gomock.Any(), "s3cret").DoAndReturn(func(s share.Share, password string) (share.Share, string, error) {
+			assert.Equal(t, userID, s.UserID)
+			assert.Equal(t, notePath, s.Path)
+			assert.NotNil(t, s.ExpiresAt)
+			s.ID = shareID
+			s.CreatedAt = createdAt
+			s.PasswordHash = "hash"
+			s.ExpiresAt = nil
+			return s, shareToken, nil
+		})
+		handler := NewShareHandler(mockShareService, mockGithubService, mockUserService, mockTokenService, nil, nil)
+
+		router.POST("/api/v1/notes/:path/share", getClaimsHandler(), handler.CreateShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/notes/%s/share", url.QueryEscape(notePath)),
+			strings.NewReader(`{"password":"s3cret","expires_in_days":7}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.JSONEq(t, `{"id":51,"path":"foo/bar.md","url":"/s/c2hhcmUtdG9rZW4","has_password":true,"view_count":0,
+			"created_at":"2022-10-18T10:00:00Z"}`, response.Body.String())
+	})
+
+	t.Run("should return forbidden when the user is a viewer of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		nb := validNotebook()
+		nb.UserID = userID + 1
+		nb.Role = notebook.RoleViewer
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(nb, nil)
+		handler := NewShareHandler(nil, nil, mockUserService, nil, mockNotebookService, nil)
+
+		router.POST("/api/v1/notebooks/:notebook/notes/:path/share", getClaimsHandler(), handler.CreateShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/notebooks/7/notes/%s/share", url.QueryEscape(notePath)), nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("should return bad request when the expiry is out of range", func(t *testing.T) {
+		router := getRouter()
+		handler := NewShareHandler(nil, nil, nil, nil, nil, nil)
+
+		router.POST("/api/v1/notes/:path/share", getClaimsHandler(), handler.CreateShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/notes/%s/share", url.QueryEscape(notePath)),
+			strings.NewReader(`{"expires_in_days":400}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"validation_failed", "message":"expires_in_days: must be no greater than 365."}`, response.Body.String())
+	})
+}
+
+func TestGetShares(t *testing.T) {
+	t.Run("should return the share links of the user without the token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().GetAll(userID).Return([]share.Share{validShare()}, nil)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.GET("/api/v1/shares", getClaimsHandler(), handler.GetShares)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/shares", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `[{"id":51,"path":"foo/bar.md","has_password":false,"view_count":3,"created_at":"2022-10-18T10:00:00Z"}]`, response.Body.String())
+	})
+}
+
+func TestRevokeShare(t *testing.T) {
+	t.Run("should revoke the share link of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().Revoke(userID, shareID).Return(nil)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.DELETE("/api/v1/shares/:share", getClaimsHandler(), handler.RevokeShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/shares/51", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should return bad request when the share does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().Revoke(userID, shareID).Return(share.ErrShareNotFound)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.DELETE("/api/v1/shares/:share", getClaimsHandler(), handler.RevokeShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/shares/51", nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"share not found"}`, response.Body.String())
+	})
+}
+
+func TestViewShare(t *testing.T) {
+	t.Run("should return the shared note read with the token of the user who shared it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		ghToken := getOAuth2Token(u.GithubToken)
+		fp := github.GitFileProps{Path: notePath, RepoDetails: github.GitRepoProps{Repository: repository, DefaultBranch: branch, Owner: owner}}
+		mockShareService.EXPECT().Open(shareToken, "s3cret").Return(validShare(), nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), ghToken, fp).Return(validGitFile(), nil)
+		handler := NewShareHandler(mockShareService, mockGithubService, mockUserService, mockTokenService, nil, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+		req.Header.Set("X-Share-Password", "s3cret")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"path":"foo/bar.md","content":"Hello","size":5}`, response.Body.String())
+	})
+
+	t.Run("should return the shared note rendered to html when the client accepts html", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		f := validGitFile()
+		f.Content = "# Hello\n\n[other](other.md)\n"
+		mockShareService.EXPECT().Open(shareToken, "").Return(validShare(), nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(f, nil)
+		handler := NewShareHandler(mockShareService, mockGithubService, mockUserService, mockTokenService, nil, markdown.NewRenderer())
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+		req.Header.Set("Accept", "text/html")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/html; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.Equal(t, "default-src 'none'; img-src https: data:; style-src 'unsafe-inline'", response.Header().Get("Content-Security-Policy"))
+		assert.Equal(t, "nosniff", response.Header().Get("X-Content-Type-Options"))
+		assert.Equal(t, "<h1 id=\"hello\">Hello</h1>\n<p><a href=\"other.md\" rel=\"nofollow\">other</a></p>\n", response.Body.String())
+	})
+
+	t.Run("should return not found when the share is expired or revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().Open(shareToken, "").Return(share.Share{}, share.ErrInvalidShare)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return unauthorized when the password of protected share is not provided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().Open(shareToken, "").Return(share.Share{}, share.ErrPasswordRequired)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"share password is required"}`, response.Body.String())
+	})
+
+	t.Run("should return the password form when the client accepts html & the password of protected share is not provided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().Open(shareToken, "").Return(share.Share{}, share.ErrPasswordRequired)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+		req.Header.Set("Accept", "text/html")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Equal(t, "text/html; charset=utf-8", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Body.String(), `<form method="post">`)
+		assert.NotContains(t, response.Body.String(), "<p>")
+	})
+
+	t.Run("should return the shared note when the password is posted by the form", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockShareService.EXPECT().Open(shareToken, "s3cret").Return(validShare(), nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(validGitFile(), nil)
+		handler := NewShareHandler(mockShareService, mockGithubService, mockUserService, mockTokenService, nil, markdown.NewRenderer())
+
+		router.POST("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/s/"+shareToken, strings.NewReader("password=s3cret"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "text/html")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "text/html; charset=utf-8", response.Header().Get("Content-Type"))
+	})
+
+	t.Run("should return the password form with the error when the posted password does not match", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().Open(shareToken, "wrong").Return(share.Share{}, share.ErrInvalidPassword)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.POST("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/s/"+shareToken, strings.NewReader("password=wrong"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "text/html")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Contains(t, response.Body.String(), "<p>invalid share password</p>")
+	})
+
+	t.Run("should return too many requests when the password attempts of the share are locked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+
+		router := getRouter()
+		mockShareService.EXPECT().Open(shareToken, "s3cret").Return(share.Share{}, share.ErrTooManyAttempts)
+		handler := NewShareHandler(mockShareService, nil, nil, nil, nil, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+		req.Header.Set("X-Share-Password", "s3cret")
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+		assert.JSONEq(t, `{"code":"invalid_request", "message":"too many share password attempts"}`, response.Body.String())
+	})
+
+	t.Run("should return not found when the account of the user who shared the note is disabled or deleted", func(t *testing.T) {
+		for _, statusErr := range []error{user.ErrUserDisabled, user.ErrUserDeleted, user.ErrUserNotFound} {
+			ctrl := gomock.NewController(t)
+			mockShareService := share.NewMockService(ctrl)
+			mockUserService := user.NewMockService(ctrl)
+
+			router := getRouter()
+			mockShareService.EXPECT().Open(shareToken, "").Return(validShare(), nil)
+			mockUserService.EXPECT().CheckStatus(userID).Return(statusErr)
+			handler := NewShareHandler(mockShareService, nil, mockUserService, nil, nil, nil)
+
+			router.GET("/s/:token", handler.ViewShare)
+			response := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+			router.ServeHTTP(response, req)
+			assert.Equal(t, http.StatusNotFound, response.Code)
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("should return not found when the creator of the notebook of the share is disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		s := validShare()
+		s.NotebookID = notebookID
+		creatorID := uint(2024)
+		mockShareService.EXPECT().Open(shareToken, "").Return(s, nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(notebook.Notebook{UserID: creatorID, Repository: "work-notes", Role: notebook.RoleEditor}, nil)
+		mockUserService.EXPECT().Get(creatorID).Return(user.User{Model: gorm.Model{ID: creatorID}}, nil)
+		mockUserService.EXPECT().CheckStatus(creatorID).Return(user.ErrUserDisabled)
+		handler := NewShareHandler(mockShareService, nil, mockUserService, nil, mockNotebookService, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return not found when the role of the user who shared the note dropped to viewer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		s := validShare()
+		s.NotebookID = notebookID
+		mockShareService.EXPECT().Open(shareToken, "").Return(s, nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(notebook.Notebook{UserID: 2024, Repository: "work-notes", Role: notebook.RoleViewer}, nil)
+		handler := NewShareHandler(mockShareService, nil, mockUserService, nil, mockNotebookService, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return not found when the shared note is deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockShareService.EXPECT().Open(shareToken, "").Return(validShare(), nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(github.GitFile{}, github.ErrFileNotFound)
+		handler := NewShareHandler(mockShareService, mockGithubService, mockUserService, mockTokenService, nil, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return not found when the user lost access to the notebook of the share", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockNotebookService := notebook.NewMockService(ctrl)
+
+		router := getRouter()
+		s := validShare()
+		s.NotebookID = notebookID
+		mockShareService.EXPECT().Open(shareToken, "").Return(s, nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(validUser(), nil)
+		mockNotebookService.EXPECT().GetAccessible(userID, notebookID).Return(notebook.Notebook{}, notebook.ErrNotebookNotFound)
+		handler := NewShareHandler(mockShareService, nil, mockUserService, nil, mockNotebookService, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("should return internal server error when reading the note fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockShareService := share.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+
+		router := getRouter()
+		u := validUser()
+		mockShareService.EXPECT().Open(shareToken, "").Return(validShare(), nil)
+		mockUserService.EXPECT().CheckStatus(userID).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(getOAuth2Token(u.GithubToken), nil)
+		mockGithubService.EXPECT().GetFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(github.GitFile{}, errors.New("some error"))
+		handler := NewShareHandler(mockShareService, mockGithubService, mockUserService, mockTokenService, nil, nil)
+
+		router.GET("/s/:token", handler.ViewShare)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/s/"+shareToken, nil)
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
+
+func validShare() share.Share {
+	return share.Share{
+		Model:     gorm.Model{ID: shareID, CreatedAt: time.Date(2022, 10, 18, 10, 0, 0, 0, time.UTC)},
+		UserID:    userID,
+		Path:      notePath,
+		ViewCount: 3,
+	}
+}
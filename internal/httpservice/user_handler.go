@@ -3,21 +3,54 @@ package httpservice
 import (
 	"errors"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/batnoter/batnoter-api/internal/user"
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
 	"github.com/sirupsen/logrus"
 )
 
+// localeRegex matches the BCP 47 language tags like "en" or "en-US".
+var localeRegex = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// UserProfileRequestPayload represents the http request payload to edit the user profile.
+// Only the attributes present in the payload are updated, an empty value clears the attribute.
+type UserProfileRequestPayload struct {
+	DisplayName *string `json:"display_name"`
+	Timezone    *string `json:"timezone"`
+	Locale      *string `json:"locale"`
+	CommitName  *string `json:"commit_name"`
+	CommitEmail *string `json:"commit_email"`
+}
+
+// Validate validates the user profile http request payload.
+func (u UserProfileRequestPayload) Validate() error {
+	return validation.ValidateStruct(&u,
+		validation.Field(&u.DisplayName, validation.Length(0, 100)),
+		validation.Field(&u.Timezone, validation.Length(0, 64), validation.By(validateTimezone)),
+		validation.Field(&u.Locale, validation.Length(0, 35), validation.Match(localeRegex)),
+		validation.Field(&u.CommitName, validation.Length(0, 100)),
+		validation.Field(&u.CommitEmail, validation.Length(0, 255), is.Email),
+	)
+}
+
 // UserResponsePayload represents the http response payload of user entity.
 type UserResponsePayload struct {
 	Email       string       `json:"email"`
 	Name        string       `json:"name,omitempty"`
 	Location    string       `json:"location,omitempty"`
 	AvatarURL   string       `json:"avatar_url,omitempty"`
+	DisplayName string       `json:"display_name,omitempty"`
+	Timezone    string       `json:"timezone,omitempty"`
+	Locale      string       `json:"locale,omitempty"`
+	CommitName  string       `json:"commit_name"`
+	CommitEmail string       `json:"commit_email"`
 	DisabledAt  *time.Time   `json:"disabled_at,omitempty"`
 	DefaultRepo *RepoPayload `json:"default_repo,omitempty"`
 }
@@ -48,22 +81,89 @@ func (u *UserHandler) Profile(c *gin.Context) {
 		abortRequestWithError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, userResponse(dbUser))
+	logrus.WithField("user-id", userID).Info("request for profile successful")
+}
+
+// UpdateProfile updates the profile attributes edited by the user & returns the updated profile as a http response.
+// These attributes are not overwritten from github when the user logs in.
+func (u *UserHandler) UpdateProfile(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		logrus.Errorf("fetching user-id from context failed")
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	var profilePayload UserProfileRequestPayload
+	if err := c.ShouldBindJSON(&profilePayload); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeInvalidRequest, "invalid request payload"))
+		return
+	}
+	if err := profilePayload.Validate(); err != nil {
+		abortRequestWithError(c, NewAppError(ErrorCodeValidationFailed, err.Error()))
+		return
+	}
+
+	logrus.WithField("user-id", userID).Info("request to update profile started")
+	dbUser, err := u.userService.Get(userID)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	profile := dbUser.Profile()
+	setIfPresent(&profile.DisplayName, profilePayload.DisplayName)
+	setIfPresent(&profile.Timezone, profilePayload.Timezone)
+	setIfPresent(&profile.Locale, profilePayload.Locale)
+	setIfPresent(&profile.CommitName, profilePayload.CommitName)
+	setIfPresent(&profile.CommitEmail, profilePayload.CommitEmail)
+	dbUser, err = u.userService.UpdateProfile(userID, profile)
+	if err != nil {
+		abortRequestWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, userResponse(dbUser))
+	logrus.WithField("user-id", userID).Info("request to update profile successful")
+}
+
+func userResponse(dbUser user.User) UserResponsePayload {
 	userResp := UserResponsePayload{
-		Email:      dbUser.Email,
-		Name:       dbUser.Name,
-		Location:   dbUser.Location,
-		AvatarURL:  dbUser.AvatarURL,
-		DisabledAt: dbUser.DisabledAt,
+		Email:       dbUser.Email,
+		Name:        dbUser.GetDisplayName(),
+		Location:    dbUser.Location,
+		AvatarURL:   dbUser.AvatarURL,
+		DisplayName: dbUser.DisplayName,
+		Timezone:    dbUser.Timezone,
+		Locale:      dbUser.Locale,
+		CommitName:  dbUser.GetCommitName(),
+		CommitEmail: dbUser.GetCommitEmail(),
+		DisabledAt:  dbUser.DisabledAt,
 	}
-	if dbUser.DefaultRepo != nil {
+	// default repo record may only hold the github app installation until the repo is linked
+	if dbUser.DefaultRepo != nil && dbUser.DefaultRepo.Name != "" {
 		userResp.DefaultRepo = &RepoPayload{
 			Name:          dbUser.DefaultRepo.Name,
 			Visibility:    dbUser.DefaultRepo.Visibility,
 			DefaultBranch: dbUser.DefaultRepo.DefaultBranch,
 		}
 	}
-	c.JSON(http.StatusOK, userResp)
-	logrus.WithField("user-id", userID).Info("request for profile successful")
+	return userResp
+}
+
+func setIfPresent(attr *string, value *string) {
+	if value != nil {
+		*attr = strings.TrimSpace(*value)
+	}
+}
+
+func validateTimezone(value interface{}) error {
+	timezone, _ := value.(*string)
+	if timezone == nil || *timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(*timezone); err != nil {
+		return errors.New("must be a valid IANA time zone")
+	}
+	return nil
 }
 
 func getUserIDFromContext(c *gin.Context) (uint, error) {
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dgrijalva/jwt-go"
@@ -39,7 +40,8 @@ func TestProfile(t *testing.T) {
 
 		router.ServeHTTP(response, req)
 		assert.Equal(t, http.StatusOK, response.Code)
-		assert.JSONEq(t, `{"avatar_url":"http://example.com/avatar", "email":"john.doe@example.com", "location":"New York", "name":"John Doe"}`, response.Body.String())
+		assert.JSONEq(t, `{"avatar_url":"http://example.com/avatar", "email":"john.doe@example.com", "location":"New York", "name":"John Doe",
+			"commit_name":"John Doe", "commit_email":"john.doe@example.com"}`, response.Body.String())
 	})
 
 	t.Run("should fail with unauthorized response when the claims are not available in context", func(t *testing.T) {
@@ -81,3 +83,99 @@ func TestProfile(t *testing.T) {
 	})
 
 }
+
+func TestUpdateProfile(t *testing.T) {
+	t.Run("should update only the profile attributes present in the request payload", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewUserHandler(mockUserService)
+		dbUser := user.User{Email: email, Name: name, Timezone: "Europe/Berlin", Locale: "de-DE"}
+		profile := user.Profile{DisplayName: "Johnny", Timezone: "America/New_York", Locale: "de-DE", CommitEmail: "john.doe@work.example.com"}
+		updated := user.User{Email: email, Name: name, DisplayName: "Johnny", Timezone: "America/New_York", Locale: "de-DE", CommitEmail: "john.doe@work.example.com"}
+		mockUserService.EXPECT().Get(userID).Return(dbUser, nil)
+		mockUserService.EXPECT().UpdateProfile(userID, profile).Return(updated, nil)
+
+		// simulate auth middleware with custom handler
+		router.PATCH("/api/v1/user/me", getClaimsHandler(), handler.UpdateProfile)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, "/api/v1/user/me",
+			strings.NewReader(`{"display_name":" Johnny ","timezone":"America/New_York","commit_email":"john.doe@work.example.com"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.JSONEq(t, `{"email":"john.doe@example.com", "name":"Johnny", "display_name":"Johnny", "timezone":"America/New_York", "locale":"de-DE",
+			"commit_name":"Johnny", "commit_email":"john.doe@work.example.com"}`, response.Body.String())
+	})
+
+	t.Run("should clear the profile attribute when the value is empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewUserHandler(mockUserService)
+		dbUser := user.User{Email: email, Name: name, DisplayName: "Johnny", CommitName: "John at Work"}
+		mockUserService.EXPECT().Get(userID).Return(dbUser, nil)
+		mockUserService.EXPECT().UpdateProfile(userID, user.Profile{DisplayName: "Johnny"}).Return(user.User{Email: email, Name: name, DisplayName: "Johnny"}, nil)
+
+		// simulate auth middleware with custom handler
+		router.PATCH("/api/v1/user/me", getClaimsHandler(), handler.UpdateProfile)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, "/api/v1/user/me", strings.NewReader(`{"commit_name":""}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should fail with validation error when the request payload is invalid", func(t *testing.T) {
+		tests := []struct {
+			payload string
+			message string
+		}{
+			{`{"timezone":"Mars/Olympus_Mons"}`, "timezone: must be a valid IANA time zone."},
+			{`{"locale":"english!"}`, "locale: must be in a valid format."},
+			{`{"commit_email":"john.doe"}`, "commit_email: must be a valid email address."},
+			{`{"display_name":"` + strings.Repeat("a", 101) + `"}`, "display_name: the length must be no more than 100."},
+		}
+		for _, test := range tests {
+			gin.SetMode(gin.TestMode)
+			router := gin.Default()
+			handler := NewUserHandler(nil)
+
+			// simulate auth middleware with custom handler
+			router.PATCH("/api/v1/user/me", getClaimsHandler(), handler.UpdateProfile)
+			response := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPatch, "/api/v1/user/me", strings.NewReader(test.payload))
+
+			router.ServeHTTP(response, req)
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			assert.JSONEq(t, `{"code":"validation_failed", "message":"`+test.message+`"}`, response.Body.String())
+		}
+	})
+
+	t.Run("should fail with internal server error when updating profile fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUserService := user.NewMockService(ctrl)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.Default()
+		handler := NewUserHandler(mockUserService)
+		mockUserService.EXPECT().Get(userID).Return(user.User{Email: email}, nil)
+		mockUserService.EXPECT().UpdateProfile(userID, user.Profile{Locale: "en-US"}).Return(user.User{}, errors.New("some error"))
+
+		// simulate auth middleware with custom handler
+		router.PATCH("/api/v1/user/me", getClaimsHandler(), handler.UpdateProfile)
+		response := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPatch, "/api/v1/user/me", strings.NewReader(`{"locale":"en-US"}`))
+
+		router.ServeHTTP(response, req)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.JSONEq(t, internalServerErrJSON, response.Body.String())
+	})
+}
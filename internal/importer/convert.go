@@ -0,0 +1,365 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrInvalidUpload is returned when the upload can not be read as an export of the source.
+var ErrInvalidUpload = errors.New("upload is not a valid export of the source")
+
+// ErrTooManyItems is returned when the upload contains more items than can be imported at once.
+var ErrTooManyItems = errors.New("upload contains too many items")
+
+// ErrUploadTooLarge is returned when the files of the uploaded archive are too large to be imported once extracted.
+var ErrUploadTooLarge = errors.New("upload is too large once extracted")
+
+var errFileTooLarge = errors.New("file of the archive is too large")
+
+const (
+	// maximum count of the items (notes & attachments) of an upload
+	maxItems = 2000
+
+	// attachments larger than this size are skipped, github does not accept large files through api
+	maxAttachmentSize = 10 << 20
+
+	// maximum size of the zip archives within the uploaded archive
+	maxNestedZipSize = 64 << 20
+
+	// maximum total size of the files of the uploaded archive once extracted, the archive may be highly compressed
+	maxExtractedSize = 256 << 20
+
+	// name of the notes whose name does not contain any valid character
+	untitledName = "Untitled"
+)
+
+// converter converts an uploaded export to the notes & attachments to be committed to the repo.
+// progress is called as the items of the upload are converted.
+type converter func(upload []byte, progress func(total int, processed int)) (*collection, error)
+
+// converters are the converters of the uploads by their source.
+var converters = map[string]converter{
+	SourceObsidian: convertObsidian,
+	SourceNotion:   convertNotion,
+	SourceEvernote: convertEvernote,
+}
+
+// collection collects the converted files with a unique valid path along with the skipped items of the upload.
+type collection struct {
+	files       map[string]string // content of the files by their path relative to the import folder
+	paths       map[string]bool   // lower case paths in use, github repos can be checked out on case-insensitive file systems
+	notes       int
+	attachments int
+	skipped     SkippedItems
+}
+
+func newCollection() *collection {
+	return &collection{
+		files: make(map[string]string),
+		paths: make(map[string]bool),
+	}
+}
+
+// notePath reserves & returns a unique path for the note with the (unsanitized) folders & name.
+// The returned path matches the default rules of the note files, the names are sanitized & suffixed with a number when used already.
+func (c *collection) notePath(dirs []string, name string) string {
+	dir := sanitizeDirs(dirs)
+	base := sanitizeName(name)
+	if base == "" {
+		base = untitledName
+	}
+	return c.reserve(dir, base, ".md")
+}
+
+// attachmentPath reserves & returns a unique path for the attachment with the (unsanitized) folders & file name.
+func (c *collection) attachmentPath(dirs []string, fileName string) string {
+	dir := sanitizeDirs(dirs)
+	ext := path.Ext(fileName)
+	base := sanitizeName(strings.TrimSuffix(fileName, ext))
+	ext = strings.ToLower(sanitizeName(ext))
+	if base == "" {
+		base = "attachment"
+	}
+	if ext != "" {
+		ext = "." + ext
+	}
+	return c.reserve(dir, base, ext)
+}
+
+func (c *collection) reserve(dir string, base string, ext string) string {
+	p := path.Join(dir, base+ext)
+	for i := 2; c.paths[strings.ToLower(p)]; i++ {
+		p = path.Join(dir, fmt.Sprintf("%s %d%s", base, i, ext))
+	}
+	c.paths[strings.ToLower(p)] = true
+	return p
+}
+
+// addNote adds the note with the reserved path to the collection.
+func (c *collection) addNote(p string, content string) {
+	c.files[p] = content
+	c.notes++
+}
+
+// addAttachment adds the attachment with the reserved path to the collection.
+func (c *collection) addAttachment(p string, content []byte) {
+	c.files[p] = string(content)
+	c.attachments++
+}
+
+// skip reports the item of the upload which could not be imported.
+func (c *collection) skip(name string, reason string) {
+	c.skipped = append(c.skipped, SkippedItem{Name: name, Reason: reason})
+}
+
+// filesIn returns the files of the collection moved to the folder. The notes whose path does not match the
+// rules of the note files of the repo are skipped since they can not be opened, the sanitized names always match
+// the default rules unless the folder does not.
+func (c *collection) filesIn(folder string, rules github.NoteFileRules) (map[string]string, error) {
+	validNotePath, err := rules.Regexp()
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(c.files))
+	for p, content := range c.files {
+		p = path.Join(folder, p)
+		if strings.HasSuffix(p, ".md") && !validNotePath.MatchString(p) {
+			c.notes--
+			c.skip(p, "invalid note path")
+			continue
+		}
+		files[p] = content
+	}
+	return files, nil
+}
+
+// stripMarks removes the diacritical marks so that the accented letters are retained as ascii letters in the paths.
+var stripMarks = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// sanitizeName returns the name with only the characters allowed by ValidNotePathRegex.
+// Letters & digits (without diacritical marks) and hyphens are retained, the other characters are replaced by
+// single spaces & the invalid characters (e.g. emojis) are removed.
+func sanitizeName(name string) string {
+	if s, _, err := transform.String(stripMarks, name); err == nil {
+		name = s
+	}
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-'):
+			b.WriteRune(r)
+		case r < unicode.MaxASCII || unicode.IsSpace(r):
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+func sanitizeDirs(dirs []string) string {
+	sanitized := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if s := sanitizeName(dir); s != "" {
+			sanitized = append(sanitized, s)
+		}
+	}
+	return strings.Join(sanitized, "/")
+}
+
+// splitPath returns the folders & the name of the slash separated path.
+func splitPath(p string) ([]string, string) {
+	parts := strings.Split(strings.Trim(p, "/"), "/")
+	return parts[:len(parts)-1], parts[len(parts)-1]
+}
+
+// relativeURL returns the url of the target path relative to the folder of the note, the segments of the url are escaped.
+func relativeURL(from string, to string) string {
+	fromDirs := strings.Split(path.Dir(from), "/")
+	if fromDirs[0] == "." {
+		fromDirs = nil
+	}
+	toParts := strings.Split(to, "/")
+	common := 0
+	for common < len(fromDirs) && common < len(toParts)-1 && fromDirs[common] == toParts[common] {
+		common++
+	}
+	segments := make([]string, 0, len(fromDirs)-common+len(toParts)-common)
+	for i := common; i < len(fromDirs); i++ {
+		segments = append(segments, "..")
+	}
+	for _, part := range toParts[common:] {
+		segments = append(segments, url.PathEscape(part))
+	}
+	return strings.Join(segments, "/")
+}
+
+// markdownLinkRegex matches the inline markdown links & images along with their destination which is optionally
+// enclosed in angle brackets.
+var markdownLinkRegex = regexp.MustCompile(`(!?\[[^\]\n]*\])\((<[^>\n]+>|[^)\s]+)((?:\s+"[^"\n]*")?\))`)
+
+// rewriteLinks rewrites the relative destinations of the markdown links of the note with the result of resolve.
+// resolve receives the destination resolved against the original path of the note & returns the new path of the
+// destination or an empty string when the destination is not imported. The destinations which are not resolved are retained.
+func rewriteLinks(content string, originalPath string, newPath string, resolve func(p string) string) string {
+	return markdownLinkRegex.ReplaceAllStringFunc(content, func(link string) string {
+		m := markdownLinkRegex.FindStringSubmatch(link)
+		destination := strings.TrimSuffix(strings.TrimPrefix(m[2], "<"), ">")
+		u, err := url.Parse(destination)
+		if err != nil {
+			// destinations in angle brackets may contain spaces which are not escaped
+			u, err = url.Parse(strings.ReplaceAll(destination, " ", "%20"))
+		}
+		if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+			return link
+		}
+		target := resolve(path.Join(path.Dir(originalPath), u.Path))
+		if target == "" {
+			return link
+		}
+		result := relativeURL(newPath, target)
+		if u.Fragment != "" {
+			result += "#" + headingAnchor(u.Fragment)
+		}
+		return m[1] + "(" + result + m[3]
+	})
+}
+
+var nonAnchorChars = regexp.MustCompile(`[^\p{L}\p{N}\- ]+`)
+
+// headingAnchor returns the anchor of the heading as generated by the markdown renderer of the notes.
+func headingAnchor(heading string) string {
+	anchor := nonAnchorChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(heading)), "")
+	return strings.ReplaceAll(anchor, " ", "-")
+}
+
+// zipEntry represents a file of the uploaded zip archive.
+type zipEntry struct {
+	name string // slash separated path of the file in the archive
+	file *zip.File
+}
+
+// readZip returns the files of the zip archive skipping the directories & the files created by the operating
+// systems (e.g. __MACOSX). The zip archives within the archive (e.g. the parts of a large notion export) are expanded.
+// The common top level folder of the files is removed from their names.
+// The size of the files is declared by the archive & enforced while the files are read, the upload is rejected
+// when the files are too large in total once extracted.
+func readZip(upload []byte) ([]zipEntry, error) {
+	zr, err := zip.NewReader(bytes.NewReader(upload), int64(len(upload)))
+	if err != nil {
+		return nil, ErrInvalidUpload
+	}
+	entries := make([]zipEntry, 0, len(zr.File))
+	var extractedSize uint64
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(strings.ReplaceAll(f.Name, `\`, "/"), "/")
+		if f.FileInfo().IsDir() || isSystemFile(name) {
+			continue
+		}
+		if strings.EqualFold(path.Ext(name), ".zip") {
+			nested, err := readNestedZip(f)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, nested...)
+			continue
+		}
+		entries = append(entries, zipEntry{name: name, file: f})
+	}
+	if len(entries) > maxItems {
+		return nil, ErrTooManyItems
+	}
+	for _, e := range entries {
+		if extractedSize += e.file.UncompressedSize64; extractedSize > maxExtractedSize {
+			return nil, ErrUploadTooLarge
+		}
+	}
+	return trimCommonFolder(entries), nil
+}
+
+func readNestedZip(f *zip.File) ([]zipEntry, error) {
+	if f.UncompressedSize64 > maxNestedZipSize {
+		return nil, ErrUploadTooLarge
+	}
+	content, err := readLimited(f, maxNestedZipSize)
+	if errors.Is(err, errFileTooLarge) {
+		return nil, ErrUploadTooLarge
+	}
+	if err != nil {
+		return nil, ErrInvalidUpload
+	}
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, ErrInvalidUpload
+	}
+	entries := make([]zipEntry, 0, len(zr.File))
+	for _, nf := range zr.File {
+		name := strings.TrimPrefix(strings.ReplaceAll(nf.Name, `\`, "/"), "/")
+		if !nf.FileInfo().IsDir() && !isSystemFile(name) {
+			entries = append(entries, zipEntry{name: name, file: nf})
+		}
+	}
+	return entries, nil
+}
+
+// readZipFile reads the file of the archive, the files larger than the attachments are not read.
+func readZipFile(f *zip.File) ([]byte, error) {
+	return readLimited(f, maxAttachmentSize)
+}
+
+// readLimited reads at most limit bytes of the file of the archive, errFileTooLarge is returned when the file is larger.
+func readLimited(f *zip.File, limit int64) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		return nil, errFileTooLarge
+	}
+	return content, nil
+}
+
+// isSystemFile checks whether the file is created by the operating system or the file is hidden (e.g. .obsidian config).
+func isSystemFile(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" || part == "Thumbs.db" {
+			return true
+		}
+	}
+	return false
+}
+
+func trimCommonFolder(entries []zipEntry) []zipEntry {
+	if len(entries) == 0 {
+		return entries
+	}
+	folder, _, found := strings.Cut(entries[0].name, "/")
+	if !found {
+		return entries
+	}
+	for _, e := range entries {
+		if !strings.HasPrefix(e.name, folder+"/") {
+			return entries
+		}
+	}
+	for i := range entries {
+		entries[i].name = strings.TrimPrefix(entries[i].name, folder+"/")
+	}
+	return entries
+}
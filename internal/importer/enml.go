@@ -0,0 +1,308 @@
+package importer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// enmlMedia represents the resource referenced from the content of an evernote note.
+type enmlMedia struct {
+	URL   string
+	Name  string
+	Image bool
+}
+
+// enmlConverter converts the enml (evernote markup language) content of the evernote notes to markdown.
+type enmlConverter struct {
+	media     map[string]enmlMedia // resources by the md5 hash of their data
+	encrypted bool                 // whether the content contains encrypted text which can not be converted
+}
+
+var (
+	blankLinesRegex    = regexp.MustCompile(`\n{3,}`)
+	trailingSpaceRegex = regexp.MustCompile(`[ \t]+\n`)
+	markdownEscaper    = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+)
+
+// enmlToMarkdown converts the enml content to markdown. It also reports whether the content contains encrypted text
+// which is left out of the note.
+func enmlToMarkdown(content string, media map[string]enmlMedia) (string, bool) {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return "", false
+	}
+	c := &enmlConverter{media: media}
+	var b strings.Builder
+	c.children(doc, &b)
+	return cleanMarkdown(b.String()) + "\n", c.encrypted
+}
+
+func cleanMarkdown(s string) string {
+	s = trailingSpaceRegex.ReplaceAllString(s, "\n")
+	s = blankLinesRegex.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+func (c *enmlConverter) children(n *html.Node, b *strings.Builder) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child, b)
+	}
+}
+
+// render returns the markdown of the children of the node.
+func (c *enmlConverter) render(n *html.Node) string {
+	var b strings.Builder
+	c.children(n, &b)
+	return b.String()
+}
+
+// node writes the markdown of the node. The html parser does not support the self-closing tags of the unknown elements,
+// so the content following the self-closing en-todo & en-media elements is nested within them & it is written as well.
+func (c *enmlConverter) node(n *html.Node, b *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text == "" {
+			if n.Data != "" && !atLineStart(b) {
+				b.WriteString(" ")
+			}
+			return
+		}
+		if strings.TrimLeft(n.Data, " \t\r\n") != n.Data && !atLineStart(b) {
+			text = " " + text
+		}
+		if strings.TrimRight(n.Data, " \t\r\n") != n.Data {
+			text += " "
+		}
+		b.WriteString(markdownEscaper.Replace(text))
+		return
+	case html.ElementNode:
+	default:
+		c.children(n, b)
+		return
+	}
+
+	switch n.Data {
+	case "head", "script", "style", "title":
+	case "en-crypt":
+		c.encrypted = true
+	case "br":
+		b.WriteString("\n")
+	case "hr":
+		b.WriteString("\n\n---\n\n")
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Data[1:])
+		b.WriteString("\n\n" + strings.Repeat("#", level) + " " + inline(c.render(n)) + "\n\n")
+	case "b", "strong":
+		c.wrap(n, b, "**")
+	case "i", "em":
+		c.wrap(n, b, "_")
+	case "s", "strike", "del":
+		c.wrap(n, b, "~~")
+	case "code":
+		b.WriteString("`" + textContent(n) + "`")
+	case "pre":
+		b.WriteString("\n\n```\n" + strings.Trim(textContent(n), "\n") + "\n```\n\n")
+	case "a":
+		text := inline(c.render(n))
+		href := attr(n, "href")
+		if text == "" || href == "" {
+			b.WriteString(text)
+			break
+		}
+		b.WriteString("[" + text + "](" + escapeDestination(href) + ")")
+	case "img":
+		if src := attr(n, "src"); src != "" {
+			b.WriteString("![" + markdownEscaper.Replace(attr(n, "alt")) + "](" + escapeDestination(src) + ")")
+		}
+	case "en-media":
+		c.writeMedia(n, b)
+		c.children(n, b)
+	case "en-todo":
+		checkbox := "[ ] "
+		if attr(n, "checked") == "true" {
+			checkbox = "[x] "
+		}
+		if !hasAncestor(n, "li") {
+			checkbox = "- " + checkbox
+		}
+		b.WriteString(checkbox)
+		c.children(n, b)
+	case "ul", "ol":
+		c.writeList(n, b)
+	case "blockquote":
+		quote := cleanMarkdown(c.render(n))
+		b.WriteString("\n\n> " + strings.ReplaceAll(quote, "\n", "\n> ") + "\n\n")
+	case "table":
+		c.writeTable(n, b)
+	case "div", "p", "en-note", "center", "section", "article":
+		if strings.Contains(strings.ReplaceAll(attr(n, "style"), " ", ""), "-en-codeblock:true") {
+			b.WriteString("\n\n```\n" + strings.Trim(textContent(n), "\n") + "\n```\n\n")
+			break
+		}
+		b.WriteString("\n\n")
+		c.children(n, b)
+		b.WriteString("\n\n")
+	default:
+		c.children(n, b)
+	}
+}
+
+// wrap writes the children of the node enclosed in the delimiter, the spaces around the text are kept outside the delimiters.
+func (c *enmlConverter) wrap(n *html.Node, b *strings.Builder, delimiter string) {
+	text := c.render(n)
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		b.WriteString(text)
+		return
+	}
+	if strings.TrimLeft(text, " ") != text {
+		b.WriteString(" ")
+	}
+	b.WriteString(delimiter + trimmed + delimiter)
+	if strings.TrimRight(text, " ") != text {
+		b.WriteString(" ")
+	}
+}
+
+func (c *enmlConverter) writeMedia(n *html.Node, b *strings.Builder) {
+	media, ok := c.media[attr(n, "hash")]
+	if !ok {
+		return
+	}
+	if media.Image {
+		b.WriteString("![" + markdownEscaper.Replace(media.Name) + "](" + media.URL + ")")
+		return
+	}
+	b.WriteString("[" + markdownEscaper.Replace(media.Name) + "](" + media.URL + ")")
+}
+
+// writeList writes the items of the list, the nested content of the items is indented under the list marker.
+func (c *enmlConverter) writeList(n *html.Node, b *strings.Builder) {
+	b.WriteString("\n\n")
+	number := 1
+	for item := n.FirstChild; item != nil; item = item.NextSibling {
+		if item.Type != html.ElementNode || item.Data != "li" {
+			continue
+		}
+		marker := "- "
+		if n.Data == "ol" {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		content := blankLinesRegex.ReplaceAllString(cleanMarkdown(c.render(item)), "\n\n")
+		content = strings.ReplaceAll(strings.ReplaceAll(content, "\n\n", "\n"), "\n", "\n"+strings.Repeat(" ", len(marker)))
+		b.WriteString(marker + content + "\n")
+	}
+	b.WriteString("\n")
+}
+
+// writeTable writes the table as a markdown table with its first row as the header.
+func (c *enmlConverter) writeTable(n *html.Node, b *strings.Builder) {
+	var rows [][]string
+	var collect func(node *html.Node)
+	collect = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if child.Data != "tr" {
+				collect(child)
+				continue
+			}
+			var row []string
+			for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+					row = append(row, tableCell(cleanMarkdown(c.render(cell))))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return
+	}
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	b.WriteString("\n\n")
+	for i, row := range rows {
+		b.WriteString("|")
+		for j := 0; j < columns; j++ {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString(strings.Repeat("| --- ", columns) + "|\n")
+		}
+	}
+	b.WriteString("\n")
+}
+
+// textContent returns the text of the node keeping the line breaks of the block elements.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			b.WriteString(node.Data)
+			return
+		}
+		if node.Type == html.ElementNode && node.Data == "br" {
+			b.WriteString("\n")
+			return
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if node.Type == html.ElementNode && (node.Data == "div" || node.Data == "p") && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// inline returns the markdown on a single line.
+func inline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func atLineStart(b *strings.Builder) bool {
+	s := b.String()
+	return s == "" || strings.HasSuffix(s, "\n") || strings.HasSuffix(s, " ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAncestor(n *html.Node, tag string) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeDestination escapes the spaces & parentheses of the link destination which end the destination in markdown.
+func escapeDestination(destination string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(destination)
+}
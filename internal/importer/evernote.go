@@ -0,0 +1,164 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// evernoteAttachmentsFolder is the folder the resources of the evernote notes are imported to.
+const evernoteAttachmentsFolder = "attachments"
+
+// evernoteTimeLayout is the layout of the timestamps of the enex export.
+const evernoteTimeLayout = "20060102T150405Z"
+
+// enexNote represents a note of the enex export.
+type enexNote struct {
+	Title     string         `xml:"title"`
+	Content   string         `xml:"content"`
+	Created   string         `xml:"created"`
+	Tags      []string       `xml:"tag"`
+	Resources []enexResource `xml:"resource"`
+}
+
+// enexResource represents a file (e.g. image) attached to a note of the enex export.
+type enexResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+	Mime     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+// evernoteFrontMatter represents the front matter of the notes imported from evernote.
+type evernoteFrontMatter struct {
+	Title   string   `yaml:"title"`
+	Created string   `yaml:"created,omitempty"`
+	Tags    []string `yaml:"tags,omitempty"`
+}
+
+// convertEvernote converts the enex export of evernote.
+// The notes are converted from enml to markdown with a front matter containing the title, creation time & tags of the note.
+// The resources of the notes are imported as attachments to the attachments folder.
+func convertEvernote(upload []byte, progress func(total int, processed int)) (*collection, error) {
+	total := bytes.Count(upload, []byte("<note>"))
+	if total > maxItems {
+		return nil, ErrTooManyItems
+	}
+	c := newCollection()
+	decoder := xml.NewDecoder(bytes.NewReader(upload))
+	foundExport := false
+	processed := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidUpload
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "en-export":
+			foundExport = true
+		case "note":
+			var note enexNote
+			if err := decoder.DecodeElement(&note, &start); err != nil {
+				return nil, ErrInvalidUpload
+			}
+			c.addEvernoteNote(note)
+			processed++
+			progress(total, processed)
+		}
+	}
+	if !foundExport {
+		return nil, ErrInvalidUpload
+	}
+	return c, nil
+}
+
+func (c *collection) addEvernoteNote(note enexNote) {
+	title := strings.TrimSpace(note.Title)
+	if title == "" {
+		title = untitledName
+	}
+	notePath := c.notePath(nil, title)
+
+	// resources are referenced from the content of the note by the md5 hash of their data
+	media := make(map[string]enmlMedia, len(note.Resources))
+	for _, resource := range note.Resources {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(resource.Data.Value), ""))
+		if err != nil {
+			c.skip(title+"/"+resource.FileName, "attachment could not be read")
+			continue
+		}
+		if len(data) > maxAttachmentSize {
+			c.skip(title+"/"+resource.FileName, "attachment is larger than 10 MB")
+			continue
+		}
+		fileName := resource.FileName
+		if fileName == "" {
+			fileName = "attachment" + mimeExtension(resource.Mime)
+		}
+		attachmentPath := c.attachmentPath([]string{evernoteAttachmentsFolder}, fileName)
+		c.addAttachment(attachmentPath, data)
+		hash := md5.Sum(data)
+		media[hex.EncodeToString(hash[:])] = enmlMedia{
+			URL:   relativeURL(notePath, attachmentPath),
+			Name:  fileName,
+			Image: strings.HasPrefix(resource.Mime, "image/"),
+		}
+	}
+
+	content, encrypted := enmlToMarkdown(note.Content, media)
+	if encrypted {
+		c.skip(title, "encrypted content is not imported")
+	}
+	frontMatter, err := yaml.Marshal(evernoteFrontMatter{Title: title, Created: evernoteTime(note.Created), Tags: note.Tags})
+	if err != nil {
+		c.skip(title, "note could not be converted")
+		return
+	}
+	c.addNote(notePath, "---\n"+string(frontMatter)+"---\n\n"+content)
+}
+
+// evernoteTime converts the timestamp of the enex export to RFC 3339 format.
+func evernoteTime(value string) string {
+	t, err := time.Parse(evernoteTimeLayout, value)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// mimeExtensions are the extensions of the common mime types of the resources, mime package returns multiple
+// extensions for some of these types (e.g. .jfif for image/jpeg).
+var mimeExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+}
+
+func mimeExtension(mimeType string) string {
+	if ext, ok := mimeExtensions[mimeType]; ok {
+		return ext
+	}
+	extensions, err := mime.ExtensionsByType(mimeType)
+	if err != nil || len(extensions) == 0 {
+		return ""
+	}
+	return extensions[0]
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertEvernote(t *testing.T) {
+	t.Run("should convert the notes from enml to markdown & import the resources", func(t *testing.T) {
+		image := []byte("\x89PNG image")
+		hash := md5.Sum(image)
+		upload := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE en-export SYSTEM "http://xml.evernote.com/pub/evernote-export4.dtd">
+<en-export export-date="20221019T100000Z" application="Evernote" version="10.0">
+<note>
+<title>Meeting: Q4 planning</title>
+<created>20221001T093000Z</created>
+<tag>work</tag>
+<tag>planning</tag>
+<content><![CDATA[<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE en-note SYSTEM "http://xml.evernote.com/pub/enml2.dtd">
+<en-note><h1>Agenda</h1><div>Review <b>roadmap</b> &amp; <i>budget</i>, see <a href="https://example.com/q4">the plan</a>.</div>
+<div><en-todo checked="true"/>Book room</div><div><en-todo checked="false"/>Send notes</div>
+<ul><li>First</li><li>Second<ol><li>Nested</li></ol></li></ul>
+<div><en-media hash="` + hex.EncodeToString(hash[:]) + `" type="image/png"/> on the board</div>
+<table><tr><th>Owner</th><th>Task</th></tr><tr><td>John</td><td>snake_case</td></tr></table>
+<div style="-en-codeblock: true;"><div>go test ./...</div><div>go vet ./...</div></div>
+<div><en-crypt cipher="AES">c2VjcmV0</en-crypt></div></en-note>]]></content>
+<resource>
+<data encoding="base64">
+` + base64.StdEncoding.EncodeToString(image) + `
+</data>
+<mime>image/png</mime>
+<resource-attributes><file-name>board photo.png</file-name></resource-attributes>
+</resource>
+</note>
+<note><title></title><content><![CDATA[<en-note><div>Untitled note</div></en-note>]]></content></note>
+</en-export>`)
+		var processed []int
+		c, err := convertEvernote(upload, func(total int, p int) {
+			assert.Equal(t, 2, total)
+			processed = append(processed, p)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, processed)
+		assert.Equal(t, 2, c.notes)
+		assert.Equal(t, 1, c.attachments)
+		assert.Equal(t, SkippedItems{{Name: "Meeting: Q4 planning", Reason: "encrypted content is not imported"}}, c.skipped)
+		assert.ElementsMatch(t, []string{"Meeting Q4 planning.md", "Untitled.md", "attachments/board photo.png"}, keys(c.files))
+		assert.Equal(t, string(image), c.files["attachments/board photo.png"])
+		assert.Equal(t, `---
+title: 'Meeting: Q4 planning'
+created: "2022-10-01T09:30:00Z"
+tags:
+    - work
+    - planning
+---
+
+# Agenda
+
+Review **roadmap** & _budget_, see [the plan](https://example.com/q4).
+
+- [x] Book room
+
+- [ ] Send notes
+
+- First
+- Second
+  1. Nested
+
+![board photo.png](attachments/board%20photo.png) on the board
+
+| Owner | Task |
+| --- | --- |
+| John | snake\_case |
+
+`+"```"+`
+go test ./...
+go vet ./...
+`+"```"+`
+`, c.files["Meeting Q4 planning.md"])
+		assert.Equal(t, "---\ntitle: Untitled\n---\n\nUntitled note\n", c.files["Untitled.md"])
+	})
+
+	t.Run("should return error when the upload is not an enex export", func(t *testing.T) {
+		_, err := convertEvernote([]byte(`<?xml version="1.0"?><notes></notes>`), func(int, int) {})
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+	})
+
+	t.Run("should return error when the upload is not xml", func(t *testing.T) {
+		_, err := convertEvernote([]byte(`<en-export><note>`), func(int, int) {})
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+	})
+}
@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+
+// Package importer is a generated GoMock package.
+package importer
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepo is a mock of Repo interface.
+type MockRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder
+}
+
+// MockRepoMockRecorder is the mock recorder for MockRepo.
+type MockRepoMockRecorder struct {
+	mock *MockRepo
+}
+
+// NewMockRepo creates a new mock instance.
+func NewMockRepo(ctrl *gomock.Controller) *MockRepo {
+	mock := &MockRepo{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockRepo) Get(userID, importID uint) (Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", userID, importID)
+	ret0, _ := ret[0].(Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRepoMockRecorder) Get(userID, importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepo)(nil).Get), userID, importID)
+}
+
+// GetAllUnfinished mocks base method.
+func (m *MockRepo) GetAllUnfinished() ([]Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllUnfinished")
+	ret0, _ := ret[0].([]Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllUnfinished indicates an expected call of GetAllUnfinished.
+func (mr *MockRepoMockRecorder) GetAllUnfinished() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUnfinished", reflect.TypeOf((*MockRepo)(nil).GetAllUnfinished))
+}
+
+// GetLatestByUserID mocks base method.
+func (m *MockRepo) GetLatestByUserID(userID uint) (Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestByUserID", userID)
+	ret0, _ := ret[0].(Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestByUserID indicates an expected call of GetLatestByUserID.
+func (mr *MockRepoMockRecorder) GetLatestByUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestByUserID", reflect.TypeOf((*MockRepo)(nil).GetLatestByUserID), userID)
+}
+
+// Save mocks base method.
+func (m *MockRepo) Save(imp Import) (Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", imp)
+	ret0, _ := ret[0].(Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRepoMockRecorder) Save(imp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepo)(nil).Save), imp)
+}
+
+// UpdateProgress mocks base method.
+func (m *MockRepo) UpdateProgress(importID uint, total, processed int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProgress", importID, total, processed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProgress indicates an expected call of UpdateProgress.
+func (mr *MockRepoMockRecorder) UpdateProgress(importID, total, processed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockRepo)(nil).UpdateProgress), importID, total, processed)
+}
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package importer is a generated GoMock package.
+package importer
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockService) Get(userID, importID uint) (Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", userID, importID)
+	ret0, _ := ret[0].(Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockServiceMockRecorder) Get(userID, importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), userID, importID)
+}
+
+// GetLatest mocks base method.
+func (m *MockService) GetLatest(userID uint) (Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatest", userID)
+	ret0, _ := ret[0].(Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatest indicates an expected call of GetLatest.
+func (mr *MockServiceMockRecorder) GetLatest(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatest", reflect.TypeOf((*MockService)(nil).GetLatest), userID)
+}
+
+// Request mocks base method.
+func (m *MockService) Request(imp Import) (Import, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request", imp)
+	ret0, _ := ret[0].(Import)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Request indicates an expected call of Request.
+func (mr *MockServiceMockRecorder) Request(imp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockService)(nil).Request), imp)
+}
+
+// ResumeUnfinished mocks base method.
+func (m *MockService) ResumeUnfinished() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeUnfinished")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeUnfinished indicates an expected call of ResumeUnfinished.
+func (mr *MockServiceMockRecorder) ResumeUnfinished() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeUnfinished", reflect.TypeOf((*MockService)(nil).ResumeUnfinished))
+}
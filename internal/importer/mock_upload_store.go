@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: upload_store.go
+
+// Package importer is a generated GoMock package.
+package importer
+
+import (
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUploadStore is a mock of UploadStore interface.
+type MockUploadStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadStoreMockRecorder
+}
+
+// MockUploadStoreMockRecorder is the mock recorder for MockUploadStore.
+type MockUploadStoreMockRecorder struct {
+	mock *MockUploadStore
+}
+
+// NewMockUploadStore creates a new mock instance.
+func NewMockUploadStore(ctrl *gomock.Controller) *MockUploadStore {
+	mock := &MockUploadStore{ctrl: ctrl}
+	mock.recorder = &MockUploadStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadStore) EXPECT() *MockUploadStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockUploadStore) Delete(importID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", importID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUploadStoreMockRecorder) Delete(importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUploadStore)(nil).Delete), importID)
+}
+
+// Open mocks base method.
+func (m *MockUploadStore) Open(importID uint) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", importID)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockUploadStoreMockRecorder) Open(importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockUploadStore)(nil).Open), importID)
+}
+
+// Save mocks base method.
+func (m *MockUploadStore) Save(importID uint, write func(io.Writer) error) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", importID, write)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockUploadStoreMockRecorder) Save(importID, write interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockUploadStore)(nil).Save), importID, write)
+}
@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// StatusPending is the status of the import waiting to be processed.
+	StatusPending = "pending"
+
+	// StatusRunning is the status of the import being processed.
+	StatusRunning = "running"
+
+	// StatusCompleted is the status of the import whose notes are committed to the repo.
+	StatusCompleted = "completed"
+
+	// StatusFailed is the status of the import which could not be processed.
+	StatusFailed = "failed"
+)
+
+const (
+	// SourceObsidian is the source of the imports of the zip archive of an obsidian vault.
+	SourceObsidian = "obsidian"
+
+	// SourceNotion is the source of the imports of the markdown & csv export (zip archive) of notion.
+	SourceNotion = "notion"
+
+	// SourceEvernote is the source of the imports of the enex export of evernote.
+	SourceEvernote = "evernote"
+)
+
+// Sources are the sources the notes can be imported from.
+var Sources = []interface{}{SourceObsidian, SourceNotion, SourceEvernote}
+
+// Import represents an entity model used to store & retrieve the imports of the notes to/from database.
+// Upload holds the uploaded export until it is stored in the upload store, it is not stored in the database.
+type Import struct {
+	gorm.Model
+	UserID uint
+
+	Source      string
+	FileName    string
+	Path        string // folder of the repo the notes are imported to
+	Status      string
+	Error       string
+	Upload      []byte `gorm:"-"`
+	Total       int    // count of the items found in the upload
+	Processed   int    // count of the items converted so far
+	Notes       int
+	Attachments int
+	Skipped     SkippedItems
+	CommitSHA   string
+	CompletedAt *time.Time
+}
+
+// Finished checks whether the processing of the import is finished.
+func (i Import) Finished() bool {
+	return i.Status == StatusCompleted || i.Status == StatusFailed
+}
+
+// SkippedItem represents an item of the upload which could not be imported.
+type SkippedItem struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// SkippedItems are the items of the upload which could not be imported, they are stored as a json array.
+type SkippedItems []SkippedItem
+
+// Value returns the json array of the skipped items to be stored in database.
+func (s SkippedItems) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan reads the skipped items from the json array stored in database.
+func (s *SkippedItems) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case []byte:
+		return json.Unmarshal(v, s)
+	}
+	return errors.New("unsupported type of skipped items")
+}
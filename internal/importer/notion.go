@@ -0,0 +1,140 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// notionIDRegex matches the id of the page which notion appends to the names of the exported files & folders.
+var notionIDRegex = regexp.MustCompile(`\s+[0-9a-fA-F]{32}$`)
+
+// notionAllSuffix is the suffix of the csv file containing all the rows of a database, notion exports it along with
+// the csv file of the current view of the database.
+const notionAllSuffix = "_all"
+
+// convertNotion converts the markdown & csv export (zip archive) of notion.
+// The ids of the pages are removed from the names of the notes & folders, the databases are converted to the notes
+// with a markdown table. The other files of the export are imported as attachments.
+func convertNotion(upload []byte, progress func(total int, processed int)) (*collection, error) {
+	entries, err := readZip(upload)
+	if err != nil {
+		return nil, err
+	}
+	allViews := make(map[string]bool)
+	for _, e := range entries {
+		if stem := strings.TrimSuffix(e.name, path.Ext(e.name)); strings.EqualFold(path.Ext(e.name), ".csv") && strings.HasSuffix(stem, notionAllSuffix) {
+			allViews[strings.TrimSuffix(stem, notionAllSuffix)] = true
+		}
+	}
+
+	c := newCollection()
+	files := newVaultFiles()
+	imported := make([]zipEntry, 0, len(entries))
+	newPaths := make(map[string]string, len(entries))
+	total, processed := len(entries), 0
+	var views []string
+	for _, e := range entries {
+		dirs, name := splitPath(e.name)
+		for i := range dirs {
+			dirs[i] = stripNotionID(dirs[i])
+		}
+		ext := path.Ext(name)
+		stem := strings.TrimSuffix(name, ext)
+		var newPath string
+		switch {
+		case strings.EqualFold(ext, ".md"):
+			newPath = c.notePath(dirs, stripNotionID(stem))
+		case strings.EqualFold(ext, ".csv") && allViews[strings.TrimSuffix(e.name, ext)]:
+			// the current view of the database is a subset of the database with all the rows,
+			// the links to the view point to the note of the database
+			views = append(views, e.name)
+			processed++
+			continue
+		case strings.EqualFold(ext, ".csv"):
+			newPath = c.notePath(dirs, stripNotionID(strings.TrimSuffix(stem, notionAllSuffix)))
+		case e.file.UncompressedSize64 > maxAttachmentSize:
+			c.skip(e.name, "attachment is larger than 10 MB")
+			processed++
+			continue
+		default:
+			newPath = c.attachmentPath(dirs, name)
+		}
+		files.add(e.name, newPath)
+		newPaths[e.name] = newPath
+		imported = append(imported, e)
+	}
+	for _, view := range views {
+		ext := path.Ext(view)
+		if newPath := files.resolvePath(strings.TrimSuffix(view, ext) + notionAllSuffix + ext); newPath != "" {
+			files.add(view, newPath)
+		}
+	}
+
+	for _, e := range imported {
+		newPath := newPaths[e.name]
+		content, err := readZipFile(e.file)
+		switch {
+		case err != nil:
+			c.skip(e.name, "file could not be read")
+		case strings.EqualFold(path.Ext(e.name), ".csv"):
+			table, err := csvToMarkdown(content)
+			if err != nil {
+				c.skip(e.name, "database could not be read")
+				break
+			}
+			_, name := splitPath(newPath)
+			c.addNote(newPath, "# "+strings.TrimSuffix(name, ".md")+"\n\n"+table)
+		case strings.HasSuffix(newPath, ".md"):
+			c.addNote(newPath, rewriteLinks(string(content), e.name, newPath, files.resolvePath))
+		default:
+			c.addAttachment(newPath, content)
+		}
+		processed++
+		progress(total, processed)
+	}
+	return c, nil
+}
+
+func stripNotionID(name string) string {
+	return notionIDRegex.ReplaceAllString(name, "")
+}
+
+// csvToMarkdown converts the csv export of a notion database to a markdown table, the first row is the header of the table.
+func csvToMarkdown(content []byte) (string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", nil
+	}
+	columns := len(records[0])
+	var b strings.Builder
+	for i, record := range records {
+		b.WriteString("|")
+		for j := 0; j < columns; j++ {
+			cell := ""
+			if j < len(record) {
+				cell = tableCell(record[j])
+			}
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString(strings.Repeat("| --- ", columns) + "|\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// tableCell escapes the text to be used as a cell of the markdown table.
+func tableCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,51 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertNotion(t *testing.T) {
+	t.Run("should convert the pages & databases removing the page ids", func(t *testing.T) {
+		upload := makeZip(t, map[string]string{
+			"Team Wiki 0123456789abcdef0123456789abcdef.md": "# Team Wiki\n\n" +
+				"[Onboarding](Team%20Wiki%200123456789abcdef0123456789abcdef/Onboarding%20fedcba9876543210fedcba9876543210.md)\n\n" +
+				"[Tasks](Team%20Wiki%200123456789abcdef0123456789abcdef/Tasks%2011111111111111111111111111111111.csv)\n\n" +
+				"[Notion](https://www.notion.so/Team-Wiki-0123456789abcdef0123456789abcdef)\n",
+			"Team Wiki 0123456789abcdef0123456789abcdef/Onboarding fedcba9876543210fedcba9876543210.md": "# Onboarding\n\n" +
+				"![Laptop setup](Onboarding%20fedcba9876543210fedcba9876543210/laptop.png)\n",
+			"Team Wiki 0123456789abcdef0123456789abcdef/Onboarding fedcba9876543210fedcba9876543210/laptop.png":                          "\x89PNG",
+			"Team Wiki 0123456789abcdef0123456789abcdef/Tasks 11111111111111111111111111111111.csv":                                      "Name,Status\nTodo,Open\n",
+			"Team Wiki 0123456789abcdef0123456789abcdef/Tasks 11111111111111111111111111111111_all.csv":                                  "\ufeffName,Status\nTodo,Open\n\"Ship | release\",Done\n",
+			"Team Wiki 0123456789abcdef0123456789abcdef/Tasks 11111111111111111111111111111111/Todo 2222222222222222222222222222abcd.md": "Todo",
+		})
+		var processed int
+		c, err := convertNotion(upload, func(total int, p int) {
+			assert.Equal(t, 6, total)
+			processed = p
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 6, processed)
+		assert.Equal(t, 4, c.notes)
+		assert.Equal(t, 1, c.attachments)
+		assert.ElementsMatch(t, []string{"Team Wiki.md", "Team Wiki/Onboarding.md", "Team Wiki/Onboarding/laptop.png", "Team Wiki/Tasks.md", "Team Wiki/Tasks/Todo.md"}, keys(c.files))
+		assert.Equal(t, "# Team Wiki\n\n[Onboarding](Team%20Wiki/Onboarding.md)\n\n[Tasks](Team%20Wiki/Tasks.md)\n\n"+
+			"[Notion](https://www.notion.so/Team-Wiki-0123456789abcdef0123456789abcdef)\n", c.files["Team Wiki.md"])
+		assert.Equal(t, "# Onboarding\n\n![Laptop setup](Onboarding/laptop.png)\n", c.files["Team Wiki/Onboarding.md"])
+		assert.Equal(t, "# Tasks\n\n| Name | Status |\n| --- | --- |\n| Todo | Open |\n| Ship \\| release | Done |\n", c.files["Team Wiki/Tasks.md"])
+	})
+
+	t.Run("should expand the zip archives of a large export", func(t *testing.T) {
+		part := makeZip(t, map[string]string{"Page 0123456789abcdef0123456789abcdef.md": "Hello"})
+		upload := makeZip(t, map[string]string{"Export-part-1.zip": string(part)})
+		c, err := convertNotion(upload, func(int, int) {})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"Page.md": "Hello"}, c.files)
+	})
+
+	t.Run("should return error when the upload is not a zip archive", func(t *testing.T) {
+		_, err := convertNotion([]byte("not a zip"), func(int, int) {})
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+	})
+}
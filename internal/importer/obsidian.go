@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// wikiLinkRegex matches the obsidian wiki links (e.g. [[Note#Heading|Alias]]) & embeds (e.g. ![[image.png]]).
+var wikiLinkRegex = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+?)\]\]`)
+
+var imageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".bmp": true, ".avif": true}
+
+// vaultFiles maps the original paths of the files of the upload to the paths of the imported files.
+type vaultFiles struct {
+	byPath map[string]string // by lower case original path
+	byName map[string]string // by lower case original file name, the first file with the name wins
+}
+
+func newVaultFiles() *vaultFiles {
+	return &vaultFiles{
+		byPath: make(map[string]string),
+		byName: make(map[string]string),
+	}
+}
+
+func (v *vaultFiles) add(originalPath string, newPath string) {
+	v.byPath[strings.ToLower(originalPath)] = newPath
+	name := strings.ToLower(path.Base(originalPath))
+	if _, ok := v.byName[name]; !ok {
+		v.byName[name] = newPath
+	}
+}
+
+// resolvePath returns the new path of the file with the original path, the markdown extension is optional for the notes.
+func (v *vaultFiles) resolvePath(p string) string {
+	p = strings.ToLower(strings.TrimPrefix(p, "/"))
+	if newPath, ok := v.byPath[p]; ok {
+		return newPath
+	}
+	return v.byPath[p+".md"]
+}
+
+// resolveName returns the new path of the file with the original file name, the markdown extension is optional for the notes.
+func (v *vaultFiles) resolveName(p string) string {
+	name := strings.ToLower(path.Base(p))
+	if newPath, ok := v.byName[name]; ok {
+		return newPath
+	}
+	return v.byName[name+".md"]
+}
+
+// resolve returns the new path of the file linked from the note, the link is resolved against the note's folder,
+// the root of the vault & then by the file name (obsidian's shortest path links) in that order.
+func (v *vaultFiles) resolve(notePath string, link string) string {
+	if newPath := v.resolvePath(path.Join(path.Dir(notePath), link)); newPath != "" {
+		return newPath
+	}
+	if newPath := v.resolvePath(link); newPath != "" {
+		return newPath
+	}
+	return v.resolveName(link)
+}
+
+// convertObsidian converts the zip archive of an obsidian vault.
+// The folder structure of the vault is retained, the wiki links & embeds are converted to markdown links & images.
+// The non markdown files of the vault are imported as attachments, the obsidian configuration is not imported.
+func convertObsidian(upload []byte, progress func(total int, processed int)) (*collection, error) {
+	entries, err := readZip(upload)
+	if err != nil {
+		return nil, err
+	}
+	c := newCollection()
+	files := newVaultFiles()
+	imported := make([]zipEntry, 0, len(entries))
+	newPaths := make(map[string]string, len(entries))
+	for _, e := range entries {
+		dirs, name := splitPath(e.name)
+		var newPath string
+		if strings.EqualFold(path.Ext(name), ".md") {
+			newPath = c.notePath(dirs, strings.TrimSuffix(name, path.Ext(name)))
+		} else if e.file.UncompressedSize64 > maxAttachmentSize {
+			c.skip(e.name, "attachment is larger than 10 MB")
+			continue
+		} else {
+			newPath = c.attachmentPath(dirs, name)
+		}
+		files.add(e.name, newPath)
+		newPaths[e.name] = newPath
+		imported = append(imported, e)
+	}
+
+	total := len(entries)
+	processed := total - len(imported)
+	for _, e := range imported {
+		content, err := readZipFile(e.file)
+		if err != nil {
+			c.skip(e.name, "file could not be read")
+		} else if newPath := newPaths[e.name]; strings.HasSuffix(newPath, ".md") {
+			note := rewriteLinks(string(content), e.name, newPath, func(p string) string {
+				if newPath := files.resolvePath(p); newPath != "" {
+					return newPath
+				}
+				return files.resolveName(p)
+			})
+			c.addNote(newPath, convertWikiLinks(note, e.name, newPath, files))
+		} else {
+			c.addAttachment(newPath, content)
+		}
+		processed++
+		progress(total, processed)
+	}
+	return c, nil
+}
+
+// convertWikiLinks converts the wiki links & embeds of the note to the markdown links & images.
+// The embedded notes are converted to links since the notes can not be embedded in markdown.
+// The links to the files which are not imported are converted to plain text.
+func convertWikiLinks(content string, originalPath string, newPath string, files *vaultFiles) string {
+	return wikiLinkRegex.ReplaceAllStringFunc(content, func(link string) string {
+		m := wikiLinkRegex.FindStringSubmatch(link)
+		embed := m[1] == "!"
+		target, alias, _ := strings.Cut(m[2], "|")
+		target = strings.TrimSuffix(target, `\`) // the alias separator is escaped within the tables
+		target, fragment, _ := strings.Cut(target, "#")
+		target, fragment, alias = strings.TrimSpace(target), strings.TrimSpace(fragment), strings.TrimSpace(alias)
+
+		anchor := ""
+		if fragment != "" && !strings.HasPrefix(fragment, "^") {
+			// block references are not supported, the link points to the note instead
+			anchor = "#" + headingAnchor(fragment)
+		}
+		text := alias
+		if text == "" {
+			text = target
+			if fragment != "" && !strings.HasPrefix(fragment, "^") {
+				text = strings.TrimSpace(target + " " + fragment)
+			}
+		}
+		if target == "" {
+			if anchor == "" {
+				return text
+			}
+			return "[" + text + "](" + anchor + ")"
+		}
+
+		resolved := files.resolve(originalPath, target)
+		if resolved == "" {
+			return text
+		}
+		destination := relativeURL(newPath, resolved)
+		if embed && imageExtensions[strings.ToLower(path.Ext(resolved))] {
+			if isImageSize(alias) {
+				alias = ""
+			}
+			return "![" + alias + "](" + destination + ")"
+		}
+		return "[" + text + "](" + destination + anchor + ")"
+	})
+}
+
+var imageSizeRegex = regexp.MustCompile(`^\d+(x\d+)?$`)
+
+// isImageSize checks whether the alias of the embedded image is the size of the image (e.g. ![[image.png|300]]).
+func isImageSize(alias string) bool {
+	return imageSizeRegex.MatchString(alias)
+}
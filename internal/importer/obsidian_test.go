@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertObsidian(t *testing.T) {
+	t.Run("should convert the notes of the vault retaining the folders & converting the wiki links", func(t *testing.T) {
+		upload := makeZip(t, map[string]string{
+			"My Vault/Home.md":                   "# Home\n\nSee [[Projects/Roadmap Q4|the roadmap]], [[Roadmap Q4#Next steps]] & [[Missing note]].\n\n![[diagram.png|300]]\n",
+			"My Vault/Projects/Roadmap Q4.md":    "Back to [Home](../Home.md) or [[#Goals]].\n\n![[Home]]\n",
+			"My Vault/Projects/Café (draft).md":  "Draft",
+			"My Vault/assets/diagram.png":        "\x89PNG",
+			"My Vault/.obsidian/workspace.json":  "{}",
+			"__MACOSX/My Vault/._Home.md":        "",
+			"My Vault/Projects/notes_2022.v2.md": "[[Home]]",
+		})
+		var progress []int
+		c, err := convertObsidian(upload, func(total int, processed int) {
+			assert.Equal(t, 5, total)
+			progress = append(progress, processed)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, progress)
+		assert.Equal(t, 4, c.notes)
+		assert.Equal(t, 1, c.attachments)
+		assert.Empty(t, c.skipped)
+		assert.ElementsMatch(t, []string{"Home.md", "Projects/Roadmap Q4.md", "Projects/Cafe draft.md", "Projects/notes 2022 v2.md", "assets/diagram.png"}, keys(c.files))
+		assert.Equal(t, "# Home\n\nSee [the roadmap](Projects/Roadmap%20Q4.md), [Roadmap Q4 Next steps](Projects/Roadmap%20Q4.md#next-steps) & Missing note.\n\n"+
+			"![](assets/diagram.png)\n", c.files["Home.md"])
+		assert.Equal(t, "Back to [Home](../Home.md) or [Goals](#goals).\n\n[Home](../Home.md)\n", c.files["Projects/Roadmap Q4.md"])
+		assert.Equal(t, "[Home](../Home.md)", c.files["Projects/notes 2022 v2.md"])
+		assert.Equal(t, "\x89PNG", c.files["assets/diagram.png"])
+	})
+
+	t.Run("should suffix the notes whose sanitized paths are same", func(t *testing.T) {
+		upload := makeZip(t, map[string]string{"a_b.md": "one", "a.b.md": "two", "link.md": "[[a.b]]"})
+		c, err := convertObsidian(upload, func(int, int) {})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a b.md", "a b 2.md", "link.md"}, keys(c.files))
+		// the order of the files in the archive decides which note is suffixed
+		linked, _ := url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(c.files["link.md"], "[a.b]("), ")"))
+		assert.Equal(t, "two", c.files[linked])
+	})
+
+	t.Run("should skip the attachments larger than 10 MB", func(t *testing.T) {
+		upload := makeZip(t, map[string]string{"note.md": "![[video.mp4]]", "video.mp4": string(make([]byte, maxAttachmentSize+1))})
+		c, err := convertObsidian(upload, func(int, int) {})
+		assert.NoError(t, err)
+		assert.Equal(t, SkippedItems{{Name: "video.mp4", Reason: "attachment is larger than 10 MB"}}, c.skipped)
+		assert.Equal(t, "video.mp4", c.files["note.md"])
+	})
+
+	t.Run("should return error when the upload is not a zip archive", func(t *testing.T) {
+		_, err := convertObsidian([]byte("not a zip"), func(int, int) {})
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+	})
+
+	t.Run("should return error when the files of the upload are too large once extracted", func(t *testing.T) {
+		upload := makeRawZip(t, map[string]uint64{"note.md": 8, "a.pdf": maxExtractedSize / 2, "b.pdf": maxExtractedSize / 2})
+		_, err := convertObsidian(upload, func(int, int) {})
+		assert.ErrorIs(t, err, ErrUploadTooLarge)
+	})
+
+	t.Run("should return error when the zip archive within the upload is too large", func(t *testing.T) {
+		upload := makeRawZip(t, map[string]uint64{"note.md": 8, "part.zip": maxNestedZipSize + 1})
+		_, err := convertObsidian(upload, func(int, int) {})
+		assert.ErrorIs(t, err, ErrUploadTooLarge)
+	})
+}
+
+func TestReadZipFile(t *testing.T) {
+	t.Run("should return error when the file is larger than declared by the archive", func(t *testing.T) {
+		entries, err := readZip(makeRawZip(t, map[string]uint64{"note.md": 8}))
+		assert.NoError(t, err)
+		_, err = readZipFile(entries[0].file)
+		assert.Error(t, err)
+	})
+
+	t.Run("should not read the file larger than the attachments", func(t *testing.T) {
+		entries, err := readZip(makeZip(t, map[string]string{"video.mp4": string(make([]byte, maxAttachmentSize+1))}))
+		assert.NoError(t, err)
+		_, err = readZipFile(entries[0].file)
+		assert.ErrorIs(t, err, errFileTooLarge)
+	})
+}
+
+// makeRawZip creates the zip archive whose files declare the sizes regardless of their content.
+func makeRawZip(t *testing.T, sizes map[string]uint64) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, size := range sizes {
+		content := []byte("content of " + name)
+		w, err := zw.CreateRaw(&zip.FileHeader{Name: name, Method: zip.Store, CRC32: crc32.ChecksumIEEE(content),
+			CompressedSize64: uint64(len(content)), UncompressedSize64: size})
+		assert.NoError(t, err)
+		_, err = w.Write(content)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, files map[string]string) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func keys(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
+
+func TestFilesIn(t *testing.T) {
+	t.Run("should move the files to the folder & skip the notes not matching the rules of the note files", func(t *testing.T) {
+		c := newCollection()
+		c.addNote("Home.md", "Hello")
+		c.addNote("Todo.md", "# Todo")
+		c.addAttachment("image.png", []byte("\x89PNG"))
+		files, err := c.filesIn("Vault", github.NoteFileRules{PathPattern: `Vault/Home`})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"Vault/Home.md": "Hello", "Vault/image.png": "\x89PNG"}, files)
+		assert.Equal(t, 1, c.notes)
+		assert.Equal(t, SkippedItems{{Name: "Vault/Todo.md", Reason: "invalid note path"}}, c.skipped)
+	})
+
+	t.Run("should return error when the path pattern of the note files is invalid", func(t *testing.T) {
+		c := newCollection()
+		c.addNote("Home.md", "Hello")
+		_, err := c.filesIn("", github.NoteFileRules{PathPattern: `[a-z`})
+		assert.Error(t, err)
+	})
+}
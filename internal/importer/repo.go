@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Repo represents an import repository.
+// It provides methods to store & retrieve the imports of the notes from the database.
+//go:generate mockgen -source=repo.go -package=importer -destination=mock_repo.go
+type Repo interface {
+	Save(imp Import) (Import, error)
+	UpdateProgress(importID uint, total int, processed int) error
+	Get(userID uint, importID uint) (Import, error)
+	GetLatestByUserID(userID uint) (Import, error)
+	GetAllUnfinished() ([]Import, error)
+}
+
+type repoImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates and returns a new instance of import repository.
+func NewRepository(db *gorm.DB) Repo {
+	return &repoImpl{
+		db: db,
+	}
+}
+
+// Save stores a given import record to database.
+func (r *repoImpl) Save(imp Import) (Import, error) {
+	if err := r.db.Save(&imp).Error; err != nil {
+		return imp, errors.Wrap(err, "storing import to database failed")
+	}
+	return imp, nil
+}
+
+// UpdateProgress updates the count of the total & processed items of an import record.
+func (r *repoImpl) UpdateProgress(importID uint, total int, processed int) error {
+	err := r.db.Model(&Import{}).Where("id = ?", importID).Updates(map[string]interface{}{"total": total, "processed": processed}).Error
+	if err != nil {
+		return errors.Wrap(err, "updating import progress in database failed")
+	}
+	return nil
+}
+
+// Get returns an import record of a user by import-id.
+func (r *repoImpl) Get(userID uint, importID uint) (Import, error) {
+	var imp Import
+	if err := r.db.Where("id = ? and user_id = ?", importID, userID).First(&imp).Error; err != nil {
+		return imp, errors.Wrap(err, "retrieving import from database failed")
+	}
+	return imp, nil
+}
+
+// GetLatestByUserID returns the latest import record of a user.
+func (r *repoImpl) GetLatestByUserID(userID uint) (Import, error) {
+	var imp Import
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").First(&imp).Error
+	if err == gorm.ErrRecordNotFound {
+		return Import{}, nil
+	}
+	if err != nil {
+		return imp, errors.Wrap(err, "retrieving user's import from database failed")
+	}
+	return imp, nil
+}
+
+// GetAllUnfinished returns the import records which are either pending or running.
+func (r *repoImpl) GetAllUnfinished() ([]Import, error) {
+	var imports []Import
+	err := r.db.Where("status in ?", []string{StatusPending, StatusRunning}).Order("created_at").Find(&imports).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving unfinished imports from database failed")
+	}
+	return imports, nil
+}
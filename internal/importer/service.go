@@ -0,0 +1,284 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ErrImportInProgress is returned when an import is requested while the previous import of the user is not finished.
+var ErrImportInProgress = errors.New("import is already in progress")
+
+// ErrImportNotFound is returned when the import does not exist or it belongs to another user.
+var ErrImportNotFound = errors.New("import not found")
+
+// ErrNoNotes is returned when the upload does not contain any note to be imported.
+var ErrNoNotes = errors.New("no notes found in the upload")
+
+// ErrDefaultRepoNotConfigured is returned when the user has not configured the repo the notes are imported to.
+var ErrDefaultRepoNotConfigured = errors.New("default repo is not configured")
+
+// Service represents a notes import service.
+// It converts the uploaded exports of the other note taking apps in background & commits the notes to the user's repo.
+//go:generate mockgen -source=service.go -package=importer -destination=mock_service.go
+type Service interface {
+	Request(imp Import) (Import, error)
+	Get(userID uint, importID uint) (Import, error)
+	GetLatest(userID uint) (Import, error)
+	ResumeUnfinished() error
+}
+
+const (
+	// import which takes longer than this duration is failed
+	importTimeout = 30 * time.Minute
+
+	// imports are processed in background with limited concurrency since the uploads are converted in memory
+	maxConcurrentImports = 2
+
+	// progress of the import is stored after converting these many items
+	progressInterval = 25
+)
+
+// defaultFolders are the folders of the repo the notes are imported to by source when the folder is not provided.
+var defaultFolders = map[string]string{
+	SourceObsidian: "Obsidian",
+	SourceNotion:   "Notion",
+	SourceEvernote: "Evernote",
+}
+
+// userErrors are the errors whose message is reported to the user when the import fails.
+var userErrors = []error{ErrInvalidUpload, ErrTooManyItems, ErrUploadTooLarge, ErrNoNotes, ErrDefaultRepoNotConfigured}
+
+type service struct {
+	repo          Repo
+	userService   user.Service
+	githubService github.Service
+	tokenService  user.TokenService
+	store         UploadStore
+
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewService creates and returns a new import service.
+// The uploads are kept in the store until their import is finished.
+func NewService(repo Repo, userService user.Service, githubService github.Service, tokenService user.TokenService, store UploadStore) Service {
+	return &service{
+		repo:          repo,
+		userService:   userService,
+		githubService: githubService,
+		tokenService:  tokenService,
+		store:         store,
+		slots:         make(chan struct{}, maxConcurrentImports),
+	}
+}
+
+// Request creates a new import of the uploaded export & starts processing it in background.
+// The user-id, source, upload & the folder the notes are imported to are taken from the provided import.
+// The notes are imported to the folder named after the source when the folder is not provided.
+// It returns the pending import along with any error occurred while creating it.
+func (s *service) Request(imp Import) (Import, error) {
+	latest, err := s.repo.GetLatestByUserID(imp.UserID)
+	if err != nil {
+		return Import{}, err
+	}
+	if latest.Status == StatusPending || latest.Status == StatusRunning {
+		return latest, ErrImportInProgress
+	}
+	if imp.Path == "" {
+		imp.Path = defaultFolders[imp.Source]
+	}
+	upload := imp.Upload
+	imp, err = s.repo.Save(Import{
+		UserID:   imp.UserID,
+		Source:   imp.Source,
+		FileName: imp.FileName,
+		Path:     imp.Path,
+		Status:   StatusPending,
+	})
+	if err != nil {
+		return Import{}, err
+	}
+	// the upload is stored so that the import can be resumed when the server is stopped before the import is finished
+	_, err = s.store.Save(imp.ID, func(w io.Writer) error {
+		_, err := w.Write(upload)
+		return err
+	})
+	if err != nil {
+		imp.Status = StatusFailed
+		imp.Error = "importing notes failed"
+		if _, saveErr := s.repo.Save(imp); saveErr != nil {
+			logrus.WithField("import-id", imp.ID).WithError(saveErr).Error("storing failed import failed")
+		}
+		return Import{}, err
+	}
+	imp.Upload = upload
+	s.start(imp)
+	imp.Upload = nil
+	return imp, nil
+}
+
+// Get retrieves the import of the user to poll its progress.
+// ErrImportNotFound is returned when the import does not belong to the user.
+func (s *service) Get(userID uint, importID uint) (Import, error) {
+	imp, err := s.repo.Get(userID, importID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Import{}, ErrImportNotFound
+	}
+	return imp, err
+}
+
+// GetLatest retrieves the latest import of the user.
+// The import is empty when the user has not requested any import.
+func (s *service) GetLatest(userID uint) (Import, error) {
+	return s.repo.GetLatestByUserID(userID)
+}
+
+// ResumeUnfinished starts processing the imports which were not finished before the server was stopped.
+func (s *service) ResumeUnfinished() error {
+	imports, err := s.repo.GetAllUnfinished()
+	if err != nil {
+		return err
+	}
+	for _, imp := range imports {
+		s.start(imp)
+	}
+	return nil
+}
+
+// start processes the import in background.
+func (s *service) start(imp Import) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.slots <- struct{}{}
+		defer func() { <-s.slots }()
+		s.process(imp)
+	}()
+}
+
+// process converts the upload of the import & commits the notes to the repo. The import is marked as failed when
+// the upload can not be read back from the store or the conversion or the commit fails.
+// The upload is removed from the store once the import is finished.
+func (s *service) process(imp Import) {
+	log := logrus.WithField("user-id", imp.UserID).WithField("import-id", imp.ID)
+	upload := imp.Upload
+	imp.Upload = nil
+	imp.Status = StatusRunning
+	imp, err := s.repo.Save(imp)
+	if err != nil {
+		log.WithError(err).Error("starting import failed")
+		return
+	}
+	log.WithField("source", imp.Source).Info("import started")
+
+	var c *collection
+	var commitSHA string
+	if upload == nil {
+		// the import is resumed after the server was restarted, so its upload is read back from the store
+		upload, err = s.readUpload(imp.ID)
+	}
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+		defer cancel()
+		c, commitSHA, err = s.importNotes(ctx, &imp, upload)
+	}
+	if c != nil {
+		imp.Notes = c.notes
+		imp.Attachments = c.attachments
+		imp.Skipped = c.skipped
+	}
+	if err != nil {
+		log.WithError(err).Error("importing notes failed")
+		imp.Status = StatusFailed
+		imp.Error = "importing notes failed"
+		for _, userErr := range userErrors {
+			if errors.Is(err, userErr) {
+				imp.Error = userErr.Error()
+			}
+		}
+	} else {
+		now := time.Now().UTC()
+		imp.Status = StatusCompleted
+		imp.CommitSHA = commitSHA
+		imp.CompletedAt = &now
+	}
+	if _, err := s.repo.Save(imp); err != nil {
+		log.WithError(err).Error("storing import failed")
+		return
+	}
+	if err := s.store.Delete(imp.ID); err != nil {
+		log.WithError(err).Error("removing import upload failed")
+	}
+	log.WithField("status", imp.Status).WithField("notes", imp.Notes).Info("import finished")
+}
+
+// readUpload reads the upload of the import back from the store.
+func (s *service) readUpload(importID uint) ([]byte, error) {
+	r, err := s.store.Open(importID)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// importNotes converts the upload & commits the converted files to the user's repo in a single commit.
+// It returns the converted files along with the sha of the commit.
+func (s *service) importNotes(ctx context.Context, imp *Import, upload []byte) (*collection, string, error) {
+	convert, ok := converters[imp.Source]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported import source: %s", imp.Source)
+	}
+	c, err := convert(upload, func(total int, processed int) {
+		imp.Total, imp.Processed = total, processed
+		if processed%progressInterval == 0 || processed == total {
+			if err := s.repo.UpdateProgress(imp.ID, total, processed); err != nil {
+				logrus.WithField("import-id", imp.ID).WithError(err).Warn("storing import progress failed")
+			}
+		}
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if c.notes == 0 {
+		return c, "", ErrNoNotes
+	}
+
+	u, err := s.userService.Get(imp.UserID)
+	if err != nil {
+		return c, "", err
+	}
+	if u.DefaultRepo == nil || u.DefaultRepo.Name == "" {
+		return c, "", ErrDefaultRepoNotConfigured
+	}
+	repoDetails := u.GetDefaultRepoDetails()
+	files, err := c.filesIn(imp.Path, repoDetails.NoteFiles)
+	if err != nil {
+		return c, "", err
+	}
+	if c.notes == 0 {
+		return c, "", ErrNoNotes
+	}
+	ghToken, err := s.tokenService.GetRepoToken(ctx, u, repoDetails)
+	if err != nil {
+		return c, "", err
+	}
+	commitSHA, err := s.githubService.CommitFiles(ctx, ghToken, github.GitCommitProps{
+		Branch:      u.DefaultRepo.DefaultBranch,
+		Message:     fmt.Sprintf("Import %d notes from %s", c.notes, imp.Source),
+		Files:       files,
+		AuthorName:  u.GetCommitName(),
+		AuthorEmail: u.GetCommitEmail(),
+		RepoDetails: repoDetails,
+	})
+	return c, commitSHA, err
+}
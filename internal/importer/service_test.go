@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/preference"
+	"github.com/batnoter/batnoter-api/internal/user"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	userID   = uint(1001)
+	importID = uint(61)
+)
+
+func TestRequest(t *testing.T) {
+	t.Run("should create the import & commit the converted notes to the repo in background", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, mockUserService, mockGithubService, mockTokenService, mockStore)
+		u := validUser()
+		ghToken := oauth2.Token{AccessToken: "gho_token"}
+		upload := makeZip(t, map[string]string{"vault/Home.md": "See [[Todo]]", "vault/Todo.md": "# Todo", "vault/huge.bin": string(make([]byte, maxAttachmentSize+1))})
+
+		var saved []Import
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Import{Status: StatusCompleted}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			imp.ID = importID
+			saved = append(saved, imp)
+			return imp, nil
+		}).Times(3)
+		var stored bytes.Buffer
+		mockStore.EXPECT().Save(importID, gomock.Any()).DoAndReturn(func(importID uint, write func(w io.Writer) error) (int64, error) {
+			return int64(stored.Len()), write(&stored)
+		})
+		mockStore.EXPECT().Delete(importID).Return(nil)
+		mockRepo.EXPECT().UpdateProgress(importID, 3, 3).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(ghToken, nil)
+		mockGithubService.EXPECT().CommitFiles(gomock.Any(), ghToken, github.GitCommitProps{
+			Branch:      "main",
+			Message:     "Import 2 notes from obsidian",
+			Files:       map[string]string{"Obsidian/Home.md": "See [Todo](Todo.md)", "Obsidian/Todo.md": "# Todo"},
+			AuthorName:  "John Doe",
+			AuthorEmail: "john.doe@example.com",
+			RepoDetails: github.GitRepoProps{Repository: "notes", DefaultBranch: "main", Owner: "johndoe"},
+		}).Return("commitsha", nil)
+
+		imp, err := service.Request(Import{UserID: userID, Source: SourceObsidian, FileName: "vault.zip", Upload: upload})
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusPending, imp.Status)
+		assert.Equal(t, "Obsidian", imp.Path)
+		assert.Nil(t, imp.Upload)
+		assert.Equal(t, []string{StatusPending, StatusRunning, StatusCompleted}, []string{saved[0].Status, saved[1].Status, saved[2].Status})
+		assert.Equal(t, upload, stored.Bytes())
+		assert.Nil(t, saved[0].Upload)
+
+		completed := saved[2]
+		assert.Equal(t, "commitsha", completed.CommitSHA)
+		assert.Equal(t, 3, completed.Total)
+		assert.Equal(t, 3, completed.Processed)
+		assert.Equal(t, 2, completed.Notes)
+		assert.Equal(t, 0, completed.Attachments)
+		assert.Equal(t, SkippedItems{{Name: "huge.bin", Reason: "attachment is larger than 10 MB"}}, completed.Skipped)
+		assert.NotNil(t, completed.CompletedAt)
+		assert.Nil(t, completed.Upload)
+	})
+
+	t.Run("should mark the import as failed when the upload is not valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, mockStore)
+
+		var failed Import
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Import{}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			failed = imp
+			return imp, nil
+		}).Times(3)
+		mockStore.EXPECT().Save(uint(0), gomock.Any()).Return(int64(0), nil)
+		mockStore.EXPECT().Delete(uint(0)).Return(nil)
+
+		_, err := service.Request(Import{UserID: userID, Source: SourceNotion, Upload: []byte("not a zip")})
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Equal(t, "upload is not a valid export of the source", failed.Error)
+		assert.Nil(t, failed.Upload)
+	})
+
+	t.Run("should mark the import as failed when the default repo is not configured", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, mockUserService, nil, nil, mockStore)
+
+		var failed Import
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Import{}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			failed = imp
+			return imp, nil
+		}).Times(3)
+		mockStore.EXPECT().Save(uint(0), gomock.Any()).Return(int64(0), nil)
+		mockStore.EXPECT().Delete(uint(0)).Return(nil)
+		mockRepo.EXPECT().UpdateProgress(gomock.Any(), 1, 1).Return(nil)
+		mockUserService.EXPECT().Get(userID).Return(user.User{}, nil)
+
+		_, err := service.Request(Import{UserID: userID, Source: SourceObsidian, Upload: makeZip(t, map[string]string{"Home.md": "Hello"})})
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Equal(t, "default repo is not configured", failed.Error)
+		assert.Equal(t, 1, failed.Notes)
+	})
+
+	t.Run("should mark the import as failed with a generic error when committing the notes fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockUserService := user.NewMockService(ctrl)
+		mockGithubService := github.NewMockService(ctrl)
+		mockTokenService := user.NewMockTokenService(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, mockUserService, mockGithubService, mockTokenService, mockStore)
+		u := validUser()
+
+		var failed Import
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Import{}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			failed = imp
+			return imp, nil
+		}).Times(3)
+		mockStore.EXPECT().Save(uint(0), gomock.Any()).Return(int64(0), nil)
+		mockStore.EXPECT().Delete(uint(0)).Return(nil)
+		mockRepo.EXPECT().UpdateProgress(gomock.Any(), 1, 1).Return(errors.New("some error"))
+		mockUserService.EXPECT().Get(userID).Return(u, nil)
+		mockTokenService.EXPECT().GetRepoToken(gomock.Any(), u, gomock.Any()).Return(oauth2.Token{}, nil)
+		mockGithubService.EXPECT().CommitFiles(gomock.Any(), gomock.Any(), gomock.Any()).Return("", errors.New("github error"))
+
+		_, err := service.Request(Import{UserID: userID, Source: SourceObsidian, Upload: makeZip(t, map[string]string{"Home.md": "Hello"})})
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Equal(t, "importing notes failed", failed.Error)
+	})
+
+	t.Run("should mark the import as failed when the upload does not contain any note", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, mockStore)
+
+		var failed Import
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Import{}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			failed = imp
+			return imp, nil
+		}).Times(3)
+		mockStore.EXPECT().Save(uint(0), gomock.Any()).Return(int64(0), nil)
+		mockStore.EXPECT().Delete(uint(0)).Return(nil)
+		mockRepo.EXPECT().UpdateProgress(gomock.Any(), 1, 1).Return(nil)
+
+		_, err := service.Request(Import{UserID: userID, Source: SourceObsidian, Upload: makeZip(t, map[string]string{"image.png": "\x89PNG"})})
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Equal(t, "no notes found in the upload", failed.Error)
+	})
+
+	t.Run("should mark the import as failed & return error when storing the upload fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, mockStore)
+
+		var saved []Import
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Import{}, nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			imp.ID = importID
+			saved = append(saved, imp)
+			return imp, nil
+		}).Times(2)
+		mockStore.EXPECT().Save(importID, gomock.Any()).Return(int64(0), errors.New("disk full"))
+
+		_, err := service.Request(Import{UserID: userID, Source: SourceObsidian, Upload: []byte("PK")})
+		wait(service)
+		assert.EqualError(t, err, "disk full")
+		assert.Equal(t, StatusFailed, saved[1].Status)
+		assert.Equal(t, "importing notes failed", saved[1].Error)
+	})
+
+	t.Run("should return error when the previous import is in progress", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, nil)
+		mockRepo.EXPECT().GetLatestByUserID(userID).Return(Import{Status: StatusPending}, nil)
+
+		_, err := service.Request(Import{UserID: userID, Source: SourceEvernote})
+		assert.ErrorIs(t, err, ErrImportInProgress)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("should return the import of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, nil)
+		mockRepo.EXPECT().Get(userID, importID).Return(Import{Status: StatusRunning, Total: 10, Processed: 4}, nil)
+
+		imp, err := service.Get(userID, importID)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, imp.Processed)
+	})
+
+	t.Run("should return error when the import does not belong to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, nil)
+		mockRepo.EXPECT().Get(userID, importID).Return(Import{}, gorm.ErrRecordNotFound)
+
+		_, err := service.Get(userID, importID)
+		assert.ErrorIs(t, err, ErrImportNotFound)
+	})
+}
+
+func TestResumeUnfinished(t *testing.T) {
+	t.Run("should process the unfinished imports with their stored upload", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, mockStore)
+
+		var failed Import
+		mockRepo.EXPECT().GetAllUnfinished().Return([]Import{{Model: gorm.Model{ID: importID}, UserID: userID, Source: SourceEvernote, Status: StatusRunning}}, nil)
+		mockStore.EXPECT().Open(importID).Return(io.NopCloser(bytes.NewReader([]byte("<notes/>"))), nil)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			failed = imp
+			return imp, nil
+		}).Times(2)
+		mockStore.EXPECT().Delete(importID).Return(nil)
+
+		err := service.ResumeUnfinished()
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+	})
+	t.Run("should mark the unfinished import as failed when its upload can not be read", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		mockStore := NewMockUploadStore(ctrl)
+		service := NewService(mockRepo, nil, nil, nil, mockStore)
+
+		var failed Import
+		mockRepo.EXPECT().GetAllUnfinished().Return([]Import{{Model: gorm.Model{ID: importID}, UserID: userID, Source: SourceNotion, Status: StatusPending}}, nil)
+		mockStore.EXPECT().Open(importID).Return(nil, errors.New("file not found"))
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(imp Import) (Import, error) {
+			failed = imp
+			return imp, nil
+		}).Times(2)
+		mockStore.EXPECT().Delete(importID).Return(nil)
+
+		err := service.ResumeUnfinished()
+		wait(service)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusFailed, failed.Status)
+		assert.Equal(t, "importing notes failed", failed.Error)
+	})
+}
+
+func TestSkippedItems(t *testing.T) {
+	t.Run("should store & read the skipped items as json array", func(t *testing.T) {
+		skipped := SkippedItems{{Name: "video.mp4", Reason: "attachment is larger than 10 MB"}}
+		value, err := skipped.Value()
+		assert.NoError(t, err)
+		assert.Equal(t, `[{"name":"video.mp4","reason":"attachment is larger than 10 MB"}]`, value)
+
+		var scanned SkippedItems
+		assert.NoError(t, scanned.Scan([]byte(value.(string))))
+		assert.Equal(t, skipped, scanned)
+	})
+}
+
+func validUser() user.User {
+	u := user.User{
+		Email:          "john.doe@example.com",
+		Name:           "John Doe",
+		GithubID:       12345,
+		GithubUsername: "johndoe",
+		GithubToken:    `{"access_token":"gho_token"}`,
+		DefaultRepo:    &preference.DefaultRepo{Name: "notes", Visibility: "private", DefaultBranch: "main"},
+	}
+	u.ID = userID
+	return u
+}
+
+// wait waits until the imports started by the service are processed.
+func wait(s Service) {
+	s.(*service).wg.Wait()
+}
@@ -0,0 +1,13 @@
+package importer
+
+import "io"
+
+// UploadStore represents a storage of the uploads of the imports.
+// It provides methods to write, read & delete the uploads identified by their import id.
+//
+//go:generate mockgen -source=upload_store.go -package=importer -destination=mock_upload_store.go
+type UploadStore interface {
+	Save(importID uint, write func(w io.Writer) error) (int64, error)
+	Open(importID uint) (io.ReadCloser, error)
+	Delete(importID uint) error
+}
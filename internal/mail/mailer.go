@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Mailer represents an email sender.
+// It provides method to send plain text emails to the users.
+//go:generate mockgen -source=mailer.go -package=mail -destination=mock_mailer.go
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// Config represents the smtp server used to send the emails.
+// Emails are not sent when the host is not set.
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type sendFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+type smtpMailer struct {
+	config Config
+	send   sendFunc
+}
+
+type noopMailer struct{}
+
+// NewMailer creates and returns a new mailer which sends the emails using the smtp server.
+// A mailer which only logs the emails is returned when the smtp server is not configured.
+func NewMailer(config Config) Mailer {
+	if config.Host == "" {
+		return noopMailer{}
+	}
+	return &smtpMailer{
+		config: config,
+		send:   smtp.SendMail,
+	}
+}
+
+// Send sends a plain text email to the recipient.
+// It returns an error when the addresses are not valid or sending the email fails.
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	from, err := netmail.ParseAddress(m.config.From)
+	if err != nil {
+		return errors.Wrap(err, "parsing sender address failed")
+	}
+	recipient, err := netmail.ParseAddress(to)
+	if err != nil {
+		return errors.Wrap(err, "parsing recipient address failed")
+	}
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+	msg := message(from, recipient, subject, body, time.Now())
+	if err := m.send(net.JoinHostPort(m.config.Host, m.config.Port), auth, from.Address, []string{recipient.Address}, msg); err != nil {
+		return errors.Wrap(err, "sending email failed")
+	}
+	return nil
+}
+
+// Send logs the email instead of sending it.
+func (noopMailer) Send(to string, subject string, body string) error {
+	logrus.WithField("subject", subject).Info("email not sent since the smtp server is not configured")
+	return nil
+}
+
+// message builds the email message with the headers. The subject is encoded since it may contain non ascii characters.
+func message(from *netmail.Address, to *netmail.Address, subject string, body string, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buf.Bytes()
+}
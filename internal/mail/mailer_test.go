@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"errors"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	t.Run("should send the email to the recipient using the smtp server", func(t *testing.T) {
+		var sentAddr, sentFrom string
+		var sentTo []string
+		var sentMsg []byte
+		mailer := &smtpMailer{
+			config: Config{Host: "smtp.example.com", Port: "587", Username: "user", Password: "secret", From: "BatNoter <noreply@example.com>"},
+			send: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				sentAddr, sentFrom, sentTo, sentMsg = addr, from, to, msg
+				return nil
+			},
+		}
+
+		err := mailer.Send("john.doe@example.com", "Your export is ready", "Hello\nWorld")
+		assert.NoError(t, err)
+		assert.Equal(t, "smtp.example.com:587", sentAddr)
+		assert.Equal(t, "noreply@example.com", sentFrom)
+		assert.Equal(t, []string{"john.doe@example.com"}, sentTo)
+		assert.Contains(t, string(sentMsg), "From: \"BatNoter\" <noreply@example.com>\r\n")
+		assert.Contains(t, string(sentMsg), "To: <john.doe@example.com>\r\n")
+		assert.Contains(t, string(sentMsg), "Subject: Your export is ready\r\n")
+		assert.Contains(t, string(sentMsg), "\r\n\r\nHello\r\nWorld")
+	})
+
+	t.Run("should return error when the recipient address is not valid", func(t *testing.T) {
+		mailer := &smtpMailer{
+			config: Config{Host: "smtp.example.com", Port: "587", From: "noreply@example.com"},
+			send: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				t.Fatal("email must not be sent")
+				return nil
+			},
+		}
+
+		err := mailer.Send("john.doe@example.com\r\nBcc: eve@example.com", "subject", "body")
+		assert.Error(t, err)
+	})
+
+	t.Run("should return error when sending the email fails", func(t *testing.T) {
+		mailer := &smtpMailer{
+			config: Config{Host: "smtp.example.com", Port: "587", From: "noreply@example.com"},
+			send: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+				return errors.New("some error")
+			},
+		}
+
+		err := mailer.Send("john.doe@example.com", "subject", "body")
+		assert.Error(t, err)
+	})
+
+	t.Run("should not send the email when the smtp server is not configured", func(t *testing.T) {
+		err := NewMailer(Config{}).Send("john.doe@example.com", "subject", "body")
+		assert.NoError(t, err)
+	})
+}
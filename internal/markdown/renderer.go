@@ -0,0 +1,125 @@
+package markdown
+
+import (
+	"bytes"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Options represents the options used to render a note.
+// The relative links & images of the note are resolved against the directory of the note path
+// & rewritten with LinkURL & ImageURL. They are kept as it is when the respective func is not set.
+type Options struct {
+	Path     string
+	LinkURL  func(path string) string
+	ImageURL func(path string) string
+}
+
+// Renderer represents a markdown renderer.
+// It renders the markdown of the notes to html which is safe to be embedded in a web page.
+//go:generate mockgen -source=renderer.go -package=markdown -destination=mock_renderer.go
+type Renderer interface {
+	Render(source []byte, opts Options) (string, error)
+}
+
+type renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+}
+
+// NewRenderer creates and returns a new markdown renderer.
+// CommonMark is supported along with the github flavored markdown extensions (tables, task lists, strikethrough,
+// autolinks), footnotes & syntax highlighting of the fenced code blocks.
+func NewRenderer() Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+			// code is highlighted with css classes so that the inline styles are not required to be allowed by the sanitizer
+			highlighting.NewHighlighting(highlighting.WithFormatOptions(chromahtml.WithClasses(true))),
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// raw html of the notes is rendered & then sanitized along with the rest of the output
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	return &renderer{
+		md:     md,
+		policy: newPolicy(),
+	}
+}
+
+// Render renders the markdown source to sanitized html.
+func (r *renderer) Render(source []byte, opts Options) (string, error) {
+	doc := r.md.Parser().Parse(text.NewReader(source))
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.Link:
+			node.Destination = rewriteURL(node.Destination, opts.Path, opts.LinkURL)
+		case *ast.Image:
+			node.Destination = rewriteURL(node.Destination, opts.Path, opts.ImageURL)
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err := r.md.Renderer().Render(buf, source, doc); err != nil {
+		return "", err
+	}
+	return r.policy.Sanitize(buf.String()), nil
+}
+
+// rewriteURL rewrites the relative destination using the rewrite func.
+// The destination is resolved against the directory of the note, the query & fragment of the destination are retained.
+// Absolute urls, root relative paths, fragments & paths pointing outside of the repo are not rewritten.
+func rewriteURL(destination []byte, notePath string, rewrite func(path string) string) []byte {
+	if rewrite == nil || len(destination) == 0 {
+		return destination
+	}
+	u, err := url.Parse(string(destination))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" || strings.HasPrefix(u.Path, "/") {
+		return destination
+	}
+	resolved := path.Join(path.Dir(notePath), u.Path)
+	if resolved == ".." || strings.HasPrefix(resolved, "../") {
+		return destination
+	}
+	result := rewrite(resolved)
+	if u.RawQuery != "" {
+		result += "?" + u.RawQuery
+	}
+	if u.Fragment != "" {
+		result += "#" + u.EscapedFragment()
+	}
+	return []byte(result)
+}
+
+// newPolicy creates the sanitization policy allowing the user generated content along with
+// the markup produced by the extensions of the renderer.
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	// css classes of the highlighted code, footnotes & fenced code languages
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-zA-Z0-9\-_ ]+$`)).OnElements("pre", "code", "span", "div", "a")
+	// task list items
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	// footnotes
+	policy.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("a", "div")
+	return policy
+}
@@ -0,0 +1,79 @@
+package markdown
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("should render github flavored markdown tables, task lists & strikethrough", func(t *testing.T) {
+		source := "| a | b |\n|---|---|\n| 1 | 2 |\n\n- [x] done\n- [ ] todo\n\n~~old~~\n"
+
+		html, err := NewRenderer().Render([]byte(source), Options{})
+		assert.NoError(t, err)
+		assert.Contains(t, html, "<table>")
+		assert.Contains(t, html, "<td>1</td>")
+		assert.Contains(t, html, `<li><input checked="" disabled="" type="checkbox"> done</li>`)
+		assert.Contains(t, html, `<li><input disabled="" type="checkbox"> todo</li>`)
+		assert.Contains(t, html, "<del>old</del>")
+	})
+
+	t.Run("should render footnotes", func(t *testing.T) {
+		source := "Text[^1]\n\n[^1]: The footnote.\n"
+
+		html, err := NewRenderer().Render([]byte(source), Options{})
+		assert.NoError(t, err)
+		assert.Contains(t, html, `<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref"`)
+		assert.Contains(t, html, `<li id="fn:1">`)
+		assert.Contains(t, html, "The footnote.")
+	})
+
+	t.Run("should highlight fenced code blocks with css classes", func(t *testing.T) {
+		source := "```go\npackage main\n```\n"
+
+		html, err := NewRenderer().Render([]byte(source), Options{})
+		assert.NoError(t, err)
+		assert.Contains(t, html, `<pre class="chroma">`)
+		assert.Contains(t, html, `<span class="kn">package</span>`)
+		assert.NotContains(t, html, "style=")
+	})
+
+	t.Run("should remove scripts, event handlers & javascript urls", func(t *testing.T) {
+		source := "[click](javascript:alert(1))\n\n<script>alert(1)</script>\n\n<img src=\"cat.png\" onerror=\"alert(1)\">\n\n<a href=\"javascript:alert(1)\">link</a>\n"
+
+		html, err := NewRenderer().Render([]byte(source), Options{})
+		assert.NoError(t, err)
+		assert.NotContains(t, html, "<script")
+		assert.NotContains(t, html, "onerror")
+		assert.NotContains(t, html, "javascript:")
+		assert.Contains(t, html, `<img src="cat.png">`)
+	})
+
+	t.Run("should rewrite the relative links & images resolved against the directory of the note", func(t *testing.T) {
+		source := "[meeting](../meeting.md#agenda) [todo](todo.md) [site](https://example.com) [top](#top) [root](/about.md)\n\n![diagram](images/flow.png)\n"
+		opts := Options{
+			Path:     "work/notes.md",
+			LinkURL:  func(path string) string { return "/api/v1/notes/" + url.PathEscape(path) + "/html" },
+			ImageURL: func(path string) string { return "/api/v1/notes/" + url.PathEscape(path) },
+		}
+
+		html, err := NewRenderer().Render([]byte(source), opts)
+		assert.NoError(t, err)
+		assert.Contains(t, html, `<a href="/api/v1/notes/meeting.md/html#agenda"`)
+		assert.Contains(t, html, `<a href="/api/v1/notes/work%2Ftodo.md/html"`)
+		assert.Contains(t, html, `<a href="https://example.com"`)
+		assert.Contains(t, html, `<a href="#top"`)
+		assert.Contains(t, html, `<a href="/about.md"`)
+		assert.Contains(t, html, `<img src="/api/v1/notes/work%2Fimages%2Fflow.png" alt="diagram">`)
+	})
+
+	t.Run("should not rewrite the links pointing outside of the repo", func(t *testing.T) {
+		opts := Options{Path: "notes.md", LinkURL: func(path string) string { return "/api/v1/notes/" + path }}
+
+		html, err := NewRenderer().Render([]byte("[up](../secret.md)\n"), opts)
+		assert.NoError(t, err)
+		assert.Contains(t, html, `<a href="../secret.md"`)
+	})
+}
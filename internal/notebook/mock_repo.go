@@ -0,0 +1,229 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+
+// Package notebook is a generated GoMock package.
+package notebook
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepo is a mock of Repo interface.
+type MockRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder
+}
+
+// MockRepoMockRecorder is the mock recorder for MockRepo.
+type MockRepoMockRecorder struct {
+	mock *MockRepo
+}
+
+// NewMockRepo creates a new mock instance.
+func NewMockRepo(ctrl *gomock.Controller) *MockRepo {
+	mock := &MockRepo{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockRepo) Delete(userID, notebookID uint) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", userID, notebookID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepoMockRecorder) Delete(userID, notebookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepo)(nil).Delete), userID, notebookID)
+}
+
+// DeleteMember mocks base method.
+func (m *MockRepo) DeleteMember(notebookID, memberID uint) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMember", notebookID, memberID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMember indicates an expected call of DeleteMember.
+func (mr *MockRepoMockRecorder) DeleteMember(notebookID, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockRepo)(nil).DeleteMember), notebookID, memberID)
+}
+
+// Get mocks base method.
+func (m *MockRepo) Get(userID, notebookID uint) (Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", userID, notebookID)
+	ret0, _ := ret[0].(Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRepoMockRecorder) Get(userID, notebookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepo)(nil).Get), userID, notebookID)
+}
+
+// GetAllByUserID mocks base method.
+func (m *MockRepo) GetAllByUserID(userID uint) ([]Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUserID", userID)
+	ret0, _ := ret[0].([]Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUserID indicates an expected call of GetAllByUserID.
+func (mr *MockRepoMockRecorder) GetAllByUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockRepo)(nil).GetAllByUserID), userID)
+}
+
+// GetByName mocks base method.
+func (m *MockRepo) GetByName(userID uint, name string) (Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", userID, name)
+	ret0, _ := ret[0].(Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockRepoMockRecorder) GetByName(userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockRepo)(nil).GetByName), userID, name)
+}
+
+// GetInvitation mocks base method.
+func (m *MockRepo) GetInvitation(memberID uint) (Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitation", memberID)
+	ret0, _ := ret[0].(Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitation indicates an expected call of GetInvitation.
+func (mr *MockRepoMockRecorder) GetInvitation(memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitation", reflect.TypeOf((*MockRepo)(nil).GetInvitation), memberID)
+}
+
+// GetInvitations mocks base method.
+func (m *MockRepo) GetInvitations(email, login string) ([]Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations", email, login)
+	ret0, _ := ret[0].([]Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockRepoMockRecorder) GetInvitations(email, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockRepo)(nil).GetInvitations), email, login)
+}
+
+// GetMember mocks base method.
+func (m *MockRepo) GetMember(notebookID, userID uint) (Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", notebookID, userID)
+	ret0, _ := ret[0].(Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockRepoMockRecorder) GetMember(notebookID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockRepo)(nil).GetMember), notebookID, userID)
+}
+
+// GetMemberByID mocks base method.
+func (m *MockRepo) GetMemberByID(notebookID, memberID uint) (Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberByID", notebookID, memberID)
+	ret0, _ := ret[0].(Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberByID indicates an expected call of GetMemberByID.
+func (mr *MockRepoMockRecorder) GetMemberByID(notebookID, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberByID", reflect.TypeOf((*MockRepo)(nil).GetMemberByID), notebookID, memberID)
+}
+
+// GetMembers mocks base method.
+func (m *MockRepo) GetMembers(notebookID uint) ([]Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", notebookID)
+	ret0, _ := ret[0].([]Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockRepoMockRecorder) GetMembers(notebookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockRepo)(nil).GetMembers), notebookID)
+}
+
+// GetMembershipsByUserID mocks base method.
+func (m *MockRepo) GetMembershipsByUserID(userID uint) ([]Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembershipsByUserID", userID)
+	ret0, _ := ret[0].([]Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembershipsByUserID indicates an expected call of GetMembershipsByUserID.
+func (mr *MockRepoMockRecorder) GetMembershipsByUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembershipsByUserID", reflect.TypeOf((*MockRepo)(nil).GetMembershipsByUserID), userID)
+}
+
+// Save mocks base method.
+func (m *MockRepo) Save(notebook Notebook) (Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", notebook)
+	ret0, _ := ret[0].(Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRepoMockRecorder) Save(notebook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepo)(nil).Save), notebook)
+}
+
+// SaveMember mocks base method.
+func (m *MockRepo) SaveMember(member Member) (Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMember", member)
+	ret0, _ := ret[0].(Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveMember indicates an expected call of SaveMember.
+func (mr *MockRepoMockRecorder) SaveMember(member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMember", reflect.TypeOf((*MockRepo)(nil).SaveMember), member)
+}
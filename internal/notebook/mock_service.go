@@ -0,0 +1,211 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package notebook is a generated GoMock package.
+package notebook
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockService) AcceptInvitation(userID uint, email, login string, memberID uint) (Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", userID, email, login, memberID)
+	ret0, _ := ret[0].(Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockServiceMockRecorder) AcceptInvitation(userID, email, login, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockService)(nil).AcceptInvitation), userID, email, login, memberID)
+}
+
+// DeclineInvitation mocks base method.
+func (m *MockService) DeclineInvitation(email, login string, memberID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeclineInvitation", email, login, memberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeclineInvitation indicates an expected call of DeclineInvitation.
+func (mr *MockServiceMockRecorder) DeclineInvitation(email, login, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeclineInvitation", reflect.TypeOf((*MockService)(nil).DeclineInvitation), email, login, memberID)
+}
+
+// Delete mocks base method.
+func (m *MockService) Delete(userID, notebookID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", userID, notebookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockServiceMockRecorder) Delete(userID, notebookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), userID, notebookID)
+}
+
+// Get mocks base method.
+func (m *MockService) Get(userID, notebookID uint) (Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", userID, notebookID)
+	ret0, _ := ret[0].(Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockServiceMockRecorder) Get(userID, notebookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockService)(nil).Get), userID, notebookID)
+}
+
+// GetAccessible mocks base method.
+func (m *MockService) GetAccessible(userID, notebookID uint) (Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessible", userID, notebookID)
+	ret0, _ := ret[0].(Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessible indicates an expected call of GetAccessible.
+func (mr *MockServiceMockRecorder) GetAccessible(userID, notebookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessible", reflect.TypeOf((*MockService)(nil).GetAccessible), userID, notebookID)
+}
+
+// GetAll mocks base method.
+func (m *MockService) GetAll(userID uint) ([]Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAll", userID)
+	ret0, _ := ret[0].([]Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAll indicates an expected call of GetAll.
+func (mr *MockServiceMockRecorder) GetAll(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockService)(nil).GetAll), userID)
+}
+
+// GetInvitations mocks base method.
+func (m *MockService) GetInvitations(email, login string) ([]Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvitations", email, login)
+	ret0, _ := ret[0].([]Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvitations indicates an expected call of GetInvitations.
+func (mr *MockServiceMockRecorder) GetInvitations(email, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvitations", reflect.TypeOf((*MockService)(nil).GetInvitations), email, login)
+}
+
+// GetMembers mocks base method.
+func (m *MockService) GetMembers(userID, notebookID uint) ([]Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", userID, notebookID)
+	ret0, _ := ret[0].([]Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockServiceMockRecorder) GetMembers(userID, notebookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockService)(nil).GetMembers), userID, notebookID)
+}
+
+// Invite mocks base method.
+func (m *MockService) Invite(userID, notebookID uint, member Member) (Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Invite", userID, notebookID, member)
+	ret0, _ := ret[0].(Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Invite indicates an expected call of Invite.
+func (mr *MockServiceMockRecorder) Invite(userID, notebookID, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invite", reflect.TypeOf((*MockService)(nil).Invite), userID, notebookID, member)
+}
+
+// RemoveMember mocks base method.
+func (m *MockService) RemoveMember(userID, notebookID, memberID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", userID, notebookID, memberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockServiceMockRecorder) RemoveMember(userID, notebookID, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockService)(nil).RemoveMember), userID, notebookID, memberID)
+}
+
+// Save mocks base method.
+func (m *MockService) Save(notebook Notebook) (Notebook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", notebook)
+	ret0, _ := ret[0].(Notebook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockServiceMockRecorder) Save(notebook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockService)(nil).Save), notebook)
+}
+
+// UpdateMemberRole mocks base method.
+func (m *MockService) UpdateMemberRole(userID, notebookID, memberID uint, role string) (Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMemberRole", userID, notebookID, memberID, role)
+	ret0, _ := ret[0].(Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMemberRole indicates an expected call of UpdateMemberRole.
+func (mr *MockServiceMockRecorder) UpdateMemberRole(userID, notebookID, memberID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRole", reflect.TypeOf((*MockService)(nil).UpdateMemberRole), userID, notebookID, memberID, role)
+}
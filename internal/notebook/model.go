@@ -0,0 +1,78 @@
+package notebook
+
+import "gorm.io/gorm"
+
+// Notebook represents an entity model used to store & retrieve the notebooks of the user to/from database.
+// A notebook is a github repo & the branch the notes are stored on, the user can have many notebooks
+// in addition to the default repo. The name of the notebook is unique for the user.
+// The repo is owned by the user's github account unless an organization or another user is set as the owner.
+// NoteExtensions is a space separated list of the file extensions of the notes & NotePathPattern is the regex the path
+// of the notes (without the extension) must match, the defaults of the default repo are used when they are empty.
+type Notebook struct {
+	gorm.Model
+	UserID uint
+
+	Name            string
+	Owner           string
+	Repository      string
+	Visibility      string
+	Branch          string
+	NoteExtensions  string
+	NotePathPattern string
+
+	// InstallationID is the id of github app installation used to access the repo.
+	// It is zero when the repo is accessed with the user's oauth2 token.
+	InstallationID int64
+
+	// Role is the role of the requesting user on the notebook, it is not stored.
+	Role string `gorm:"-"`
+}
+
+const (
+	// RoleOwner allows managing the members of the notebook in addition to editing the notes.
+	RoleOwner = "owner"
+	// RoleEditor allows creating, updating & deleting the notes of the notebook.
+	RoleEditor = "editor"
+	// RoleViewer allows reading the notes of the notebook.
+	RoleViewer = "viewer"
+)
+
+const (
+	// StatusInvited is the status of the member who has not accepted the invitation yet.
+	StatusInvited = "invited"
+	// StatusAccepted is the status of the member who accepted the invitation.
+	StatusAccepted = "accepted"
+)
+
+// roleRanks orders the roles, a role grants all the permissions of the roles ranked lower.
+var roleRanks = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+// Roles are the roles a member of the notebook can have.
+var Roles = []interface{}{RoleOwner, RoleEditor, RoleViewer}
+
+// HasRole checks whether the role grants the permissions of the required role.
+func HasRole(role string, required string) bool {
+	return roleRanks[role] != 0 && roleRanks[role] >= roleRanks[required]
+}
+
+// Member represents an entity model used to store & retrieve the members of the shared notebooks to/from database.
+// The member is invited by github login or email, the user id is set once the invitee accepts the invitation.
+// The user who created the notebook is its owner & is not stored as a member.
+type Member struct {
+	gorm.Model
+	NotebookID uint
+	UserID     uint
+
+	Role         string
+	Status       string
+	InviteeEmail string
+	InviteeLogin string
+	InvitedBy    uint
+
+	Notebook Notebook `gorm:"foreignkey:NotebookID"`
+}
+
+// TableName returns the database table name of the notebook members.
+func (Member) TableName() string {
+	return "notebook_members"
+}
@@ -0,0 +1,182 @@
+package notebook
+
+import (
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Repo represents a notebook repository.
+// It provides methods to retrieve and manage notebook records from the database.
+//go:generate mockgen -source=repo.go -package=notebook -destination=mock_repo.go
+type Repo interface {
+	Get(userID uint, notebookID uint) (Notebook, error)
+	GetByName(userID uint, name string) (Notebook, error)
+	GetAllByUserID(userID uint) ([]Notebook, error)
+	Save(notebook Notebook) (Notebook, error)
+	Delete(userID uint, notebookID uint) (int64, error)
+
+	GetMember(notebookID uint, userID uint) (Member, error)
+	GetMemberByID(notebookID uint, memberID uint) (Member, error)
+	GetMembers(notebookID uint) ([]Member, error)
+	GetMembershipsByUserID(userID uint) ([]Member, error)
+	GetInvitation(memberID uint) (Member, error)
+	GetInvitations(email string, login string) ([]Member, error)
+	SaveMember(member Member) (Member, error)
+	DeleteMember(notebookID uint, memberID uint) (int64, error)
+}
+
+type repoImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates and returns a new instance of notebook repository.
+func NewRepository(db *gorm.DB) Repo {
+	return &repoImpl{
+		db: db,
+	}
+}
+
+// Get returns a notebook record of the user by notebook-id.
+// An empty notebook is returned when the notebook does not exist for the user.
+func (r *repoImpl) Get(userID uint, notebookID uint) (Notebook, error) {
+	var notebook Notebook
+	err := r.db.Where("id = ? AND user_id = ?", notebookID, userID).First(&notebook).Error
+	if err == gorm.ErrRecordNotFound {
+		return Notebook{}, nil
+	}
+	if err != nil {
+		return notebook, errors.Wrap(err, "retrieving notebook from database failed")
+	}
+	return notebook, nil
+}
+
+// GetByName returns a notebook record of the user by name.
+// An empty notebook is returned when the notebook does not exist for the user.
+func (r *repoImpl) GetByName(userID uint, name string) (Notebook, error) {
+	var notebook Notebook
+	err := r.db.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).First(&notebook).Error
+	if err == gorm.ErrRecordNotFound {
+		return Notebook{}, nil
+	}
+	if err != nil {
+		return notebook, errors.Wrap(err, "retrieving notebook from database failed")
+	}
+	return notebook, nil
+}
+
+// GetAllByUserID returns all the notebook records of the user ordered by name.
+func (r *repoImpl) GetAllByUserID(userID uint) ([]Notebook, error) {
+	var notebooks []Notebook
+	if err := r.db.Where("user_id = ?", userID).Order("name").Find(&notebooks).Error; err != nil {
+		return nil, errors.Wrap(err, "retrieving notebooks from database failed")
+	}
+	return notebooks, nil
+}
+
+// Save creates or updates a notebook record & returns the stored record.
+func (r *repoImpl) Save(notebook Notebook) (Notebook, error) {
+	if err := r.db.Save(&notebook).Error; err != nil {
+		return notebook, errors.Wrap(err, "storing notebook to database failed")
+	}
+	return notebook, nil
+}
+
+// Delete permanently deletes a notebook record of the user by notebook-id.
+// It returns the count of deleted records.
+func (r *repoImpl) Delete(userID uint, notebookID uint) (int64, error) {
+	result := r.db.Unscoped().Where("id = ? AND user_id = ?", notebookID, userID).Delete(&Notebook{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "deleting notebook from database failed")
+	}
+	return result.RowsAffected, nil
+}
+
+// GetMember returns the accepted member record of the user for the notebook along with the notebook.
+// An empty member is returned when the user is not a member of the notebook.
+func (r *repoImpl) GetMember(notebookID uint, userID uint) (Member, error) {
+	var member Member
+	err := r.db.Preload("Notebook").Where("notebook_id = ? AND user_id = ? AND status = ?", notebookID, userID, StatusAccepted).First(&member).Error
+	if err == gorm.ErrRecordNotFound {
+		return Member{}, nil
+	}
+	if err != nil {
+		return member, errors.Wrap(err, "retrieving notebook member from database failed")
+	}
+	return member, nil
+}
+
+// GetMemberByID returns a member record of the notebook by member-id.
+// An empty member is returned when the member does not exist for the notebook.
+func (r *repoImpl) GetMemberByID(notebookID uint, memberID uint) (Member, error) {
+	var member Member
+	err := r.db.Where("id = ? AND notebook_id = ?", memberID, notebookID).First(&member).Error
+	if err == gorm.ErrRecordNotFound {
+		return Member{}, nil
+	}
+	if err != nil {
+		return member, errors.Wrap(err, "retrieving notebook member from database failed")
+	}
+	return member, nil
+}
+
+// GetMembers returns all the member records of the notebook including the pending invitations.
+func (r *repoImpl) GetMembers(notebookID uint) ([]Member, error) {
+	var members []Member
+	if err := r.db.Where("notebook_id = ?", notebookID).Order("id").Find(&members).Error; err != nil {
+		return nil, errors.Wrap(err, "retrieving notebook members from database failed")
+	}
+	return members, nil
+}
+
+// GetMembershipsByUserID returns the accepted member records of the user along with their notebooks.
+func (r *repoImpl) GetMembershipsByUserID(userID uint) ([]Member, error) {
+	var members []Member
+	err := r.db.Preload("Notebook").Where("user_id = ? AND status = ?", userID, StatusAccepted).Order("id").Find(&members).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving notebook memberships from database failed")
+	}
+	return members, nil
+}
+
+// GetInvitation returns the pending invitation by member-id along with its notebook.
+// An empty member is returned when the invitation does not exist or it is already accepted.
+func (r *repoImpl) GetInvitation(memberID uint) (Member, error) {
+	var member Member
+	err := r.db.Preload("Notebook").Where("id = ? AND status = ?", memberID, StatusInvited).First(&member).Error
+	if err == gorm.ErrRecordNotFound {
+		return Member{}, nil
+	}
+	if err != nil {
+		return member, errors.Wrap(err, "retrieving notebook invitation from database failed")
+	}
+	return member, nil
+}
+
+// GetInvitations returns the pending invitations sent to the email or github login along with their notebooks.
+func (r *repoImpl) GetInvitations(email string, login string) ([]Member, error) {
+	var members []Member
+	err := r.db.Preload("Notebook").Where("status = ? AND ((invitee_email <> '' AND LOWER(invitee_email) = LOWER(?)) OR "+
+		"(invitee_login <> '' AND LOWER(invitee_login) = LOWER(?)))", StatusInvited, email, login).Order("id").Find(&members).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving notebook invitations from database failed")
+	}
+	return members, nil
+}
+
+// SaveMember creates or updates a member record & returns the stored record.
+func (r *repoImpl) SaveMember(member Member) (Member, error) {
+	if err := r.db.Omit("Notebook").Save(&member).Error; err != nil {
+		return member, errors.Wrap(err, "storing notebook member to database failed")
+	}
+	return member, nil
+}
+
+// DeleteMember permanently deletes a member record of the notebook by member-id.
+// It returns the count of deleted records.
+func (r *repoImpl) DeleteMember(notebookID uint, memberID uint) (int64, error) {
+	result := r.db.Unscoped().Where("id = ? AND notebook_id = ?", memberID, notebookID).Delete(&Member{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "deleting notebook member from database failed")
+	}
+	return result.RowsAffected, nil
+}
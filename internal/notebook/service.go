@@ -0,0 +1,278 @@
+package notebook
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotebookNotFound is returned when the notebook does not exist for the user.
+var ErrNotebookNotFound = errors.New("notebook not found")
+
+// ErrNotebookNameTaken is returned when the user already has another notebook with the same name.
+var ErrNotebookNameTaken = errors.New("notebook name is already taken")
+
+// ErrInsufficientRole is returned when the role of the user on the notebook does not permit the operation.
+var ErrInsufficientRole = errors.New("insufficient role on notebook")
+
+// ErrMemberExists is returned when the invitee is already a member of the notebook or already invited to it.
+var ErrMemberExists = errors.New("member already exists")
+
+// ErrMemberNotFound is returned when the member does not exist for the notebook.
+var ErrMemberNotFound = errors.New("member not found")
+
+// ErrInvitationNotFound is returned when the pending invitation does not exist for the user.
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// Service represents a notebook service.
+// It provides methods to manage the notebooks of the user & the members of the shared notebooks.
+//go:generate mockgen -source=service.go -package=notebook -destination=mock_service.go
+type Service interface {
+	Get(userID uint, notebookID uint) (Notebook, error)
+	GetAccessible(userID uint, notebookID uint) (Notebook, error)
+	GetAll(userID uint) ([]Notebook, error)
+	Save(notebook Notebook) (Notebook, error)
+	Delete(userID uint, notebookID uint) error
+
+	GetMembers(userID uint, notebookID uint) ([]Member, error)
+	Invite(userID uint, notebookID uint, member Member) (Member, error)
+	UpdateMemberRole(userID uint, notebookID uint, memberID uint, role string) (Member, error)
+	RemoveMember(userID uint, notebookID uint, memberID uint) error
+	GetInvitations(email string, login string) ([]Member, error)
+	AcceptInvitation(userID uint, email string, login string, memberID uint) (Member, error)
+	DeclineInvitation(email string, login string, memberID uint) error
+}
+
+type service struct {
+	repo Repo
+}
+
+// NewService creates and returns a new notebook service.
+func NewService(repo Repo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// Get retrieves the notebook created by the user with given notebook id.
+// ErrNotebookNotFound is returned when the notebook does not exist for the user.
+func (s *service) Get(userID uint, notebookID uint) (Notebook, error) {
+	notebook, err := s.repo.Get(userID, notebookID)
+	if err != nil {
+		return Notebook{}, err
+	}
+	if notebook.ID == 0 {
+		return Notebook{}, ErrNotebookNotFound
+	}
+	notebook.Role = RoleOwner
+	return notebook, nil
+}
+
+// GetAccessible retrieves the notebook with given notebook id created by the user or shared with the user.
+// The role of the user on the notebook is set on the returned notebook.
+// ErrNotebookNotFound is returned when the user can not access the notebook.
+func (s *service) GetAccessible(userID uint, notebookID uint) (Notebook, error) {
+	notebook, err := s.repo.Get(userID, notebookID)
+	if err != nil {
+		return Notebook{}, err
+	}
+	if notebook.ID != 0 {
+		notebook.Role = RoleOwner
+		return notebook, nil
+	}
+	member, err := s.repo.GetMember(notebookID, userID)
+	if err != nil {
+		return Notebook{}, err
+	}
+	if member.ID == 0 {
+		return Notebook{}, ErrNotebookNotFound
+	}
+	notebook = member.Notebook
+	notebook.Role = member.Role
+	return notebook, nil
+}
+
+// GetAll retrieves the notebooks created by the user followed by the notebooks shared with the user.
+func (s *service) GetAll(userID uint) ([]Notebook, error) {
+	notebooks, err := s.repo.GetAllByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range notebooks {
+		notebooks[i].Role = RoleOwner
+	}
+	memberships, err := s.repo.GetMembershipsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range memberships {
+		notebook := member.Notebook
+		notebook.Role = member.Role
+		notebooks = append(notebooks, notebook)
+	}
+	return notebooks, nil
+}
+
+// Save creates a new notebook or updates the existing notebook of the user.
+// It returns the stored notebook along with any error occurred while storing it.
+// ErrNotebookNotFound is returned when the notebook to be updated does not exist & ErrNotebookNameTaken
+// when another notebook of the user has the same name.
+func (s *service) Save(notebook Notebook) (Notebook, error) {
+	notebook.Name = strings.TrimSpace(notebook.Name)
+	if notebook.ID != 0 {
+		existing, err := s.Get(notebook.UserID, notebook.ID)
+		if err != nil {
+			return Notebook{}, err
+		}
+		notebook.CreatedAt = existing.CreatedAt
+	}
+	sameName, err := s.repo.GetByName(notebook.UserID, notebook.Name)
+	if err != nil {
+		return Notebook{}, err
+	}
+	if sameName.ID != 0 && sameName.ID != notebook.ID {
+		return Notebook{}, ErrNotebookNameTaken
+	}
+	return s.repo.Save(notebook)
+}
+
+// Delete permanently deletes the notebook of the user with given notebook id. The notes in the repo are not deleted.
+// ErrNotebookNotFound is returned when the notebook does not exist for the user.
+func (s *service) Delete(userID uint, notebookID uint) error {
+	count, err := s.repo.Delete(userID, notebookID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotebookNotFound
+	}
+	return nil
+}
+
+// GetMembers retrieves the members of the notebook accessible to the user including the pending invitations.
+func (s *service) GetMembers(userID uint, notebookID uint) ([]Member, error) {
+	if _, err := s.GetAccessible(userID, notebookID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetMembers(notebookID)
+}
+
+// Invite invites the user with the github login or email of the member to the notebook with the role of the member.
+// Only the owners of the notebook can invite the members. ErrMemberExists is returned when the invitee
+// is already a member of the notebook or already invited to it.
+func (s *service) Invite(userID uint, notebookID uint, member Member) (Member, error) {
+	if err := s.checkRole(userID, notebookID, RoleOwner); err != nil {
+		return Member{}, err
+	}
+	members, err := s.repo.GetMembers(notebookID)
+	if err != nil {
+		return Member{}, err
+	}
+	for _, m := range members {
+		if matchesInvitee(m, member.InviteeEmail, member.InviteeLogin) {
+			return Member{}, ErrMemberExists
+		}
+	}
+	member.NotebookID = notebookID
+	member.UserID = 0
+	member.Status = StatusInvited
+	member.InvitedBy = userID
+	return s.repo.SaveMember(member)
+}
+
+// UpdateMemberRole changes the role of the member of the notebook. Only the owners of the notebook can change the roles.
+// ErrMemberNotFound is returned when the member does not exist for the notebook.
+func (s *service) UpdateMemberRole(userID uint, notebookID uint, memberID uint, role string) (Member, error) {
+	if err := s.checkRole(userID, notebookID, RoleOwner); err != nil {
+		return Member{}, err
+	}
+	member, err := s.repo.GetMemberByID(notebookID, memberID)
+	if err != nil {
+		return Member{}, err
+	}
+	if member.ID == 0 {
+		return Member{}, ErrMemberNotFound
+	}
+	member.Role = role
+	return s.repo.SaveMember(member)
+}
+
+// RemoveMember removes the member from the notebook or revokes the pending invitation.
+// The owners of the notebook can remove any member & the members can remove themselves to leave the notebook.
+// ErrMemberNotFound is returned when the member does not exist for the notebook.
+func (s *service) RemoveMember(userID uint, notebookID uint, memberID uint) error {
+	member, err := s.repo.GetMemberByID(notebookID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.ID == 0 {
+		return ErrMemberNotFound
+	}
+	if member.UserID != userID || member.Status != StatusAccepted {
+		if err := s.checkRole(userID, notebookID, RoleOwner); err != nil {
+			return err
+		}
+	}
+	if _, err := s.repo.DeleteMember(notebookID, memberID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetInvitations retrieves the pending invitations sent to the email or github login of the user.
+func (s *service) GetInvitations(email string, login string) ([]Member, error) {
+	return s.repo.GetInvitations(email, login)
+}
+
+// AcceptInvitation makes the user a member of the notebook with the role of the invitation.
+// ErrInvitationNotFound is returned when the pending invitation is not sent to the email or github login of the user.
+func (s *service) AcceptInvitation(userID uint, email string, login string, memberID uint) (Member, error) {
+	invitation, err := s.getInvitation(email, login, memberID)
+	if err != nil {
+		return Member{}, err
+	}
+	invitation.UserID = userID
+	invitation.Status = StatusAccepted
+	return s.repo.SaveMember(invitation)
+}
+
+// DeclineInvitation deletes the pending invitation sent to the email or github login of the user.
+// ErrInvitationNotFound is returned when the pending invitation is not sent to the user.
+func (s *service) DeclineInvitation(email string, login string, memberID uint) error {
+	invitation, err := s.getInvitation(email, login, memberID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.DeleteMember(invitation.NotebookID, invitation.ID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *service) getInvitation(email string, login string, memberID uint) (Member, error) {
+	invitation, err := s.repo.GetInvitation(memberID)
+	if err != nil {
+		return Member{}, err
+	}
+	if invitation.ID == 0 || !matchesInvitee(invitation, email, login) {
+		return Member{}, ErrInvitationNotFound
+	}
+	return invitation, nil
+}
+
+// checkRole verifies that the role of the user on the notebook grants the permissions of the required role.
+func (s *service) checkRole(userID uint, notebookID uint, required string) error {
+	notebook, err := s.GetAccessible(userID, notebookID)
+	if err != nil {
+		return err
+	}
+	if !HasRole(notebook.Role, required) {
+		return ErrInsufficientRole
+	}
+	return nil
+}
+
+// matchesInvitee checks whether the member is invited with the email or the github login, ignoring the case.
+func matchesInvitee(member Member, email string, login string) bool {
+	return (member.InviteeEmail != "" && strings.EqualFold(member.InviteeEmail, email)) ||
+		(member.InviteeLogin != "" && strings.EqualFold(member.InviteeLogin, login))
+}
@@ -0,0 +1,358 @@
+package notebook
+
+import (
+	"errors"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const (
+	userID       = uint(1001)
+	memberUserID = uint(1002)
+	notebookID   = uint(7)
+	memberID     = uint(11)
+)
+
+func TestGet(t *testing.T) {
+	t.Run("should retrieve the notebook of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(userID, notebookID).Return(validNotebook(), nil)
+
+		notebook, err := service.Get(userID, notebookID)
+		assert.NoError(t, err)
+		assert.Equal(t, notebookID, notebook.ID)
+		assert.Equal(t, RoleOwner, notebook.Role)
+	})
+
+	t.Run("should return notebook not found error when the notebook does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(userID, notebookID).Return(Notebook{}, nil)
+
+		_, err := service.Get(userID, notebookID)
+		assert.ErrorIs(t, err, ErrNotebookNotFound)
+	})
+}
+
+func TestGetAccessible(t *testing.T) {
+	t.Run("should retrieve the notebook shared with the user along with the role of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(memberUserID, notebookID).Return(Notebook{}, nil)
+		mockRepo.EXPECT().GetMember(notebookID, memberUserID).Return(validMember(RoleEditor), nil)
+
+		notebook, err := service.GetAccessible(memberUserID, notebookID)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, notebook.UserID)
+		assert.Equal(t, RoleEditor, notebook.Role)
+	})
+
+	t.Run("should return notebook not found error when the notebook is not shared with the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(memberUserID, notebookID).Return(Notebook{}, nil)
+		mockRepo.EXPECT().GetMember(notebookID, memberUserID).Return(Member{}, nil)
+
+		_, err := service.GetAccessible(memberUserID, notebookID)
+		assert.ErrorIs(t, err, ErrNotebookNotFound)
+	})
+}
+
+func TestGetAll(t *testing.T) {
+	t.Run("should retrieve the notebooks of the user followed by the notebooks shared with the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		own := Notebook{Model: gorm.Model{ID: 8}, UserID: memberUserID, Name: "Personal"}
+		mockRepo.EXPECT().GetAllByUserID(memberUserID).Return([]Notebook{own}, nil)
+		mockRepo.EXPECT().GetMembershipsByUserID(memberUserID).Return([]Member{validMember(RoleViewer)}, nil)
+
+		notebooks, err := service.GetAll(memberUserID)
+		assert.NoError(t, err)
+		assert.Len(t, notebooks, 2)
+		assert.Equal(t, []string{"Personal", "Work"}, []string{notebooks[0].Name, notebooks[1].Name})
+		assert.Equal(t, []string{RoleOwner, RoleViewer}, []string{notebooks[0].Role, notebooks[1].Role})
+	})
+}
+
+func TestSave(t *testing.T) {
+	t.Run("should create the notebook with trimmed name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		notebook := Notebook{UserID: userID, Name: " Work ", Repository: "work-notes", Branch: "main"}
+		expected := Notebook{UserID: userID, Name: "Work", Repository: "work-notes", Branch: "main"}
+		mockRepo.EXPECT().GetByName(userID, "Work").Return(Notebook{}, nil)
+		mockRepo.EXPECT().Save(expected).Return(validNotebook(), nil)
+
+		saved, err := service.Save(notebook)
+		assert.NoError(t, err)
+		assert.Equal(t, notebookID, saved.ID)
+	})
+
+	t.Run("should update the notebook retaining its name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		notebook := validNotebook()
+		notebook.Branch = "develop"
+		mockRepo.EXPECT().Get(userID, notebookID).Return(validNotebook(), nil)
+		mockRepo.EXPECT().GetByName(userID, "Work").Return(validNotebook(), nil)
+		mockRepo.EXPECT().Save(notebook).Return(notebook, nil)
+
+		saved, err := service.Save(notebook)
+		assert.NoError(t, err)
+		assert.Equal(t, "develop", saved.Branch)
+	})
+
+	t.Run("should return notebook name taken error when another notebook has the same name", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetByName(userID, "Work").Return(validNotebook(), nil)
+
+		_, err := service.Save(Notebook{UserID: userID, Name: "Work"})
+		assert.ErrorIs(t, err, ErrNotebookNameTaken)
+	})
+
+	t.Run("should return notebook not found error when the notebook to be updated does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(userID, notebookID).Return(Notebook{}, nil)
+
+		_, err := service.Save(validNotebook())
+		assert.ErrorIs(t, err, ErrNotebookNotFound)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	t.Run("should delete the notebook of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Delete(userID, notebookID).Return(int64(1), nil)
+
+		err := service.Delete(userID, notebookID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return notebook not found error when the notebook does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Delete(userID, notebookID).Return(int64(0), nil)
+
+		err := service.Delete(userID, notebookID)
+		assert.ErrorIs(t, err, ErrNotebookNotFound)
+	})
+
+	t.Run("should return error when deleting the notebook fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Delete(userID, notebookID).Return(int64(0), errors.New("some error"))
+
+		err := service.Delete(userID, notebookID)
+		assert.Error(t, err)
+	})
+}
+
+func TestInvite(t *testing.T) {
+	t.Run("should invite the member to the notebook when the user is the owner", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		expected := Member{NotebookID: notebookID, Role: RoleEditor, Status: StatusInvited, InviteeLogin: "janedoe", InvitedBy: userID}
+		mockRepo.EXPECT().Get(userID, notebookID).Return(validNotebook(), nil)
+		mockRepo.EXPECT().GetMembers(notebookID).Return([]Member{{InviteeEmail: "someone@example.com"}}, nil)
+		mockRepo.EXPECT().SaveMember(expected).Return(expected, nil)
+
+		member, err := service.Invite(userID, notebookID, Member{Role: RoleEditor, InviteeLogin: "janedoe"})
+		assert.NoError(t, err)
+		assert.Equal(t, StatusInvited, member.Status)
+	})
+
+	t.Run("should return member exists error when the invitee is already invited", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(userID, notebookID).Return(validNotebook(), nil)
+		mockRepo.EXPECT().GetMembers(notebookID).Return([]Member{{InviteeLogin: "JaneDoe"}}, nil)
+
+		_, err := service.Invite(userID, notebookID, Member{Role: RoleEditor, InviteeLogin: "janedoe"})
+		assert.ErrorIs(t, err, ErrMemberExists)
+	})
+
+	t.Run("should return insufficient role error when the user is not an owner of the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(memberUserID, notebookID).Return(Notebook{}, nil)
+		mockRepo.EXPECT().GetMember(notebookID, memberUserID).Return(validMember(RoleEditor), nil)
+
+		_, err := service.Invite(memberUserID, notebookID, Member{Role: RoleViewer, InviteeEmail: "someone@example.com"})
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("should let the member leave the notebook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetMemberByID(notebookID, memberID).Return(validMember(RoleViewer), nil)
+		mockRepo.EXPECT().DeleteMember(notebookID, memberID).Return(int64(1), nil)
+
+		err := service.RemoveMember(memberUserID, notebookID, memberID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return insufficient role error when a member removes another member", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		otherUserID := uint(1003)
+		mockRepo.EXPECT().GetMemberByID(notebookID, memberID).Return(validMember(RoleViewer), nil)
+		mockRepo.EXPECT().Get(otherUserID, notebookID).Return(Notebook{}, nil)
+		mockRepo.EXPECT().GetMember(notebookID, otherUserID).Return(Member{Role: RoleEditor, Notebook: validNotebook(), Model: gorm.Model{ID: 12}}, nil)
+
+		err := service.RemoveMember(otherUserID, notebookID, memberID)
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("should return member not found error when the member does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetMemberByID(notebookID, memberID).Return(Member{}, nil)
+
+		err := service.RemoveMember(userID, notebookID, memberID)
+		assert.ErrorIs(t, err, ErrMemberNotFound)
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	t.Run("should make the user a member when the invitation is sent to the user's github login", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		invitation := Member{Model: gorm.Model{ID: memberID}, NotebookID: notebookID, Role: RoleEditor, Status: StatusInvited, InviteeLogin: "janedoe"}
+		accepted := invitation
+		accepted.UserID = memberUserID
+		accepted.Status = StatusAccepted
+		mockRepo.EXPECT().GetInvitation(memberID).Return(invitation, nil)
+		mockRepo.EXPECT().SaveMember(accepted).Return(accepted, nil)
+
+		member, err := service.AcceptInvitation(memberUserID, "jane.doe@example.com", "JaneDoe", memberID)
+		assert.NoError(t, err)
+		assert.Equal(t, StatusAccepted, member.Status)
+	})
+
+	t.Run("should return invitation not found error when the invitation is sent to someone else", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetInvitation(memberID).Return(Member{Model: gorm.Model{ID: memberID}, Status: StatusInvited, InviteeEmail: "someone@example.com"}, nil)
+
+		_, err := service.AcceptInvitation(memberUserID, "jane.doe@example.com", "janedoe", memberID)
+		assert.ErrorIs(t, err, ErrInvitationNotFound)
+	})
+}
+
+func TestDeclineInvitation(t *testing.T) {
+	t.Run("should delete the invitation sent to the user's email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetInvitation(memberID).Return(Member{Model: gorm.Model{ID: memberID}, NotebookID: notebookID, Status: StatusInvited,
+			InviteeEmail: "jane.doe@example.com"}, nil)
+		mockRepo.EXPECT().DeleteMember(notebookID, memberID).Return(int64(1), nil)
+
+		err := service.DeclineInvitation("Jane.Doe@example.com", "janedoe", memberID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestHasRole(t *testing.T) {
+	t.Run("should grant the permissions of the lower ranked roles", func(t *testing.T) {
+		assert.True(t, HasRole(RoleOwner, RoleEditor))
+		assert.True(t, HasRole(RoleEditor, RoleEditor))
+		assert.False(t, HasRole(RoleViewer, RoleEditor))
+		assert.False(t, HasRole("", RoleViewer))
+	})
+}
+
+func validNotebook() Notebook {
+	return Notebook{
+		Model:      gorm.Model{ID: notebookID},
+		UserID:     userID,
+		Name:       "Work",
+		Repository: "work-notes",
+		Visibility: "private",
+		Branch:     "main",
+	}
+}
+
+func validMember(role string) Member {
+	return Member{
+		Model:        gorm.Model{ID: memberID},
+		NotebookID:   notebookID,
+		UserID:       memberUserID,
+		Role:         role,
+		Status:       StatusAccepted,
+		InviteeLogin: "janedoe",
+		InvitedBy:    userID,
+		Notebook:     validNotebook(),
+	}
+}
@@ -10,4 +10,12 @@ type DefaultRepo struct {
 	Name          string
 	Visibility    string
 	DefaultBranch string
+
+	// Owner is the github organization or user owning the repo.
+	// It is empty when the repo is owned by the user's github account.
+	Owner string
+
+	// InstallationID is the id of github app installation used to access the repo.
+	// It is zero when the repo is accessed with the user's oauth2 token.
+	InstallationID int64
 }
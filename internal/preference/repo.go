@@ -11,6 +11,7 @@ import (
 type Repo interface {
 	Save(defaultRepo DefaultRepo) error
 	GetByUserID(userID uint) (DefaultRepo, error)
+	DeleteByUserID(userID uint) error
 }
 
 type repoImpl struct {
@@ -44,3 +45,11 @@ func (r *repoImpl) Save(defaultRepo DefaultRepo) error {
 	}
 	return nil
 }
+
+// DeleteByUserID permanently deletes all the preference records of the user.
+func (r *repoImpl) DeleteByUserID(userID uint) error {
+	if err := r.db.Unscoped().Where("user_id = ?", userID).Delete(&DefaultRepo{}).Error; err != nil {
+		return errors.Wrap(err, "deleting user's preferences from database failed")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package preference
 type Service interface {
 	Save(defaultRepo DefaultRepo) error
 	GetByUserID(userID uint) (DefaultRepo, error)
+	DeleteByUserID(userID uint) error
 }
 
 type serviceImpl struct {
@@ -34,3 +35,9 @@ func (s *serviceImpl) GetByUserID(userID uint) (DefaultRepo, error) {
 	}
 	return defaultRepo, nil
 }
+
+// DeleteByUserID permanently deletes all the preferences of the user.
+// It returns any error occurred while deleting them.
+func (s *serviceImpl) DeleteByUserID(userID uint) error {
+	return s.repo.DeleteByUserID(userID)
+}
@@ -71,3 +71,29 @@ func TestSave(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestDeleteByUserID(t *testing.T) {
+	t.Run("should delete the preferences of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().DeleteByUserID(userID).Return(nil)
+
+		err := service.DeleteByUserID(userID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when deleting the preferences fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().DeleteByUserID(userID).Return(errors.New("some error"))
+
+		err := service.DeleteByUserID(userID)
+		assert.Error(t, err)
+	})
+}
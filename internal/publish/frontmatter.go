@@ -0,0 +1,61 @@
+package publish
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelimiter = "---"
+
+// frontMatter represents the yaml front matter of a note.
+type frontMatter struct {
+	Title string  `yaml:"title"`
+	Tags  tagList `yaml:"tags"`
+}
+
+// tagList represents the tags of a note, the tags are provided either as a list or as a comma separated string.
+type tagList []string
+
+// UnmarshalYAML unmarshals the tags from a yaml list or a comma separated yaml string.
+func (t *tagList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*t = strings.Split(value.Value, ",")
+		return nil
+	}
+	var tags []string
+	if err := value.Decode(&tags); err != nil {
+		return err
+	}
+	*t = tags
+	return nil
+}
+
+// parseFrontMatter parses the yaml front matter at the start of the note.
+// It returns the front matter along with the content of the note without the front matter.
+// The content is returned as it is when the note does not start with a valid front matter.
+func parseFrontMatter(content string) (frontMatter, string) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(content, frontMatterDelimiter+"\n") {
+		return frontMatter{}, content
+	}
+	rest := content[len(frontMatterDelimiter)+1:]
+	var raw, body string
+	if strings.HasPrefix(rest, frontMatterDelimiter+"\n") || rest == frontMatterDelimiter {
+		raw, body = "", strings.TrimPrefix(rest, frontMatterDelimiter)
+	} else {
+		end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+		if end < 0 {
+			if !strings.HasSuffix(rest, "\n"+frontMatterDelimiter) {
+				return frontMatter{}, content
+			}
+			end = len(rest) - len(frontMatterDelimiter) - 1
+		}
+		raw, body = rest[:end], rest[end+len(frontMatterDelimiter)+1:]
+	}
+	var fm frontMatter
+	if err := yaml.Unmarshal([]byte(raw), &fm); err != nil {
+		return frontMatter{}, content
+	}
+	return fm, strings.TrimPrefix(body, "\n")
+}
@@ -0,0 +1,146 @@
+package publish
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"strings"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// link represents a link to a page of the site rendered by the layout.
+type link struct {
+	URL     string
+	Title   string
+	Count   int
+	Current bool
+}
+
+// layoutData represents the data of a page rendered by the layout.
+type layoutData struct {
+	SiteTitle string
+	Title     string
+	Root      string
+	TagsDir   string
+	Nav       []link
+	Tags      []link
+	Content   template.HTML
+	List      []link
+	Backlinks []link
+}
+
+const baseStylesheet = `body{margin:0;font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;line-height:1.6;color:#24292f;display:flex}
+nav{width:16rem;min-height:100vh;padding:1.5rem;background:#f6f8fa;border-right:1px solid #d0d7de;box-sizing:border-box}
+nav ul{list-style:none;padding:0}
+nav li.current a{font-weight:600}
+main{flex:1;max-width:50rem;padding:1.5rem 2rem}
+a{color:#0969da;text-decoration:none}
+.site-title{font-size:1.25rem;font-weight:600;color:#24292f}
+.tags{list-style:none;padding:0;display:flex;gap:.5rem}
+.tags a{padding:.1rem .5rem;border-radius:1rem;background:#ddf4ff;font-size:.85rem}
+.backlinks{margin-top:3rem;border-top:1px solid #d0d7de}
+table{border-collapse:collapse}
+th,td{border:1px solid #d0d7de;padding:.4rem .8rem}
+pre{padding:1rem;overflow:auto;background:#f6f8fa;border-radius:6px}
+`
+
+var layoutTemplate = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} · {{.SiteTitle}}</title>
+<link rel="stylesheet" href="{{.Root}}style.css">
+</head>
+<body>
+<nav>
+<a class="site-title" href="{{.Root}}index.html">{{.SiteTitle}}</a>
+<ul>
+{{- range .Nav}}
+<li{{if .Current}} class="current"{{end}}><a href="{{.URL}}">{{.Title}}</a></li>
+{{- end}}
+</ul>
+<a href="{{.Root}}{{.TagsDir}}/index.html">Tags</a>
+</nav>
+<main>
+{{- if .Tags}}
+<ul class="tags">
+{{- range .Tags}}
+<li><a href="{{.URL}}">{{.Title}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Content}}
+{{.Content}}
+{{- else}}
+<h1>{{.Title}}</h1>
+<ul>
+{{- range .List}}
+<li><a href="{{.URL}}">{{.Title}}</a>{{if .Count}} ({{.Count}}){{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Backlinks}}
+<section class="backlinks">
+<h2>Linked from</h2>
+<ul>
+{{- range .Backlinks}}
+<li><a href="{{.URL}}">{{.Title}}</a></li>
+{{- end}}
+</ul>
+</section>
+{{- end}}
+</main>
+</body>
+</html>
+`))
+
+// renderLayout renders the page of a note with the navigation to all the pages of the site.
+func renderLayout(siteTitle string, tagsDir string, url string, title string, content template.HTML, tags []link, backlinks []link,
+	pages []*page) (string, error) {
+	return executeLayout(layoutData{
+		SiteTitle: siteTitle,
+		Title:     title,
+		Root:      strings.Repeat("../", strings.Count(url, "/")),
+		TagsDir:   tagsDir,
+		Nav:       navLinks(url, pages),
+		Tags:      tags,
+		Content:   content,
+		Backlinks: backlinks,
+	})
+}
+
+// renderListPage renders a generated page listing the links, like the home page & the tag pages.
+func renderListPage(siteTitle string, tagsDir string, url string, title string, list []link, pages []*page) (string, error) {
+	return executeLayout(layoutData{
+		SiteTitle: siteTitle,
+		Title:     title,
+		Root:      strings.Repeat("../", strings.Count(url, "/")),
+		TagsDir:   tagsDir,
+		Nav:       navLinks(url, pages),
+		List:      list,
+	})
+}
+
+func executeLayout(data layoutData) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := layoutTemplate.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func navLinks(url string, pages []*page) []link {
+	links := pageLinks(url, pages)
+	for i, p := range pages {
+		links[i].Current = p.url == url
+	}
+	return links
+}
+
+// writeCodeStylesheet writes the styles of the css classes used by the highlighted code blocks.
+func writeCodeStylesheet(w io.Writer) error {
+	return chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(w, styles.Get("github"))
+}
@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package publish is a generated GoMock package.
+package publish
+
+import (
+	context "context"
+	reflect "reflect"
+
+	github "github.com/batnoter/batnoter-api/internal/github"
+	gomock "github.com/golang/mock/gomock"
+	oauth2 "golang.org/x/oauth2"
+)
+
+// MockService is a mock of Service interface.
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService.
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance.
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Build mocks base method.
+func (m *MockService) Build(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, opts Options) (Site, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Build", ctx, ghToken, repoDetails, opts)
+	ret0, _ := ret[0].(Site)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Build indicates an expected call of Build.
+func (mr *MockServiceMockRecorder) Build(ctx, ghToken, repoDetails, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockService)(nil).Build), ctx, ghToken, repoDetails, opts)
+}
+
+// Deploy mocks base method.
+func (m *MockService) Deploy(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, site Site, authorName, authorEmail string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deploy", ctx, ghToken, repoDetails, site, authorName, authorEmail)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Deploy indicates an expected call of Deploy.
+func (mr *MockServiceMockRecorder) Deploy(ctx, ghToken, repoDetails, site, authorName, authorEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deploy", reflect.TypeOf((*MockService)(nil).Deploy), ctx, ghToken, repoDetails, site, authorName, authorEmail)
+}
@@ -0,0 +1,40 @@
+package publish
+
+import (
+	"archive/zip"
+	"bytes"
+	"sort"
+)
+
+// Site represents a static website generated from the notes of a folder.
+// Files contains the content of the files of the site by their path relative to the root of the site.
+type Site struct {
+	Title string
+	Pages int
+	Files map[string]string
+}
+
+// Archive returns the zip archive containing the files of the site.
+func (s Site) Archive() ([]byte, error) {
+	paths := make([]string, 0, len(s.Files))
+	for p := range s.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, p := range paths {
+		w, err := zw.Create(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(s.Files[p])); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,360 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoNotes is returned when there are no notes in the folder to be published.
+var ErrNoNotes = errors.New("no notes found in the path")
+
+// ErrTooManyNotes is returned when the folder to be published contains more notes than allowed.
+var ErrTooManyNotes = errors.New("too many notes in the path")
+
+// ErrPagesBranchConflict is returned when the site is deployed to the repo whose default branch is the pages branch.
+var ErrPagesBranchConflict = errors.New("default branch of the repo can not be used to publish the site")
+
+const (
+	// PagesBranch is the branch of the repo the site is deployed to, github pages serves the site from this branch.
+	PagesBranch = "gh-pages"
+
+	// notes are fetched from github one by one, so the count of the notes published at once is limited
+	maxNotes = 200
+
+	deployMessage = "Published with BatNoter"
+
+	// tag pages are placed in this folder unless a folder of the notes has the same name
+	defaultTagsDir = "tags"
+)
+
+// Options represents the options used to build a site.
+// Path is the folder of the repo to be published, all the notes of the repo are published when it is empty.
+type Options struct {
+	Path  string
+	Title string
+}
+
+// Service represents a publish service.
+// It builds the static website from the notes of a folder & deploys it to the pages branch of the repo.
+//go:generate mockgen -source=service.go -package=publish -destination=mock_service.go
+type Service interface {
+	Build(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, opts Options) (Site, error)
+	Deploy(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, site Site, authorName string, authorEmail string) (string, error)
+}
+
+type service struct {
+	githubService github.Service
+	renderer      markdown.Renderer
+}
+
+// NewService creates and returns a new publish service.
+func NewService(githubService github.Service, renderer markdown.Renderer) Service {
+	return &service{
+		githubService: githubService,
+		renderer:      renderer,
+	}
+}
+
+// page represents a page of the site generated from a note.
+type page struct {
+	notePath  string
+	url       string // path of the page relative to the root of the site
+	title     string
+	tags      []string
+	body      string
+	content   string
+	backlinks []*page
+}
+
+// Build builds the static website from the markdown notes of the folder.
+// Each note is rendered to a html page with navigation, tags & backlinks from the other pages of the site.
+// The title & tags of the notes are read from their yaml front matter, the tags get their own pages listing the tagged pages.
+// The links between the notes are rewritten to the links between the pages, the images are not included in the site.
+func (s *service) Build(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, opts Options) (Site, error) {
+	root := strings.Trim(opts.Path, "/")
+	files, err := s.githubService.GetTree(ctx, ghToken, github.GitFileProps{RepoDetails: repoDetails})
+	if err != nil {
+		return Site{}, err
+	}
+	notes := make([]github.GitFile, 0, len(files))
+	for _, file := range files {
+		if !file.IsDir && (root == "" || strings.HasPrefix(file.Path, root+"/")) {
+			notes = append(notes, file)
+		}
+	}
+	if len(notes) == 0 {
+		return Site{}, ErrNoNotes
+	}
+	if len(notes) > maxNotes {
+		return Site{}, ErrTooManyNotes
+	}
+
+	pages := make(map[string]*page, len(notes))
+	for _, note := range notes {
+		content, err := s.githubService.GetBlob(ctx, ghToken, github.GitFileProps{SHA: note.SHA, Path: note.Path, RepoDetails: repoDetails})
+		if err != nil {
+			return Site{}, err
+		}
+		pages[note.Path] = makePage(note.Path, root, string(content))
+	}
+	dedupePageURLs(pages)
+	for _, p := range pages {
+		if err := s.render(p, pages); err != nil {
+			return Site{}, err
+		}
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = repoDetails.Repository
+		if root != "" {
+			title = path.Base(root)
+		}
+	}
+	return buildSite(title, sortedPages(pages))
+}
+
+// Deploy commits the files of the site to the pages branch of the repo, replacing the previously published site.
+// It returns the sha of the commit along with any error occurred while deploying the site.
+func (s *service) Deploy(ctx context.Context, ghToken oauth2.Token, repoDetails github.GitRepoProps, site Site, authorName string,
+	authorEmail string) (string, error) {
+	if repoDetails.DefaultBranch == PagesBranch {
+		return "", ErrPagesBranchConflict
+	}
+	return s.githubService.ReplaceBranch(ctx, ghToken, github.GitCommitProps{
+		Branch:      PagesBranch,
+		Message:     deployMessage,
+		Files:       site.Files,
+		AuthorName:  authorName,
+		AuthorEmail: authorEmail,
+		RepoDetails: repoDetails,
+	})
+}
+
+// render renders the note of the page, the links to the other notes of the site are rewritten to their pages
+// & the page is added to the backlinks of the linked pages.
+func (s *service) render(p *page, pages map[string]*page) error {
+	content, err := s.renderer.Render([]byte(p.body), markdown.Options{
+		Path: p.notePath,
+		LinkURL: func(notePath string) string {
+			target, ok := pages[notePath]
+			if !ok {
+				return relativeURL(p.notePath, notePath)
+			}
+			if target != p && !containsPage(target.backlinks, p) {
+				target.backlinks = append(target.backlinks, p)
+			}
+			return relativeURL(p.url, target.url)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	p.content = content
+	return nil
+}
+
+func makePage(notePath string, root string, content string) *page {
+	fm, body := parseFrontMatter(content)
+	url := strings.TrimSuffix(notePath, path.Ext(notePath)) + ".html"
+	if root != "" {
+		url = strings.TrimPrefix(url, root+"/")
+	}
+	title := strings.TrimSpace(fm.Title)
+	if title == "" {
+		title = strings.ReplaceAll(strings.TrimSuffix(path.Base(notePath), path.Ext(notePath)), "-", " ")
+	}
+	tags := make([]string, 0, len(fm.Tags))
+	for _, tag := range fm.Tags {
+		tag = strings.TrimSpace(tag)
+		if tagSlug(tag) != "" && !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return &page{notePath: notePath, url: url, title: title, tags: tags, body: body}
+}
+
+// dedupePageURLs keeps the note extension in the urls of the pages whose notes differ only by the extension
+// (e.g. a.md & a.markdown are published as a.md.html & a.markdown.html), so a page does not overwrite another one.
+func dedupePageURLs(pages map[string]*page) {
+	byURL := make(map[string][]*page, len(pages))
+	for _, p := range pages {
+		byURL[p.url] = append(byURL[p.url], p)
+	}
+	for _, colliding := range byURL {
+		if len(colliding) < 2 {
+			continue
+		}
+		for _, p := range colliding {
+			p.url = strings.TrimSuffix(p.url, ".html") + path.Ext(p.notePath) + ".html"
+		}
+	}
+}
+
+func sortedPages(pages map[string]*page) []*page {
+	result := make([]*page, 0, len(pages))
+	for _, p := range pages {
+		sort.Slice(p.backlinks, func(i, j int) bool { return p.backlinks[i].url < p.backlinks[j].url })
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].url < result[j].url })
+	return result
+}
+
+// buildSite lays out the rendered pages along with the home page, the tag pages & the stylesheet of the site.
+// The note at the root of the folder named index is used as the home page, otherwise the home page lists all the pages.
+// The tag pages are placed in a folder not used by the pages, so a folder of notes named tags is not overwritten.
+func buildSite(title string, pages []*page) (Site, error) {
+	site := Site{Title: title, Pages: len(pages), Files: map[string]string{
+		// github pages should serve the files as they are instead of building them with jekyll
+		".nojekyll": "",
+	}}
+	css, err := stylesheet()
+	if err != nil {
+		return Site{}, err
+	}
+	site.Files["style.css"] = css
+
+	tagsDir := tagPagesDir(pages)
+	tagged := make(map[string][]*page)
+	tagNames := make(map[string]string)
+	for _, p := range pages {
+		html, err := renderLayout(title, tagsDir, p.url, p.title, template.HTML(p.content), pageTags(tagsDir, p.url, p.tags),
+			pageLinks(p.url, p.backlinks), pages)
+		if err != nil {
+			return Site{}, err
+		}
+		site.Files[p.url] = html
+		for _, tag := range p.tags {
+			slug := tagSlug(tag)
+			if _, ok := tagNames[slug]; !ok {
+				tagNames[slug] = tag
+			}
+			tagged[slug] = append(tagged[slug], p)
+		}
+	}
+
+	if _, ok := site.Files["index.html"]; !ok {
+		html, err := renderListPage(title, tagsDir, "index.html", title, pageLinks("index.html", pages), pages)
+		if err != nil {
+			return Site{}, err
+		}
+		site.Files["index.html"] = html
+	}
+
+	slugs := make([]string, 0, len(tagged))
+	for slug := range tagged {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	tagsURL := path.Join(tagsDir, "index.html")
+	tagLinks := make([]link, 0, len(slugs))
+	for _, slug := range slugs {
+		tagURL := path.Join(tagsDir, slug+".html")
+		tagLinks = append(tagLinks, link{URL: relativeURL(tagsURL, tagURL), Title: tagNames[slug], Count: len(tagged[slug])})
+		html, err := renderListPage(title, tagsDir, tagURL, "Tagged "+tagNames[slug], pageLinks(tagURL, tagged[slug]), pages)
+		if err != nil {
+			return Site{}, err
+		}
+		site.Files[tagURL] = html
+	}
+	html, err := renderListPage(title, tagsDir, tagsURL, "Tags", tagLinks, pages)
+	if err != nil {
+		return Site{}, err
+	}
+	site.Files[tagsURL] = html
+	return site, nil
+}
+
+func pageLinks(fromURL string, pages []*page) []link {
+	links := make([]link, 0, len(pages))
+	for _, p := range pages {
+		links = append(links, link{URL: relativeURL(fromURL, p.url), Title: p.title})
+	}
+	return links
+}
+
+// tagPagesDir returns the folder of the tag pages. The folder is suffixed with a number when a page of the notes is in the folder.
+func tagPagesDir(pages []*page) string {
+	dir := defaultTagsDir
+	for i := 2; containsDir(pages, dir); i++ {
+		dir = fmt.Sprintf("%s-%d", defaultTagsDir, i)
+	}
+	return dir
+}
+
+func containsDir(pages []*page, dir string) bool {
+	for _, p := range pages {
+		if strings.HasPrefix(p.url, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func pageTags(tagsDir string, fromURL string, tags []string) []link {
+	links := make([]link, 0, len(tags))
+	for _, tag := range tags {
+		links = append(links, link{URL: relativeURL(fromURL, path.Join(tagsDir, tagSlug(tag)+".html")), Title: tag})
+	}
+	return links
+}
+
+// relativeURL returns the url of the target relative to the page, both are the paths relative to the root of the site.
+func relativeURL(from string, to string) string {
+	fromDirs := strings.Split(path.Dir(from), "/")
+	if fromDirs[0] == "." {
+		fromDirs = nil
+	}
+	toParts := strings.Split(to, "/")
+	common := 0
+	for common < len(fromDirs) && common < len(toParts)-1 && fromDirs[common] == toParts[common] {
+		common++
+	}
+	return strings.Repeat("../", len(fromDirs)-common) + strings.Join(toParts[common:], "/")
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// tagSlug returns the name of the tag page, the tags differing only in case & punctuation share the same page.
+func tagSlug(tag string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(tag), "-"), "-")
+}
+
+func containsPage(pages []*page, p *page) bool {
+	for _, candidate := range pages {
+		if candidate == p {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, candidate := range tags {
+		if tagSlug(candidate) == tagSlug(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// stylesheet returns the stylesheet of the site including the styles of the highlighted code.
+func stylesheet() (string, error) {
+	buf := bytes.NewBufferString(baseStylesheet)
+	if err := writeCodeStylesheet(buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
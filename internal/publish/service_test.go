@@ -0,0 +1,241 @@
+package publish
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/markdown"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ghToken     = oauth2.Token{AccessToken: "gho_token"}
+	repoDetails = github.GitRepoProps{Repository: "notes", DefaultBranch: "main", Owner: "johndoe"}
+)
+
+func TestBuild(t *testing.T) {
+	t.Run("should build the site with pages, navigation, tags & backlinks from the notes of the folder", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(mockGithubService, markdown.NewRenderer())
+		mockGithubService.EXPECT().GetTree(gomock.Any(), ghToken, github.GitFileProps{RepoDetails: repoDetails}).Return([]github.GitFile{
+			{SHA: "sha1", Path: "docs/setup.md"},
+			{SHA: "sha2", Path: "docs/guides/deploy.md"},
+			{SHA: "sha3", Path: "personal/diary.md"},
+		}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha1", Path: "docs/setup.md", RepoDetails: repoDetails}).
+			Return([]byte("---\ntitle: Getting Started\ntags: [Ops, setup]\n---\n# Setup\n\nSee [deploy](guides/deploy.md) & [diary](../personal/diary.md).\n"), nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha2", Path: "docs/guides/deploy.md", RepoDetails: repoDetails}).
+			Return([]byte("---\ntags: ops\n---\nBack to [setup](../setup.md).\n"), nil)
+
+		site, err := service.Build(context.Background(), ghToken, repoDetails, Options{Path: "/docs/", Title: "Team Docs"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Team Docs", site.Title)
+		assert.Equal(t, 2, site.Pages)
+		assert.ElementsMatch(t, []string{".nojekyll", "style.css", "index.html", "setup.html", "guides/deploy.html", "tags/index.html", "tags/ops.html", "tags/setup.html"}, keys(site.Files))
+
+		setup := site.Files["setup.html"]
+		assert.Contains(t, setup, "<title>Getting Started · Team Docs</title>")
+		assert.Contains(t, setup, `<link rel="stylesheet" href="style.css">`)
+		assert.Contains(t, setup, `<li class="current"><a href="setup.html">Getting Started</a></li>`)
+		assert.Contains(t, setup, `<li><a href="guides/deploy.html">deploy</a></li>`)
+		assert.Contains(t, setup, `<a href="guides/deploy.html" rel="nofollow">deploy</a>`)
+		assert.Contains(t, setup, `<a href="../personal/diary.md" rel="nofollow">diary</a>`)
+		assert.Contains(t, setup, `<li><a href="tags/ops.html">Ops</a></li>`)
+		assert.Contains(t, setup, `<h2>Linked from</h2>`)
+		assert.NotContains(t, setup, "title: Getting Started")
+
+		deploy := site.Files["guides/deploy.html"]
+		assert.Contains(t, deploy, `<link rel="stylesheet" href="../style.css">`)
+		assert.Contains(t, deploy, `<a href="../setup.html" rel="nofollow">setup</a>`)
+		assert.Contains(t, deploy, `<section class="backlinks">
+<h2>Linked from</h2>
+<ul>
+<li><a href="../setup.html">Getting Started</a></li>
+</ul>`)
+
+		assert.Contains(t, site.Files["index.html"], `<h1>Team Docs</h1>`)
+		assert.Contains(t, site.Files["tags/index.html"], `<li><a href="ops.html">ops</a> (2)</li>`)
+		assert.Contains(t, site.Files["tags/ops.html"], `<li><a href="../guides/deploy.html">deploy</a></li>`)
+		assert.Contains(t, site.Files["style.css"], ".chroma")
+	})
+
+	t.Run("should use the index note as the home page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(mockGithubService, markdown.NewRenderer())
+		mockGithubService.EXPECT().GetTree(gomock.Any(), ghToken, gomock.Any()).Return([]github.GitFile{{SHA: "sha1", Path: "index.md"}}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, gomock.Any()).Return([]byte("# Welcome"), nil)
+
+		site, err := service.Build(context.Background(), ghToken, repoDetails, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, "notes", site.Title)
+		assert.Contains(t, site.Files["index.html"], `<h1 id="welcome">Welcome</h1>`)
+	})
+
+	t.Run("should place the tag pages in another folder when a folder of the notes is named tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(mockGithubService, markdown.NewRenderer())
+		mockGithubService.EXPECT().GetTree(gomock.Any(), ghToken, gomock.Any()).Return([]github.GitFile{
+			{SHA: "sha1", Path: "tags/ops.md"},
+			{SHA: "sha2", Path: "setup.md"},
+		}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha1", Path: "tags/ops.md", RepoDetails: repoDetails}).
+			Return([]byte("# Ops tag conventions\n"), nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha2", Path: "setup.md", RepoDetails: repoDetails}).
+			Return([]byte("---\ntags: [ops]\n---\n# Setup\n"), nil)
+
+		site, err := service.Build(context.Background(), ghToken, repoDetails, Options{})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{".nojekyll", "style.css", "index.html", "setup.html", "tags/ops.html", "tags-2/index.html", "tags-2/ops.html"}, keys(site.Files))
+		assert.Contains(t, site.Files["tags/ops.html"], `<h1 id="ops-tag-conventions">Ops tag conventions</h1>`)
+		assert.Contains(t, site.Files["setup.html"], `<li><a href="tags-2/ops.html">ops</a></li>`)
+		assert.Contains(t, site.Files["setup.html"], `<a href="tags-2/index.html">Tags</a>`)
+	})
+
+	t.Run("should keep the note extension in the urls of the pages when the notes differ only by the extension", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(mockGithubService, markdown.NewRenderer())
+		mockGithubService.EXPECT().GetTree(gomock.Any(), ghToken, gomock.Any()).Return([]github.GitFile{
+			{SHA: "sha1", Path: "setup.md"},
+			{SHA: "sha2", Path: "setup.markdown"},
+			{SHA: "sha3", Path: "deploy.md"},
+		}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha1", Path: "setup.md", RepoDetails: repoDetails}).
+			Return([]byte("# Setup\n\nSee [deploy](deploy.md).\n"), nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha2", Path: "setup.markdown", RepoDetails: repoDetails}).
+			Return([]byte("# Old setup\n"), nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, github.GitFileProps{SHA: "sha3", Path: "deploy.md", RepoDetails: repoDetails}).
+			Return([]byte("Back to [setup](setup.markdown).\n"), nil)
+
+		site, err := service.Build(context.Background(), ghToken, repoDetails, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, site.Pages)
+		assert.ElementsMatch(t, []string{".nojekyll", "style.css", "index.html", "setup.md.html", "setup.markdown.html", "deploy.html", "tags/index.html"}, keys(site.Files))
+		assert.Contains(t, site.Files["setup.md.html"], `<h1 id="setup">Setup</h1>`)
+		assert.Contains(t, site.Files["setup.markdown.html"], `<h1 id="old-setup">Old setup</h1>`)
+		assert.Contains(t, site.Files["deploy.html"], `<a href="setup.markdown.html" rel="nofollow">setup</a>`)
+	})
+
+	t.Run("should return no notes error when the folder does not contain notes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(mockGithubService, nil)
+		mockGithubService.EXPECT().GetTree(gomock.Any(), ghToken, gomock.Any()).Return([]github.GitFile{{SHA: "sha1", Path: "docsx/setup.md"}}, nil)
+
+		_, err := service.Build(context.Background(), ghToken, repoDetails, Options{Path: "docs"})
+		assert.ErrorIs(t, err, ErrNoNotes)
+	})
+
+	t.Run("should return error when fetching the note fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(mockGithubService, nil)
+		mockGithubService.EXPECT().GetTree(gomock.Any(), ghToken, gomock.Any()).Return([]github.GitFile{{SHA: "sha1", Path: "setup.md"}}, nil)
+		mockGithubService.EXPECT().GetBlob(gomock.Any(), ghToken, gomock.Any()).Return(nil, errors.New("some error"))
+
+		_, err := service.Build(context.Background(), ghToken, repoDetails, Options{})
+		assert.Error(t, err)
+	})
+}
+
+func TestDeploy(t *testing.T) {
+	t.Run("should commit the files of the site to the pages branch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockGithubService := github.NewMockService(ctrl)
+		service := NewService(mockGithubService, nil)
+		site := Site{Pages: 1, Files: map[string]string{"index.html": "<h1>Hello</h1>"}}
+		mockGithubService.EXPECT().ReplaceBranch(gomock.Any(), ghToken, github.GitCommitProps{
+			Branch:      PagesBranch,
+			Message:     "Published with BatNoter",
+			Files:       site.Files,
+			AuthorName:  "John Doe",
+			AuthorEmail: "john.doe@example.com",
+			RepoDetails: repoDetails,
+		}).Return("commitsha", nil)
+
+		sha, err := service.Deploy(context.Background(), ghToken, repoDetails, site, "John Doe", "john.doe@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "commitsha", sha)
+	})
+
+	t.Run("should return error when the default branch of the repo is the pages branch", func(t *testing.T) {
+		service := NewService(nil, nil)
+
+		_, err := service.Deploy(context.Background(), ghToken, github.GitRepoProps{Repository: "notes", DefaultBranch: PagesBranch}, Site{}, "", "")
+		assert.ErrorIs(t, err, ErrPagesBranchConflict)
+	})
+}
+
+func TestArchive(t *testing.T) {
+	t.Run("should return the zip archive of the files of the site", func(t *testing.T) {
+		site := Site{Files: map[string]string{"index.html": "<h1>Hello</h1>", "guides/deploy.html": "<p>Deploy</p>"}}
+
+		archive, err := site.Archive()
+		assert.NoError(t, err)
+		zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		assert.NoError(t, err)
+		files := make(map[string]string)
+		for _, f := range zr.File {
+			r, _ := f.Open()
+			content, _ := io.ReadAll(r)
+			r.Close()
+			files[f.Name] = string(content)
+		}
+		assert.Equal(t, site.Files, files)
+	})
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	t.Run("should parse the title & tags from the front matter", func(t *testing.T) {
+		fm, body := parseFrontMatter("---\r\ntitle: Setup\r\ntags:\r\n  - ops\r\n  - setup\r\n---\r\n# Setup\r\n")
+		assert.Equal(t, "Setup", fm.Title)
+		assert.Equal(t, tagList{"ops", "setup"}, fm.Tags)
+		assert.Equal(t, "# Setup\n", body)
+	})
+
+	t.Run("should return the content as it is when the front matter is not closed", func(t *testing.T) {
+		fm, body := parseFrontMatter("---\ntitle: Setup\n# Setup\n")
+		assert.Equal(t, frontMatter{}, fm)
+		assert.Equal(t, "---\ntitle: Setup\n# Setup\n", body)
+	})
+
+	t.Run("should return the content as it is when the front matter is invalid", func(t *testing.T) {
+		fm, body := parseFrontMatter("---\ntitle: [Setup\n---\n# Setup\n")
+		assert.Equal(t, frontMatter{}, fm)
+		assert.Equal(t, "---\ntitle: [Setup\n---\n# Setup\n", body)
+	})
+}
+
+func TestRelativeURL(t *testing.T) {
+	t.Run("should return the url of the target relative to the page", func(t *testing.T) {
+		assert.Equal(t, "guides/deploy.html", relativeURL("index.html", "guides/deploy.html"))
+		assert.Equal(t, "../index.html", relativeURL("guides/deploy.html", "index.html"))
+		assert.Equal(t, "ops.html", relativeURL("tags/index.html", "tags/ops.html"))
+		assert.Equal(t, "../../b/c.html", relativeURL("a/x/page.html", "b/c.html"))
+	})
+}
+
+func keys(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: repo.go
+
+// Package share is a generated GoMock package.
+package share
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepo is a mock of Repo interface.
+type MockRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder
+}
+
+// MockRepoMockRecorder is the mock recorder for MockRepo.
+type MockRepoMockRecorder struct {
+	mock *MockRepo
+}
+
+// NewMockRepo creates a new mock instance.
+func NewMockRepo(ctrl *gomock.Controller) *MockRepo {
+	mock := &MockRepo{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockRepo) Get(shareID uint) (Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", shareID)
+	ret0, _ := ret[0].(Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockRepoMockRecorder) Get(shareID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepo)(nil).Get), shareID)
+}
+
+// GetAllByUserID mocks base method.
+func (m *MockRepo) GetAllByUserID(userID uint) ([]Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllByUserID", userID)
+	ret0, _ := ret[0].([]Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllByUserID indicates an expected call of GetAllByUserID.
+func (mr *MockRepoMockRecorder) GetAllByUserID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUserID", reflect.TypeOf((*MockRepo)(nil).GetAllByUserID), userID)
+}
+
+// GetByTokenHash mocks base method.
+func (m *MockRepo) GetByTokenHash(tokenHash string) (Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTokenHash", tokenHash)
+	ret0, _ := ret[0].(Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTokenHash indicates an expected call of GetByTokenHash.
+func (mr *MockRepoMockRecorder) GetByTokenHash(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTokenHash", reflect.TypeOf((*MockRepo)(nil).GetByTokenHash), tokenHash)
+}
+
+// RecordFailedAttempt mocks base method.
+func (m *MockRepo) RecordFailedAttempt(shareID uint, maxAttempts int, lockedUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedAttempt", shareID, maxAttempts, lockedUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFailedAttempt indicates an expected call of RecordFailedAttempt.
+func (mr *MockRepoMockRecorder) RecordFailedAttempt(shareID, maxAttempts, lockedUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedAttempt", reflect.TypeOf((*MockRepo)(nil).RecordFailedAttempt), shareID, maxAttempts, lockedUntil)
+}
+
+// RecordView mocks base method.
+func (m *MockRepo) RecordView(shareID uint, viewedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordView", shareID, viewedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordView indicates an expected call of RecordView.
+func (mr *MockRepoMockRecorder) RecordView(shareID, viewedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordView", reflect.TypeOf((*MockRepo)(nil).RecordView), shareID, viewedAt)
+}
+
+// Revoke mocks base method.
+func (m *MockRepo) Revoke(shareID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", shareID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockRepoMockRecorder) Revoke(shareID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRepo)(nil).Revoke), shareID)
+}
+
+// Save mocks base method.
+func (m *MockRepo) Save(share Share) (Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", share)
+	ret0, _ := ret[0].(Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRepoMockRecorder) Save(share interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepo)(nil).Save), share)
+}
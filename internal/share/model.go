@@ -0,0 +1,43 @@
+package share
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Share represents an entity model used to store & retrieve the public share links of the notes to/from database.
+// The note is identified by its path in the default repo of the user or in the repo of the notebook when NotebookID is set.
+// Only the hash of the share token is stored, the token is part of the share url handed out to the user.
+type Share struct {
+	gorm.Model
+	UserID     uint
+	NotebookID uint
+
+	Path         string
+	TokenHash    string
+	PasswordHash string
+	ExpiresAt    *time.Time
+	RevokedAt    *time.Time
+	ViewCount    int64
+	LastViewedAt *time.Time
+
+	// FailedAttempts is the count of the wrong passwords since the last lock, the share is locked for a while once it reaches the limit.
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
+// Active checks whether the share can be viewed at the given time.
+func (s Share) Active(now time.Time) bool {
+	return s.ID != 0 && s.RevokedAt == nil && (s.ExpiresAt == nil || s.ExpiresAt.After(now))
+}
+
+// Locked checks whether the password attempts of the share are locked at the given time.
+func (s Share) Locked(now time.Time) bool {
+	return s.LockedUntil != nil && s.LockedUntil.After(now)
+}
+
+// HasPassword checks whether the share is protected with a password.
+func (s Share) HasPassword() bool {
+	return s.PasswordHash != ""
+}
@@ -0,0 +1,110 @@
+package share
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Repo represents a share repository.
+// It provides methods to retrieve and manage share records from the database.
+//go:generate mockgen -source=repo.go -package=share -destination=mock_repo.go
+type Repo interface {
+	Get(shareID uint) (Share, error)
+	GetByTokenHash(tokenHash string) (Share, error)
+	GetAllByUserID(userID uint) ([]Share, error)
+	Save(share Share) (Share, error)
+	Revoke(shareID uint) error
+	RecordView(shareID uint, viewedAt time.Time) error
+	RecordFailedAttempt(shareID uint, maxAttempts int, lockedUntil time.Time) error
+}
+
+type repoImpl struct {
+	db *gorm.DB
+}
+
+// NewRepository creates and returns a new instance of share repository.
+func NewRepository(db *gorm.DB) Repo {
+	return &repoImpl{
+		db: db,
+	}
+}
+
+// Get returns a share record by share-id.
+// An empty share is returned when the share does not exist.
+func (r *repoImpl) Get(shareID uint) (Share, error) {
+	var share Share
+	err := r.db.Where("id = ?", shareID).First(&share).Error
+	if err == gorm.ErrRecordNotFound {
+		return Share{}, nil
+	}
+	if err != nil {
+		return share, errors.Wrap(err, "retrieving share from database failed")
+	}
+	return share, nil
+}
+
+// GetByTokenHash returns a share record by the hash of the share token.
+// An empty share is returned when the share does not exist.
+func (r *repoImpl) GetByTokenHash(tokenHash string) (Share, error) {
+	var share Share
+	err := r.db.Where("token_hash = ?", tokenHash).First(&share).Error
+	if err == gorm.ErrRecordNotFound {
+		return Share{}, nil
+	}
+	if err != nil {
+		return share, errors.Wrap(err, "retrieving share from database failed")
+	}
+	return share, nil
+}
+
+// GetAllByUserID returns the share records of the user which are not revoked, the latest first.
+func (r *repoImpl) GetAllByUserID(userID uint) ([]Share, error) {
+	var shares []Share
+	if err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).Order("id desc").Find(&shares).Error; err != nil {
+		return nil, errors.Wrap(err, "retrieving shares from database failed")
+	}
+	return shares, nil
+}
+
+// Save creates or updates a share record & returns the stored record.
+func (r *repoImpl) Save(share Share) (Share, error) {
+	if err := r.db.Save(&share).Error; err != nil {
+		return share, errors.Wrap(err, "storing share to database failed")
+	}
+	return share, nil
+}
+
+// Revoke marks the share record as revoked.
+func (r *repoImpl) Revoke(shareID uint) error {
+	if err := r.db.Model(&Share{}).Where("id = ?", shareID).Update("revoked_at", time.Now().UTC()).Error; err != nil {
+		return errors.Wrap(err, "revoking share in database failed")
+	}
+	return nil
+}
+
+// RecordView increments the view count of the share record & updates its last viewed time.
+// The count is incremented in the database to not lose the concurrent views. The failed password attempts are reset.
+func (r *repoImpl) RecordView(shareID uint, viewedAt time.Time) error {
+	err := r.db.Model(&Share{}).Where("id = ?", shareID).
+		Updates(map[string]interface{}{"view_count": gorm.Expr("view_count + 1"), "last_viewed_at": viewedAt, "failed_attempts": 0}).Error
+	if err != nil {
+		return errors.Wrap(err, "recording share view in database failed")
+	}
+	return nil
+}
+
+// RecordFailedAttempt increments the failed password attempts of the share record. The share is locked until the given time
+// & the count is reset once the count reaches the max attempts. The count is incremented in the database to not lose the concurrent attempts.
+func (r *repoImpl) RecordFailedAttempt(shareID uint, maxAttempts int, lockedUntil time.Time) error {
+	err := r.db.Model(&Share{}).Where("id = ?", shareID).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", maxAttempts),
+			"locked_until":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END", maxAttempts, lockedUntil),
+		}).Error
+	if err != nil {
+		return errors.Wrap(err, "recording failed share password attempt in database failed")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package share
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrShareNotFound is returned when the share to be revoked does not exist for the user.
+var ErrShareNotFound = errors.New("share not found")
+
+// ErrInvalidShare is returned when the share token is unknown or the share is expired or revoked.
+var ErrInvalidShare = errors.New("invalid share")
+
+// ErrPasswordRequired is returned when the share is protected with a password & the password is not provided.
+var ErrPasswordRequired = errors.New("share password is required")
+
+// ErrInvalidPassword is returned when the provided password does not match the password of the share.
+var ErrInvalidPassword = errors.New("invalid share password")
+
+// ErrTooManyAttempts is returned when the password attempts of the share are locked after too many wrong passwords.
+var ErrTooManyAttempts = errors.New("too many share password attempts")
+
+// share tokens are random & long enough to be unguessable
+const shareTokenSize = 32
+
+const (
+	// the password attempts of the share are locked for a while after this many wrong passwords in a row
+	maxPasswordAttempts = 5
+	passwordLockTTL     = 15 * time.Minute
+)
+
+// Service represents a share service.
+// It provides methods to create, open & revoke the public share links of the notes.
+//go:generate mockgen -source=service.go -package=share -destination=mock_service.go
+type Service interface {
+	Create(share Share, password string) (Share, string, error)
+	GetAll(userID uint) ([]Share, error)
+	Revoke(userID uint, shareID uint) error
+	Open(token string, password string) (Share, error)
+}
+
+type service struct {
+	repo Repo
+}
+
+// NewService creates and returns a new share service.
+func NewService(repo Repo) Service {
+	return &service{
+		repo: repo,
+	}
+}
+
+// Create generates a new share token for the note of the share & stores the hash of the token.
+// The share is protected with the password when it is provided, only the bcrypt hash of the password is stored.
+// It returns the stored share along with the plain token which is never stored & can not be retrieved later.
+func (s *service) Create(share Share, password string) (Share, string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return Share{}, "", err
+	}
+	share.TokenHash = hashToken(token)
+	share.PasswordHash = ""
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return Share{}, "", err
+		}
+		share.PasswordHash = string(hash)
+	}
+	share, err = s.repo.Save(share)
+	if err != nil {
+		return Share{}, "", err
+	}
+	return share, token, nil
+}
+
+// GetAll returns the shares of the user which are not revoked.
+func (s *service) GetAll(userID uint) ([]Share, error) {
+	return s.repo.GetAllByUserID(userID)
+}
+
+// Revoke revokes the share of the user, the share url can not be viewed anymore.
+func (s *service) Revoke(userID uint, shareID uint) error {
+	share, err := s.repo.Get(shareID)
+	if err != nil {
+		return err
+	}
+	if share.ID == 0 || share.UserID != userID || share.RevokedAt != nil {
+		return ErrShareNotFound
+	}
+	return s.repo.Revoke(share.ID)
+}
+
+// Open validates the share token & the password of the share & records the view of the share.
+// ErrInvalidShare is returned when the share is unknown, expired or revoked.
+// ErrPasswordRequired or ErrInvalidPassword is returned when the share is protected & the password does not match.
+// ErrTooManyAttempts is returned without checking the password while the password attempts are locked.
+func (s *service) Open(token string, password string) (Share, error) {
+	share, err := s.repo.GetByTokenHash(hashToken(token))
+	if err != nil {
+		return Share{}, err
+	}
+	now := time.Now().UTC()
+	if !share.Active(now) {
+		return Share{}, ErrInvalidShare
+	}
+	if share.HasPassword() {
+		if share.Locked(now) {
+			return Share{}, ErrTooManyAttempts
+		}
+		if password == "" {
+			return Share{}, ErrPasswordRequired
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(share.PasswordHash), []byte(password)); err != nil {
+			if err := s.repo.RecordFailedAttempt(share.ID, maxPasswordAttempts, now.Add(passwordLockTTL)); err != nil {
+				return Share{}, err
+			}
+			return Share{}, ErrInvalidPassword
+		}
+	}
+	if err := s.repo.RecordView(share.ID, now); err != nil {
+		// failing to count the view does not fail viewing the note
+		logrus.WithField("share-id", share.ID).WithError(err).Warn("recording share view failed")
+	} else {
+		share.ViewCount++
+		share.LastViewedAt = &now
+	}
+	return share, nil
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, shareTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex encoded sha256 hash of the token.
+// Share tokens are random & long enough, so a fast unsalted hash is sufficient.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,242 @@
+package share
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	userID     = uint(1001)
+	shareID    = uint(51)
+	shareToken = "c2hhcmUtdG9rZW4"
+	password   = "s3cret"
+	notePath   = "foo/bar.md"
+)
+
+func TestCreate(t *testing.T) {
+	t.Run("should create share & store the hash of the token & the password", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		var stored Share
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(share Share) (Share, error) {
+			stored = share
+			share.ID = shareID
+			return share, nil
+		})
+
+		share, token, err := service.Create(Share{UserID: userID, Path: notePath}, password)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, shareID, share.ID)
+		assert.Equal(t, hashToken(token), stored.TokenHash)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte(password)))
+		assert.True(t, share.HasPassword())
+	})
+
+	t.Run("should create share without password when password is not provided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Save(gomock.Any()).DoAndReturn(func(share Share) (Share, error) { return share, nil })
+
+		share, _, err := service.Create(Share{UserID: userID, Path: notePath}, "")
+		assert.NoError(t, err)
+		assert.False(t, share.HasPassword())
+	})
+
+	t.Run("should return error when storing share fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Save(gomock.Any()).Return(Share{}, errors.New("some error"))
+
+		_, _, err := service.Create(Share{UserID: userID, Path: notePath}, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestRevoke(t *testing.T) {
+	t.Run("should revoke the share of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(shareID).Return(validShare(), nil)
+		mockRepo.EXPECT().Revoke(shareID).Return(nil)
+
+		err := service.Revoke(userID, shareID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return share not found error when the share belongs to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().Get(shareID).Return(validShare(), nil)
+
+		err := service.Revoke(userID+1, shareID)
+		assert.ErrorIs(t, err, ErrShareNotFound)
+	})
+
+	t.Run("should return share not found error when the share is already revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		revokedAt := time.Now()
+		share := validShare()
+		share.RevokedAt = &revokedAt
+		mockRepo.EXPECT().Get(shareID).Return(share, nil)
+
+		err := service.Revoke(userID, shareID)
+		assert.ErrorIs(t, err, ErrShareNotFound)
+	})
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("should return the share & record the view when the token is valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(validShare(), nil)
+		mockRepo.EXPECT().RecordView(shareID, gomock.Any()).Return(nil)
+
+		share, err := service.Open(shareToken, "")
+		assert.NoError(t, err)
+		assert.Equal(t, shareID, share.ID)
+		assert.Equal(t, int64(4), share.ViewCount)
+		assert.NotNil(t, share.LastViewedAt)
+	})
+
+	t.Run("should return the share when recording the view fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(validShare(), nil)
+		mockRepo.EXPECT().RecordView(shareID, gomock.Any()).Return(errors.New("some error"))
+
+		share, err := service.Open(shareToken, "")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), share.ViewCount)
+	})
+
+	t.Run("should return invalid share error when the token is unknown", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(Share{}, nil)
+
+		_, err := service.Open(shareToken, "")
+		assert.ErrorIs(t, err, ErrInvalidShare)
+	})
+
+	t.Run("should return invalid share error when the share is expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		expiresAt := time.Now().Add(-time.Hour)
+		share := validShare()
+		share.ExpiresAt = &expiresAt
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(share, nil)
+
+		_, err := service.Open(shareToken, "")
+		assert.ErrorIs(t, err, ErrInvalidShare)
+	})
+
+	t.Run("should return password required error when the password of protected share is not provided", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(protectedShare(t), nil)
+
+		_, err := service.Open(shareToken, "")
+		assert.ErrorIs(t, err, ErrPasswordRequired)
+	})
+
+	t.Run("should return invalid password error when the password does not match", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(protectedShare(t), nil)
+		mockRepo.EXPECT().RecordFailedAttempt(shareID, maxPasswordAttempts, gomock.Any()).Return(nil)
+
+		_, err := service.Open(shareToken, "wrong")
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("should return too many attempts error without checking the password when the share is locked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		lockedShare := protectedShare(t)
+		lockedUntil := time.Now().Add(time.Minute)
+		lockedShare.LockedUntil = &lockedUntil
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(lockedShare, nil)
+
+		_, err := service.Open(shareToken, password)
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+	})
+
+	t.Run("should return the protected share when the lock of the password attempts is over", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		unlockedShare := protectedShare(t)
+		lockedUntil := time.Now().Add(-time.Minute)
+		unlockedShare.LockedUntil = &lockedUntil
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(unlockedShare, nil)
+		mockRepo.EXPECT().RecordView(shareID, gomock.Any()).Return(nil)
+
+		_, err := service.Open(shareToken, password)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return the protected share when the password matches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		service := NewService(mockRepo)
+		mockRepo.EXPECT().GetByTokenHash(hashToken(shareToken)).Return(protectedShare(t), nil)
+		mockRepo.EXPECT().RecordView(shareID, gomock.Any()).Return(nil)
+
+		_, err := service.Open(shareToken, password)
+		assert.NoError(t, err)
+	})
+}
+
+func validShare() Share {
+	return Share{
+		Model:     gorm.Model{ID: shareID},
+		UserID:    userID,
+		Path:      notePath,
+		TokenHash: hashToken(shareToken),
+		ViewCount: 3,
+	}
+}
+
+func protectedShare(t *testing.T) Share {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	assert.NoError(t, err)
+	share := validShare()
+	share.PasswordHash = string(hash)
+	return share
+}
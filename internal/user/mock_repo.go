@@ -6,7 +6,9 @@ package user
 
 import (
 	reflect "reflect"
+	time "time"
 
+	audit "github.com/batnoter/batnoter-api/internal/audit"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,17 +36,31 @@ func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
 }
 
 // Delete mocks base method.
-func (m *MockRepo) Delete(userID uint) error {
+func (m *MockRepo) Delete(userID uint, event audit.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", userID)
+	ret := m.ctrl.Call(m, "Delete", userID, event)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockRepoMockRecorder) Delete(userID interface{}) *gomock.Call {
+func (mr *MockRepoMockRecorder) Delete(userID, event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepo)(nil).Delete), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepo)(nil).Delete), userID, event)
+}
+
+// DeleteIdentity mocks base method.
+func (m *MockRepo) DeleteIdentity(identityID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdentity", identityID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdentity indicates an expected call of DeleteIdentity.
+func (mr *MockRepoMockRecorder) DeleteIdentity(identityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdentity", reflect.TypeOf((*MockRepo)(nil).DeleteIdentity), identityID)
 }
 
 // Get mocks base method.
@@ -77,6 +93,81 @@ func (mr *MockRepoMockRecorder) GetByEmail(email interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockRepo)(nil).GetByEmail), email)
 }
 
+// GetIdentities mocks base method.
+func (m *MockRepo) GetIdentities(userID uint) ([]Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdentities", userID)
+	ret0, _ := ret[0].([]Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentities indicates an expected call of GetIdentities.
+func (mr *MockRepoMockRecorder) GetIdentities(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentities", reflect.TypeOf((*MockRepo)(nil).GetIdentities), userID)
+}
+
+// GetIdentity mocks base method.
+func (m *MockRepo) GetIdentity(provider, providerUserID string) (Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdentity", provider, providerUserID)
+	ret0, _ := ret[0].(Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentity indicates an expected call of GetIdentity.
+func (mr *MockRepoMockRecorder) GetIdentity(provider, providerUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentity", reflect.TypeOf((*MockRepo)(nil).GetIdentity), provider, providerUserID)
+}
+
+// GetStatus mocks base method.
+func (m *MockRepo) GetStatus(userID uint) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStatus", userID)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStatus indicates an expected call of GetStatus.
+func (mr *MockRepoMockRecorder) GetStatus(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStatus", reflect.TypeOf((*MockRepo)(nil).GetStatus), userID)
+}
+
+// Purge mocks base method.
+func (m *MockRepo) Purge(deletedBefore time.Time) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", deletedBefore)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockRepoMockRecorder) Purge(deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockRepo)(nil).Purge), deletedBefore)
+}
+
+// ReEncryptTokens mocks base method.
+func (m *MockRepo) ReEncryptTokens() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReEncryptTokens")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReEncryptTokens indicates an expected call of ReEncryptTokens.
+func (mr *MockRepoMockRecorder) ReEncryptTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReEncryptTokens", reflect.TypeOf((*MockRepo)(nil).ReEncryptTokens))
+}
+
 // Save mocks base method.
 func (m *MockRepo) Save(user User) (uint, error) {
 	m.ctrl.T.Helper()
@@ -91,3 +182,75 @@ func (mr *MockRepoMockRecorder) Save(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepo)(nil).Save), user)
 }
+
+// SaveGithubToken mocks base method.
+func (m *MockRepo) SaveGithubToken(userID uint, githubToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGithubToken", userID, githubToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGithubToken indicates an expected call of SaveGithubToken.
+func (mr *MockRepoMockRecorder) SaveGithubToken(userID, githubToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGithubToken", reflect.TypeOf((*MockRepo)(nil).SaveGithubToken), userID, githubToken)
+}
+
+// SaveIdentity mocks base method.
+func (m *MockRepo) SaveIdentity(identity Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdentity", identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdentity indicates an expected call of SaveIdentity.
+func (mr *MockRepoMockRecorder) SaveIdentity(identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdentity", reflect.TypeOf((*MockRepo)(nil).SaveIdentity), identity)
+}
+
+// Search mocks base method.
+func (m *MockRepo) Search(query string, offset, limit int) ([]User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", query, offset, limit)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockRepoMockRecorder) Search(query, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockRepo)(nil).Search), query, offset, limit)
+}
+
+// SetDisabled mocks base method.
+func (m *MockRepo) SetDisabled(userID uint, disabledAt *time.Time, reason string, event audit.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDisabled", userID, disabledAt, reason, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDisabled indicates an expected call of SetDisabled.
+func (mr *MockRepoMockRecorder) SetDisabled(userID, disabledAt, reason, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDisabled", reflect.TypeOf((*MockRepo)(nil).SetDisabled), userID, disabledAt, reason, event)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockRepo) UpdateProfile(userID uint, profile Profile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", userID, profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockRepoMockRecorder) UpdateProfile(userID, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockRepo)(nil).UpdateProfile), userID, profile)
+}
@@ -6,6 +6,7 @@ package user
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -33,18 +34,60 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CheckStatus mocks base method.
+func (m *MockService) CheckStatus(userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckStatus", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckStatus indicates an expected call of CheckStatus.
+func (mr *MockServiceMockRecorder) CheckStatus(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckStatus", reflect.TypeOf((*MockService)(nil).CheckStatus), userID)
+}
+
 // Delete mocks base method.
-func (m *MockService) Delete(userID uint) error {
+func (m *MockService) Delete(actorID, userID uint, reason string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", userID)
+	ret := m.ctrl.Call(m, "Delete", actorID, userID, reason)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockServiceMockRecorder) Delete(userID interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Delete(actorID, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), actorID, userID, reason)
+}
+
+// Disable mocks base method.
+func (m *MockService) Disable(actorID, userID uint, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", actorID, userID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockServiceMockRecorder) Disable(actorID, userID, reason interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockService)(nil).Delete), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockService)(nil).Disable), actorID, userID, reason)
+}
+
+// Enable mocks base method.
+func (m *MockService) Enable(actorID, userID uint, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", actorID, userID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockServiceMockRecorder) Enable(actorID, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockService)(nil).Enable), actorID, userID, reason)
 }
 
 // Get mocks base method.
@@ -77,6 +120,94 @@ func (mr *MockServiceMockRecorder) GetByEmail(email interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockService)(nil).GetByEmail), email)
 }
 
+// GetByIdentity mocks base method.
+func (m *MockService) GetByIdentity(provider, providerUserID string) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByIdentity", provider, providerUserID)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByIdentity indicates an expected call of GetByIdentity.
+func (mr *MockServiceMockRecorder) GetByIdentity(provider, providerUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByIdentity", reflect.TypeOf((*MockService)(nil).GetByIdentity), provider, providerUserID)
+}
+
+// GetIdentities mocks base method.
+func (m *MockService) GetIdentities(userID uint) ([]Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdentities", userID)
+	ret0, _ := ret[0].([]Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdentities indicates an expected call of GetIdentities.
+func (mr *MockServiceMockRecorder) GetIdentities(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdentities", reflect.TypeOf((*MockService)(nil).GetIdentities), userID)
+}
+
+// IsAdmin mocks base method.
+func (m *MockService) IsAdmin(user User) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAdmin", user)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsAdmin indicates an expected call of IsAdmin.
+func (mr *MockServiceMockRecorder) IsAdmin(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAdmin", reflect.TypeOf((*MockService)(nil).IsAdmin), user)
+}
+
+// LinkIdentity mocks base method.
+func (m *MockService) LinkIdentity(userID uint, identity Identity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkIdentity", userID, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkIdentity indicates an expected call of LinkIdentity.
+func (mr *MockServiceMockRecorder) LinkIdentity(userID, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkIdentity", reflect.TypeOf((*MockService)(nil).LinkIdentity), userID, identity)
+}
+
+// Purge mocks base method.
+func (m *MockService) Purge(deletedBefore time.Time) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", deletedBefore)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockServiceMockRecorder) Purge(deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockService)(nil).Purge), deletedBefore)
+}
+
+// ReEncryptTokens mocks base method.
+func (m *MockService) ReEncryptTokens() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReEncryptTokens")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReEncryptTokens indicates an expected call of ReEncryptTokens.
+func (mr *MockServiceMockRecorder) ReEncryptTokens() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReEncryptTokens", reflect.TypeOf((*MockService)(nil).ReEncryptTokens))
+}
+
 // Save mocks base method.
 func (m *MockService) Save(user User) (uint, error) {
 	m.ctrl.T.Helper()
@@ -91,3 +222,63 @@ func (mr *MockServiceMockRecorder) Save(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockService)(nil).Save), user)
 }
+
+// SaveGithubToken mocks base method.
+func (m *MockService) SaveGithubToken(userID uint, githubToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveGithubToken", userID, githubToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveGithubToken indicates an expected call of SaveGithubToken.
+func (mr *MockServiceMockRecorder) SaveGithubToken(userID, githubToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveGithubToken", reflect.TypeOf((*MockService)(nil).SaveGithubToken), userID, githubToken)
+}
+
+// Search mocks base method.
+func (m *MockService) Search(query string, page, perPage int) ([]User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", query, page, perPage)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockServiceMockRecorder) Search(query, page, perPage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockService)(nil).Search), query, page, perPage)
+}
+
+// UnlinkIdentity mocks base method.
+func (m *MockService) UnlinkIdentity(userID, identityID uint) (Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlinkIdentity", userID, identityID)
+	ret0, _ := ret[0].(Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnlinkIdentity indicates an expected call of UnlinkIdentity.
+func (mr *MockServiceMockRecorder) UnlinkIdentity(userID, identityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlinkIdentity", reflect.TypeOf((*MockService)(nil).UnlinkIdentity), userID, identityID)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockService) UpdateProfile(userID uint, profile Profile) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", userID, profile)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockServiceMockRecorder) UpdateProfile(userID, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockService)(nil).UpdateProfile), userID, profile)
+}
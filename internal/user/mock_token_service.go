@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: token_service.go
+
+// Package user is a generated GoMock package.
+package user
+
+import (
+	context "context"
+	reflect "reflect"
+
+	github "github.com/batnoter/batnoter-api/internal/github"
+	gomock "github.com/golang/mock/gomock"
+	oauth2 "golang.org/x/oauth2"
+)
+
+// MockTokenService is a mock of TokenService interface.
+type MockTokenService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenServiceMockRecorder
+}
+
+// MockTokenServiceMockRecorder is the mock recorder for MockTokenService.
+type MockTokenServiceMockRecorder struct {
+	mock *MockTokenService
+}
+
+// NewMockTokenService creates a new mock instance.
+func NewMockTokenService(ctrl *gomock.Controller) *MockTokenService {
+	mock := &MockTokenService{ctrl: ctrl}
+	mock.recorder = &MockTokenServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenService) EXPECT() *MockTokenServiceMockRecorder {
+	return m.recorder
+}
+
+// GetGithubToken mocks base method.
+func (m *MockTokenService) GetGithubToken(ctx context.Context, user User) (oauth2.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGithubToken", ctx, user)
+	ret0, _ := ret[0].(oauth2.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGithubToken indicates an expected call of GetGithubToken.
+func (mr *MockTokenServiceMockRecorder) GetGithubToken(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGithubToken", reflect.TypeOf((*MockTokenService)(nil).GetGithubToken), ctx, user)
+}
+
+// GetRepoToken mocks base method.
+func (m *MockTokenService) GetRepoToken(ctx context.Context, user User, repoDetails github.GitRepoProps) (oauth2.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepoToken", ctx, user, repoDetails)
+	ret0, _ := ret[0].(oauth2.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepoToken indicates an expected call of GetRepoToken.
+func (mr *MockTokenServiceMockRecorder) GetRepoToken(ctx, user, repoDetails interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepoToken", reflect.TypeOf((*MockTokenService)(nil).GetRepoToken), ctx, user, repoDetails)
+}
+
+// TokenSource mocks base method.
+func (m *MockTokenService) TokenSource(ctx context.Context, user User) oauth2.TokenSource {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TokenSource", ctx, user)
+	ret0, _ := ret[0].(oauth2.TokenSource)
+	return ret0
+}
+
+// TokenSource indicates an expected call of TokenSource.
+func (mr *MockTokenServiceMockRecorder) TokenSource(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenSource", reflect.TypeOf((*MockTokenService)(nil).TokenSource), ctx, user)
+}
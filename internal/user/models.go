@@ -1,13 +1,20 @@
 package user
 
 import (
+	"strconv"
 	"time"
 
+	"github.com/batnoter/batnoter-api/internal/github"
 	"github.com/batnoter/batnoter-api/internal/preference"
 	"gorm.io/gorm"
 )
 
+// ProviderGithub is the identity provider of the github accounts.
+const ProviderGithub = "github"
+
 // User represent an entity model used for storing and retrieving user to/from database.
+// The github account of the user is the primary identity, it owns the notes repo of the user.
+// The github token of the primary identity is stored in the linked identity record.
 type User struct {
 	gorm.Model
 
@@ -17,8 +24,102 @@ type User struct {
 	AvatarURL      string
 	GithubID       int64
 	GithubUsername string
-	GithubToken    string
+	GithubToken    string `gorm:"-"`
 	DisabledAt     *time.Time
+	DisabledReason string
+
+	// profile attributes edited by the user, these are not synced from github on login
+	DisplayName string
+	Timezone    string
+	Locale      string
+	CommitName  string
+	CommitEmail string
 
 	DefaultRepo *preference.DefaultRepo `gorm:"foreignkey:UserID"`
 }
+
+// Identity represent an entity model used for storing and retrieving the identity linked to the user to/from database.
+// An identity is an account of the user with an identity provider, the user can login with any of the linked identities.
+type Identity struct {
+	gorm.Model
+
+	UserID         uint
+	Provider       string
+	ProviderUserID string
+	Username       string
+	Email          string
+	Token          string
+}
+
+// Profile represents the profile attributes of the user which can be edited by the user.
+type Profile struct {
+	DisplayName string
+	Timezone    string
+	Locale      string
+	CommitName  string
+	CommitEmail string
+}
+
+// Profile returns the editable profile attributes of the user.
+func (u User) Profile() Profile {
+	return Profile{
+		DisplayName: u.DisplayName,
+		Timezone:    u.Timezone,
+		Locale:      u.Locale,
+		CommitName:  u.CommitName,
+		CommitEmail: u.CommitEmail,
+	}
+}
+
+// GetDisplayName returns the name of the user chosen by the user, falling back to the name from github.
+func (u User) GetDisplayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Name
+}
+
+// GetCommitName returns the author name used for the commits of the user.
+// It falls back to the display name & then to the github username.
+func (u User) GetCommitName() string {
+	if u.CommitName != "" {
+		return u.CommitName
+	}
+	if name := u.GetDisplayName(); name != "" {
+		return name
+	}
+	return u.GithubUsername
+}
+
+// GetCommitEmail returns the author email used for the commits of the user, falling back to the primary email.
+func (u User) GetCommitEmail() string {
+	if u.CommitEmail != "" {
+		return u.CommitEmail
+	}
+	return u.Email
+}
+
+// GetRepoOwner returns the github organization or user owning the notes repo of the user.
+// It falls back to the github username since the notes repo is owned by the user's github account by default.
+func (u User) GetRepoOwner() string {
+	if u.DefaultRepo != nil && u.DefaultRepo.Owner != "" {
+		return u.DefaultRepo.Owner
+	}
+	return u.GithubUsername
+}
+
+// GetDefaultRepoDetails returns the details of the notes repo of the user along with the app installation used to access it.
+func (u User) GetDefaultRepoDetails() github.GitRepoProps {
+	repoDetails := github.GitRepoProps{Owner: u.GetRepoOwner()}
+	if u.DefaultRepo != nil {
+		repoDetails.Repository = u.DefaultRepo.Name
+		repoDetails.DefaultBranch = u.DefaultRepo.DefaultBranch
+		repoDetails.InstallationID = u.DefaultRepo.InstallationID
+	}
+	return repoDetails
+}
+
+// IsPrimaryIdentity checks whether the identity is the primary identity of the user.
+func (u User) IsPrimaryIdentity(identity Identity) bool {
+	return u.GithubID != 0 && identity.Provider == ProviderGithub && identity.ProviderUserID == strconv.FormatInt(u.GithubID, 10)
+}
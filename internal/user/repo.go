@@ -1,6 +1,12 @@
 package user
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/audit"
+	"github.com/batnoter/batnoter-api/internal/encryption"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
@@ -12,17 +18,41 @@ type Repo interface {
 	Get(userID uint) (User, error)
 	GetByEmail(email string) (User, error)
 	Save(user User) (uint, error)
-	Delete(userID uint) error
+	Delete(userID uint, event audit.Event) error
+	GetStatus(userID uint) (User, error)
+	SetDisabled(userID uint, disabledAt *time.Time, reason string, event audit.Event) error
+	UpdateProfile(userID uint, profile Profile) error
+	Search(query string, offset int, limit int) ([]User, int64, error)
+	Purge(deletedBefore time.Time) ([]uint, error)
+	SaveGithubToken(userID uint, githubToken string) error
+	ReEncryptTokens() (int, error)
+	GetIdentity(provider string, providerUserID string) (Identity, error)
+	GetIdentities(userID uint) ([]Identity, error)
+	SaveIdentity(identity Identity) error
+	DeleteIdentity(identityID uint) error
 }
 
 type repoImpl struct {
-	db *gorm.DB
+	db        *gorm.DB
+	encrypter encryption.Encrypter
 }
 
+const reEncryptBatchSize = 100
+
+// likeEscaper escapes the wildcards of the LIKE patterns, so the search query is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// userDataTables are the tables holding the data of the users, the records of these tables are deleted when a user is purged.
+// Audit events are retained since they hold no personal data.
+var userDataTables = []string{"default_repos", "notebook_members", "notebooks", "refresh_tokens", "sessions", "api_tokens", "exports", "identities", "shares", "imports",
+	"attachment_variants"}
+
 // NewRepository creates and returns a new instance of user repository.
-func NewRepository(db *gorm.DB) Repo {
+// The tokens of the linked identities are encrypted before storing them to database & decrypted after retrieving them.
+func NewRepository(db *gorm.DB, encrypter encryption.Encrypter) Repo {
 	return &repoImpl{
-		db: db,
+		db:        db,
+		encrypter: encrypter,
 	}
 }
 
@@ -32,35 +62,303 @@ func (r *repoImpl) Get(userID uint) (User, error) {
 	if err := r.db.Where("id = ?", userID).Preload("DefaultRepo").First(&user).Error; err != nil {
 		return user, errors.Wrap(err, "retrieving user from database failed")
 	}
-	return user, nil
+	return r.loadGithubToken(user)
 }
 
 // GetByEmail returns a user record matching the provided email.
+// Deleted user records are also returned, so that a deleted user can be identified.
 func (r *repoImpl) GetByEmail(email string) (User, error) {
 	var user User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.Unscoped().Where("email = ?", email).First(&user).Error
 	if err == gorm.ErrRecordNotFound {
 		return User{}, nil
 	}
 	if err != nil {
 		return user, errors.Wrap(err, "retrieving user from database failed")
 	}
-	return user, nil
+	return r.loadGithubToken(user)
 }
 
 // Save stores a given user record to database.
+// The primary github identity of the user is stored along with its github token.
 func (r *repoImpl) Save(user User) (uint, error) {
-	if err := r.db.Save(&user).Error; err != nil {
+	githubToken, err := r.encrypter.Encrypt(user.GithubToken)
+	if err != nil {
+		return 0, errors.Wrap(err, "encrypting user's github token failed")
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		if user.GithubID == 0 {
+			return nil
+		}
+		var identity Identity
+		err := tx.Where("provider = ? AND provider_user_id = ?", ProviderGithub, strconv.FormatInt(user.GithubID, 10)).First(&identity).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		if identity.ID != 0 && identity.UserID != user.ID {
+			return ErrIdentityLinked
+		}
+		identity.UserID = user.ID
+		identity.Provider = ProviderGithub
+		identity.ProviderUserID = strconv.FormatInt(user.GithubID, 10)
+		identity.Username = user.GithubUsername
+		identity.Email = user.Email
+		identity.Token = githubToken
+		return tx.Save(&identity).Error
+	})
+	if err != nil {
 		return 0, errors.Wrap(err, "storing user to database failed")
 	}
 	return user.ID, nil
 }
 
-// Delete marks a user record matching provided user-id as deleted in database.
-func (r *repoImpl) Delete(userID uint) error {
-	var user User
-	if err := r.db.Where("id = ?", userID).Delete(&user).Error; err != nil {
+// Delete marks a user record matching provided user-id as deleted in database & stores the audit event of the deletion.
+// The tokens of the linked identities are removed since these are not used anymore.
+func (r *repoImpl) Delete(userID uint, event audit.Event) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Identity{}).Where("user_id = ?", userID).UpdateColumn("token", "").Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", userID).Delete(&User{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
 		return errors.Wrap(err, "deleting user from database failed")
 	}
 	return nil
 }
+
+// Purge permanently deletes the user records marked as deleted before the given time along with the data of the users.
+// It returns the ids of the purged users.
+func (r *repoImpl) Purge(deletedBefore time.Time) ([]uint, error) {
+	var userIDs []uint
+	if err := r.db.Unscoped().Model(&User{}).Where("deleted_at < ?", deletedBefore).Pluck("id", &userIDs).Error; err != nil {
+		return nil, errors.Wrap(err, "retrieving deleted users from database failed")
+	}
+	purged := make([]uint, 0, len(userIDs))
+	for _, userID := range userIDs {
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			for _, table := range userDataTables {
+				if err := tx.Exec("DELETE FROM "+table+" WHERE user_id = ?", userID).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Unscoped().Where("id = ?", userID).Delete(&User{}).Error
+		})
+		if err != nil {
+			return purged, errors.Wrapf(err, "purging user %d from database failed", userID)
+		}
+		purged = append(purged, userID)
+	}
+	return purged, nil
+}
+
+// GetStatus returns the account status fields of a user record (including deleted one) by user-id.
+func (r *repoImpl) GetStatus(userID uint) (User, error) {
+	var user User
+	err := r.db.Unscoped().Select("id", "disabled_at", "deleted_at").Where("id = ?", userID).First(&user).Error
+	if err == gorm.ErrRecordNotFound {
+		return User{}, nil
+	}
+	if err != nil {
+		return user, errors.Wrap(err, "retrieving user status from database failed")
+	}
+	return user, nil
+}
+
+// SetDisabled updates the disabled time & reason of a user record matching provided user-id
+// & stores the audit event of the change in the same transaction.
+func (r *repoImpl) SetDisabled(userID uint, disabledAt *time.Time, reason string, event audit.Event) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"disabled_at": disabledAt, "disabled_reason": reason}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return errors.Wrap(err, "updating user status in database failed")
+	}
+	return nil
+}
+
+// UpdateProfile updates the editable profile attributes of a user record matching provided user-id.
+func (r *repoImpl) UpdateProfile(userID uint, profile Profile) error {
+	err := r.db.Model(&User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"display_name": profile.DisplayName,
+			"timezone":     profile.Timezone,
+			"locale":       profile.Locale,
+			"commit_name":  profile.CommitName,
+			"commit_email": profile.CommitEmail,
+		}).Error
+	if err != nil {
+		return errors.Wrap(err, "updating user profile in database failed")
+	}
+	return nil
+}
+
+// Search returns a page of user records (including deleted ones) matching the query along with the total count of matches.
+// The query is matched against email, name & github username. A numeric query is also matched against user-id & github id.
+func (r *repoImpl) Search(query string, offset int, limit int) ([]User, int64, error) {
+	tx := r.db.Unscoped().Model(&User{})
+	if query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(query)) + "%"
+		cond := r.db.Where(`LOWER(email) LIKE ? ESCAPE '\'`, pattern).
+			Or(`LOWER(name) LIKE ? ESCAPE '\'`, pattern).
+			Or(`LOWER(github_username) LIKE ? ESCAPE '\'`, pattern)
+		if id, err := strconv.ParseInt(query, 10, 64); err == nil {
+			cond = cond.Or("id = ?", id).Or("github_id = ?", id)
+		}
+		tx = tx.Where(cond)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, errors.Wrap(err, "counting users in database failed")
+	}
+	var users []User
+	err := tx.Preload("DefaultRepo").Order("id").Offset(offset).Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "searching users in database failed")
+	}
+	return users, total, nil
+}
+
+// SaveGithubToken updates the github token of the primary identity of a user record matching provided user-id.
+func (r *repoImpl) SaveGithubToken(userID uint, githubToken string) error {
+	githubToken, err := r.encrypter.Encrypt(githubToken)
+	if err != nil {
+		return errors.Wrap(err, "encrypting user's github token failed")
+	}
+	primary := r.db.Model(&User{}).Select("CAST(github_id AS TEXT)").Where("id = ?", userID)
+	err = r.db.Model(&Identity{}).Where("user_id = ? AND provider = ? AND provider_user_id = (?)", userID, ProviderGithub, primary).
+		UpdateColumn("token", githubToken).Error
+	if err != nil {
+		return errors.Wrap(err, "storing user's github token to database failed")
+	}
+	return nil
+}
+
+// ReEncryptTokens encrypts the tokens of all the linked identities with the primary key.
+// Tokens which are stored as plaintext or encrypted with an older key are re-encrypted.
+// It returns the count of re-encrypted tokens.
+func (r *repoImpl) ReEncryptTokens() (int, error) {
+	count := 0
+	var identities []Identity
+	err := r.db.Unscoped().Select("id", "token").FindInBatches(&identities, reEncryptBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, identity := range identities {
+			if !r.encrypter.NeedsRotation(identity.Token) {
+				continue
+			}
+			token, err := r.encrypter.Decrypt(identity.Token)
+			if err != nil {
+				return errors.Wrapf(err, "decrypting token of identity %d failed", identity.ID)
+			}
+			if token, err = r.encrypter.Encrypt(token); err != nil {
+				return errors.Wrapf(err, "encrypting token of identity %d failed", identity.ID)
+			}
+			if err := r.db.Unscoped().Model(&Identity{}).Where("id = ?", identity.ID).UpdateColumn("token", token).Error; err != nil {
+				return errors.Wrapf(err, "storing token of identity %d failed", identity.ID)
+			}
+			count++
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return count, errors.Wrap(err, "re-encrypting identity tokens failed")
+	}
+	return count, nil
+}
+
+// GetIdentity returns the identity record matching the provider & the user-id at the provider.
+// An empty identity is returned when the identity is not linked to any user.
+func (r *repoImpl) GetIdentity(provider string, providerUserID string) (Identity, error) {
+	var identity Identity
+	err := r.db.Where("provider = ? AND provider_user_id = ?", provider, providerUserID).First(&identity).Error
+	if err == gorm.ErrRecordNotFound {
+		return Identity{}, nil
+	}
+	if err != nil {
+		return identity, errors.Wrap(err, "retrieving identity from database failed")
+	}
+	return r.decryptIdentityToken(identity)
+}
+
+// GetIdentities returns all the identity records linked to the user by user-id.
+func (r *repoImpl) GetIdentities(userID uint) ([]Identity, error) {
+	var identities []Identity
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&identities).Error; err != nil {
+		return nil, errors.Wrap(err, "retrieving identities from database failed")
+	}
+	for i := range identities {
+		identity, err := r.decryptIdentityToken(identities[i])
+		if err != nil {
+			return nil, err
+		}
+		identities[i] = identity
+	}
+	return identities, nil
+}
+
+// SaveIdentity stores a given identity record to database.
+func (r *repoImpl) SaveIdentity(identity Identity) error {
+	token, err := r.encrypter.Encrypt(identity.Token)
+	if err != nil {
+		return errors.Wrap(err, "encrypting identity token failed")
+	}
+	identity.Token = token
+	if err := r.db.Save(&identity).Error; err != nil {
+		return errors.Wrap(err, "storing identity to database failed")
+	}
+	return nil
+}
+
+// DeleteIdentity permanently deletes an identity record matching provided identity-id from database.
+func (r *repoImpl) DeleteIdentity(identityID uint) error {
+	if err := r.db.Unscoped().Where("id = ?", identityID).Delete(&Identity{}).Error; err != nil {
+		return errors.Wrap(err, "deleting identity from database failed")
+	}
+	return nil
+}
+
+// loadGithubToken sets the github token of the user from the primary identity of the user.
+func (r *repoImpl) loadGithubToken(user User) (User, error) {
+	if user.ID == 0 || user.GithubID == 0 {
+		return user, nil
+	}
+	var identity Identity
+	err := r.db.Where("user_id = ? AND provider = ? AND provider_user_id = ?", user.ID, ProviderGithub, strconv.FormatInt(user.GithubID, 10)).
+		First(&identity).Error
+	if err == gorm.ErrRecordNotFound {
+		return user, nil
+	}
+	if err != nil {
+		return user, errors.Wrap(err, "retrieving user's github identity from database failed")
+	}
+	identity, err = r.decryptIdentityToken(identity)
+	if err != nil {
+		return user, err
+	}
+	user.GithubToken = identity.Token
+	return user, nil
+}
+
+func (r *repoImpl) decryptIdentityToken(identity Identity) (Identity, error) {
+	token, err := r.encrypter.Decrypt(identity.Token)
+	if err != nil {
+		return identity, errors.Wrap(err, "decrypting identity token failed")
+	}
+	identity.Token = token
+	return identity, nil
+}
+
+// escapeLike escapes the LIKE wildcards & the escape character in the value.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
@@ -0,0 +1,67 @@
+package user
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// retainedTables are the tables with user_id column which are intentionally not purged along with the users.
+var retainedTables = map[string]string{
+	"audit_events": "audit events are retained since they hold no personal data",
+}
+
+var (
+	createTableRegex  = regexp.MustCompile(`(?is)create table if not exists (\w+)\s*\((.*?)\);`)
+	addUserIDRegex    = regexp.MustCompile(`(?i)alter table (\w+) add column (?:if not exists )?user_id\b`)
+	userIDColumnRegex = regexp.MustCompile(`(?im)^\s*user_id\s`)
+)
+
+func TestEscapeLike(t *testing.T) {
+	t.Run("should escape the like wildcards & the escape character", func(t *testing.T) {
+		assert.Equal(t, `john\_doe\%\\`, escapeLike(`john_doe%\`))
+	})
+
+	t.Run("should return the value as is when it has no wildcards", func(t *testing.T) {
+		assert.Equal(t, "john.doe@example.com", escapeLike("john.doe@example.com"))
+	})
+}
+
+func TestUserDataTables(t *testing.T) {
+	t.Run("should purge every table with user_id column unless it is retained", func(t *testing.T) {
+		files, err := filepath.Glob("../../migrations/*.up.sql")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, files)
+
+		tables := make(map[string]bool)
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			for _, match := range createTableRegex.FindAllStringSubmatch(string(content), -1) {
+				if userIDColumnRegex.MatchString(match[2]) {
+					tables[match[1]] = true
+				}
+			}
+			for _, match := range addUserIDRegex.FindAllStringSubmatch(string(content), -1) {
+				tables[match[1]] = true
+			}
+		}
+		assert.NotEmpty(t, tables)
+		for table := range tables {
+			_, retained := retainedTables[table]
+			assert.True(t, retained || contains(userDataTables, table), "table %s is neither purged nor retained", table)
+		}
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,23 +1,71 @@
 package user
 
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/audit"
+)
+
+// ErrUserNotFound is returned when the user does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrUserDisabled is returned when the user account is disabled.
+var ErrUserDisabled = errors.New("user is disabled")
+
+// ErrUserDeleted is returned when the user account is deleted.
+var ErrUserDeleted = errors.New("user is deleted")
+
+// ErrIdentityLinked is returned when the identity to be linked is already linked to another user.
+var ErrIdentityLinked = errors.New("identity is linked to another user")
+
+// ErrIdentityNotFound is returned when the identity to be unlinked is not linked to the user.
+var ErrIdentityNotFound = errors.New("identity not found")
+
+// ErrPrimaryIdentity is returned when unlinking the primary identity of the user.
+var ErrPrimaryIdentity = errors.New("primary identity can not be unlinked")
+
 // Service represents a user service.
 // It provides different methods to manage app user.
 //go:generate mockgen -source=service.go -package=user -destination=mock_service.go
 type Service interface {
 	Get(userID uint) (User, error)
 	GetByEmail(email string) (User, error)
+	GetByIdentity(provider string, providerUserID string) (User, error)
 	Save(user User) (uint, error)
-	Delete(userID uint) error
+	Delete(actorID uint, userID uint, reason string) error
+	CheckStatus(userID uint) error
+	Disable(actorID uint, userID uint, reason string) error
+	Enable(actorID uint, userID uint, reason string) error
+	UpdateProfile(userID uint, profile Profile) (User, error)
+	Search(query string, page int, perPage int) ([]User, int64, error)
+	IsAdmin(user User) bool
+	Purge(deletedBefore time.Time) ([]uint, error)
+	SaveGithubToken(userID uint, githubToken string) error
+	ReEncryptTokens() (int, error)
+	GetIdentities(userID uint) ([]Identity, error)
+	LinkIdentity(userID uint, identity Identity) error
+	UnlinkIdentity(userID uint, identityID uint) (Identity, error)
+}
+
+// Admins represents the users granted the admin role, identified by their email or github id.
+type Admins struct {
+	Emails    []string
+	GithubIDs []int64
 }
 
 type service struct {
-	repo Repo
+	repo   Repo
+	admins Admins
 }
 
 // NewService creates and return a new user service.
-func NewService(repo Repo) Service {
+// The admins are the users granted the admin role.
+func NewService(repo Repo, admins Admins) Service {
 	return &service{
-		repo: repo,
+		repo:   repo,
+		admins: admins,
 	}
 }
 
@@ -33,14 +81,35 @@ func (s *service) Get(userID uint) (User, error) {
 
 // GetByEmail retrieves a user with given email.
 // It returns a user along with any error occurred while retrieving it.
+// ErrUserDeleted is returned when the user with given email is deleted.
 func (s *service) GetByEmail(email string) (User, error) {
 	user, err := s.repo.GetByEmail(email)
 	if err != nil {
 		return user, err
 	}
+	if user.DeletedAt.Valid {
+		return User{}, ErrUserDeleted
+	}
 	return user, nil
 }
 
+// GetByIdentity retrieves the user the identity with given provider & provider user id is linked to.
+// It returns an empty user when the identity is not linked to any user.
+// ErrUserDeleted is returned when the user of the identity is deleted.
+func (s *service) GetByIdentity(provider string, providerUserID string) (User, error) {
+	identity, err := s.repo.GetIdentity(provider, providerUserID)
+	if err != nil {
+		return User{}, err
+	}
+	if identity.ID == 0 {
+		return User{}, nil
+	}
+	if err := s.CheckStatus(identity.UserID); err != nil && !errors.Is(err, ErrUserDisabled) {
+		return User{}, err
+	}
+	return s.repo.Get(identity.UserID)
+}
+
 // Save stores the user.
 // It returns the user id of the user along with any error occurred while storing the user.
 func (s *service) Save(user User) (uint, error) {
@@ -51,8 +120,160 @@ func (s *service) Save(user User) (uint, error) {
 	return userID, nil
 }
 
-// Delete deletes the user with given user id.
+// Delete deletes the user with given user id & records the action in the audit trail of the user.
+// The action is recorded as a deletion request when the users delete their own account.
 // It returns any error occurred while deleting the user.
-func (s *service) Delete(userID uint) error {
-	return s.repo.Delete(userID)
+func (s *service) Delete(actorID uint, userID uint, reason string) error {
+	action := audit.ActionUserDeleted
+	if actorID == userID {
+		action = audit.ActionUserDeletionRequested
+	}
+	return s.repo.Delete(userID, auditEvent(actorID, userID, action, reason))
+}
+
+// CheckStatus checks that the user with given user id exists & is allowed to access the app.
+// It returns ErrUserNotFound, ErrUserDeleted or ErrUserDisabled when the user is not allowed.
+func (s *service) CheckStatus(userID uint) error {
+	user, err := s.repo.GetStatus(userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == 0 {
+		return ErrUserNotFound
+	}
+	if user.DeletedAt.Valid {
+		return ErrUserDeleted
+	}
+	if user.DisabledAt != nil {
+		return ErrUserDisabled
+	}
+	return nil
+}
+
+// Disable disables the user with given user id & records the action in the audit trail of the user.
+// The disabled user can not login or access the app.
+// It returns any error occurred while disabling the user.
+func (s *service) Disable(actorID uint, userID uint, reason string) error {
+	if err := s.CheckStatus(userID); err != nil && !errors.Is(err, ErrUserDisabled) {
+		return err
+	}
+	disabledAt := time.Now().UTC()
+	return s.repo.SetDisabled(userID, &disabledAt, reason, auditEvent(actorID, userID, audit.ActionUserDisabled, reason))
+}
+
+// Enable re-enables the disabled user with given user id & records the action in the audit trail of the user.
+// It returns any error occurred while enabling the user.
+func (s *service) Enable(actorID uint, userID uint, reason string) error {
+	if err := s.CheckStatus(userID); err != nil && !errors.Is(err, ErrUserDisabled) {
+		return err
+	}
+	return s.repo.SetDisabled(userID, nil, "", auditEvent(actorID, userID, audit.ActionUserEnabled, reason))
+}
+
+// UpdateProfile updates the editable profile attributes of the user with given user id.
+// It returns the updated user along with any error occurred while updating the profile.
+func (s *service) UpdateProfile(userID uint, profile Profile) (User, error) {
+	if err := s.repo.UpdateProfile(userID, profile); err != nil {
+		return User{}, err
+	}
+	return s.repo.Get(userID)
+}
+
+// Search retrieves the users (including deleted ones) matching the query, page numbers start from 1.
+// It returns a page of users & the total count of matching users along with any error occurred while searching.
+func (s *service) Search(query string, page int, perPage int) ([]User, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	return s.repo.Search(strings.TrimSpace(query), (page-1)*perPage, perPage)
+}
+
+// IsAdmin checks whether the user is granted the admin role.
+func (s *service) IsAdmin(user User) bool {
+	for _, email := range s.admins.Emails {
+		if user.Email != "" && strings.EqualFold(email, user.Email) {
+			return true
+		}
+	}
+	for _, githubID := range s.admins.GithubIDs {
+		if user.GithubID != 0 && githubID == user.GithubID {
+			return true
+		}
+	}
+	return false
+}
+
+// Purge permanently deletes the users marked as deleted before the given time along with all their data.
+// It returns the ids of the purged users along with any error occurred while purging them.
+func (s *service) Purge(deletedBefore time.Time) ([]uint, error) {
+	return s.repo.Purge(deletedBefore)
+}
+
+// SaveGithubToken stores the github token of the user with given user id.
+// It returns any error occurred while storing the token.
+func (s *service) SaveGithubToken(userID uint, githubToken string) error {
+	return s.repo.SaveGithubToken(userID, githubToken)
+}
+
+// ReEncryptTokens encrypts the stored github tokens of all users with the primary encryption key.
+// It returns the count of re-encrypted tokens along with any error occurred while re-encrypting them.
+func (s *service) ReEncryptTokens() (int, error) {
+	return s.repo.ReEncryptTokens()
+}
+
+// GetIdentities retrieves all the identities linked to the user with given user id.
+func (s *service) GetIdentities(userID uint) ([]Identity, error) {
+	return s.repo.GetIdentities(userID)
+}
+
+// LinkIdentity links the identity to the user with given user id, the user can then login with the identity.
+// The username, email & token of an already linked identity are updated.
+// ErrIdentityLinked is returned when the identity is linked to another user.
+func (s *service) LinkIdentity(userID uint, identity Identity) error {
+	linked, err := s.repo.GetIdentity(identity.Provider, identity.ProviderUserID)
+	if err != nil {
+		return err
+	}
+	if linked.ID != 0 && linked.UserID != userID {
+		return ErrIdentityLinked
+	}
+	identity.ID = linked.ID
+	identity.CreatedAt = linked.CreatedAt
+	identity.UserID = userID
+	return s.repo.SaveIdentity(identity)
+}
+
+// UnlinkIdentity unlinks the identity with given identity id from the user with given user id.
+// It returns the unlinked identity along with any error occurred while unlinking it.
+// ErrIdentityNotFound is returned when the identity is not linked to the user & ErrPrimaryIdentity when it is the primary identity.
+func (s *service) UnlinkIdentity(userID uint, identityID uint) (Identity, error) {
+	user, err := s.repo.Get(userID)
+	if err != nil {
+		return Identity{}, err
+	}
+	identities, err := s.repo.GetIdentities(userID)
+	if err != nil {
+		return Identity{}, err
+	}
+	for _, identity := range identities {
+		if identity.ID != identityID {
+			continue
+		}
+		if user.IsPrimaryIdentity(identity) {
+			return Identity{}, ErrPrimaryIdentity
+		}
+		return identity, s.repo.DeleteIdentity(identityID)
+	}
+	return Identity{}, ErrIdentityNotFound
+}
+
+// auditEvent returns the audit event of the action performed by the actor on the user.
+// The event is stored along with the change it records.
+func auditEvent(actorID uint, userID uint, action string, reason string) audit.Event {
+	return audit.Event{
+		ActorID: actorID,
+		UserID:  userID,
+		Action:  action,
+		Reason:  reason,
+	}
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/batnoter/batnoter-api/internal/audit"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
@@ -12,6 +13,7 @@ import (
 
 const (
 	userID    = uint(1234)
+	adminID   = uint(4321)
 	email     = "john.doe@example.com"
 	name      = "John Doe"
 	location  = "New York"
@@ -25,7 +27,7 @@ func TestGet(t *testing.T) {
 		mockRepo := NewMockRepo(ctrl)
 		n := User{}
 
-		service := NewService(mockRepo)
+		service := NewService(mockRepo, Admins{})
 		mockRepo.EXPECT().Get(userID).Return(n, nil)
 
 		_, err := service.Get(userID)
@@ -38,7 +40,7 @@ func TestGet(t *testing.T) {
 		mockRepo := NewMockRepo(ctrl)
 		n := User{}
 
-		service := NewService(mockRepo)
+		service := NewService(mockRepo, Admins{})
 		mockRepo.EXPECT().Get(gomock.Any()).Return(n, errors.New("some error"))
 
 		_, err := service.Get(userID)
@@ -53,7 +55,7 @@ func TestGetByEmail(t *testing.T) {
 		mockRepo := NewMockRepo(ctrl)
 		n := User{}
 
-		service := NewService(mockRepo)
+		service := NewService(mockRepo, Admins{})
 		mockRepo.EXPECT().GetByEmail(email).Return(n, nil)
 
 		_, err := service.GetByEmail(email)
@@ -66,12 +68,25 @@ func TestGetByEmail(t *testing.T) {
 		mockRepo := NewMockRepo(ctrl)
 		n := User{}
 
-		service := NewService(mockRepo)
+		service := NewService(mockRepo, Admins{})
 		mockRepo.EXPECT().GetByEmail(gomock.Any()).Return(n, errors.New("some error"))
 
 		_, err := service.GetByEmail(email)
 		assert.Error(t, err)
 	})
+
+	t.Run("should return user deleted error when the user with email is deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		n := User{Model: gorm.Model{ID: userID, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}, Email: email}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetByEmail(email).Return(n, nil)
+
+		_, err := service.GetByEmail(email)
+		assert.ErrorIs(t, err, ErrUserDeleted)
+	})
 }
 
 func TestSave(t *testing.T) {
@@ -96,7 +111,7 @@ func TestSave(t *testing.T) {
 			DisabledAt:     nil,
 		}
 
-		service := NewService(mockRepo)
+		service := NewService(mockRepo, Admins{})
 		mockRepo.EXPECT().Save(n).Return(userID, nil)
 
 		id, err := service.Save(n)
@@ -110,7 +125,7 @@ func TestSave(t *testing.T) {
 		mockRepo := NewMockRepo(ctrl)
 		n := User{}
 
-		service := NewService(mockRepo)
+		service := NewService(mockRepo, Admins{})
 		mockRepo.EXPECT().Save(gomock.Any()).Return(uint(0), errors.New("some error"))
 
 		_, err := service.Save(n)
@@ -119,15 +134,27 @@ func TestSave(t *testing.T) {
 }
 
 func TestDelete(t *testing.T) {
-	t.Run("should delete a user", func(t *testing.T) {
+	t.Run("should delete a user along with the audit event of the admin", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockRepo := NewMockRepo(ctrl)
 
-		service := NewService(mockRepo)
-		mockRepo.EXPECT().Delete(userID).Return(nil)
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Delete(userID, audit.Event{ActorID: adminID, UserID: userID, Action: audit.ActionUserDeleted, Reason: "spam"}).Return(nil)
 
-		err := service.Delete(userID)
+		err := service.Delete(adminID, userID, "spam")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should record the deletion request when the user deletes own account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Delete(userID, audit.Event{ActorID: userID, UserID: userID, Action: audit.ActionUserDeletionRequested}).Return(nil)
+
+		err := service.Delete(userID, userID, "")
 		assert.NoError(t, err)
 	})
 
@@ -136,10 +163,426 @@ func TestDelete(t *testing.T) {
 		defer ctrl.Finish()
 		mockRepo := NewMockRepo(ctrl)
 
-		service := NewService(mockRepo)
-		mockRepo.EXPECT().Delete(gomock.Any()).Return(errors.New("some error"))
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
 
-		err := service.Delete(userID)
+		err := service.Delete(adminID, userID, "spam")
 		assert.Error(t, err)
 	})
 }
+
+func TestReEncryptTokens(t *testing.T) {
+	t.Run("should re-encrypt the github tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().ReEncryptTokens().Return(5, nil)
+
+		count, err := service.ReEncryptTokens()
+		assert.Equal(t, 5, count)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when re-encrypting github tokens fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().ReEncryptTokens().Return(0, errors.New("some error"))
+
+		_, err := service.ReEncryptTokens()
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveGithubToken(t *testing.T) {
+	t.Run("should save the github token of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().SaveGithubToken(userID, "token").Return(nil)
+
+		err := service.SaveGithubToken(userID, "token")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when saving the github token fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().SaveGithubToken(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+		err := service.SaveGithubToken(userID, "token")
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckStatus(t *testing.T) {
+	t.Run("should not return error when the user is active", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		n := User{Model: gorm.Model{ID: userID}}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(n, nil)
+
+		err := service.CheckStatus(userID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return user not found error when the user does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(User{}, nil)
+
+		err := service.CheckStatus(userID)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("should return user deleted error when the user is deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		n := User{Model: gorm.Model{ID: userID, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(n, nil)
+
+		err := service.CheckStatus(userID)
+		assert.ErrorIs(t, err, ErrUserDeleted)
+	})
+
+	t.Run("should return user disabled error when the user is disabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		disabledAt := time.Now()
+		n := User{Model: gorm.Model{ID: userID}, DisabledAt: &disabledAt}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(n, nil)
+
+		err := service.CheckStatus(userID)
+		assert.ErrorIs(t, err, ErrUserDisabled)
+	})
+}
+
+func TestDisable(t *testing.T) {
+	t.Run("should disable the user with the reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(User{Model: gorm.Model{ID: userID}}, nil)
+		mockRepo.EXPECT().SetDisabled(userID, gomock.Not(gomock.Nil()), "spam",
+			audit.Event{ActorID: adminID, UserID: userID, Action: audit.ActionUserDisabled, Reason: "spam"}).Return(nil)
+
+		err := service.Disable(adminID, userID, "spam")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when the user is deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		n := User{Model: gorm.Model{ID: userID, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(n, nil)
+
+		err := service.Disable(adminID, userID, "spam")
+		assert.ErrorIs(t, err, ErrUserDeleted)
+	})
+}
+
+func TestEnable(t *testing.T) {
+	t.Run("should enable the disabled user & clear the reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		disabledAt := time.Now()
+		n := User{Model: gorm.Model{ID: userID}, DisabledAt: &disabledAt, DisabledReason: "spam"}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(n, nil)
+		mockRepo.EXPECT().SetDisabled(userID, nil, "",
+			audit.Event{ActorID: adminID, UserID: userID, Action: audit.ActionUserEnabled, Reason: "appeal accepted"}).Return(nil)
+
+		err := service.Enable(adminID, userID, "appeal accepted")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return error when the user does not exist", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetStatus(userID).Return(User{}, nil)
+
+		err := service.Enable(adminID, userID, "appeal accepted")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
+
+func TestUpdateProfile(t *testing.T) {
+	t.Run("should update the profile & return the updated user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		profile := Profile{DisplayName: "Johnny", Timezone: "Europe/Berlin", CommitEmail: "john.doe@work.example.com"}
+		n := User{Model: gorm.Model{ID: userID}, Name: "John Doe", DisplayName: "Johnny", Timezone: "Europe/Berlin", CommitEmail: "john.doe@work.example.com"}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().UpdateProfile(userID, profile).Return(nil)
+		mockRepo.EXPECT().Get(userID).Return(n, nil)
+
+		u, err := service.UpdateProfile(userID, profile)
+		assert.NoError(t, err)
+		assert.Equal(t, n, u)
+		assert.Equal(t, profile, u.Profile())
+	})
+
+	t.Run("should return error when updating the profile fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().UpdateProfile(userID, Profile{}).Return(errors.New("some error"))
+
+		_, err := service.UpdateProfile(userID, Profile{})
+		assert.Error(t, err)
+	})
+}
+
+func TestCommitIdentity(t *testing.T) {
+	t.Run("should use the commit identity chosen by the user", func(t *testing.T) {
+		u := User{Email: "john.doe@example.com", Name: "John Doe", GithubUsername: "johndoe", CommitName: "John at Work", CommitEmail: "john.doe@work.example.com"}
+		assert.Equal(t, "John at Work", u.GetCommitName())
+		assert.Equal(t, "john.doe@work.example.com", u.GetCommitEmail())
+	})
+
+	t.Run("should fall back to the display name, github name & github username", func(t *testing.T) {
+		assert.Equal(t, "Johnny", User{Name: "John Doe", DisplayName: "Johnny", GithubUsername: "johndoe"}.GetCommitName())
+		assert.Equal(t, "John Doe", User{Name: "John Doe", GithubUsername: "johndoe"}.GetCommitName())
+		assert.Equal(t, "johndoe", User{GithubUsername: "johndoe"}.GetCommitName())
+		assert.Equal(t, "john.doe@example.com", User{Email: "john.doe@example.com"}.GetCommitEmail())
+	})
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("should search the users with the offset of the requested page", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		users := []User{{Email: email}}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Search("john", 40, 20).Return(users, int64(41), nil)
+
+		result, total, err := service.Search(" john ", 3, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, users, result)
+		assert.Equal(t, int64(41), total)
+	})
+
+	t.Run("should search the first page when the page is not valid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Search("", 0, 20).Return(nil, int64(0), errors.New("some error"))
+
+		_, _, err := service.Search("", 0, 20)
+		assert.Error(t, err)
+	})
+}
+
+func TestIsAdmin(t *testing.T) {
+	service := NewService(nil, Admins{Emails: []string{"Admin@Example.com"}, GithubIDs: []int64{4242}})
+
+	t.Run("should grant admin role to the user with admin email", func(t *testing.T) {
+		assert.True(t, service.IsAdmin(User{Email: "admin@example.com"}))
+	})
+
+	t.Run("should grant admin role to the user with admin github id", func(t *testing.T) {
+		assert.True(t, service.IsAdmin(User{Email: email, GithubID: 4242}))
+	})
+
+	t.Run("should not grant admin role to the other users", func(t *testing.T) {
+		assert.False(t, service.IsAdmin(User{Email: email, GithubID: 12345}))
+		assert.False(t, service.IsAdmin(User{}))
+	})
+}
+
+func TestPurge(t *testing.T) {
+	t.Run("should purge the users deleted before the given time", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		deletedBefore := time.Now().Add(-time.Hour)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Purge(deletedBefore).Return([]uint{userID}, nil)
+
+		userIDs, err := service.Purge(deletedBefore)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{userID}, userIDs)
+	})
+}
+
+func TestGetByIdentity(t *testing.T) {
+	t.Run("should return the user the identity is linked to", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		n := User{Model: gorm.Model{ID: userID}, Email: "john.doe@example.com", GithubID: 12345}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetIdentity(ProviderGithub, "12345").Return(Identity{Model: gorm.Model{ID: 51}, UserID: userID}, nil)
+		mockRepo.EXPECT().GetStatus(userID).Return(User{Model: gorm.Model{ID: userID}}, nil)
+		mockRepo.EXPECT().Get(userID).Return(n, nil)
+
+		u, err := service.GetByIdentity(ProviderGithub, "12345")
+		assert.NoError(t, err)
+		assert.Equal(t, n, u)
+	})
+
+	t.Run("should return empty user when the identity is not linked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetIdentity(ProviderGithub, "12345").Return(Identity{}, nil)
+
+		u, err := service.GetByIdentity(ProviderGithub, "12345")
+		assert.NoError(t, err)
+		assert.Equal(t, User{}, u)
+	})
+
+	t.Run("should return user deleted error when the user of the identity is deleted", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		deleted := User{Model: gorm.Model{ID: userID, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}}
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetIdentity(ProviderGithub, "12345").Return(Identity{Model: gorm.Model{ID: 51}, UserID: userID}, nil)
+		mockRepo.EXPECT().GetStatus(userID).Return(deleted, nil)
+
+		_, err := service.GetByIdentity(ProviderGithub, "12345")
+		assert.ErrorIs(t, err, ErrUserDeleted)
+	})
+}
+
+func TestLinkIdentity(t *testing.T) {
+	t.Run("should link the identity to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		identity := Identity{Provider: ProviderGithub, ProviderUserID: "54321", Username: "johndoe-work", Token: "token"}
+		linked := identity
+		linked.UserID = userID
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetIdentity(ProviderGithub, "54321").Return(Identity{}, nil)
+		mockRepo.EXPECT().SaveIdentity(linked).Return(nil)
+
+		err := service.LinkIdentity(userID, identity)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should update the identity already linked to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+		createdAt := time.Now()
+		identity := Identity{Provider: ProviderGithub, ProviderUserID: "54321", Username: "johndoe-work", Token: "new-token"}
+		existing := Identity{Model: gorm.Model{ID: 52, CreatedAt: createdAt}, UserID: userID, Provider: ProviderGithub, ProviderUserID: "54321", Token: "old-token"}
+		updated := identity
+		updated.Model = gorm.Model{ID: 52, CreatedAt: createdAt}
+		updated.UserID = userID
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetIdentity(ProviderGithub, "54321").Return(existing, nil)
+		mockRepo.EXPECT().SaveIdentity(updated).Return(nil)
+
+		err := service.LinkIdentity(userID, identity)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should return identity linked error when the identity is linked to another user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().GetIdentity(ProviderGithub, "54321").Return(Identity{Model: gorm.Model{ID: 52}, UserID: userID + 1}, nil)
+
+		err := service.LinkIdentity(userID, Identity{Provider: ProviderGithub, ProviderUserID: "54321"})
+		assert.ErrorIs(t, err, ErrIdentityLinked)
+	})
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	identities := []Identity{
+		{Model: gorm.Model{ID: 51}, UserID: userID, Provider: ProviderGithub, ProviderUserID: "12345"},
+		{Model: gorm.Model{ID: 52}, UserID: userID, Provider: ProviderGithub, ProviderUserID: "54321"},
+	}
+
+	t.Run("should unlink the identity & return it", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Get(userID).Return(User{Model: gorm.Model{ID: userID}, GithubID: 12345}, nil)
+		mockRepo.EXPECT().GetIdentities(userID).Return(identities, nil)
+		mockRepo.EXPECT().DeleteIdentity(uint(52)).Return(nil)
+
+		identity, err := service.UnlinkIdentity(userID, 52)
+		assert.NoError(t, err)
+		assert.Equal(t, identities[1], identity)
+	})
+
+	t.Run("should return primary identity error when unlinking the primary identity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Get(userID).Return(User{Model: gorm.Model{ID: userID}, GithubID: 12345}, nil)
+		mockRepo.EXPECT().GetIdentities(userID).Return(identities, nil)
+
+		_, err := service.UnlinkIdentity(userID, 51)
+		assert.ErrorIs(t, err, ErrPrimaryIdentity)
+	})
+
+	t.Run("should return identity not found error when the identity is not linked to the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRepo := NewMockRepo(ctrl)
+
+		service := NewService(mockRepo, Admins{})
+		mockRepo.EXPECT().Get(userID).Return(User{Model: gorm.Model{ID: userID}, GithubID: 12345}, nil)
+		mockRepo.EXPECT().GetIdentities(userID).Return(identities, nil)
+
+		_, err := service.UnlinkIdentity(userID, 99)
+		assert.ErrorIs(t, err, ErrIdentityNotFound)
+	})
+}
@@ -0,0 +1,145 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"golang.org/x/sync/singleflight"
+)
+
+// TokenService represents a github token service.
+// It provides the github tokens of the user, refreshing & persisting them when they are expired.
+//go:generate mockgen -source=token_service.go -package=user -destination=mock_token_service.go
+type TokenService interface {
+	TokenSource(ctx context.Context, user User) oauth2.TokenSource
+	GetGithubToken(ctx context.Context, user User) (oauth2.Token, error)
+	GetRepoToken(ctx context.Context, user User, repoDetails github.GitRepoProps) (oauth2.Token, error)
+}
+
+type tokenService struct {
+	oauth2Config     *oauth2.Config
+	githubAppService github.AppService
+	service          Service
+
+	group singleflight.Group
+}
+
+// NewTokenService creates and returns a new github token service.
+// Refreshed github tokens are stored using the user service.
+func NewTokenService(oauth2Config *oauth2.Config, githubAppService github.AppService, service Service) TokenService {
+	return &tokenService{
+		oauth2Config:     oauth2Config,
+		githubAppService: githubAppService,
+		service:          service,
+	}
+}
+
+// TokenSource returns a token source of the user's github oauth2 token.
+// The token source refreshes the expired token & stores the refreshed token against the user.
+func (t *tokenService) TokenSource(ctx context.Context, user User) oauth2.TokenSource {
+	return &persistingTokenSource{
+		ctx:          ctx,
+		userID:       user.ID,
+		token:        ParseGithubToken(user.GithubToken),
+		tokenService: t,
+	}
+}
+
+// GetGithubToken returns a valid github oauth2 token of the user.
+// It returns the token along with any error occurred while refreshing or storing the token.
+func (t *tokenService) GetGithubToken(ctx context.Context, user User) (oauth2.Token, error) {
+	token, err := t.TokenSource(ctx, user).Token()
+	if err != nil {
+		return oauth2.Token{}, err
+	}
+	return *token, nil
+}
+
+// GetRepoToken returns the github token used by the user to access the repo, the default repo or the repo of a notebook.
+// Installation token is used when the repo is linked with a github app installation,
+// otherwise the user's oauth2 token is used. The oauth2 token does not have the repo scope when the github app
+// is enabled, github.ErrInstallationRequired is returned in that case until the user installs the app.
+func (t *tokenService) GetRepoToken(ctx context.Context, user User, repoDetails github.GitRepoProps) (oauth2.Token, error) {
+	if repoDetails.InstallationID != 0 {
+		return t.githubAppService.GetInstallationToken(ctx, repoDetails.InstallationID, repoDetails.Repository)
+	}
+	if t.githubAppService.Enabled() {
+		return oauth2.Token{}, github.ErrInstallationRequired
+	}
+	return t.GetGithubToken(ctx, user)
+}
+
+// refresh refreshes the expired github token of the user & stores it.
+// Concurrent refreshes of the same user are deduplicated, the callers waiting on an in-flight refresh share its result.
+func (t *tokenService) refresh(ctx context.Context, userID uint, token oauth2.Token) (*oauth2.Token, error) {
+	v, err, _ := t.group.Do(strconv.FormatUint(uint64(userID), 10), func() (interface{}, error) {
+		return t.refreshAndStore(ctx, userID, token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	refreshedToken := *v.(*oauth2.Token)
+	return &refreshedToken, nil
+}
+
+// refreshAndStore refreshes the github token of the user unless it is already refreshed & stores the refreshed token.
+func (t *tokenService) refreshAndStore(ctx context.Context, userID uint, token oauth2.Token) (*oauth2.Token, error) {
+	// token may have been refreshed by an earlier request
+	user, err := t.service.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if storedToken := ParseGithubToken(user.GithubToken); storedToken.Valid() {
+		return &storedToken, nil
+	}
+
+	refreshedToken, err := t.oauth2Config.TokenSource(ctx, &token).Token()
+	if err != nil {
+		return nil, errors.Wrap(err, "refreshing github token failed")
+	}
+	tokenJSON, err := json.Marshal(refreshedToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting github token to json failed")
+	}
+	if err := t.service.SaveGithubToken(userID, string(tokenJSON)); err != nil {
+		return nil, err
+	}
+	logrus.WithField("user-id", userID).Info("github token refreshed")
+	return refreshedToken, nil
+}
+
+type persistingTokenSource struct {
+	ctx          context.Context
+	userID       uint
+	token        oauth2.Token
+	tokenService *tokenService
+}
+
+// Token returns the github token, the expired token is refreshed & stored against the user.
+func (p *persistingTokenSource) Token() (*oauth2.Token, error) {
+	// tokens without expiry or refresh token never expire & can not be refreshed
+	if p.token.Valid() || p.token.RefreshToken == "" {
+		token := p.token
+		return &token, nil
+	}
+	token, err := p.tokenService.refresh(p.ctx, p.userID, p.token)
+	if err != nil {
+		return nil, err
+	}
+	p.token = *token
+	return token, nil
+}
+
+// ParseGithubToken parses the github token json stored against the user.
+func ParseGithubToken(githubToken string) oauth2.Token {
+	oauth2Token := oauth2.Token{}
+	if err := json.Unmarshal([]byte(githubToken), &oauth2Token); err != nil {
+		logrus.Warn("failed to parse token json to oauth2 token")
+	}
+	return oauth2Token
+}
@@ -0,0 +1,217 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/batnoter/batnoter-api/internal/github"
+	"github.com/batnoter/batnoter-api/internal/preference"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestGetGithubToken(t *testing.T) {
+	t.Run("should return the stored token when it is not expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		token := oauth2.Token{AccessToken: "gho_token", TokenType: "bearer", RefreshToken: "ghr_token", Expiry: time.Now().Add(time.Hour)}
+
+		tokenService := NewTokenService(&oauth2.Config{}, nil, mockService)
+
+		t1, err := tokenService.GetGithubToken(context.Background(), User{GithubToken: tokenJSON(token)})
+		assert.NoError(t, err)
+		assert.Equal(t, "gho_token", t1.AccessToken)
+	})
+
+	t.Run("should refresh the expired token & store the refreshed token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		server, _ := tokenServer()
+		defer server.Close()
+		expiredToken := oauth2.Token{AccessToken: "gho_token", TokenType: "bearer", RefreshToken: "ghr_token", Expiry: time.Now().Add(-time.Hour)}
+		u := User{GithubToken: tokenJSON(expiredToken)}
+		u.ID = userID
+
+		tokenService := NewTokenService(oauth2Config(server.URL), nil, mockService)
+		mockService.EXPECT().Get(userID).Return(u, nil)
+		mockService.EXPECT().SaveGithubToken(userID, gomock.Any()).Return(nil)
+
+		token, err := tokenService.GetGithubToken(context.Background(), u)
+		assert.NoError(t, err)
+		assert.Equal(t, "gho_refreshed_token", token.AccessToken)
+		assert.Equal(t, "ghr_refreshed_token", token.RefreshToken)
+	})
+
+	t.Run("should refresh the expired token only once when it is requested concurrently", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		server, refreshCount := tokenServer()
+		defer server.Close()
+		expiredToken := oauth2.Token{AccessToken: "gho_token", TokenType: "bearer", RefreshToken: "ghr_token", Expiry: time.Now().Add(-time.Hour)}
+		u := User{GithubToken: tokenJSON(expiredToken)}
+		u.ID = userID
+
+		var mu sync.Mutex
+		storedUser := u
+		tokenService := NewTokenService(oauth2Config(server.URL), nil, mockService)
+		mockService.EXPECT().Get(userID).DoAndReturn(func(userID uint) (User, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return storedUser, nil
+		}).AnyTimes()
+		mockService.EXPECT().SaveGithubToken(userID, gomock.Any()).DoAndReturn(func(userID uint, githubToken string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			storedUser.GithubToken = githubToken
+			return nil
+		}).Times(1)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := tokenService.GetGithubToken(context.Background(), u)
+				assert.NoError(t, err)
+				assert.Equal(t, "gho_refreshed_token", token.AccessToken)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), atomic.LoadInt32(refreshCount))
+	})
+
+	t.Run("should return error when storing the refreshed token fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		server, _ := tokenServer()
+		defer server.Close()
+		expiredToken := oauth2.Token{AccessToken: "gho_token", TokenType: "bearer", RefreshToken: "ghr_token", Expiry: time.Now().Add(-time.Hour)}
+		u := User{GithubToken: tokenJSON(expiredToken)}
+		u.ID = userID
+
+		tokenService := NewTokenService(oauth2Config(server.URL), nil, mockService)
+		mockService.EXPECT().Get(userID).Return(u, nil)
+		mockService.EXPECT().SaveGithubToken(userID, gomock.Any()).Return(errors.New("some error"))
+
+		_, err := tokenService.GetGithubToken(context.Background(), u)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetRepoToken(t *testing.T) {
+	t.Run("should return installation token when the repo is linked with github app installation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		installationToken := oauth2.Token{AccessToken: "ghs_token", TokenType: "token"}
+		u := User{GithubToken: tokenJSON(oauth2.Token{AccessToken: "gho_token"}), DefaultRepo: &preference.DefaultRepo{Name: "notes", InstallationID: 8765}}
+
+		tokenService := NewTokenService(&oauth2.Config{}, mockGithubAppService, mockService)
+		mockGithubAppService.EXPECT().GetInstallationToken(gomock.Any(), int64(8765), "notes").Return(installationToken, nil)
+
+		token, err := tokenService.GetRepoToken(context.Background(), u, u.GetDefaultRepoDetails())
+		assert.NoError(t, err)
+		assert.Equal(t, installationToken, token)
+	})
+
+	t.Run("should return installation token of the repo of the notebook instead of the default repo", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		installationToken := oauth2.Token{AccessToken: "ghs_token", TokenType: "token"}
+		u := User{GithubToken: tokenJSON(oauth2.Token{AccessToken: "gho_token"}), DefaultRepo: &preference.DefaultRepo{InstallationID: 8765}}
+		repoDetails := github.GitRepoProps{Repository: "team-notes", Owner: "acme", InstallationID: 1111}
+
+		tokenService := NewTokenService(&oauth2.Config{}, mockGithubAppService, mockService)
+		mockGithubAppService.EXPECT().GetInstallationToken(gomock.Any(), int64(1111), "team-notes").Return(installationToken, nil)
+
+		token, err := tokenService.GetRepoToken(context.Background(), u, repoDetails)
+		assert.NoError(t, err)
+		assert.Equal(t, installationToken, token)
+	})
+
+	t.Run("should return installation required error when the repo of the notebook is not linked even if the default repo is", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		u := User{GithubToken: tokenJSON(oauth2.Token{AccessToken: "gho_token"}), DefaultRepo: &preference.DefaultRepo{InstallationID: 8765}}
+
+		tokenService := NewTokenService(&oauth2.Config{}, mockGithubAppService, mockService)
+		mockGithubAppService.EXPECT().Enabled().Return(true)
+
+		_, err := tokenService.GetRepoToken(context.Background(), u, github.GitRepoProps{Repository: "team-notes", Owner: "acme"})
+		assert.ErrorIs(t, err, github.ErrInstallationRequired)
+	})
+
+	t.Run("should return user's github token when the repo is not linked with github app installation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		u := User{GithubToken: tokenJSON(oauth2.Token{AccessToken: "gho_token"}), DefaultRepo: &preference.DefaultRepo{}}
+
+		tokenService := NewTokenService(&oauth2.Config{}, mockGithubAppService, mockService)
+		mockGithubAppService.EXPECT().Enabled().Return(false)
+
+		token, err := tokenService.GetRepoToken(context.Background(), u, u.GetDefaultRepoDetails())
+		assert.NoError(t, err)
+		assert.Equal(t, "gho_token", token.AccessToken)
+	})
+
+	t.Run("should return installation required error when the github app is enabled but the repo is not linked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockService := NewMockService(ctrl)
+		mockGithubAppService := github.NewMockAppService(ctrl)
+		u := User{GithubToken: tokenJSON(oauth2.Token{AccessToken: "gho_token"}), DefaultRepo: &preference.DefaultRepo{}}
+
+		tokenService := NewTokenService(&oauth2.Config{}, mockGithubAppService, mockService)
+		mockGithubAppService.EXPECT().Enabled().Return(true)
+
+		_, err := tokenService.GetRepoToken(context.Background(), u, u.GetDefaultRepoDetails())
+		assert.ErrorIs(t, err, github.ErrInstallationRequired)
+	})
+}
+
+func tokenServer() (*httptest.Server, *int32) {
+	var refreshCount int32
+	// to get the details of github response structure
+	// refer - https://docs.github.com/en/developers/apps/building-github-apps/refreshing-user-to-server-access-tokens
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&refreshCount, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"gho_refreshed_token","expires_in":28800,"refresh_token":"ghr_refreshed_token","refresh_token_expires_in":15811200,"token_type":"bearer"}`)
+	}))
+	return server, &refreshCount
+}
+
+func oauth2Config(serverURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID: "testclient",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  serverURL + "/auth",
+			TokenURL: serverURL + "/token",
+		},
+	}
+}
+
+func tokenJSON(token oauth2.Token) string {
+	tokenJSON, _ := json.Marshal(token)
+	return string(tokenJSON)
+}
@@ -0,0 +1 @@
+alter table default_repos drop column if exists installation_id;
@@ -0,0 +1 @@
+alter table default_repos add column if not exists installation_id bigint not null default 0;
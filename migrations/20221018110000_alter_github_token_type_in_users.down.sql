@@ -0,0 +1 @@
+-- the column is kept as text, the encrypted github tokens do not fit in varchar(255) & can not be decrypted in sql
\ No newline at end of file
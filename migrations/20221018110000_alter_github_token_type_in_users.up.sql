@@ -0,0 +1 @@
+alter table users alter column github_token type text;
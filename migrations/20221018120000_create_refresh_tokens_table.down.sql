@@ -0,0 +1 @@
+drop table if exists refresh_tokens;
\ No newline at end of file
@@ -0,0 +1,15 @@
+create table if not exists refresh_tokens
+(
+    id          serial primary key,
+    created_at  timestamp without time zone default (now() at time zone 'utc'),
+    updated_at  timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at  timestamp without time zone default null,
+    user_id     integer not null,
+
+    family_id   varchar(50) not null,
+    token_hash  varchar(64) not null unique,
+    expires_at  timestamp without time zone not null,
+    revoked_at  timestamp without time zone default null,
+    constraint fk_user foreign key(user_id) references users(id)
+);
+create index if not exists idx_refresh_tokens_family_id on refresh_tokens(family_id);
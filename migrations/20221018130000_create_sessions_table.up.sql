@@ -0,0 +1,17 @@
+create table if not exists sessions
+(
+    id              serial primary key,
+    created_at      timestamp without time zone default (now() at time zone 'utc'),
+    updated_at      timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at      timestamp without time zone default null,
+    user_id         integer not null,
+
+    token_id        varchar(50) not null unique,
+    device          varchar(100) null,
+    user_agent      varchar(512) null,
+    ip_address      varchar(50) null,
+    last_seen_at    timestamp without time zone not null,
+    revoked_at      timestamp without time zone default null,
+    constraint fk_user foreign key(user_id) references users(id)
+);
+create index if not exists idx_sessions_user_id on sessions(user_id);
@@ -0,0 +1,17 @@
+create table if not exists api_tokens
+(
+    id              serial primary key,
+    created_at      timestamp without time zone default (now() at time zone 'utc'),
+    updated_at      timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at      timestamp without time zone default null,
+    user_id         integer not null,
+
+    name            varchar(100) not null,
+    token_hash      varchar(64) not null unique,
+    scopes          varchar(255) not null,
+    expires_at      timestamp without time zone default null,
+    last_used_at    timestamp without time zone default null,
+    revoked_at      timestamp without time zone default null,
+    constraint fk_user foreign key(user_id) references users(id)
+);
+create index if not exists idx_api_tokens_user_id on api_tokens(user_id);
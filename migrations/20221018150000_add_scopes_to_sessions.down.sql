@@ -0,0 +1 @@
+alter table sessions drop column if exists scopes;
\ No newline at end of file
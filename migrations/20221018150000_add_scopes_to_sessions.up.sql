@@ -0,0 +1,3 @@
+-- sessions created before scopes were introduced keep the scopes of a user login
+alter table sessions add column if not exists scopes varchar(255) not null default 'notes:read notes:write preferences:write';
+alter table sessions alter column scopes drop default;
@@ -0,0 +1 @@
+alter table users drop column if exists disabled_reason;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table users add column if not exists disabled_reason varchar(255) null;
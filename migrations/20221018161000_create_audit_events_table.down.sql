@@ -0,0 +1 @@
+drop table if exists audit_events;
\ No newline at end of file
@@ -0,0 +1,10 @@
+create table if not exists audit_events
+(
+    id              serial primary key,
+    created_at      timestamp without time zone default (now() at time zone 'utc'),
+    actor_id        integer not null,
+    user_id         integer not null,
+    action          varchar(50) not null,
+    reason          varchar(255) null
+);
+create index if not exists idx_audit_events_user_id on audit_events(user_id);
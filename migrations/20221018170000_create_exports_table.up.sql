@@ -0,0 +1,18 @@
+create table if not exists exports
+(
+    id              serial primary key,
+    created_at      timestamp without time zone default (now() at time zone 'utc'),
+    updated_at      timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at      timestamp without time zone default null,
+    user_id         integer not null,
+
+    include_notes   boolean not null default false,
+    status          varchar(20) not null,
+    error           varchar(255) null,
+    archive         bytea null,
+    size            integer not null default 0,
+    completed_at    timestamp without time zone default null,
+    expires_at      timestamp without time zone default null,
+    constraint fk_user foreign key(user_id) references users(id)
+);
+create index if not exists idx_exports_user_id on exports(user_id);
@@ -0,0 +1,5 @@
+alter table users drop column if exists commit_email;
+alter table users drop column if exists commit_name;
+alter table users drop column if exists locale;
+alter table users drop column if exists timezone;
+alter table users drop column if exists display_name;
\ No newline at end of file
@@ -0,0 +1,5 @@
+alter table users add column if not exists display_name varchar(255) null;
+alter table users add column if not exists timezone varchar(64) null;
+alter table users add column if not exists locale varchar(35) null;
+alter table users add column if not exists commit_name varchar(255) null;
+alter table users add column if not exists commit_email varchar(255) null;
\ No newline at end of file
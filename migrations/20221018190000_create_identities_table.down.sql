@@ -0,0 +1,10 @@
+alter table users add column if not exists github_token text not null default '';
+
+update users
+set github_token = identities.token
+from identities
+where identities.user_id = users.id
+  and identities.provider = 'github'
+  and identities.provider_user_id = cast(users.github_id as text);
+
+drop table if exists identities;
\ No newline at end of file
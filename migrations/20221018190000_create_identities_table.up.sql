@@ -0,0 +1,24 @@
+create table if not exists identities
+(
+    id               serial primary key,
+    created_at       timestamp without time zone default (now() at time zone 'utc'),
+    updated_at       timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at       timestamp without time zone default null,
+    user_id          integer not null,
+    provider         varchar(50) not null,
+    provider_user_id varchar(255) not null,
+    username         varchar(255) null,
+    email            varchar(255) null,
+    token            text not null default '',
+    unique (provider, provider_user_id)
+);
+create index if not exists idx_identities_user_id on identities(user_id);
+
+-- github accounts of the existing users are linked as their primary identity
+insert into identities (user_id, provider, provider_user_id, username, email, token)
+select id, 'github', cast(github_id as text), github_username, email, github_token
+from users
+where github_id is not null and github_id <> 0
+on conflict (provider, provider_user_id) do nothing;
+
+alter table users drop column if exists github_token;
\ No newline at end of file
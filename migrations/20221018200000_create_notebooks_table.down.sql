@@ -0,0 +1 @@
+drop table if exists notebooks;
\ No newline at end of file
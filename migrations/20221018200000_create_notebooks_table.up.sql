@@ -0,0 +1,15 @@
+create table if not exists notebooks
+(
+    id          serial primary key,
+    created_at  timestamp without time zone default (now() at time zone 'utc'),
+    updated_at  timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at  timestamp without time zone default null,
+    user_id     integer not null,
+
+    name        varchar(50) not null,
+    repository  varchar(100) not null,
+    visibility  varchar(20) not null,
+    branch      varchar(255) not null,
+    constraint fk_user foreign key(user_id) references users(id)
+);
+create unique index if not exists idx_notebooks_user_id_name on notebooks(user_id, lower(name));
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table notebooks drop column if exists owner;
+alter table default_repos drop column if exists owner;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table default_repos add column if not exists owner varchar(100) not null default '';
+alter table notebooks add column if not exists owner varchar(100) not null default '';
\ No newline at end of file
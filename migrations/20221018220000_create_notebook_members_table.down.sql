@@ -0,0 +1 @@
+drop table if exists notebook_members;
\ No newline at end of file
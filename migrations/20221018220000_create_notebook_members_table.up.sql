@@ -0,0 +1,18 @@
+create table if not exists notebook_members
+(
+    id            serial primary key,
+    created_at    timestamp without time zone default (now() at time zone 'utc'),
+    updated_at    timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at    timestamp without time zone default null,
+    notebook_id   integer not null,
+    user_id       integer not null default 0,
+
+    role          varchar(20) not null,
+    status        varchar(20) not null,
+    invitee_email varchar(255) not null default '',
+    invitee_login varchar(39) not null default '',
+    invited_by    integer not null,
+    constraint fk_notebook foreign key(notebook_id) references notebooks(id) on delete cascade
+);
+create index if not exists idx_notebook_members_notebook_id on notebook_members(notebook_id);
+create index if not exists idx_notebook_members_user_id on notebook_members(user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists shares;
\ No newline at end of file
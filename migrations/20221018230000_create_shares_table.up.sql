@@ -0,0 +1,19 @@
+create table if not exists shares
+(
+    id              serial primary key,
+    created_at      timestamp without time zone default (now() at time zone 'utc'),
+    updated_at      timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at      timestamp without time zone default null,
+    user_id         integer not null,
+    notebook_id     integer not null default 0,
+
+    path            varchar(1024) not null,
+    token_hash      varchar(64) not null unique,
+    password_hash   varchar(100) not null default '',
+    expires_at      timestamp without time zone default null,
+    revoked_at      timestamp without time zone default null,
+    view_count      bigint not null default 0,
+    last_viewed_at  timestamp without time zone default null,
+    constraint fk_user foreign key(user_id) references users(id)
+);
+create index if not exists idx_shares_user_id on shares(user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists imports;
\ No newline at end of file
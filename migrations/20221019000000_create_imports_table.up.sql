@@ -0,0 +1,24 @@
+create table if not exists imports
+(
+    id              serial primary key,
+    created_at      timestamp without time zone default (now() at time zone 'utc'),
+    updated_at      timestamp without time zone default (now() at time zone 'utc'),
+    deleted_at      timestamp without time zone default null,
+    user_id         integer not null,
+
+    source          varchar(20) not null,
+    file_name       varchar(255) not null default '',
+    path            varchar(1024) not null default '',
+    status          varchar(20) not null,
+    error           varchar(255) null,
+    upload          bytea null,
+    total           integer not null default 0,
+    processed       integer not null default 0,
+    notes           integer not null default 0,
+    attachments     integer not null default 0,
+    skipped         text not null default '[]',
+    commit_sha      varchar(40) null,
+    completed_at    timestamp without time zone default null,
+    constraint fk_user foreign key(user_id) references users(id)
+);
+create index if not exists idx_imports_user_id on imports(user_id);
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists attachment_variants;
\ No newline at end of file
@@ -0,0 +1,10 @@
+create table if not exists attachment_variants
+(
+    id              serial primary key,
+    created_at      timestamp without time zone default (now() at time zone 'utc'),
+    blob_sha        varchar(40) not null,
+    size            varchar(20) not null,
+    content_type    varchar(50) not null,
+    content         bytea not null
+);
+create unique index if not exists idx_attachment_variants_blob_sha_size on attachment_variants(blob_sha, size);
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table notebooks drop column if exists note_path_pattern;
+alter table notebooks drop column if exists note_extensions;
\ No newline at end of file
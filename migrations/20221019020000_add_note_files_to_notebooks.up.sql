@@ -0,0 +1,2 @@
+alter table notebooks add column if not exists note_extensions varchar(100) not null default '';
+alter table notebooks add column if not exists note_path_pattern varchar(255) not null default '';
\ No newline at end of file
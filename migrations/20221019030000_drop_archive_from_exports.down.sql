@@ -0,0 +1 @@
+alter table exports add column if not exists archive bytea null;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table exports drop column if exists archive;
\ No newline at end of file
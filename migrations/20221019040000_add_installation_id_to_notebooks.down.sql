@@ -0,0 +1 @@
+alter table notebooks drop column if exists installation_id;
\ No newline at end of file
@@ -0,0 +1 @@
+alter table notebooks add column if not exists installation_id bigint not null default 0;
\ No newline at end of file
@@ -0,0 +1,2 @@
+alter table shares drop column if exists locked_until;
+alter table shares drop column if exists failed_attempts;
\ No newline at end of file
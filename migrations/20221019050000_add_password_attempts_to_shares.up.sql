@@ -0,0 +1,2 @@
+alter table shares add column if not exists failed_attempts integer not null default 0;
+alter table shares add column if not exists locked_until timestamp without time zone default null;
\ No newline at end of file
@@ -0,0 +1,5 @@
+drop index if exists idx_attachment_variants_user_id_blob_sha_size;
+delete from attachment_variants a using attachment_variants b where a.id > b.id and a.blob_sha = b.blob_sha and a.size = b.size;
+create unique index if not exists idx_attachment_variants_blob_sha_size on attachment_variants(blob_sha, size);
+alter table attachment_variants drop constraint if exists fk_user;
+alter table attachment_variants drop column if exists user_id;
\ No newline at end of file
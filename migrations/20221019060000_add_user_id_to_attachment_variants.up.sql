@@ -0,0 +1,6 @@
+-- the cached variants are regenerated on request, so the variants cached without the user are dropped
+delete from attachment_variants;
+alter table attachment_variants add column if not exists user_id integer not null;
+alter table attachment_variants add constraint fk_user foreign key(user_id) references users(id);
+drop index if exists idx_attachment_variants_blob_sha_size;
+create unique index if not exists idx_attachment_variants_user_id_blob_sha_size on attachment_variants(user_id, blob_sha, size);
\ No newline at end of file
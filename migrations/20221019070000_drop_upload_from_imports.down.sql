@@ -0,0 +1 @@
+alter table imports add column if not exists upload bytea null;
\ No newline at end of file
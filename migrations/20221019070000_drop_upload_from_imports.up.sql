@@ -0,0 +1 @@
+alter table imports drop column if exists upload;
\ No newline at end of file